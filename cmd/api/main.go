@@ -32,8 +32,11 @@ func main() {
 	businessContainer := container.NewBusinessContainer(
 		appContainer.DB,
 		appContainer.Cache,
-		appContainer.FCMClient,
+		appContainer.PubSub,
+		appContainer.PushDriver,
+		appContainer.AuditCheckpoints,
 		appContainer.Config.CORS,
+		appContainer.Config.Notification,
 	)
 
 	// Initialize Server with containers
@@ -0,0 +1,70 @@
+// Command rbac applies an RBAC manifest of permissions, shared roles and shared menus.
+//
+//	go run ./cmd/rbac sync                        apply the configured manifest (RBAC_MANIFEST_FILE)
+//	go run ./cmd/rbac sync -file rbac.json        apply another manifest
+//	go run ./cmd/rbac sync -dry-run               print the changes without writing them
+//	go run ./cmd/rbac sync -prune                 also delete entries missing from the manifest
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"usermanagement-api/config"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/pkg/database"
+	"usermanagement-api/pkg/logger"
+	"usermanagement-api/pkg/rbac"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "sync" {
+		fmt.Fprintln(os.Stderr, "usage: rbac sync [-file path] [-dry-run] [-prune]")
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		os.Exit(1)
+	}
+
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	file := flags.String("file", cfg.RBAC.ManifestFile, "manifest file, YAML or JSON")
+	dryRun := flags.Bool("dry-run", false, "print the changes without writing them")
+	prune := flags.Bool("prune", cfg.RBAC.Prune, "delete permissions, shared roles and shared menus missing from the manifest")
+	_ = flags.Parse(os.Args[2:])
+
+	if err := logger.Initialize(cfg.Logger); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to initialize logger:", err)
+		os.Exit(1)
+	}
+	log := logger.GetLogger()
+	defer logger.Sync()
+
+	manifest, err := rbac.Load(*file)
+	if err != nil {
+		log.Fatal("Failed to load RBAC manifest", zap.Error(err))
+	}
+
+	db, err := database.ConnectDB(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+
+	changes, err := rbac.Sync(db, manifest, rbac.Options{
+		DryRun:               *dryRun,
+		Prune:                *prune,
+		ProtectedPermissions: constants.BuiltinPermissionNames(),
+		ProtectedRoles:       []string{constants.AdminRoleName},
+	}, log)
+	if err != nil {
+		log.Fatal("Failed to sync RBAC manifest", zap.Error(err))
+	}
+
+	output, _ := json.MarshalIndent(changes, "", "  ")
+	fmt.Println(string(output))
+}
@@ -10,20 +10,27 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Email    EmailConfig
-	Redis    RedisConfig
-	Firebase FirebaseConfig
-	CORS     CORSConfig
-	Logger   logger.Config
+	Server       ServerConfig
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	Email        EmailConfig
+	Redis        RedisConfig
+	Firebase     FirebaseConfig
+	Push         PushConfig
+	Notification NotificationConfig
+	Audit        AuditConfig
+	RBAC         RBACConfig
+	CORS         CORSConfig
+	Logger       logger.Config
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
 	Port int
 	Host string
+	// TrustedProxies are the proxy IPs or CIDRs whose X-Forwarded-For header is believed,
+	// none by default so the client IP is the remote address of the connection
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds database-related configuration
@@ -39,10 +46,10 @@ type DatabaseConfig struct {
 
 // JWTConfig holds JWT-related configuration
 type JWTConfig struct {
-	Secret                string
-	RefreshTokenSecret    string
-	Expiration            int // in hours
-	AccessTokenExpiration int // in hours
+	Secret                 string
+	RefreshTokenSecret     string
+	Expiration             int // in hours
+	AccessTokenExpiration  int // in hours
 	RefreshTokenExpiration int // in hours
 }
 
@@ -67,6 +74,31 @@ type FirebaseConfig struct {
 	CredentialsFile string
 }
 
+// PushConfig holds push notification driver configuration
+type PushConfig struct {
+	Driver   string // fcm, log, file, memory or disabled; empty picks fcm when credentials are set
+	FilePath string // output file of the file driver
+}
+
+// NotificationConfig holds in-app notification configuration
+type NotificationConfig struct {
+	StreamHeartbeat int // in seconds
+}
+
+// AuditConfig holds audit log checkpoint configuration
+type AuditConfig struct {
+	CheckpointFile     string
+	CheckpointSecret   string // HMAC key for checkpoints, checkpoints are disabled when empty
+	CheckpointInterval int    // in minutes
+}
+
+// RBACConfig holds the RBAC manifest applied at startup
+type RBACConfig struct {
+	ManifestFile  string
+	SyncOnStartup bool // apply ManifestFile after migrations
+	Prune         bool // delete permissions, shared roles and shared menus missing from the manifest
+}
+
 // CORSConfig holds CORS-related configuration
 type CORSConfig struct {
 	AllowedOrigins   []string
@@ -128,6 +160,19 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	trustedProxiesStr := v.GetString("server.trusted_proxies")
+	if trustedProxiesStr == "" {
+		trustedProxiesStr = v.GetString("SERVER_TRUSTED_PROXIES")
+	}
+	var trustedProxies []string
+	if trustedProxiesStr != "" {
+		for _, proxy := range strings.Split(trustedProxiesStr, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				trustedProxies = append(trustedProxies, proxy)
+			}
+		}
+	}
+
 	// Load database config
 	dbPort := v.GetInt("database.port")
 	if dbPort == 0 {
@@ -252,6 +297,70 @@ func LoadConfig() (*Config, error) {
 		firebaseCredentialsFile = v.GetString("FIREBASE_CREDENTIALS_FILE")
 	}
 
+	// Load push config
+	pushDriver := v.GetString("push.driver")
+	if pushDriver == "" {
+		pushDriver = v.GetString("PUSH_DRIVER")
+	}
+
+	pushFilePath := v.GetString("push.file_path")
+	if pushFilePath == "" {
+		pushFilePath = v.GetString("PUSH_FILE_PATH")
+		if pushFilePath == "" {
+			pushFilePath = "push-notifications.jsonl"
+		}
+	}
+
+	// Load notification config
+	streamHeartbeat := v.GetInt("notification.stream_heartbeat")
+	if streamHeartbeat == 0 {
+		streamHeartbeat = v.GetInt("NOTIFICATION_STREAM_HEARTBEAT")
+		if streamHeartbeat == 0 {
+			streamHeartbeat = 15 // must stay below the proxy read timeout
+		}
+	}
+
+	// Load audit config
+	auditCheckpointFile := v.GetString("audit.checkpoint_file")
+	if auditCheckpointFile == "" {
+		auditCheckpointFile = v.GetString("AUDIT_CHECKPOINT_FILE")
+		if auditCheckpointFile == "" {
+			auditCheckpointFile = "audit-checkpoints.jsonl"
+		}
+	}
+
+	auditCheckpointSecret := v.GetString("audit.checkpoint_secret")
+	if auditCheckpointSecret == "" {
+		auditCheckpointSecret = v.GetString("AUDIT_CHECKPOINT_SECRET")
+	}
+
+	auditCheckpointInterval := v.GetInt("audit.checkpoint_interval")
+	if auditCheckpointInterval == 0 {
+		auditCheckpointInterval = v.GetInt("AUDIT_CHECKPOINT_INTERVAL")
+		if auditCheckpointInterval == 0 {
+			auditCheckpointInterval = 60 // hourly
+		}
+	}
+
+	// Load RBAC manifest config
+	rbacManifestFile := v.GetString("rbac.manifest_file")
+	if rbacManifestFile == "" {
+		rbacManifestFile = v.GetString("RBAC_MANIFEST_FILE")
+		if rbacManifestFile == "" {
+			rbacManifestFile = "rbac.yaml"
+		}
+	}
+
+	rbacSyncOnStartup := v.GetBool("rbac.sync_on_startup")
+	if !v.IsSet("rbac.sync_on_startup") {
+		rbacSyncOnStartup = v.GetString("RBAC_SYNC_ON_STARTUP") == "true"
+	}
+
+	rbacPrune := v.GetBool("rbac.prune")
+	if !v.IsSet("rbac.prune") {
+		rbacPrune = v.GetString("RBAC_PRUNE") == "true"
+	}
+
 	// Load CORS config
 	corsOriginsStr := v.GetString("cors.allowed_origins")
 	if corsOriginsStr == "" {
@@ -337,8 +446,9 @@ func LoadConfig() (*Config, error) {
 
 	return &Config{
 		Server: ServerConfig{
-			Port: port,
-			Host: host,
+			Port:           port,
+			Host:           host,
+			TrustedProxies: trustedProxies,
 		},
 		Database: DatabaseConfig{
 			Host:     dbHost,
@@ -350,10 +460,10 @@ func LoadConfig() (*Config, error) {
 			LogLevel: dbLogLevel,
 		},
 		JWT: JWTConfig{
-			Secret:                jwtSecret,
-			RefreshTokenSecret:    refreshTokenSecret,
-			Expiration:            jwtExpiration,
-			AccessTokenExpiration: accessTokenExpiration,
+			Secret:                 jwtSecret,
+			RefreshTokenSecret:     refreshTokenSecret,
+			Expiration:             jwtExpiration,
+			AccessTokenExpiration:  accessTokenExpiration,
 			RefreshTokenExpiration: refreshTokenExpiration,
 		},
 		Email: EmailConfig{
@@ -371,6 +481,23 @@ func LoadConfig() (*Config, error) {
 		Firebase: FirebaseConfig{
 			CredentialsFile: firebaseCredentialsFile,
 		},
+		Push: PushConfig{
+			Driver:   pushDriver,
+			FilePath: pushFilePath,
+		},
+		Notification: NotificationConfig{
+			StreamHeartbeat: streamHeartbeat,
+		},
+		Audit: AuditConfig{
+			CheckpointFile:     auditCheckpointFile,
+			CheckpointSecret:   auditCheckpointSecret,
+			CheckpointInterval: auditCheckpointInterval,
+		},
+		RBAC: RBACConfig{
+			ManifestFile:  rbacManifestFile,
+			SyncOnStartup: rbacSyncOnStartup,
+			Prune:         rbacPrune,
+		},
 		CORS: CORSConfig{
 			AllowedOrigins:   corsOrigins,
 			AllowCredentials: allowCredentials,
@@ -389,4 +516,4 @@ func (c *DatabaseConfig) GetDSN() string {
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode,
 	)
-}
\ No newline at end of file
+}
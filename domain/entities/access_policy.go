@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessPolicy is an attribute-based rule evaluated on top of role permissions
+type AccessPolicy struct {
+	ID          uuid.UUID               `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name        string                  `gorm:"not null;uniqueIndex:idx_access_policies_organization_name,priority:2;uniqueIndex:idx_access_policies_shared_name,where:organization_id IS NULL" json:"name"`
+	Description string                  `json:"description"`
+	Effect      string                  `gorm:"not null" json:"effect"` // allow or deny
+	Actions     []string                `gorm:"serializer:json" json:"actions"`
+	Conditions  []AccessPolicyCondition `gorm:"serializer:json" json:"conditions"`
+	Enabled     bool                    `gorm:"default:true" json:"enabled"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+
+	// OrganizationID scopes the policy to one tenant, nil policies apply in every organization.
+	// Names are unique per organization and among shared policies.
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_access_policies_organization_name,priority:1" json:"organization_id"`
+}
+
+// AppliesIn reports whether the policy is evaluated for callers acting in the organization
+func (p *AccessPolicy) AppliesIn(organizationID *uuid.UUID) bool {
+	return p.OrganizationID == nil || (organizationID != nil && *p.OrganizationID == *organizationID)
+}
+
+// AccessPolicyCondition compares an attribute with a literal value or another attribute
+type AccessPolicyCondition struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Value     interface{} `json:"value,omitempty"`
+	ValueFrom string      `json:"value_from,omitempty"`
+}
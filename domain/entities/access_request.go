@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessRequest is a user's request for a role, decided by one of the role's approvers
+type AccessRequest struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User          *User     `json:"user,omitempty"`
+	RoleID        uuid.UUID `gorm:"type:uuid;not null;index" json:"role_id"`
+	Role          *Role     `json:"role,omitempty"`
+	Justification string    `gorm:"not null" json:"justification"`
+	Status        string    `gorm:"not null;index" json:"status"`
+	// RoleExpiresAt limits the role once granted, nil asks for a permanent grant
+	RoleExpiresAt *time.Time `json:"role_expires_at"`
+	// ExpiresAt is when a request still pending expires
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`
+	DecidedBy    *uuid.UUID `gorm:"type:uuid" json:"decided_by"`
+	DecidedAt    *time.Time `json:"decided_at"`
+	DecisionNote string     `json:"decision_note"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// OrganizationID is the organization the role is requested in, see UserRole.OrganizationID
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000000'" json:"organization_id"`
+}
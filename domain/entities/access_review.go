@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessReviewCampaign asks reviewers to re-certify a set of role assignments. Revoke decisions
+// take effect when the campaign is closed.
+type AccessReviewCampaign struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `json:"description"`
+	Status      string    `gorm:"not null;index" json:"status"`
+	// DueAt is when undecided items escalate to EscalationReviewerID
+	DueAt                time.Time  `gorm:"not null;index" json:"due_at"`
+	EscalationReviewerID uuid.UUID  `gorm:"type:uuid;not null" json:"escalation_reviewer_id"`
+	EscalationReviewer   *User      `json:"escalation_reviewer,omitempty"`
+	RevokeUndecided      bool       `gorm:"not null;default:false" json:"revoke_undecided"`
+	CreatedBy            *uuid.UUID `gorm:"type:uuid" json:"created_by"`
+	ClosedBy             *uuid.UUID `gorm:"type:uuid" json:"closed_by"`
+	ClosedAt             *time.Time `json:"closed_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// AccessReviewItem is one user's assignment to one role, to be kept or revoked by its reviewer
+type AccessReviewItem struct {
+	ID         uuid.UUID             `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CampaignID uuid.UUID             `gorm:"type:uuid;not null;uniqueIndex:idx_access_review_assignment" json:"campaign_id"`
+	Campaign   *AccessReviewCampaign `json:"campaign,omitempty"`
+	UserID     uuid.UUID             `gorm:"type:uuid;not null;uniqueIndex:idx_access_review_assignment" json:"user_id"`
+	User       *User                 `json:"user,omitempty"`
+	RoleID     uuid.UUID             `gorm:"type:uuid;not null;uniqueIndex:idx_access_review_assignment" json:"role_id"`
+	Role       *Role                 `json:"role,omitempty"`
+	ReviewerID uuid.UUID             `gorm:"type:uuid;not null;index" json:"reviewer_id"`
+	Reviewer   *User                 `json:"reviewer,omitempty"`
+	Decision   string                `gorm:"not null;index" json:"decision"`
+	Comment    string                `json:"comment"`
+	DecidedAt  *time.Time            `json:"decided_at"`
+	// EscalatedAt is set when the item was reassigned to the escalation reviewer after the due date
+	EscalatedAt *time.Time `json:"escalated_at"`
+	// RevokedAt is set when closing the campaign removed the assignment
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// OrganizationID is the organization the reviewed role was assigned in, see UserRole.OrganizationID
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000000';uniqueIndex:idx_access_review_assignment" json:"organization_id"`
+}
@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrAuditImmutable is returned when anything tries to change or remove a stored audit event
+var ErrAuditImmutable = errors.New("audit events are append-only and cannot be modified or deleted")
+
+// AuditEvent records one administrative change: who did what to which record, and how it changed.
+// Events form a hash chain: each one includes the hash of the event before it, so editing
+// or removing any stored event breaks every hash after it.
+type AuditEvent struct {
+	ID             uuid.UUID              `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Sequence       int64                  `gorm:"uniqueIndex" json:"sequence"`
+	ActorID        *uuid.UUID             `gorm:"type:uuid;index" json:"actor_id"`
+	ImpersonatorID *uuid.UUID             `gorm:"type:uuid;index" json:"impersonator_id"`
+	Action         string                 `gorm:"not null;index" json:"action"` // e.g. user.update
+	TargetType     string                 `gorm:"not null;index:idx_audit_events_target" json:"target_type"`
+	TargetID       string                 `gorm:"index:idx_audit_events_target" json:"target_id"`
+	Before         map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"before"`
+	After          map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"after"`
+	Changes        map[string]AuditChange `gorm:"type:jsonb;serializer:json" json:"changes"`
+	IP             string                 `json:"ip"`
+	UserAgent      string                 `json:"user_agent"`
+	RequestID      string                 `gorm:"index" json:"request_id"`
+	CreatedAt      time.Time              `gorm:"index" json:"created_at"`
+	PrevHash       string                 `json:"prev_hash"`
+	Hash           string                 `gorm:"index" json:"hash"`
+	Actor          *User                  `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+
+	// OrganizationID is the organization the actor acted in, nil for changes made outside any
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index" json:"organization_id"`
+}
+
+// AuditChange is the old and new value of a single changed field
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ComputeHash returns the SHA-256 of the event's content and PrevHash.
+// CreatedAt must already be truncated to the database precision (microseconds).
+func (e *AuditEvent) ComputeHash() string {
+	// encoding/json sorts map keys, so the payload is stable across reads. organization_id is
+	// left out when empty, so events written before it existed still verify.
+	payload, _ := json.Marshal(struct {
+		Sequence       int64                  `json:"sequence"`
+		ID             uuid.UUID              `json:"id"`
+		ActorID        *uuid.UUID             `json:"actor_id"`
+		OrganizationID *uuid.UUID             `json:"organization_id,omitempty"`
+		ImpersonatorID *uuid.UUID             `json:"impersonator_id"`
+		Action         string                 `json:"action"`
+		TargetType     string                 `json:"target_type"`
+		TargetID       string                 `json:"target_id"`
+		Before         map[string]interface{} `json:"before"`
+		After          map[string]interface{} `json:"after"`
+		Changes        map[string]AuditChange `json:"changes"`
+		IP             string                 `json:"ip"`
+		UserAgent      string                 `json:"user_agent"`
+		RequestID      string                 `json:"request_id"`
+		CreatedAt      string                 `json:"created_at"`
+		PrevHash       string                 `json:"prev_hash"`
+	}{
+		Sequence:       e.Sequence,
+		ID:             e.ID,
+		ActorID:        e.ActorID,
+		OrganizationID: e.OrganizationID,
+		ImpersonatorID: e.ImpersonatorID,
+		Action:         e.Action,
+		TargetType:     e.TargetType,
+		TargetID:       e.TargetID,
+		Before:         e.Before,
+		After:          e.After,
+		Changes:        e.Changes,
+		IP:             e.IP,
+		UserAgent:      e.UserAgent,
+		RequestID:      e.RequestID,
+		CreatedAt:      e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		PrevHash:       e.PrevHash,
+	})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// BeforeUpdate blocks updates through gorm, the database trigger blocks everything else
+func (e *AuditEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditImmutable
+}
+
+// BeforeDelete blocks deletes through gorm, the database trigger blocks everything else
+func (e *AuditEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditImmutable
+}
@@ -0,0 +1,95 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testAuditEvent() *AuditEvent {
+	actorID := uuid.MustParse("6f1c2d3e-4b5a-4c7d-8e9f-0a1b2c3d4e5f")
+	impersonatorID := uuid.MustParse("3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f")
+	return &AuditEvent{
+		ID:             uuid.MustParse("0b7e3c4d-5f6a-4b8c-9d0e-1f2a3b4c5d6e"),
+		Sequence:       42,
+		ActorID:        &actorID,
+		ImpersonatorID: &impersonatorID,
+		Action:         "user.update",
+		TargetType:     "user",
+		TargetID:       "8a9b0c1d-2e3f-4a5b-6c7d-8e9f0a1b2c3d",
+		Before:         map[string]interface{}{"name": "Old", "email": "old@example.com"},
+		After:          map[string]interface{}{"name": "New", "email": "old@example.com"},
+		Changes:        map[string]AuditChange{"name": {From: "Old", To: "New"}},
+		IP:             "192.0.2.1",
+		UserAgent:      "curl/8.0",
+		RequestID:      "req-1",
+		CreatedAt:      time.Date(2024, 5, 1, 12, 30, 15, 123456000, time.UTC),
+		PrevHash:       "previous",
+	}
+}
+
+func TestAuditEventComputeHashIsStable(t *testing.T) {
+	// Pinned so a change to the hashed payload, which would break every stored chain, fails here
+	const want = "19bc78178c0d698ecd372dacb901216a44a62bb51837aae80cabb5f3d40dd217"
+
+	if got := testAuditEvent().ComputeHash(); got != want {
+		t.Errorf("ComputeHash() = %s, want %s", got, want)
+	}
+}
+
+func TestAuditEventComputeHashIgnoresLocation(t *testing.T) {
+	event := testAuditEvent()
+	want := event.ComputeHash()
+
+	event.CreatedAt = event.CreatedAt.In(time.FixedZone("UTC+7", 7*60*60))
+	if got := event.ComputeHash(); got != want {
+		t.Errorf("ComputeHash() changed with the time zone of CreatedAt")
+	}
+}
+
+func TestAuditEventComputeHashCoversContent(t *testing.T) {
+	want := testAuditEvent().ComputeHash()
+
+	tests := []struct {
+		name   string
+		change func(e *AuditEvent)
+	}{
+		{"sequence", func(e *AuditEvent) { e.Sequence++ }},
+		{"id", func(e *AuditEvent) { e.ID = uuid.New() }},
+		{"actor", func(e *AuditEvent) { e.ActorID = nil }},
+		{"impersonator", func(e *AuditEvent) { e.ImpersonatorID = nil }},
+		{"organization", func(e *AuditEvent) { id := uuid.New(); e.OrganizationID = &id }},
+		{"action", func(e *AuditEvent) { e.Action = "user.delete" }},
+		{"target", func(e *AuditEvent) { e.TargetID = "other" }},
+		{"before", func(e *AuditEvent) { e.Before["name"] = "Forged" }},
+		{"after", func(e *AuditEvent) { delete(e.After, "email") }},
+		{"changes", func(e *AuditEvent) { e.Changes["name"] = AuditChange{From: "Old", To: "Forged"} }},
+		{"ip", func(e *AuditEvent) { e.IP = "192.0.2.2" }},
+		{"user agent", func(e *AuditEvent) { e.UserAgent = "" }},
+		{"request id", func(e *AuditEvent) { e.RequestID = "req-2" }},
+		{"created at", func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+		{"prev hash", func(e *AuditEvent) { e.PrevHash = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testAuditEvent()
+			tt.change(event)
+			if event.ComputeHash() == want {
+				t.Errorf("ComputeHash() did not change when the %s changed", tt.name)
+			}
+		})
+	}
+}
+
+func TestAuditEventComputeHashExcludesStoredFields(t *testing.T) {
+	want := testAuditEvent().ComputeHash()
+
+	event := testAuditEvent()
+	event.Hash = "anything"
+	event.Actor = &User{Username: "loaded"}
+	if got := event.ComputeHash(); got != want {
+		t.Errorf("ComputeHash() depends on Hash or the preloaded Actor")
+	}
+}
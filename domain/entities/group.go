@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Group collects users so roles can be assigned to all of them at once. Members of a group
+// are members of its parent groups as well and receive their roles too.
+type Group struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name        string    `gorm:"not null;uniqueIndex:idx_groups_organization_name,priority:2;uniqueIndex:idx_groups_shared_name,where:organization_id IS NULL" json:"name"`
+	Description string    `json:"description"`
+	Members     []*User   `gorm:"many2many:group_members;" json:"members,omitempty"`
+	Roles       []*Role   `gorm:"many2many:group_roles;" json:"roles,omitempty"`
+	// Parents are the groups this group is nested in
+	Parents   []*Group       `gorm:"many2many:group_parents;joinForeignKey:GroupID;joinReferences:ParentID" json:"parents,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// OrganizationID scopes the group to one tenant, nil groups are shared by every organization.
+	// Names are unique per organization and among shared groups.
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_groups_organization_name,priority:1" json:"organization_id"`
+}
+
+// AppliesIn reports whether members receive the group's roles while acting in the organization
+func (g *Group) AppliesIn(organizationID *uuid.UUID) bool {
+	return g.OrganizationID == nil || (organizationID != nil && *g.OrganizationID == *organizationID)
+}
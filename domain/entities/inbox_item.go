@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboxItem is an in-app notification shown in the user's inbox.
+// The numeric ID doubles as the SSE event ID so clients can resume with Last-Event-ID.
+type InboxItem struct {
+	ID             uint              `gorm:"primaryKey" json:"id"`
+	UserID         uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	NotificationID *uuid.UUID        `gorm:"type:uuid;index" json:"notification_id"`
+	Title          string            `gorm:"not null" json:"title"`
+	Body           string            `json:"body"`
+	Data           map[string]string `gorm:"serializer:json" json:"data"`
+	ReadAt         *time.Time        `json:"read_at"`
+	CreatedAt      time.Time         `json:"created_at"`
+	User           *User             `gorm:"foreignKey:UserID;references:ID" json:"-"`
+}
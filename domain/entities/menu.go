@@ -22,4 +22,7 @@ type Menu struct {
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// OrganizationID scopes the menu to one tenant, nil menus are shared by every organization
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index" json:"organization_id"`
 }
@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is a single send request, kept so its delivery can be reported on later
+type Notification struct {
+	ID             uuid.UUID               `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	SenderID       *uuid.UUID              `gorm:"type:uuid;index" json:"sender_id"`
+	Title          string                  `gorm:"not null" json:"title"`
+	Body           string                  `json:"body"`
+	Data           map[string]string       `gorm:"serializer:json" json:"data"`
+	Category       string                  `gorm:"not null;default:general;index" json:"category"`
+	Critical       bool                    `gorm:"default:false" json:"critical"` // bypasses mutes and quiet hours
+	TargetType     string                  `gorm:"not null" json:"target_type"`   // user, users, topic or all
+	Topic          string                  `json:"topic"`
+	RecipientCount int                     `json:"recipient_count"`
+	Deliveries     []*NotificationDelivery `gorm:"foreignKey:NotificationID" json:"deliveries,omitempty"`
+	CreatedAt      time.Time               `json:"created_at"`
+}
+
+// NotificationDelivery tracks the outcome of a notification for one recipient on one channel/device
+type NotificationDelivery struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	NotificationID uuid.UUID  `gorm:"type:uuid;not null;index" json:"notification_id"`
+	UserID         *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	Channel        string     `gorm:"not null" json:"channel"` // push or in_app
+	DeviceToken    string     `json:"-"`
+	Status         string     `gorm:"not null;index" json:"status"`
+	ErrorCode      string     `json:"error_code"`
+	ErrorMessage   string     `json:"error_message"`
+	MessageID      string     `json:"message_id"`
+	DeliverAfter   *time.Time `gorm:"index" json:"deliver_after"` // set while deferred by quiet hours
+	SentAt         *time.Time `json:"sent_at"`
+	OpenedAt       *time.Time `json:"opened_at"`
+	ReadAt         *time.Time `json:"read_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
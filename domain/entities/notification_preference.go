@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPreference holds what a user wants to receive, and when.
+// Users without a row get the defaults from DefaultNotificationPreference.
+type NotificationPreference struct {
+	UserID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	MutedCategories   []string  `gorm:"serializer:json" json:"muted_categories"`
+	Channels          []string  `gorm:"serializer:json" json:"channels"` // enabled channels: push, in_app
+	QuietHoursEnabled bool      `gorm:"default:false" json:"quiet_hours_enabled"`
+	QuietHoursStart   string    `json:"quiet_hours_start"` // HH:MM in Timezone
+	QuietHoursEnd     string    `json:"quiet_hours_end"`   // HH:MM in Timezone, may be before start to span midnight
+	QuietHoursMode    string    `json:"quiet_hours_mode"`  // defer or suppress
+	Timezone          string    `json:"timezone"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	User              User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// DefaultNotificationPreference returns the preference applied to users who never saved one
+func DefaultNotificationPreference(userID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:          userID,
+		MutedCategories: []string{},
+		Channels:        []string{"push", "in_app"},
+		QuietHoursMode:  "defer",
+		Timezone:        "UTC",
+	}
+}
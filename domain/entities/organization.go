@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Organization is a tenant. Users can belong to several organizations and act in one at a time.
+type Organization struct {
+	ID          uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name        string         `gorm:"unique;not null" json:"name"`
+	Slug        string         `gorm:"unique;not null" json:"slug"`
+	Description string         `json:"description"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// OrganizationMember is a user's membership in an organization
+type OrganizationMember struct {
+	OrganizationID uuid.UUID     `gorm:"type:uuid;primaryKey" json:"organization_id"`
+	UserID         uuid.UUID     `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	Organization   *Organization `json:"organization,omitempty"`
+	User           *User         `json:"user,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// OrganizationSetting overrides a global setting inside one organization
+type OrganizationSetting struct {
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey" json:"organization_id"`
+	Key            string    `gorm:"primaryKey" json:"key"`
+	Value          string    `json:"value"`
+}
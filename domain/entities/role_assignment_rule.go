@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoleAssignmentRule grants a role to every user whose attribute equals one of its values. The
+// assignments it makes are tagged with the rule and revoked once the user no longer matches.
+type RoleAssignmentRule struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name        string    `gorm:"unique;not null" json:"name"`
+	Description string    `json:"description"`
+	// Attribute is "email_domain" or "meta.<key>"
+	Attribute string         `gorm:"not null" json:"attribute"`
+	Values    []string       `gorm:"serializer:json" json:"values"`
+	RoleID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"role_id"`
+	Role      *Role          `json:"role,omitempty"`
+	Enabled   bool           `gorm:"default:true" json:"enabled"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
@@ -8,12 +8,33 @@ import (
 )
 
 type Role struct {
-	ID          uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Name        string         `gorm:"unique;not null" json:"name"`
-	Description string         `json:"description"`
-	Users       []*User        `gorm:"many2many:user_roles;" json:"users,omitempty"`
-	Permissions []*Permission  `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uuid.UUID     `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name        string        `gorm:"not null;uniqueIndex:idx_roles_organization_name,priority:2;uniqueIndex:idx_roles_shared_name,where:organization_id IS NULL" json:"name"`
+	Description string        `json:"description"`
+	Users       []*User       `gorm:"many2many:user_roles;" json:"users,omitempty"`
+	Permissions []*Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
+	// DeniedPermissions are withheld from holders of this role (and its descendants) even when granted elsewhere
+	DeniedPermissions []*Permission `gorm:"many2many:role_denied_permissions;" json:"denied_permissions,omitempty"`
+	// Parents are the roles whose permissions this role inherits
+	Parents   []*Role        `gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID" json:"parents,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// OrganizationID scopes the role to one tenant, nil roles are shared by every organization.
+	// Names are unique per organization and among shared roles.
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_roles_organization_name,priority:1" json:"organization_id"`
+
+	// Approvers decide access requests for the role
+	Approvers []*User `gorm:"many2many:role_approvers;" json:"approvers,omitempty"`
+
+	// ManageableUserScope makes the role a delegated admin grant: its holders may only assign
+	// ManageableRoles, to users within the scope ("organization" or "group"). Empty for other roles.
+	ManageableUserScope string  `gorm:"size:20;not null;default:''" json:"manageable_user_scope"`
+	ManageableRoles     []*Role `gorm:"many2many:role_manageable_roles;joinForeignKey:RoleID;joinReferences:ManageableRoleID" json:"manageable_roles,omitempty"`
+}
+
+// AppliesIn reports whether holders of the role get its permissions while acting in the organization
+func (r *Role) AppliesIn(organizationID *uuid.UUID) bool {
+	return r.OrganizationID == nil || (organizationID != nil && *r.OrganizationID == *organizationID)
 }
@@ -20,4 +20,24 @@ type User struct {
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// DeniedPermissions are withheld from the user whatever their roles grant
+	DeniedPermissions []*Permission `gorm:"many2many:user_denied_permissions;" json:"denied_permissions,omitempty"`
+
+	// RoleAssignments are the assignments behind Roles, filled along with them
+	RoleAssignments []*UserRole `gorm:"-" json:"-"`
+
+	// EmailVerifiedAt is set once an administrator confirmed the user owns the email address
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+// RolesIn returns the roles whose assignment and role both apply while acting in the organization
+func (u *User) RolesIn(organizationID *uuid.UUID) []*Role {
+	var roles []*Role
+	for _, assignment := range u.RoleAssignments {
+		if assignment.Role != nil && assignment.AppliesIn(organizationID) && assignment.Role.AppliesIn(organizationID) {
+			roles = append(roles, assignment.Role)
+		}
+	}
+	return roles
 }
@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+// UserMetaKeyPolicy controls who may read and write a user meta key, see constants.MetaVisibility*
+type UserMetaKeyPolicy struct {
+	Key        string    `gorm:"primaryKey" json:"key"`
+	Visibility string    `gorm:"not null;default:default" json:"visibility"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
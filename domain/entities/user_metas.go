@@ -8,4 +8,6 @@ type UserMeta struct {
 	Value  string    `json:"value"`
 	UserID uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
 	Users  *User     `gorm:"foreignKey:UserID;references:ID" json:"-"`
+	// UpdatedBy is the user who last wrote the value, nil when it was written by a background task
+	UpdatedBy *uuid.UUID `gorm:"type:uuid" json:"-"`
 }
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserRole is a user's assignment to a role. Assignments without StartsAt and ExpiresAt are
+// permanent; otherwise they only count inside that window.
+type UserRole struct {
+	UserID    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	RoleID    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"role_id"`
+	Role      *Role      `json:"role,omitempty"`
+	GrantedBy *uuid.UUID `gorm:"type:uuid" json:"granted_by"`
+	GrantedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"granted_at"`
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"`
+	Reason    string     `json:"reason"`
+	// RuleID tags assignments made by a role assignment rule, nil for manual ones
+	RuleID *uuid.UUID `gorm:"type:uuid;index" json:"rule_id"`
+	// OrganizationID is the organization the role was assigned in, the assignment only counts
+	// there. uuid.Nil assignments were made outside any organization and count in every one.
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey;not null;default:'00000000-0000-0000-0000-000000000000'" json:"organization_id"`
+}
+
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// AppliesIn reports whether the assignment counts while acting in the organization. The role
+// itself must apply there too, see Role.AppliesIn.
+func (a *UserRole) AppliesIn(organizationID *uuid.UUID) bool {
+	return a.OrganizationID == uuid.Nil || (organizationID != nil && a.OrganizationID == *organizationID)
+}
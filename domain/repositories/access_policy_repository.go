@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AccessPolicyRepository interface {
+	// WithContext returns a repository whose reads are limited to the tenant of ctx
+	WithContext(ctx context.Context) AccessPolicyRepository
+	Create(policy *entities.AccessPolicy) error
+	FindByID(id uuid.UUID) (*entities.AccessPolicy, error)
+	FindByName(name string, organizationID *uuid.UUID) (*entities.AccessPolicy, error)
+	FindAll(page, pageSize int) ([]*entities.AccessPolicy, int64, error)
+	FindEnabled() ([]*entities.AccessPolicy, error)
+	Update(policy *entities.AccessPolicy) error
+	Delete(id uuid.UUID) error
+}
+
+type accessPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessPolicyRepository(db *gorm.DB) AccessPolicyRepository {
+	return &accessPolicyRepository{db}
+}
+
+func (r *accessPolicyRepository) WithContext(ctx context.Context) AccessPolicyRepository {
+	return &accessPolicyRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *accessPolicyRepository) Create(policy *entities.AccessPolicy) error {
+	return r.db.Create(policy).Error
+}
+
+func (r *accessPolicyRepository) FindByID(id uuid.UUID) (*entities.AccessPolicy, error) {
+	var policy entities.AccessPolicy
+	if err := r.db.First(&policy, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindByName returns a policy named name that would be visible next to a policy of the
+// organization: a shared policy or one of the organization. A shared policy (nil organizationID)
+// is visible in every organization, so any policy named name is returned for it.
+func (r *accessPolicyRepository) FindByName(name string, organizationID *uuid.UUID) (*entities.AccessPolicy, error) {
+	query := r.db.Where("name = ?", name)
+	if organizationID != nil {
+		query = query.Where("organization_id IS NULL OR organization_id = ?", *organizationID)
+	}
+
+	var policy entities.AccessPolicy
+	if err := query.First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *accessPolicyRepository) FindAll(page, pageSize int) ([]*entities.AccessPolicy, int64, error) {
+	var policies []*entities.AccessPolicy
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	if err := r.db.Model(&entities.AccessPolicy{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Order("name").Offset(offset).Limit(pageSize).Find(&policies).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return policies, count, nil
+}
+
+func (r *accessPolicyRepository) FindEnabled() ([]*entities.AccessPolicy, error) {
+	var policies []*entities.AccessPolicy
+	err := r.db.Where("enabled = ?", true).Order("name").Find(&policies).Error
+	return policies, err
+}
+
+func (r *accessPolicyRepository) Update(policy *entities.AccessPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r *accessPolicyRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&entities.AccessPolicy{}, "id = ?", id).Error
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"time"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AccessRequestRepository interface {
+	Create(request *entities.AccessRequest) error
+	FindByID(id uuid.UUID) (*entities.AccessRequest, error)
+	FindPending(userID, roleID uuid.UUID) (*entities.AccessRequest, error)
+	FindByUserID(userID uuid.UUID, status string, page, pageSize int) ([]*entities.AccessRequest, int64, error)
+	FindByRoleIDs(roleIDs []uuid.UUID, status string, page, pageSize int) ([]*entities.AccessRequest, int64, error)
+	FindExpiredPending(at time.Time) ([]*entities.AccessRequest, error)
+	Transition(request *entities.AccessRequest, from string) error
+}
+
+type accessRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessRequestRepository(db *gorm.DB) AccessRequestRepository {
+	return &accessRequestRepository{db}
+}
+
+func (r *accessRequestRepository) Create(request *entities.AccessRequest) error {
+	return r.db.Omit("User", "Role").Create(request).Error
+}
+
+func (r *accessRequestRepository) FindByID(id uuid.UUID) (*entities.AccessRequest, error) {
+	var request entities.AccessRequest
+	if err := r.db.Preload("User").Preload("Role").First(&request, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindPending returns the user's open request for the role
+func (r *accessRequestRepository) FindPending(userID, roleID uuid.UUID) (*entities.AccessRequest, error) {
+	var request entities.AccessRequest
+	err := r.db.Where("user_id = ? AND role_id = ? AND status = ?", userID, roleID, "pending").
+		First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindByUserID lists the user's requests, newest first. An empty status matches every status.
+func (r *accessRequestRepository) FindByUserID(userID uuid.UUID, status string, page, pageSize int) ([]*entities.AccessRequest, int64, error) {
+	return r.findPage(r.db.Where("user_id = ?", userID), status, page, pageSize)
+}
+
+// FindByRoleIDs lists the requests for any of the roles, newest first. An empty status matches every status.
+func (r *accessRequestRepository) FindByRoleIDs(roleIDs []uuid.UUID, status string, page, pageSize int) ([]*entities.AccessRequest, int64, error) {
+	if len(roleIDs) == 0 {
+		return []*entities.AccessRequest{}, 0, nil
+	}
+	return r.findPage(r.db.Where("role_id IN ?", roleIDs), status, page, pageSize)
+}
+
+func (r *accessRequestRepository) findPage(query *gorm.DB, status string, page, pageSize int) ([]*entities.AccessRequest, int64, error) {
+	var requests []*entities.AccessRequest
+	var count int64
+
+	offset := (page - 1) * pageSize
+	query = query.Model(&entities.AccessRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("User").Preload("Role").Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return requests, count, nil
+}
+
+// FindExpiredPending returns pending requests whose deadline is at or before the given time
+func (r *accessRequestRepository) FindExpiredPending(at time.Time) ([]*entities.AccessRequest, error) {
+	var requests []*entities.AccessRequest
+	err := r.db.Preload("Role").
+		Where("status = ? AND expires_at <= ?", "pending", at).
+		Find(&requests).Error
+	return requests, err
+}
+
+// Transition saves the request's status and decision only if its stored status is still from,
+// so concurrent decisions cannot both succeed. It returns gorm.ErrRecordNotFound otherwise.
+func (r *accessRequestRepository) Transition(request *entities.AccessRequest, from string) error {
+	result := r.db.Model(&entities.AccessRequest{}).
+		Where("id = ? AND status = ?", request.ID, from).
+		Updates(map[string]interface{}{
+			"status":          request.Status,
+			"role_expires_at": request.RoleExpiresAt,
+			"decided_by":      request.DecidedBy,
+			"decided_at":      request.DecidedAt,
+			"decision_note":   request.DecisionNote,
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
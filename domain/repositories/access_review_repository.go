@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"time"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AccessReviewRepository interface {
+	CreateCampaign(campaign *entities.AccessReviewCampaign, items []*entities.AccessReviewItem) error
+	FindCampaignByID(id uuid.UUID) (*entities.AccessReviewCampaign, error)
+	FindCampaigns(page, pageSize int) ([]*entities.AccessReviewCampaign, int64, error)
+	UpdateCampaign(campaign *entities.AccessReviewCampaign) error
+	CountItemsByDecision(campaignID uuid.UUID) (map[string]int64, error)
+	FindItems(campaignID uuid.UUID, decision string, page, pageSize int) ([]*entities.AccessReviewItem, int64, error)
+	FindAllItems(campaignID uuid.UUID) ([]*entities.AccessReviewItem, error)
+	FindItemByID(id uuid.UUID) (*entities.AccessReviewItem, error)
+	FindItemsByReviewer(reviewerID uuid.UUID, decision string, page, pageSize int) ([]*entities.AccessReviewItem, int64, error)
+	FindItemsToEscalate(at time.Time) ([]*entities.AccessReviewItem, error)
+	UpdateItem(item *entities.AccessReviewItem) error
+}
+
+type accessReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessReviewRepository(db *gorm.DB) AccessReviewRepository {
+	return &accessReviewRepository{db}
+}
+
+// CreateCampaign stores the campaign and its items together
+func (r *accessReviewRepository) CreateCampaign(campaign *entities.AccessReviewCampaign, items []*entities.AccessReviewItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("EscalationReviewer").Create(campaign).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for _, item := range items {
+			item.CampaignID = campaign.ID
+		}
+		return tx.Omit("Campaign", "User", "Role", "Reviewer").CreateInBatches(items, 200).Error
+	})
+}
+
+func (r *accessReviewRepository) FindCampaignByID(id uuid.UUID) (*entities.AccessReviewCampaign, error) {
+	var campaign entities.AccessReviewCampaign
+	if err := r.db.Preload("EscalationReviewer").First(&campaign, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *accessReviewRepository) FindCampaigns(page, pageSize int) ([]*entities.AccessReviewCampaign, int64, error) {
+	var campaigns []*entities.AccessReviewCampaign
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	if err := r.db.Model(&entities.AccessReviewCampaign{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Preload("EscalationReviewer").Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&campaigns).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return campaigns, count, nil
+}
+
+func (r *accessReviewRepository) UpdateCampaign(campaign *entities.AccessReviewCampaign) error {
+	return r.db.Omit("EscalationReviewer").Save(campaign).Error
+}
+
+// CountItemsByDecision returns how many items of the campaign carry each decision
+func (r *accessReviewRepository) CountItemsByDecision(campaignID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Decision string
+		Count    int64
+	}
+	err := r.db.Model(&entities.AccessReviewItem{}).
+		Select("decision, COUNT(*) AS count").
+		Where("campaign_id = ?", campaignID).
+		Group("decision").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Decision] = row.Count
+	}
+	return counts, nil
+}
+
+// FindItems lists the campaign's items. An empty decision matches every decision.
+func (r *accessReviewRepository) FindItems(campaignID uuid.UUID, decision string, page, pageSize int) ([]*entities.AccessReviewItem, int64, error) {
+	return r.findItemPage(r.db.Where("campaign_id = ?", campaignID), decision, page, pageSize)
+}
+
+// FindAllItems returns every item of the campaign ordered by user and role
+func (r *accessReviewRepository) FindAllItems(campaignID uuid.UUID) ([]*entities.AccessReviewItem, error) {
+	var items []*entities.AccessReviewItem
+	err := r.db.Preload("User").Preload("Role").Preload("Reviewer").
+		Where("campaign_id = ?", campaignID).
+		Order("user_id, role_id").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *accessReviewRepository) FindItemByID(id uuid.UUID) (*entities.AccessReviewItem, error) {
+	var item entities.AccessReviewItem
+	if err := r.db.Preload("Campaign").Preload("User").Preload("Role").Preload("Reviewer").First(&item, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindItemsByReviewer lists the items assigned to the reviewer in open campaigns. An empty
+// decision matches every decision.
+func (r *accessReviewRepository) FindItemsByReviewer(reviewerID uuid.UUID, decision string, page, pageSize int) ([]*entities.AccessReviewItem, int64, error) {
+	query := r.db.Where("reviewer_id = ?", reviewerID).
+		Where("campaign_id IN (SELECT id FROM access_review_campaigns WHERE status = ?)", "open")
+	return r.findItemPage(query, decision, page, pageSize)
+}
+
+// FindItemsToEscalate returns undecided, not yet escalated items of open campaigns due at or before at
+func (r *accessReviewRepository) FindItemsToEscalate(at time.Time) ([]*entities.AccessReviewItem, error) {
+	var items []*entities.AccessReviewItem
+	err := r.db.Preload("Campaign").Preload("User").Preload("Role").
+		Where("decision = ? AND escalated_at IS NULL", "pending").
+		Where("campaign_id IN (SELECT id FROM access_review_campaigns WHERE status = ? AND due_at <= ?)", "open", at).
+		Find(&items).Error
+	return items, err
+}
+
+func (r *accessReviewRepository) UpdateItem(item *entities.AccessReviewItem) error {
+	return r.db.Omit("Campaign", "User", "Role", "Reviewer").Save(item).Error
+}
+
+func (r *accessReviewRepository) findItemPage(query *gorm.DB, decision string, page, pageSize int) ([]*entities.AccessReviewItem, int64, error) {
+	var items []*entities.AccessReviewItem
+	var count int64
+
+	offset := (page - 1) * pageSize
+	query = query.Model(&entities.AccessReviewItem{})
+	if decision != "" {
+		query = query.Where("decision = ?", decision)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("Campaign").Preload("User").Preload("Role").Preload("Reviewer").Order("created_at, id").Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return items, count, nil
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditFilter narrows down audit events, zero values are ignored
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+}
+
+// auditChainLockKey serializes appends to the audit chain across all API instances
+const auditChainLockKey = 7303620071
+
+type AuditRepository interface {
+	// WithContext returns a repository whose reads are limited to the tenant of ctx. Create
+	// must not be called on it, the chain head is shared by every tenant.
+	WithContext(ctx context.Context) AuditRepository
+	// Create appends the event to the hash chain, setting its ID, sequence and hashes
+	Create(event *entities.AuditEvent) error
+	FindAll(filter AuditFilter, page, pageSize int) ([]*entities.AuditEvent, int64, error)
+	FindLast() (*entities.AuditEvent, error)
+	FindBySequence(sequence int64) (*entities.AuditEvent, error)
+	FindAfterSequence(sequence int64, limit int) ([]*entities.AuditEvent, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db}
+}
+
+func (r *auditRepository) WithContext(ctx context.Context) AuditRepository {
+	return &auditRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *auditRepository) Create(event *entities.AuditEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Held until commit, so no two events can claim the same predecessor
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+			return err
+		}
+
+		var last entities.AuditEvent
+		err := tx.Order("sequence DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		event.ID = uuid.New()
+		event.Sequence = last.Sequence + 1
+		event.PrevHash = last.Hash
+		event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		event.Hash = event.ComputeHash()
+
+		return tx.Create(event).Error
+	})
+}
+
+func (r *auditRepository) FindLast() (*entities.AuditEvent, error) {
+	var event entities.AuditEvent
+	if err := r.db.Order("sequence DESC").First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *auditRepository) FindBySequence(sequence int64) (*entities.AuditEvent, error) {
+	var event entities.AuditEvent
+	if err := r.db.Where("sequence = ?", sequence).First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// FindAfterSequence returns events in chain order, starting after the given sequence
+func (r *auditRepository) FindAfterSequence(sequence int64, limit int) ([]*entities.AuditEvent, error) {
+	var events []*entities.AuditEvent
+	err := r.db.Where("sequence > ?", sequence).Order("sequence ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func (r *auditRepository) FindAll(filter AuditFilter, page, pageSize int) ([]*entities.AuditEvent, int64, error) {
+	var events []*entities.AuditEvent
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := r.db.Model(&entities.AuditEvent{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("Actor").Order("sequence DESC").Offset(offset).Limit(pageSize).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, count, nil
+}
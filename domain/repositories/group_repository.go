@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GroupRepository interface {
+	// WithContext returns a repository whose reads are limited to the tenant of ctx
+	WithContext(ctx context.Context) GroupRepository
+	Create(group *entities.Group) error
+	FindByID(id uuid.UUID) (*entities.Group, error)
+	FindByName(name string, organizationID *uuid.UUID) (*entities.Group, error)
+	FindByIDs(ids []uuid.UUID) ([]*entities.Group, error)
+	FindAll(page, pageSize int) ([]*entities.Group, int64, error)
+	Update(group *entities.Group) error
+	Delete(id uuid.UUID) error
+	AddMembers(groupID uuid.UUID, userIDs []uuid.UUID) error
+	RemoveMember(groupID, userID uuid.UUID) error
+	FindMembers(groupID uuid.UUID, page, pageSize int) ([]*entities.User, int64, error)
+	FindAllMemberIDs(groupID uuid.UUID) ([]uuid.UUID, error)
+	AssignRoles(groupID uuid.UUID, roleIDs []uuid.UUID) error
+	AssignParents(groupID uuid.UUID, parentIDs []uuid.UUID) error
+	FindAncestorIDs(groupIDs []uuid.UUID) ([]uuid.UUID, error)
+	FindByUserID(userID uuid.UUID) ([]*entities.Group, error)
+}
+
+type groupRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepository{db}
+}
+
+func (r *groupRepository) WithContext(ctx context.Context) GroupRepository {
+	return &groupRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *groupRepository) Create(group *entities.Group) error {
+	return r.db.Create(group).Error
+}
+
+func (r *groupRepository) FindByID(id uuid.UUID) (*entities.Group, error) {
+	var group entities.Group
+	if err := r.db.Preload("Roles").Preload("Parents").First(&group, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// FindByName returns a group named name that would be visible next to a group of the
+// organization: a shared group or one of the organization. A shared group (nil organizationID)
+// is visible in every organization, so any group named name is returned for it.
+func (r *groupRepository) FindByName(name string, organizationID *uuid.UUID) (*entities.Group, error) {
+	query := r.db.Where("name = ?", name)
+	if organizationID != nil {
+		query = query.Where("organization_id IS NULL OR organization_id = ?", *organizationID)
+	}
+
+	var group entities.Group
+	if err := query.First(&group).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *groupRepository) FindByIDs(ids []uuid.UUID) ([]*entities.Group, error) {
+	var groups []*entities.Group
+	if len(ids) == 0 {
+		return groups, nil
+	}
+	if err := r.db.Preload("Roles").Preload("Parents").Where("id IN ?", ids).Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *groupRepository) FindAll(page, pageSize int) ([]*entities.Group, int64, error) {
+	var groups []*entities.Group
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	if err := r.db.Model(&entities.Group{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Preload("Roles").Order("name").Offset(offset).Limit(pageSize).Find(&groups).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return groups, count, nil
+}
+
+func (r *groupRepository) Update(group *entities.Group) error {
+	return r.db.Omit("Members", "Roles", "Parents").Save(group).Error
+}
+
+func (r *groupRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&entities.Group{}, "id = ?", id).Error
+}
+
+// AddMembers adds users to the group, existing members are left as they are
+func (r *groupRepository) AddMembers(groupID uuid.UUID, userIDs []uuid.UUID) error {
+	var users []*entities.User
+	for _, userID := range userIDs {
+		users = append(users, &entities.User{ID: userID})
+	}
+
+	return r.db.Model(&entities.Group{ID: groupID}).Omit("Members.*").Association("Members").Append(users)
+}
+
+func (r *groupRepository) RemoveMember(groupID, userID uuid.UUID) error {
+	result := r.db.Exec("DELETE FROM group_members WHERE group_id = ? AND user_id = ?", groupID, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindMembers returns the direct members of the group, members of nested groups are not included
+func (r *groupRepository) FindMembers(groupID uuid.UUID, page, pageSize int) ([]*entities.User, int64, error) {
+	var users []*entities.User
+	var count int64
+
+	offset := (page - 1) * pageSize
+	query := r.db.Model(&entities.User{}).
+		Joins("JOIN group_members ON group_members.user_id = users.id").
+		Where("group_members.group_id = ?", groupID)
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("users.username").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, count, nil
+}
+
+// FindAllMemberIDs returns the members of the group and of every group nested in it, the users
+// who receive the group's roles. Deleted groups break the chain.
+func (r *groupRepository) FindAllMemberIDs(groupID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Raw(`
+		WITH RECURSIVE descendants(id) AS (
+			SELECT CAST(? AS uuid)
+			UNION
+			SELECT gp.group_id FROM group_parents gp
+			JOIN descendants d ON gp.parent_id = d.id
+			JOIN groups ON groups.id = gp.group_id AND groups.deleted_at IS NULL
+		)
+		SELECT DISTINCT gm.user_id FROM group_members gm
+		JOIN descendants d ON gm.group_id = d.id`, groupID).Scan(&ids).Error
+
+	return ids, err
+}
+
+func (r *groupRepository) AssignRoles(groupID uuid.UUID, roleIDs []uuid.UUID) error {
+	var roles []*entities.Role
+	for _, roleID := range roleIDs {
+		roles = append(roles, &entities.Role{ID: roleID})
+	}
+
+	return r.db.Model(&entities.Group{ID: groupID}).Omit("Roles.*").Association("Roles").Replace(roles)
+}
+
+func (r *groupRepository) AssignParents(groupID uuid.UUID, parentIDs []uuid.UUID) error {
+	var parents []*entities.Group
+	for _, parentID := range parentIDs {
+		parents = append(parents, &entities.Group{ID: parentID})
+	}
+
+	return r.db.Model(&entities.Group{ID: groupID}).Omit("Parents.*").Association("Parents").Replace(parents)
+}
+
+// FindAncestorIDs returns every group reachable through group_parents from the given groups.
+// Deleted groups break the chain.
+func (r *groupRepository) FindAncestorIDs(groupIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(groupIDs) == 0 {
+		return ids, nil
+	}
+
+	err := r.db.Raw(`
+		WITH RECURSIVE ancestors(id) AS (
+			SELECT gp.parent_id FROM group_parents gp
+			JOIN groups ON groups.id = gp.parent_id AND groups.deleted_at IS NULL
+			WHERE gp.group_id IN ?
+			UNION
+			SELECT gp.parent_id FROM group_parents gp
+			JOIN ancestors a ON gp.group_id = a.id
+			JOIN groups ON groups.id = gp.parent_id AND groups.deleted_at IS NULL
+		)
+		SELECT id FROM ancestors`, groupIDs).Scan(&ids).Error
+
+	return ids, err
+}
+
+// FindByUserID returns the groups the user is a direct member of, with their roles and parents
+func (r *groupRepository) FindByUserID(userID uuid.UUID) ([]*entities.Group, error) {
+	var groups []*entities.Group
+	err := r.db.Preload("Roles").Preload("Parents").
+		Joins("JOIN group_members ON group_members.group_id = groups.id").
+		Where("group_members.user_id = ?", userID).
+		Order("groups.name").
+		Find(&groups).Error
+	return groups, err
+}
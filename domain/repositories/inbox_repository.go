@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"time"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InboxRepository interface {
+	Create(item *entities.InboxItem) error
+	FindByID(id uint) (*entities.InboxItem, error)
+	FindByUserID(userID uuid.UUID, page, pageSize int) ([]*entities.InboxItem, int64, error)
+	FindByUserIDAfter(userID uuid.UUID, afterID uint, limit int) ([]*entities.InboxItem, error)
+	CountUnread(userID uuid.UUID) (int64, error)
+	MarkRead(userID uuid.UUID, id uint) error
+	MarkAllRead(userID uuid.UUID) error
+}
+
+type inboxRepository struct {
+	db *gorm.DB
+}
+
+func NewInboxRepository(db *gorm.DB) InboxRepository {
+	return &inboxRepository{db}
+}
+
+func (r *inboxRepository) Create(item *entities.InboxItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *inboxRepository) FindByID(id uint) (*entities.InboxItem, error) {
+	var item entities.InboxItem
+	if err := r.db.First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *inboxRepository) FindByUserID(userID uuid.UUID, page, pageSize int) ([]*entities.InboxItem, int64, error) {
+	var items []*entities.InboxItem
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	if err := r.db.Model(&entities.InboxItem{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return items, count, nil
+}
+
+func (r *inboxRepository) FindByUserIDAfter(userID uuid.UUID, afterID uint, limit int) ([]*entities.InboxItem, error) {
+	var items []*entities.InboxItem
+	if err := r.db.Where("user_id = ? AND id > ?", userID, afterID).Order("id ASC").Limit(limit).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *inboxRepository) CountUnread(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.InboxItem{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *inboxRepository) MarkRead(userID uuid.UUID, id uint) error {
+	result := r.db.Model(&entities.InboxItem{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", time.Now())
+	return result.Error
+}
+
+func (r *inboxRepository) MarkAllRead(userID uuid.UUID) error {
+	return r.db.Model(&entities.InboxItem{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
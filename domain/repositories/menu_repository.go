@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"usermanagement-api/domain/entities"
 
@@ -9,8 +10,11 @@ import (
 )
 
 type MenuRepository interface {
+	// WithContext returns a repository whose reads are limited to the tenant of ctx
+	WithContext(ctx context.Context) MenuRepository
 	Create(menu *entities.Menu) error
 	FindByID(id uuid.UUID) (*entities.Menu, error)
+	FindByIDs(ids []uuid.UUID) ([]*entities.Menu, error)
 	FindByName(name string) (*entities.Menu, error)
 	FindAll(page, pageSize int) ([]*entities.Menu, int64, error)
 	FindAllActive() ([]*entities.Menu, error)
@@ -19,6 +23,7 @@ type MenuRepository interface {
 	Delete(id uuid.UUID) error
 	FindMenusByRoleID(roleID uuid.UUID) ([]*entities.Menu, error)
 	MenuBySuperUser() ([]*entities.Menu, error)
+	FindMenuPermissionNames() (map[uuid.UUID][]string, error)
 }
 
 type menuRepository struct {
@@ -29,6 +34,10 @@ func NewMenuRepository(db *gorm.DB) MenuRepository {
 	return &menuRepository{db}
 }
 
+func (r *menuRepository) WithContext(ctx context.Context) MenuRepository {
+	return &menuRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *menuRepository) Create(menu *entities.Menu) error {
 	return r.db.Create(menu).Error
 }
@@ -41,6 +50,17 @@ func (r *menuRepository) FindByID(id uuid.UUID) (*entities.Menu, error) {
 	return &menu, nil
 }
 
+func (r *menuRepository) FindByIDs(ids []uuid.UUID) ([]*entities.Menu, error) {
+	var menus []*entities.Menu
+	if len(ids) == 0 {
+		return menus, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&menus).Error; err != nil {
+		return nil, err
+	}
+	return menus, nil
+}
+
 func (r *menuRepository) FindByName(name string) (*entities.Menu, error) {
 	var menu entities.Menu
 	if err := r.db.Where("name = ?", name).First(&menu).Error; err != nil {
@@ -132,3 +152,28 @@ func (r *menuRepository) MenuBySuperUser() ([]*entities.Menu, error) {
 	}
 	return menus, nil
 }
+
+// FindMenuPermissionNames maps each active, visible menu to the names of the permissions that grant it
+func (r *menuRepository) FindMenuPermissionNames() (map[uuid.UUID][]string, error) {
+	var rows []struct {
+		MenuID uuid.UUID
+		Name   string
+	}
+	err := r.db.Table("model_permissions").
+		Select("menus.id AS menu_id, permissions.name AS name").
+		Joins("INNER JOIN menus ON menus.id = CAST(model_permissions.model_id as uuid)").
+		Joins("INNER JOIN permissions ON permissions.id = model_permissions.permission_id").
+		Where("model_permissions.model_type = ? AND model_permissions.deleted_at IS NULL", "menu").
+		Where("menus.is_active = ? AND menus.is_visible = ? AND menus.deleted_at IS NULL", true, true).
+		Where("permissions.deleted_at IS NULL").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		names[row.MenuID] = append(names[row.MenuID], row.Name)
+	}
+	return names, nil
+}
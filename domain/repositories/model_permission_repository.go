@@ -14,6 +14,7 @@ type ModelPermissionRepository interface {
 	FindAll(page, pageSize int) ([]*entities.ModelPermission, int64, error)
 	Update(modelPermission *entities.ModelPermission) error
 	Delete(id uuid.UUID) error
+	ReplaceForModel(modelType string, modelID uuid.UUID, permissionIDs []uuid.UUID) error
 	CheckPermission(modelType string, modelID uuid.UUID, permissionID uuid.UUID) (bool, error)
 }
 
@@ -70,6 +71,44 @@ func (r *modelPermissionRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&entities.ModelPermission{}, id).Error
 }
 
+// ReplaceForModel makes permissionIDs the exact set of permissions attached to the model. Links to
+// other permissions, and duplicate links, are removed.
+func (r *modelPermissionRepository) ReplaceForModel(modelType string, modelID uuid.UUID, permissionIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var links []*entities.ModelPermission
+		if err := tx.Where("model_type = ? AND model_id = ?", modelType, modelID).Find(&links).Error; err != nil {
+			return err
+		}
+
+		wanted := make(map[uuid.UUID]bool)
+		for _, permissionID := range permissionIDs {
+			wanted[permissionID] = true
+		}
+		linked := make(map[uuid.UUID]bool)
+		for _, link := range links {
+			if wanted[link.PermissionID] && !linked[link.PermissionID] {
+				linked[link.PermissionID] = true
+				continue
+			}
+			if err := tx.Delete(link).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, permissionID := range permissionIDs {
+			if linked[permissionID] {
+				continue
+			}
+			linked[permissionID] = true
+			link := &entities.ModelPermission{ModelID: modelID, ModelType: modelType, PermissionID: permissionID}
+			if err := tx.Omit("Permission").Create(link).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *modelPermissionRepository) CheckPermission(modelType string, modelID uuid.UUID, permissionID uuid.UUID) (bool, error) {
 	var count int64
 	err := r.db.Model(&entities.ModelPermission{}).
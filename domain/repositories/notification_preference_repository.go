@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationPreferenceRepository interface {
+	FindByUserID(userID uuid.UUID) (*entities.NotificationPreference, error)
+	FindByUserIDs(userIDs []uuid.UUID) ([]*entities.NotificationPreference, error)
+	Upsert(preference *entities.NotificationPreference) error
+}
+
+type notificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db}
+}
+
+func (r *notificationPreferenceRepository) FindByUserID(userID uuid.UUID) (*entities.NotificationPreference, error) {
+	var preference entities.NotificationPreference
+	if err := r.db.First(&preference, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+func (r *notificationPreferenceRepository) FindByUserIDs(userIDs []uuid.UUID) ([]*entities.NotificationPreference, error) {
+	var preferences []*entities.NotificationPreference
+	if len(userIDs) == 0 {
+		return preferences, nil
+	}
+	err := r.db.Where("user_id IN ?", userIDs).Find(&preferences).Error
+	return preferences, err
+}
+
+func (r *notificationPreferenceRepository) Upsert(preference *entities.NotificationPreference) error {
+	return r.db.Save(preference).Error
+}
@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"time"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeliveryStatusCount is one row of the per-channel, per-status aggregate of a notification
+type DeliveryStatusCount struct {
+	Channel string
+	Status  string
+	Count   int64
+}
+
+// DeliveryErrorCount counts failed deliveries of a notification by error code
+type DeliveryErrorCount struct {
+	ErrorCode string
+	Count     int64
+}
+
+type NotificationRepository interface {
+	Create(notification *entities.Notification) error
+	FindByID(id uuid.UUID) (*entities.Notification, error)
+	FindAll(page, pageSize int) ([]*entities.Notification, int64, error)
+	CreateDeliveries(deliveries []*entities.NotificationDelivery) error
+	FindDeliveries(notificationID uuid.UUID, status string, page, pageSize int) ([]*entities.NotificationDelivery, int64, error)
+	CountDeliveriesByStatus(notificationID uuid.UUID) ([]*DeliveryStatusCount, error)
+	CountDeliveriesByError(notificationID uuid.UUID) ([]*DeliveryErrorCount, error)
+	CountRecipients(notificationID uuid.UUID, statuses []string) (int64, error)
+	MarkOpened(notificationID, userID uuid.UUID) error
+	MarkRead(notificationID, userID uuid.UUID) error
+	MarkAllRead(userID uuid.UUID) error
+	// ClaimDueDeferred marks due deferred deliveries as dispatching and returns them, see the implementation
+	ClaimDueDeferred(before, staleClaimsBefore time.Time, limit int) ([]*entities.NotificationDelivery, error)
+	UpdateDelivery(delivery *entities.NotificationDelivery) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db}
+}
+
+func (r *notificationRepository) Create(notification *entities.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *notificationRepository) FindByID(id uuid.UUID) (*entities.Notification, error) {
+	var notification entities.Notification
+	if err := r.db.First(&notification, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) FindAll(page, pageSize int) ([]*entities.Notification, int64, error) {
+	var notifications []*entities.Notification
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	if err := r.db.Model(&entities.Notification{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, count, nil
+}
+
+func (r *notificationRepository) CreateDeliveries(deliveries []*entities.NotificationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(deliveries, 500).Error
+}
+
+func (r *notificationRepository) FindDeliveries(notificationID uuid.UUID, status string, page, pageSize int) ([]*entities.NotificationDelivery, int64, error) {
+	var deliveries []*entities.NotificationDelivery
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := r.db.Model(&entities.NotificationDelivery{}).Where("notification_id = ?", notificationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at ASC").Offset(offset).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, count, nil
+}
+
+func (r *notificationRepository) CountDeliveriesByStatus(notificationID uuid.UUID) ([]*DeliveryStatusCount, error) {
+	var counts []*DeliveryStatusCount
+	err := r.db.Model(&entities.NotificationDelivery{}).
+		Select("channel, status, COUNT(*) AS count").
+		Where("notification_id = ?", notificationID).
+		Group("channel, status").
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+func (r *notificationRepository) CountDeliveriesByError(notificationID uuid.UUID) ([]*DeliveryErrorCount, error) {
+	var counts []*DeliveryErrorCount
+	err := r.db.Model(&entities.NotificationDelivery{}).
+		Select("error_code, COUNT(*) AS count").
+		Where("notification_id = ? AND error_code <> ''", notificationID).
+		Group("error_code").
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// CountRecipients counts distinct users with at least one delivery in the given statuses.
+// An empty statuses slice counts every recipient.
+func (r *notificationRepository) CountRecipients(notificationID uuid.UUID, statuses []string) (int64, error) {
+	var count int64
+	query := r.db.Model(&entities.NotificationDelivery{}).
+		Where("notification_id = ? AND user_id IS NOT NULL", notificationID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	err := query.Distinct("user_id").Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkOpened(notificationID, userID uuid.UUID) error {
+	return r.db.Model(&entities.NotificationDelivery{}).
+		Where("notification_id = ? AND user_id = ? AND channel = ? AND status = ?", notificationID, userID, "push", "sent").
+		Updates(map[string]interface{}{"status": "opened", "opened_at": time.Now()}).Error
+}
+
+func (r *notificationRepository) MarkRead(notificationID, userID uuid.UUID) error {
+	return r.db.Model(&entities.NotificationDelivery{}).
+		Where("notification_id = ? AND user_id = ? AND channel = ? AND status <> ?", notificationID, userID, "in_app", "read").
+		Updates(map[string]interface{}{"status": "read", "read_at": time.Now()}).Error
+}
+
+func (r *notificationRepository) MarkAllRead(userID uuid.UUID) error {
+	return r.db.Model(&entities.NotificationDelivery{}).
+		Where("user_id = ? AND channel = ? AND status <> ?", userID, "in_app", "read").
+		Updates(map[string]interface{}{"status": "read", "read_at": time.Now()}).Error
+}
+
+// ClaimDueDeferred marks deferred deliveries whose quiet hours ended before the given time as
+// dispatching and returns them. Rows are claimed in one statement and locked rows are skipped, so
+// instances running the job at the same time never claim the same delivery. Claims last updated
+// before staleClaimsBefore belong to a dispatcher that stopped midway and are claimed again.
+func (r *notificationRepository) ClaimDueDeferred(before, staleClaimsBefore time.Time, limit int) ([]*entities.NotificationDelivery, error) {
+	var deliveries []*entities.NotificationDelivery
+	err := r.db.Raw(`
+		UPDATE notification_deliveries SET status = 'dispatching', updated_at = ?
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE (status = 'deferred' AND deliver_after <= ?)
+				OR (status = 'dispatching' AND updated_at < ?)
+			ORDER BY deliver_after ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, time.Now(), before, staleClaimsBefore, limit).Scan(&deliveries).Error
+
+	return deliveries, err
+}
+
+func (r *notificationRepository) UpdateDelivery(delivery *entities.NotificationDelivery) error {
+	return r.db.Save(delivery).Error
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrganizationRepository interface {
+	Create(organization *entities.Organization) error
+	FindByID(id uuid.UUID) (*entities.Organization, error)
+	FindAll(page, pageSize int) ([]*entities.Organization, int64, error)
+	Update(organization *entities.Organization) error
+	Delete(id uuid.UUID) error
+	AddMembers(organizationID uuid.UUID, userIDs []uuid.UUID) error
+	RemoveMember(organizationID, userID uuid.UUID) error
+	FindMembers(organizationID uuid.UUID, page, pageSize int) ([]*entities.OrganizationMember, int64, error)
+	FindByUserID(userID uuid.UUID) ([]*entities.Organization, error)
+	IsMember(organizationID, userID uuid.UUID) (bool, error)
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db}
+}
+
+func (r *organizationRepository) Create(organization *entities.Organization) error {
+	return r.db.Create(organization).Error
+}
+
+func (r *organizationRepository) FindByID(id uuid.UUID) (*entities.Organization, error) {
+	var organization entities.Organization
+	if err := r.db.First(&organization, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (r *organizationRepository) FindAll(page, pageSize int) ([]*entities.Organization, int64, error) {
+	var organizations []*entities.Organization
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	if err := r.db.Model(&entities.Organization{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Order("name").Offset(offset).Limit(pageSize).Find(&organizations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return organizations, count, nil
+}
+
+func (r *organizationRepository) Update(organization *entities.Organization) error {
+	return r.db.Save(organization).Error
+}
+
+func (r *organizationRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&entities.Organization{}, "id = ?", id).Error
+}
+
+// AddMembers adds users to the organization, existing members are left as they are
+func (r *organizationRepository) AddMembers(organizationID uuid.UUID, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	var members []*entities.OrganizationMember
+	for _, userID := range userIDs {
+		members = append(members, &entities.OrganizationMember{OrganizationID: organizationID, UserID: userID})
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+func (r *organizationRepository) RemoveMember(organizationID, userID uuid.UUID) error {
+	result := r.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&entities.OrganizationMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *organizationRepository) FindMembers(organizationID uuid.UUID, page, pageSize int) ([]*entities.OrganizationMember, int64, error) {
+	var members []*entities.OrganizationMember
+	var count int64
+
+	offset := (page - 1) * pageSize
+	query := r.db.Model(&entities.OrganizationMember{}).Where("organization_id = ?", organizationID)
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("User").Order("created_at").Offset(offset).Limit(pageSize).Find(&members).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return members, count, nil
+}
+
+// FindByUserID returns the active organizations the user belongs to, oldest membership first
+func (r *organizationRepository) FindByUserID(userID uuid.UUID) ([]*entities.Organization, error) {
+	var organizations []*entities.Organization
+	err := r.db.Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ? AND organizations.is_active = ?", userID, true).
+		Order("organization_members.created_at").
+		Find(&organizations).Error
+	return organizations, err
+}
+
+// IsMember reports whether the user belongs to the organization and the organization is active
+func (r *organizationRepository) IsMember(organizationID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.OrganizationMember{}).
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id AND organizations.deleted_at IS NULL").
+		Where("organization_members.organization_id = ? AND organization_members.user_id = ? AND organizations.is_active = ?", organizationID, userID, true).
+		Count(&count).Error
+	return count > 0, err
+}
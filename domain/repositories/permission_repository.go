@@ -14,6 +14,7 @@ type PermissionRepository interface {
 	FindAll(page, pageSize int) ([]*entities.Permission, int64, error)
 	Update(permission *entities.Permission) error
 	Delete(id uuid.UUID) error
+	FindAllNames() ([]string, error)
 }
 
 type permissionRepository struct {
@@ -68,3 +69,9 @@ func (r *permissionRepository) Update(permission *entities.Permission) error {
 func (r *permissionRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&entities.Permission{}, id).Error
 }
+
+func (r *permissionRepository) FindAllNames() ([]string, error) {
+	var names []string
+	err := r.db.Model(&entities.Permission{}).Order("name").Pluck("name", &names).Error
+	return names, err
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RoleAssignmentRuleRepository interface {
+	Create(rule *entities.RoleAssignmentRule) error
+	FindByID(id uuid.UUID) (*entities.RoleAssignmentRule, error)
+	FindByName(name string) (*entities.RoleAssignmentRule, error)
+	FindAll(page, pageSize int) ([]*entities.RoleAssignmentRule, int64, error)
+	FindEnabled() ([]*entities.RoleAssignmentRule, error)
+	Update(rule *entities.RoleAssignmentRule) error
+	Delete(id uuid.UUID) error
+	FindUserIDsByRule(ruleID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type roleAssignmentRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleAssignmentRuleRepository(db *gorm.DB) RoleAssignmentRuleRepository {
+	return &roleAssignmentRuleRepository{db}
+}
+
+func (r *roleAssignmentRuleRepository) Create(rule *entities.RoleAssignmentRule) error {
+	return r.db.Omit("Role").Create(rule).Error
+}
+
+func (r *roleAssignmentRuleRepository) FindByID(id uuid.UUID) (*entities.RoleAssignmentRule, error) {
+	var rule entities.RoleAssignmentRule
+	if err := r.db.Preload("Role").First(&rule, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *roleAssignmentRuleRepository) FindByName(name string) (*entities.RoleAssignmentRule, error) {
+	var rule entities.RoleAssignmentRule
+	if err := r.db.Where("name = ?", name).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *roleAssignmentRuleRepository) FindAll(page, pageSize int) ([]*entities.RoleAssignmentRule, int64, error) {
+	var rules []*entities.RoleAssignmentRule
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	if err := r.db.Model(&entities.RoleAssignmentRule{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Preload("Role").Order("name").Offset(offset).Limit(pageSize).Find(&rules).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rules, count, nil
+}
+
+// FindEnabled returns the enabled rules whose role still exists
+func (r *roleAssignmentRuleRepository) FindEnabled() ([]*entities.RoleAssignmentRule, error) {
+	var rules []*entities.RoleAssignmentRule
+	err := r.db.Preload("Role").
+		Joins("JOIN roles ON roles.id = role_assignment_rules.role_id AND roles.deleted_at IS NULL").
+		Where("role_assignment_rules.enabled = ?", true).
+		Order("role_assignment_rules.name").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *roleAssignmentRuleRepository) Update(rule *entities.RoleAssignmentRule) error {
+	return r.db.Omit("Role").Save(rule).Error
+}
+
+func (r *roleAssignmentRuleRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&entities.RoleAssignmentRule{}, "id = ?", id).Error
+}
+
+// FindUserIDsByRule returns the users holding an assignment made by the rule
+func (r *roleAssignmentRuleRepository) FindUserIDsByRule(ruleID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&entities.UserRole{}).Where("rule_id = ?", ruleID).Distinct().Pluck("user_id", &ids).Error
+	return ids, err
+}
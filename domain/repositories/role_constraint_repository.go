@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RoleConstraintRepository interface {
+	Create(constraint *entities.RoleConstraint) error
+	FindByID(id uuid.UUID) (*entities.RoleConstraint, error)
+	FindByName(name string) (*entities.RoleConstraint, error)
+	FindAll(page, pageSize int) ([]*entities.RoleConstraint, int64, error)
+	Update(constraint *entities.RoleConstraint) error
+	Delete(id uuid.UUID) error
+	AssignRoles(constraintID uuid.UUID, roleIDs []uuid.UUID) error
+	FindByRoleIDs(roleIDs []uuid.UUID) ([]*entities.RoleConstraint, error)
+}
+
+type roleConstraintRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleConstraintRepository(db *gorm.DB) RoleConstraintRepository {
+	return &roleConstraintRepository{db}
+}
+
+func (r *roleConstraintRepository) Create(constraint *entities.RoleConstraint) error {
+	return r.db.Omit("Roles.*").Create(constraint).Error
+}
+
+func (r *roleConstraintRepository) FindByID(id uuid.UUID) (*entities.RoleConstraint, error) {
+	var constraint entities.RoleConstraint
+	if err := r.db.Preload("Roles").First(&constraint, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &constraint, nil
+}
+
+func (r *roleConstraintRepository) FindByName(name string) (*entities.RoleConstraint, error) {
+	var constraint entities.RoleConstraint
+	if err := r.db.Where("name = ?", name).First(&constraint).Error; err != nil {
+		return nil, err
+	}
+	return &constraint, nil
+}
+
+func (r *roleConstraintRepository) FindAll(page, pageSize int) ([]*entities.RoleConstraint, int64, error) {
+	var constraints []*entities.RoleConstraint
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	if err := r.db.Model(&entities.RoleConstraint{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Preload("Roles").Order("name").Offset(offset).Limit(pageSize).Find(&constraints).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return constraints, count, nil
+}
+
+func (r *roleConstraintRepository) Update(constraint *entities.RoleConstraint) error {
+	return r.db.Omit("Roles").Save(constraint).Error
+}
+
+func (r *roleConstraintRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&entities.RoleConstraint{}, "id = ?", id).Error
+}
+
+func (r *roleConstraintRepository) AssignRoles(constraintID uuid.UUID, roleIDs []uuid.UUID) error {
+	var roles []*entities.Role
+	for _, roleID := range roleIDs {
+		roles = append(roles, &entities.Role{ID: roleID})
+	}
+
+	return r.db.Model(&entities.RoleConstraint{ID: constraintID}).Omit("Roles.*").Association("Roles").Replace(roles)
+}
+
+// FindByRoleIDs returns the constraints that include any of the given roles, with all their roles
+func (r *roleConstraintRepository) FindByRoleIDs(roleIDs []uuid.UUID) ([]*entities.RoleConstraint, error) {
+	var constraints []*entities.RoleConstraint
+	if len(roleIDs) == 0 {
+		return constraints, nil
+	}
+
+	err := r.db.Preload("Roles").
+		Where("id IN (SELECT role_constraint_id FROM role_constraint_roles WHERE role_id IN ?)", roleIDs).
+		Order("name").
+		Find(&constraints).Error
+	return constraints, err
+}
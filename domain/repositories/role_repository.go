@@ -1,22 +1,45 @@
 package repositories
 
 import (
+	"context"
+	"errors"
+	"time"
 	"usermanagement-api/domain/entities"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrRoleHierarchyCycle is returned by AssignParents when a role would become its own ancestor
+var ErrRoleHierarchyCycle = errors.New("role hierarchy would contain a cycle")
+
 type RoleRepository interface {
+	// WithContext returns a repository whose reads are limited to the tenant of ctx
+	WithContext(ctx context.Context) RoleRepository
 	Create(role *entities.Role) error
 	FindByID(id uuid.UUID) (*entities.Role, error)
-	FindByName(name string) (*entities.Role, error)
+	FindByName(name string, organizationID *uuid.UUID) (*entities.Role, error)
 	FindAll(page, pageSize int) ([]*entities.Role, int64, error)
 	Update(role *entities.Role) error
 	Delete(id uuid.UUID) error
 	AssignPermissions(roleID uuid.UUID, permissionIDs []uuid.UUID) error
-	FindRolesByUserID(userID uuid.UUID) ([]*entities.Role, error)
+	FindRolesByUserID(userID uuid.UUID, organizationID *uuid.UUID) ([]*entities.Role, error)
+	FindGroupRolesByUserID(userID uuid.UUID, organizationID *uuid.UUID) ([]*entities.Role, error)
+	FindAllGroupRolesByUserID(userID uuid.UUID) ([]*entities.Role, error)
 	FindPermissionsByRoleIDs(roleIDs []uuid.UUID) ([]*entities.Permission, error)
+	FindByIDs(ids []uuid.UUID) ([]*entities.Role, error)
+	AssignParents(roleID uuid.UUID, parentIDs []uuid.UUID) error
+	FindAncestorIDs(roleIDs []uuid.UUID) ([]uuid.UUID, error)
+	FindEffectivePermissionsByRoleIDs(roleIDs []uuid.UUID) ([]*entities.Permission, error)
+	AssignDeniedPermissions(roleID uuid.UUID, permissionIDs []uuid.UUID) error
+	FindEffectiveDeniedPermissionsByRoleIDs(roleIDs []uuid.UUID) ([]*entities.Permission, error)
+	AssignApprovers(roleID uuid.UUID, userIDs []uuid.UUID) error
+	FindApproverIDs(roleID uuid.UUID) ([]uuid.UUID, error)
+	FindRoleIDsByApprover(userID uuid.UUID) ([]uuid.UUID, error)
+	AssignDelegation(roleID uuid.UUID, userScope string, manageableRoleIDs []uuid.UUID) error
+	FindManageableRoleIDs(roleID uuid.UUID) ([]uuid.UUID, error)
+	FindDescendantIDs(roleIDs []uuid.UUID) ([]uuid.UUID, error)
+	FindUserIDsByRoleIDs(roleIDs []uuid.UUID) ([]uuid.UUID, error)
 }
 
 type roleRepository struct {
@@ -27,21 +50,33 @@ func NewRoleRepository(db *gorm.DB) RoleRepository {
 	return &roleRepository{db}
 }
 
+func (r *roleRepository) WithContext(ctx context.Context) RoleRepository {
+	return &roleRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *roleRepository) Create(role *entities.Role) error {
 	return r.db.Create(role).Error
 }
 
 func (r *roleRepository) FindByID(id uuid.UUID) (*entities.Role, error) {
 	var role entities.Role
-	if err := r.db.Preload("Permissions").First(&role, "id = ?", id).Error; err != nil {
+	if err := r.db.Preload("Permissions").Preload("DeniedPermissions").Preload("Parents").Preload("Approvers").Preload("ManageableRoles").First(&role, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
-func (r *roleRepository) FindByName(name string) (*entities.Role, error) {
+// FindByName returns a role named name that would be visible next to a role of the organization:
+// a shared role or one of the organization. A shared role (nil organizationID) is visible in
+// every organization, so any role named name is returned for it.
+func (r *roleRepository) FindByName(name string, organizationID *uuid.UUID) (*entities.Role, error) {
+	query := r.db.Where("name = ?", name)
+	if organizationID != nil {
+		query = query.Where("organization_id IS NULL OR organization_id = ?", *organizationID)
+	}
+
 	var role entities.Role
-	if err := r.db.Where("name = ?", name).First(&role).Error; err != nil {
+	if err := query.First(&role).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
@@ -95,14 +130,82 @@ func (r *roleRepository) AssignPermissions(roleID uuid.UUID, permissionIDs []uui
 	return tx.Commit().Error
 }
 
-func (r *roleRepository) FindRolesByUserID(userID uuid.UUID) ([]*entities.Role, error) {
+// FindRolesByUserID returns the roles whose assignment to the user is currently in effect in the
+// organization, followed by the roles the user holds through its groups and the groups they are
+// nested in. Callers still drop the tenant roles of other organizations, see Role.AppliesIn.
+func (r *roleRepository) FindRolesByUserID(userID uuid.UUID, organizationID *uuid.UUID) ([]*entities.Role, error) {
+	organizations := []uuid.UUID{uuid.Nil}
+	if organizationID != nil {
+		organizations = append(organizations, *organizationID)
+	}
+
 	var roles []*entities.Role
-	if err := r.db.Model(&entities.User{ID: userID}).Association("Roles").Find(&roles); err != nil {
+	err := r.db.Distinct("roles.*").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Scopes(activeRoleAssignments(time.Now())).
+		Where("user_roles.user_id = ? AND user_roles.organization_id IN ?", userID, organizations).
+		Find(&roles).Error
+	if err != nil {
 		return nil, err
 	}
+
+	groupRoles, err := r.FindGroupRolesByUserID(userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	direct := make(map[uuid.UUID]bool, len(roles))
+	for _, role := range roles {
+		direct[role.ID] = true
+	}
+	for _, role := range groupRoles {
+		if !direct[role.ID] {
+			roles = append(roles, role)
+		}
+	}
 	return roles, nil
 }
 
+// FindGroupRolesByUserID returns the roles the user holds through its groups and the groups they
+// are nested in, counting only shared groups and groups of the organization. Tenant groups of
+// other organizations are skipped along with the groups above them.
+func (r *roleRepository) FindGroupRolesByUserID(userID uuid.UUID, organizationID *uuid.UUID) ([]*entities.Role, error) {
+	if organizationID == nil {
+		return r.findGroupRoles(userID, "groups.organization_id IS NULL")
+	}
+	return r.findGroupRoles(userID, "(groups.organization_id IS NULL OR groups.organization_id = ?)", *organizationID)
+}
+
+// FindAllGroupRolesByUserID returns the roles the user holds through any of its groups, whatever
+// organization they belong to
+func (r *roleRepository) FindAllGroupRolesByUserID(userID uuid.UUID) ([]*entities.Role, error) {
+	return r.findGroupRoles(userID, "TRUE")
+}
+
+// findGroupRoles walks up from the user's groups through the groups matching groupCondition
+func (r *roleRepository) findGroupRoles(userID uuid.UUID, groupCondition string, args ...interface{}) ([]*entities.Role, error) {
+	vars := append([]interface{}{userID}, args...)
+	vars = append(vars, args...)
+
+	var roles []*entities.Role
+	err := r.db.Where(`roles.id IN (
+			WITH RECURSIVE member_of(id) AS (
+				SELECT gm.group_id FROM group_members gm
+				JOIN groups ON groups.id = gm.group_id AND groups.deleted_at IS NULL
+				WHERE gm.user_id = ? AND `+groupCondition+`
+				UNION
+				SELECT gp.parent_id FROM group_parents gp
+				JOIN member_of m ON gp.group_id = m.id
+				JOIN groups ON groups.id = gp.parent_id AND groups.deleted_at IS NULL
+				WHERE `+groupCondition+`
+			)
+			SELECT gr.role_id FROM group_roles gr JOIN member_of m ON gr.group_id = m.id
+		)`, vars...).
+		Order("roles.name").
+		Find(&roles).Error
+	return roles, err
+}
+
 func (r *roleRepository) FindPermissionsByRoleIDs(roleIDs []uuid.UUID) ([]*entities.Permission, error) {
 	var permissions []*entities.Permission
 	err := r.db.Table("permissions").
@@ -113,3 +216,215 @@ func (r *roleRepository) FindPermissionsByRoleIDs(roleIDs []uuid.UUID) ([]*entit
 
 	return permissions, err
 }
+
+func (r *roleRepository) FindByIDs(ids []uuid.UUID) ([]*entities.Role, error) {
+	var roles []*entities.Role
+	if len(ids) == 0 {
+		return roles, nil
+	}
+	if err := r.db.Preload("Permissions").Preload("DeniedPermissions").Where("id IN ?", ids).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AssignParents replaces the parents of the role, returning ErrRoleHierarchyCycle when the role
+// is already an ancestor of one of them. The check and the write share a transaction that locks
+// role_parents against other writers, so concurrent changes cannot close a cycle between them.
+func (r *roleRepository) AssignParents(roleID uuid.UUID, parentIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE role_parents IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		ancestorIDs, err := (&roleRepository{db: tx}).FindAncestorIDs(parentIDs)
+		if err != nil {
+			return err
+		}
+		for _, id := range append(ancestorIDs, parentIDs...) {
+			if id == roleID {
+				return ErrRoleHierarchyCycle
+			}
+		}
+
+		var parents []*entities.Role
+		for _, parentID := range parentIDs {
+			parents = append(parents, &entities.Role{ID: parentID})
+		}
+		return tx.Model(&entities.Role{ID: roleID}).Association("Parents").Replace(parents)
+	})
+}
+
+// FindAncestorIDs returns every role reachable through role_parents from the given roles.
+// Deleted roles break the chain.
+func (r *roleRepository) FindAncestorIDs(roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(roleIDs) == 0 {
+		return ids, nil
+	}
+
+	err := r.db.Raw(`
+		WITH RECURSIVE ancestors(id) AS (
+			SELECT rp.parent_id FROM role_parents rp
+			JOIN roles ON roles.id = rp.parent_id AND roles.deleted_at IS NULL
+			WHERE rp.role_id IN ?
+			UNION
+			SELECT rp.parent_id FROM role_parents rp
+			JOIN ancestors a ON rp.role_id = a.id
+			JOIN roles ON roles.id = rp.parent_id AND roles.deleted_at IS NULL
+		)
+		SELECT id FROM ancestors`, roleIDs).Scan(&ids).Error
+
+	return ids, err
+}
+
+// FindEffectivePermissionsByRoleIDs returns the permissions of the given roles and all their ancestors
+func (r *roleRepository) FindEffectivePermissionsByRoleIDs(roleIDs []uuid.UUID) ([]*entities.Permission, error) {
+	ancestorIDs, err := r.FindAncestorIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindPermissionsByRoleIDs(append(append([]uuid.UUID{}, roleIDs...), ancestorIDs...))
+}
+
+func (r *roleRepository) AssignDeniedPermissions(roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+	var permissions []*entities.Permission
+	for _, permissionID := range permissionIDs {
+		permissions = append(permissions, &entities.Permission{ID: permissionID})
+	}
+
+	return r.db.Model(&entities.Role{ID: roleID}).Association("DeniedPermissions").Replace(permissions)
+}
+
+// FindEffectiveDeniedPermissionsByRoleIDs returns the permissions denied by the given roles and all their ancestors
+func (r *roleRepository) FindEffectiveDeniedPermissionsByRoleIDs(roleIDs []uuid.UUID) ([]*entities.Permission, error) {
+	var permissions []*entities.Permission
+	if len(roleIDs) == 0 {
+		return permissions, nil
+	}
+
+	ancestorIDs, err := r.FindAncestorIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Table("permissions").
+		Joins("INNER JOIN role_denied_permissions ON permissions.id = role_denied_permissions.permission_id").
+		Where("role_denied_permissions.role_id IN ?", append(append([]uuid.UUID{}, roleIDs...), ancestorIDs...)).
+		Group("permissions.id").
+		Find(&permissions).Error
+
+	return permissions, err
+}
+
+func (r *roleRepository) AssignApprovers(roleID uuid.UUID, userIDs []uuid.UUID) error {
+	var users []*entities.User
+	for _, userID := range userIDs {
+		users = append(users, &entities.User{ID: userID})
+	}
+
+	return r.db.Model(&entities.Role{ID: roleID}).Omit("Approvers.*").Association("Approvers").Replace(users)
+}
+
+// FindApproverIDs returns the users who decide access requests for the role, deleted users excluded
+func (r *roleRepository) FindApproverIDs(roleID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Table("role_approvers").
+		Joins("JOIN users ON users.id = role_approvers.user_id AND users.deleted_at IS NULL").
+		Where("role_approvers.role_id = ?", roleID).
+		Pluck("role_approvers.user_id", &ids).Error
+	return ids, err
+}
+
+// FindRoleIDsByApprover returns the roles whose access requests the user decides
+func (r *roleRepository) FindRoleIDsByApprover(userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Table("role_approvers").
+		Joins("JOIN roles ON roles.id = role_approvers.role_id AND roles.deleted_at IS NULL").
+		Where("role_approvers.user_id = ?", userID).
+		Pluck("role_approvers.role_id", &ids).Error
+	return ids, err
+}
+
+// AssignDelegation sets the users and roles holders of the role may manage, an empty scope makes
+// it an ordinary role again
+func (r *roleRepository) AssignDelegation(roleID uuid.UUID, userScope string, manageableRoleIDs []uuid.UUID) error {
+	var roles []*entities.Role
+	for _, id := range manageableRoleIDs {
+		roles = append(roles, &entities.Role{ID: id})
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.Role{ID: roleID}).Update("manageable_user_scope", userScope).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entities.Role{ID: roleID}).Omit("ManageableRoles.*").Association("ManageableRoles").Replace(roles)
+	})
+}
+
+// FindManageableRoleIDs returns the roles holders of the role may assign, deleted roles excluded
+func (r *roleRepository) FindManageableRoleIDs(roleID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Table("role_manageable_roles").
+		Joins("JOIN roles ON roles.id = role_manageable_roles.manageable_role_id AND roles.deleted_at IS NULL").
+		Where("role_manageable_roles.role_id = ?", roleID).
+		Pluck("role_manageable_roles.manageable_role_id", &ids).Error
+	return ids, err
+}
+
+// FindDescendantIDs returns every role that inherits, through role_parents, from the given roles.
+// Deleted roles break the chain.
+func (r *roleRepository) FindDescendantIDs(roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(roleIDs) == 0 {
+		return ids, nil
+	}
+
+	err := r.db.Raw(`
+		WITH RECURSIVE descendants(id) AS (
+			SELECT rp.role_id FROM role_parents rp
+			JOIN roles ON roles.id = rp.role_id AND roles.deleted_at IS NULL
+			WHERE rp.parent_id IN ?
+			UNION
+			SELECT rp.role_id FROM role_parents rp
+			JOIN descendants d ON rp.parent_id = d.id
+			JOIN roles ON roles.id = rp.role_id AND roles.deleted_at IS NULL
+		)
+		SELECT id FROM descendants`, roleIDs).Scan(&ids).Error
+
+	return ids, err
+}
+
+// FindUserIDsByRoleIDs returns the existing users holding any of the roles, through an assignment
+// currently in effect or through their groups and the groups they are nested in
+func (r *roleRepository) FindUserIDsByRoleIDs(roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(roleIDs) == 0 {
+		return ids, nil
+	}
+
+	now := time.Now()
+	err := r.db.Raw(`
+		WITH RECURSIVE carriers(id) AS (
+			SELECT gr.group_id FROM group_roles gr
+			JOIN groups ON groups.id = gr.group_id AND groups.deleted_at IS NULL
+			WHERE gr.role_id IN ?
+			UNION
+			SELECT gp.group_id FROM group_parents gp
+			JOIN carriers c ON gp.parent_id = c.id
+			JOIN groups ON groups.id = gp.group_id AND groups.deleted_at IS NULL
+		)
+		SELECT users.id FROM users
+		WHERE users.deleted_at IS NULL AND (
+			users.id IN (SELECT gm.user_id FROM group_members gm JOIN carriers c ON gm.group_id = c.id)
+			OR users.id IN (
+				SELECT ur.user_id FROM user_roles ur
+				WHERE ur.role_id IN ?
+				AND (ur.starts_at IS NULL OR ur.starts_at <= ?) AND (ur.expires_at IS NULL OR ur.expires_at > ?)
+			)
+		)
+		ORDER BY users.username`, roleIDs, roleIDs, now, now).Scan(&ids).Error
+
+	return ids, err
+}
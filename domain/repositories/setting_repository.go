@@ -4,6 +4,7 @@ package repositories
 import (
 	"usermanagement-api/domain/entities"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -14,6 +15,10 @@ type SettingRepository interface {
 	Update(setting *entities.Setting) error
 	Delete(key string) error
 	Upsert(setting *entities.Setting) error
+	FindOrganizationSettings(organizationID uuid.UUID) ([]*entities.OrganizationSetting, error)
+	FindOrganizationSetting(organizationID uuid.UUID, key string) (*entities.OrganizationSetting, error)
+	UpsertOrganizationSetting(setting *entities.OrganizationSetting) error
+	DeleteOrganizationSetting(organizationID uuid.UUID, key string) error
 }
 
 type settingRepository struct {
@@ -55,3 +60,27 @@ func (r *settingRepository) Delete(key string) error {
 func (r *settingRepository) Upsert(setting *entities.Setting) error {
 	return r.db.Save(setting).Error
 }
+
+func (r *settingRepository) FindOrganizationSettings(organizationID uuid.UUID) ([]*entities.OrganizationSetting, error) {
+	var settings []*entities.OrganizationSetting
+	if err := r.db.Where("organization_id = ?", organizationID).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *settingRepository) FindOrganizationSetting(organizationID uuid.UUID, key string) (*entities.OrganizationSetting, error) {
+	var setting entities.OrganizationSetting
+	if err := r.db.Where("organization_id = ? AND key = ?", organizationID, key).First(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *settingRepository) UpsertOrganizationSetting(setting *entities.OrganizationSetting) error {
+	return r.db.Save(setting).Error
+}
+
+func (r *settingRepository) DeleteOrganizationSetting(organizationID uuid.UUID, key string) error {
+	return r.db.Where("organization_id = ? AND key = ?", organizationID, key).Delete(&entities.OrganizationSetting{}).Error
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"usermanagement-api/domain/entities"
+
+	"gorm.io/gorm"
+)
+
+type UserMetaKeyPolicyRepository interface {
+	FindByKey(key string) (*entities.UserMetaKeyPolicy, error)
+	FindAll() ([]*entities.UserMetaKeyPolicy, error)
+	Upsert(policy *entities.UserMetaKeyPolicy) error
+	Delete(key string) error
+}
+
+type userMetaKeyPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewUserMetaKeyPolicyRepository(db *gorm.DB) UserMetaKeyPolicyRepository {
+	return &userMetaKeyPolicyRepository{db}
+}
+
+func (r *userMetaKeyPolicyRepository) FindByKey(key string) (*entities.UserMetaKeyPolicy, error) {
+	var policy entities.UserMetaKeyPolicy
+	if err := r.db.First(&policy, "key = ?", key).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *userMetaKeyPolicyRepository) FindAll() ([]*entities.UserMetaKeyPolicy, error) {
+	var policies []*entities.UserMetaKeyPolicy
+	err := r.db.Order("key").Find(&policies).Error
+	return policies, err
+}
+
+func (r *userMetaKeyPolicyRepository) Upsert(policy *entities.UserMetaKeyPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r *userMetaKeyPolicyRepository) Delete(key string) error {
+	return r.db.Delete(&entities.UserMetaKeyPolicy{}, "key = ?", key).Error
+}
@@ -1,21 +1,34 @@
 package repositories
 
 import (
+	"context"
+	"time"
 	"usermanagement-api/domain/entities"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
+	// WithContext returns a repository whose reads are limited to the tenant of ctx
+	WithContext(ctx context.Context) UserRepository
 	Create(user *entities.User) error
 	FindByID(id uuid.UUID) (*entities.User, error)
 	FindByEmail(email string) (*entities.User, error)
 	FindByUsername(username string) (*entities.User, error)
 	FindAll(page, pageSize int) ([]*entities.User, int64, error)
+	FindAllIDs() ([]uuid.UUID, error)
 	Update(user *entities.User) error
 	Delete(id uuid.UUID) error
-	AssignRoles(userID uuid.UUID, roleIDs []uuid.UUID) error
+	AssignRoles(userID, organizationID uuid.UUID, roleIDs []uuid.UUID, grantedBy *uuid.UUID) error
+	AssignDeniedPermissions(userID uuid.UUID, permissionIDs []uuid.UUID) error
+	FindRoleAssignments(userID uuid.UUID) ([]*entities.UserRole, error)
+	FindRoleAssignment(userID, roleID, organizationID uuid.UUID) (*entities.UserRole, error)
+	SaveRoleAssignment(assignment *entities.UserRole) error
+	DeleteRoleAssignment(userID, roleID, organizationID uuid.UUID) error
+	FindExpiredRoleAssignments(at time.Time) ([]*entities.UserRole, error)
+	FindCurrentRoleAssignments(roleIDs, userIDs []uuid.UUID) ([]*entities.UserRole, error)
 }
 
 type userRepository struct {
@@ -26,13 +39,20 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db}
 }
 
+func (r *userRepository) WithContext(ctx context.Context) UserRepository {
+	return &userRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *userRepository) Create(user *entities.User) error {
 	return r.db.Create(user).Error
 }
 
 func (r *userRepository) FindByID(id uuid.UUID) (*entities.User, error) {
 	var user entities.User
-	if err := r.db.Preload("Roles").First(&user, id).Error; err != nil {
+	if err := r.db.Preload("DeniedPermissions").First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	if err := r.loadActiveRoles(&user); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -40,7 +60,10 @@ func (r *userRepository) FindByID(id uuid.UUID) (*entities.User, error) {
 
 func (r *userRepository) FindByEmail(email string) (*entities.User, error) {
 	var user entities.User
-	if err := r.db.Preload("Roles").Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	if err := r.loadActiveRoles(&user); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -48,7 +71,10 @@ func (r *userRepository) FindByEmail(email string) (*entities.User, error) {
 
 func (r *userRepository) FindByUsername(username string) (*entities.User, error) {
 	var user entities.User
-	if err := r.db.Preload("Roles").Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	if err := r.loadActiveRoles(&user); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -64,40 +90,174 @@ func (r *userRepository) FindAll(page, pageSize int) ([]*entities.User, int64, e
 		return nil, 0, err
 	}
 
-	if err := r.db.Preload("Roles").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	if err := r.db.Order("created_at").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.loadActiveRoles(users...); err != nil {
 		return nil, 0, err
 	}
 
 	return users, count, nil
 }
 
+func (r *userRepository) FindAllIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&entities.User{}).Order("created_at").Pluck("id", &ids).Error
+	return ids, err
+}
+
 func (r *userRepository) Update(user *entities.User) error {
-	return r.db.Save(user).Error
+	// Role assignments carry their own metadata and are only changed through AssignRoles
+	return r.db.Omit("Roles").Save(user).Error
 }
 
 func (r *userRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&entities.User{}, id).Error
 }
 
-func (r *userRepository) AssignRoles(userID uuid.UUID, roleIDs []uuid.UUID) error {
-	tx := r.db.Begin()
+// AssignRoles makes roleIDs the user's role set in the organization, uuid.Nil for assignments
+// outside any organization. Assignments made in other organizations are left alone. Assignments
+// that are kept retain their grant metadata and validity window, new ones are permanent.
+func (r *userRepository) AssignRoles(userID, organizationID uuid.UUID, roleIDs []uuid.UUID, grantedBy *uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Remove roles that are no longer assigned
+		remove := tx.Where("user_id = ? AND organization_id = ?", userID, organizationID)
+		if len(roleIDs) > 0 {
+			remove = remove.Where("role_id NOT IN ?", roleIDs)
+		}
+		if err := remove.Delete(&entities.UserRole{}).Error; err != nil {
+			return err
+		}
 
-	// Remove existing roles
-	if err := tx.Model(&entities.User{ID: userID}).Association("Roles").Clear(); err != nil {
-		tx.Rollback()
-		return err
+		// Add new roles
+		var assignments []*entities.UserRole
+		for _, roleID := range roleIDs {
+			assignments = append(assignments, &entities.UserRole{
+				UserID:         userID,
+				RoleID:         roleID,
+				OrganizationID: organizationID,
+				GrantedBy:      grantedBy,
+				GrantedAt:      time.Now(),
+			})
+		}
+		if len(assignments) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignments).Error
+	})
+}
+
+func (r *userRepository) AssignDeniedPermissions(userID uuid.UUID, permissionIDs []uuid.UUID) error {
+	var permissions []*entities.Permission
+	for _, permissionID := range permissionIDs {
+		permissions = append(permissions, &entities.Permission{ID: permissionID})
+	}
+
+	return r.db.Model(&entities.User{ID: userID}).Association("DeniedPermissions").Replace(permissions)
+}
+
+// FindRoleAssignments returns every assignment of the user, including scheduled and expired ones
+func (r *userRepository) FindRoleAssignments(userID uuid.UUID) ([]*entities.UserRole, error) {
+	var assignments []*entities.UserRole
+	if err := r.db.Preload("Role").Where("user_id = ?", userID).Order("granted_at").Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *userRepository) FindRoleAssignment(userID, roleID, organizationID uuid.UUID) (*entities.UserRole, error) {
+	var assignment entities.UserRole
+	err := r.db.Preload("Role").
+		Where("user_id = ? AND role_id = ? AND organization_id = ?", userID, roleID, organizationID).
+		First(&assignment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+func (r *userRepository) SaveRoleAssignment(assignment *entities.UserRole) error {
+	return r.db.Omit("Role").Save(assignment).Error
+}
+
+func (r *userRepository) DeleteRoleAssignment(userID, roleID, organizationID uuid.UUID) error {
+	return r.db.Where("user_id = ? AND role_id = ? AND organization_id = ?", userID, roleID, organizationID).
+		Delete(&entities.UserRole{}).Error
+}
+
+// FindExpiredRoleAssignments returns assignments whose expiry is at or before at
+func (r *userRepository) FindExpiredRoleAssignments(at time.Time) ([]*entities.UserRole, error) {
+	var assignments []*entities.UserRole
+	if err := r.db.Preload("Role").Where("expires_at <= ?", at).Find(&assignments).Error; err != nil {
+		return nil, err
 	}
+	return assignments, nil
+}
 
-	// Add new roles
-	var roles []*entities.Role
-	for _, roleID := range roleIDs {
-		roles = append(roles, &entities.Role{ID: roleID})
+// FindCurrentRoleAssignments returns the unexpired assignments of existing users to any of roleIDs
+// held by any of userIDs. An empty list leaves that side unfiltered.
+func (r *userRepository) FindCurrentRoleAssignments(roleIDs, userIDs []uuid.UUID) ([]*entities.UserRole, error) {
+	query := r.db.Preload("Role").
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Where("(user_roles.expires_at IS NULL OR user_roles.expires_at > ?)", time.Now())
+	if len(roleIDs) > 0 {
+		query = query.Where("user_roles.role_id IN ?", roleIDs)
+	}
+	if len(userIDs) > 0 {
+		query = query.Where("user_roles.user_id IN ?", userIDs)
 	}
 
-	if err := tx.Model(&entities.User{ID: userID}).Association("Roles").Append(roles); err != nil {
-		tx.Rollback()
+	var assignments []*entities.UserRole
+	if err := query.Order("user_roles.user_id, user_roles.role_id, user_roles.organization_id").Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// loadActiveRoles fills Roles with the roles whose assignment is currently in effect, in any
+// organization, and RoleAssignments with those assignments
+func (r *userRepository) loadActiveRoles(users ...*entities.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*entities.User, len(users))
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+		userIDs = append(userIDs, user.ID)
+	}
+
+	var assignments []*entities.UserRole
+	err := r.db.Preload("Role").
+		Scopes(activeRoleAssignments(time.Now())).
+		Where("user_id IN ?", userIDs).
+		Order("granted_at").
+		Find(&assignments).Error
+	if err != nil {
 		return err
 	}
 
-	return tx.Commit().Error
+	// A role assigned in several organizations is listed once in Roles
+	listed := make(map[[2]uuid.UUID]bool)
+	for _, assignment := range assignments {
+		// Deleted roles are not preloaded
+		if assignment.Role == nil {
+			continue
+		}
+		user := byID[assignment.UserID]
+		user.RoleAssignments = append(user.RoleAssignments, assignment)
+		if key := [2]uuid.UUID{assignment.UserID, assignment.RoleID}; !listed[key] {
+			listed[key] = true
+			user.Roles = append(user.Roles, assignment.Role)
+		}
+	}
+	return nil
+}
+
+// activeRoleAssignments limits user_roles to assignments in effect at t
+func activeRoleAssignments(t time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(user_roles.starts_at IS NULL OR user_roles.starts_at <= ?) AND (user_roles.expires_at IS NULL OR user_roles.expires_at > ?)", t, t)
+	}
 }
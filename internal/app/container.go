@@ -2,11 +2,15 @@ package app
 
 import (
 	"usermanagement-api/config"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/pkg/auditlog"
 	"usermanagement-api/pkg/auth"
 	"usermanagement-api/pkg/cache"
 	"usermanagement-api/pkg/database"
-	"usermanagement-api/pkg/firebase"
 	"usermanagement-api/pkg/logger"
+	"usermanagement-api/pkg/pubsub"
+	"usermanagement-api/pkg/push"
+	"usermanagement-api/pkg/rbac"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -14,11 +18,14 @@ import (
 
 // AppContainer holds all infrastructure dependencies
 type AppContainer struct {
-	Config    *config.Config
-	Logger    *zap.Logger
-	DB        *gorm.DB
-	Cache     cache.Cache
-	FCMClient firebase.FCMClient
+	Config     *config.Config
+	Logger     *zap.Logger
+	DB         *gorm.DB
+	Cache      cache.Cache
+	PubSub     pubsub.PubSub
+	PushDriver push.Driver
+	// AuditCheckpoints is nil when no checkpoint secret is configured
+	AuditCheckpoints *auditlog.CheckpointFile
 }
 
 // NewAppContainer creates and initializes a new AppContainer
@@ -40,18 +47,31 @@ func NewAppContainer() (*AppContainer, error) {
 	cacheInstance := cache.NewRedisCache(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB)
 	zapLogger.Info("Redis cache initialized", zap.String("addr", cfg.Redis.Addr))
 
-	// Initialize Firebase Cloud Messaging client (optional)
-	var fcmClient firebase.FCMClient
-	if cfg.Firebase.CredentialsFile != "" {
-		fcm, err := firebase.NewFCMClient(cfg.Firebase.CredentialsFile, zapLogger)
+	// Initialize Redis pub/sub used to fan out notification events across instances
+	pubsubInstance := pubsub.NewRedisPubSub(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB)
+
+	// Initialize push driver, falls back to disabled so sends fail cleanly instead of panicking
+	pushDriver, err := push.NewDriver(push.Config{
+		Driver:             cfg.Push.Driver,
+		FCMCredentialsFile: cfg.Firebase.CredentialsFile,
+		FilePath:           cfg.Push.FilePath,
+	}, zapLogger)
+	if err != nil {
+		zapLogger.Warn("Failed to initialize push driver, push notifications disabled", zap.Error(err))
+		pushDriver = push.NewDisabledDriver()
+	} else {
+		zapLogger.Info("Push driver initialized", zap.String("driver", cfg.Push.Driver))
+	}
+
+	// Initialize audit checkpoint file (optional)
+	var auditCheckpoints *auditlog.CheckpointFile
+	if cfg.Audit.CheckpointSecret != "" {
+		auditCheckpoints, err = auditlog.NewCheckpointFile(cfg.Audit.CheckpointFile, cfg.Audit.CheckpointSecret)
 		if err != nil {
-			zapLogger.Warn("Failed to initialize FCM client", zap.Error(err))
-		} else {
-			fcmClient = fcm
-			zapLogger.Info("FCM client initialized")
+			zapLogger.Warn("Failed to initialize audit checkpoints", zap.Error(err))
 		}
 	} else {
-		zapLogger.Info("FCM client skipped (no credentials file configured)")
+		zapLogger.Info("Audit checkpoints skipped (no checkpoint secret configured)")
 	}
 
 	// Connect to database
@@ -67,19 +87,61 @@ func NewAppContainer() (*AppContainer, error) {
 		return nil, err
 	}
 
+	// Seed the permissions used by route guards
+	if err := database.SeedPermissions(db, constants.BuiltinPermissions, constants.AdminRoleName, zapLogger); err != nil {
+		zapLogger.Fatal("Failed to seed permissions", zap.Error(err))
+		return nil, err
+	}
+
+	// Apply the RBAC manifest (optional)
+	if cfg.RBAC.SyncOnStartup {
+		if err := syncRBACManifest(db, cfg.RBAC, zapLogger); err != nil {
+			zapLogger.Fatal("Failed to sync RBAC manifest", zap.Error(err))
+			return nil, err
+		}
+	}
+
 	// Initialize JWT service and set as global for backward compatibility
 	jwtService := auth.NewJWTService(cfg.JWT)
 	auth.SetGlobalJWTService(jwtService)
 
 	return &AppContainer{
-		Config:    cfg,
-		Logger:    zapLogger,
-		DB:        db,
-		Cache:     cacheInstance,
-		FCMClient: fcmClient,
+		Config:           cfg,
+		Logger:           zapLogger,
+		DB:               db,
+		Cache:            cacheInstance,
+		PubSub:           pubsubInstance,
+		PushDriver:       pushDriver,
+		AuditCheckpoints: auditCheckpoints,
 	}, nil
 }
 
+// syncRBACManifest applies the configured manifest, never pruning the built-in permissions or the admin role
+func syncRBACManifest(db *gorm.DB, cfg config.RBACConfig, zapLogger *zap.Logger) error {
+	manifest, err := rbac.Load(cfg.ManifestFile)
+	if err != nil {
+		return err
+	}
+
+	changes, err := rbac.Sync(db, manifest, rbac.Options{
+		Prune:                cfg.Prune,
+		ProtectedPermissions: constants.BuiltinPermissionNames(),
+		ProtectedRoles:       []string{constants.AdminRoleName},
+	}, zapLogger)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		zapLogger.Info("RBAC manifest change",
+			zap.String("action", change.Action),
+			zap.String("kind", change.Kind),
+			zap.String("name", change.Name),
+			zap.String("detail", change.Detail))
+	}
+	return nil
+}
+
 // Close closes all connections in the container
 func (c *AppContainer) Close() error {
 	if c.DB != nil {
@@ -94,6 +156,13 @@ func (c *AppContainer) Close() error {
 		c.Logger.Info("Database connection closed")
 	}
 
+	if c.PubSub != nil {
+		if err := c.PubSub.Close(); err != nil {
+			c.Logger.Error("Failed to close pub/sub connection", zap.Error(err))
+			return err
+		}
+	}
+
 	// Sync logger before exit
 	if err := logger.Sync(); err != nil {
 		return err
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// backgroundJob is a periodic task run alongside the HTTP server
+type backgroundJob struct {
+	name     string
+	interval time.Duration
+	run      func() error
+}
+
+func (s *Server) backgroundJobs() []backgroundJob {
+	bc := s.businessContainer
+	log := s.appContainer.Logger
+
+	jobs := []backgroundJob{
+		{
+			name:     "notifications.dispatch_deferred",
+			interval: time.Minute,
+			run: func() error {
+				dispatched, err := bc.NotificationUseCase.DispatchDeferred()
+				if dispatched > 0 {
+					log.Info("Dispatched deferred notifications", zap.Int("count", dispatched))
+				}
+				return err
+			},
+		},
+		{
+			name:     "roles.expire_assignments",
+			interval: time.Minute,
+			run: func() error {
+				expired, err := bc.RoleAssignmentUseCase.ExpireAssignments(context.Background())
+				if expired > 0 {
+					log.Info("Expired role assignments", zap.Int("count", expired))
+				}
+				return err
+			},
+		},
+		{
+			name:     "access_requests.expire",
+			interval: time.Minute,
+			run: func() error {
+				expired, err := bc.AccessRequestUseCase.ExpireRequests(context.Background())
+				if expired > 0 {
+					log.Info("Expired access requests", zap.Int("count", expired))
+				}
+				return err
+			},
+		},
+		{
+			name:     "access_reviews.escalate",
+			interval: time.Minute,
+			run: func() error {
+				escalated, err := bc.AccessReviewUseCase.EscalateOverdue(context.Background())
+				if escalated > 0 {
+					log.Info("Escalated access review items", zap.Int("count", escalated))
+				}
+				return err
+			},
+		},
+		{
+			name:     "role_assignment_rules.sync",
+			interval: time.Hour,
+			run: func() error {
+				result, err := bc.RoleAssignmentRuleUseCase.Sync(context.Background())
+				if err == nil && result.Granted+result.Revoked > 0 {
+					log.Info("Synced role assignment rules", zap.Int("granted", result.Granted), zap.Int("revoked", result.Revoked))
+				}
+				return err
+			},
+		},
+	}
+
+	if s.appContainer.AuditCheckpoints != nil {
+		jobs = append(jobs, backgroundJob{
+			name:     "audit.checkpoint",
+			interval: time.Duration(s.appContainer.Config.Audit.CheckpointInterval) * time.Minute,
+			run: func() error {
+				written, err := bc.AuditUseCase.WriteCheckpoint()
+				if written {
+					log.Info("Audit checkpoint written")
+				}
+				return err
+			},
+		})
+	}
+
+	return jobs
+}
+
+// startBackgroundJobs runs every background job on its own ticker until ctx is cancelled
+func (s *Server) startBackgroundJobs(ctx context.Context) {
+	for _, job := range s.backgroundJobs() {
+		go s.runJob(ctx, job)
+	}
+}
+
+func (s *Server) runJob(ctx context.Context, job backgroundJob) {
+	log := s.appContainer.Logger
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job.run(); err != nil {
+				log.Error("Background job failed", zap.String("job", job.name), zap.Error(err))
+			}
+		}
+	}
+}
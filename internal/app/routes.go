@@ -1,7 +1,13 @@
 package app
 
+import (
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/delivery/http/middleware"
+)
+
 func (s *Server) setupRoutes() {
 	bc := s.businessContainer
+	can := bc.AuthMiddleware.RequirePermission
 
 	// Public routes
 	public := s.router.Group("/")
@@ -9,6 +15,9 @@ func (s *Server) setupRoutes() {
 	public.POST("/auth/login", bc.AuthHandler.Login)
 	public.POST("/auth/register", bc.AuthHandler.Register)
 
+	// The notification stream also takes the access token from the query, see EventStreamToken
+	s.router.GET("/notifications/stream", middleware.EventStreamToken(), bc.AuthMiddleware.RequireAuth(), bc.NotificationHandler.Stream)
+
 	// Protected routes
 	api := s.router.Group("/")
 	api.Use(bc.AuthMiddleware.RequireAuth())
@@ -17,59 +26,81 @@ func (s *Server) setupRoutes() {
 	auth := api.Group("/auth")
 	{
 		auth.GET("/permissions", bc.AuthHandler.GetUserPermissions)
-		auth.POST("/model-permissions", bc.AuthHandler.CreateModelPermission)
-		auth.GET("/model-permissions", bc.AuthHandler.GetModelPermissions)
+		auth.POST("/check", bc.AuthorizationHandler.CheckPermissions)
+		auth.POST("/model-permissions", can(constants.PermissionModelPermissionsWrite), bc.AuthHandler.CreateModelPermission)
+		auth.GET("/model-permissions", can(constants.PermissionModelPermissionsRead), bc.AuthHandler.GetModelPermissions)
+		auth.PUT("/model-permissions", can(constants.PermissionModelPermissionsWrite), bc.AuthHandler.ReplaceModelPermissions)
+		auth.DELETE("/model-permissions/:id", can(constants.PermissionModelPermissionsWrite), bc.AuthHandler.DeleteModelPermission)
 		auth.GET("/info", bc.AuthHandler.GetUser)
+		auth.GET("/organizations", bc.AuthHandler.GetOrganizations)
+		auth.POST("/switch-org", bc.AuthHandler.SwitchOrganization)
 		auth.POST("/metas", bc.AuthHandler.CreateMeta)
 		auth.GET("/metas", bc.AuthHandler.GetUserMeta)
+		auth.GET("/notification-preferences", bc.NotificationHandler.GetPreferences)
+		auth.PUT("/notification-preferences", bc.NotificationHandler.UpdatePreferences)
 	}
 
 	// User routes
-	users := api.Group("/users").Use(bc.AuthMiddleware.RequireRole("admin"))
+	users := api.Group("/users")
 	{
-		users.GET("", bc.UserHandler.GetAllUsers)
-		users.POST("", bc.UserHandler.CreateUser)
-		users.GET("/:id", bc.UserHandler.GetUser)
-		users.PUT("/:id", bc.UserHandler.UpdateUser)
-		users.DELETE("/:id", bc.UserHandler.DeleteUser)
-		users.POST("/:id/roles", bc.UserHandler.AssignRoles)
+		users.GET("", can(constants.PermissionUsersRead), bc.UserHandler.GetAllUsers)
+		users.POST("", can(constants.PermissionUsersWrite), bc.UserHandler.CreateUser)
+		users.GET("/:id", can(constants.PermissionUsersRead), bc.UserHandler.GetUser)
+		users.PUT("/:id", can(constants.PermissionUsersWrite), bc.UserHandler.UpdateUser)
+		users.DELETE("/:id", can(constants.PermissionUsersDelete), bc.UserHandler.DeleteUser)
+		users.POST("/:id/roles", can(constants.PermissionUsersWrite), bc.UserHandler.AssignRoles)
+		users.GET("/:id/roles/:roleId/explain", can(constants.PermissionUsersRead), bc.AuthorizationHandler.ExplainRole)
+		users.GET("/:id/effective-permissions", can(constants.PermissionUsersRead), bc.AuthorizationHandler.GetEffectivePermissions)
+		users.POST("/:id/denied-permissions", can(constants.PermissionUsersWrite), bc.UserHandler.AssignDeniedPermissions)
+		users.GET("/:id/role-assignments", can(constants.PermissionUsersRead), bc.RoleAssignmentHandler.GetRoleAssignments)
+		users.POST("/:id/role-assignments", can(constants.PermissionUsersWrite), bc.RoleAssignmentHandler.GrantRole)
+		users.DELETE("/:id/role-assignments/:roleId", can(constants.PermissionUsersWrite), bc.RoleAssignmentHandler.RevokeRole)
 	}
 
 	// Role routes
 	roles := api.Group("/roles")
 	{
-		roles.GET("", bc.RoleHandler.GetAllRoles)
-		roles.POST("", bc.RoleHandler.CreateRole)
-		roles.GET("/:id", bc.RoleHandler.GetRole)
-		roles.PUT("/:id", bc.RoleHandler.UpdateRole)
-		roles.DELETE("/:id", bc.RoleHandler.DeleteRole)
-		roles.POST("/:id/permissions", bc.RoleHandler.AssignPermissions)
+		roles.GET("", can(constants.PermissionRolesRead), bc.RoleHandler.GetAllRoles)
+		roles.POST("", can(constants.PermissionRolesWrite), bc.RoleHandler.CreateRole)
+		roles.GET("/:id", can(constants.PermissionRolesRead), bc.RoleHandler.GetRole)
+		roles.PUT("/:id", can(constants.PermissionRolesWrite), bc.RoleHandler.UpdateRole)
+		roles.DELETE("/:id", can(constants.PermissionRolesDelete), bc.RoleHandler.DeleteRole)
+		roles.POST("/:id/permissions", can(constants.PermissionRolesWrite), bc.RoleHandler.AssignPermissions)
+		roles.POST("/:id/parents", can(constants.PermissionRolesWrite), bc.RoleHandler.AssignParents)
+		roles.POST("/:id/denied-permissions", can(constants.PermissionRolesWrite), bc.RoleHandler.AssignDeniedPermissions)
+		roles.POST("/:id/approvers", can(constants.PermissionRolesWrite), bc.RoleHandler.AssignApprovers)
+		roles.POST("/:id/delegation", can(constants.PermissionRolesWrite), bc.RoleHandler.AssignDelegation)
 	}
 
 	// Permission routes
 	permissions := api.Group("/permissions")
 	{
-		permissions.GET("", bc.PermissionHandler.GetAllPermissions)
-		permissions.POST("", bc.PermissionHandler.CreatePermission)
-		permissions.GET("/:id", bc.PermissionHandler.GetPermission)
-		permissions.PUT("/:id", bc.PermissionHandler.UpdatePermission)
-		permissions.DELETE("/:id", bc.PermissionHandler.DeletePermission)
+		permissions.GET("", can(constants.PermissionPermissionsRead), bc.PermissionHandler.GetAllPermissions)
+		permissions.POST("", can(constants.PermissionPermissionsWrite), bc.PermissionHandler.CreatePermission)
+		permissions.GET("/:id", can(constants.PermissionPermissionsRead), bc.PermissionHandler.GetPermission)
+		permissions.PUT("/:id", can(constants.PermissionPermissionsWrite), bc.PermissionHandler.UpdatePermission)
+		permissions.DELETE("/:id", can(constants.PermissionPermissionsDelete), bc.PermissionHandler.DeletePermission)
 	}
 
 	// Menu routes
 	menus := api.Group("/menus")
 	{
-		menus.GET("", bc.MenuHandler.GetAllMenus)
+		menus.GET("", can(constants.PermissionMenusRead), bc.MenuHandler.GetAllMenus)
+		// Active menus drive navigation for every signed-in user
 		menus.GET("/active", bc.MenuHandler.GetActiveMenus)
-		menus.POST("", bc.MenuHandler.CreateMenu)
-		menus.GET("/:id", bc.MenuHandler.GetMenu)
-		menus.PUT("/:id", bc.MenuHandler.UpdateMenu)
-		menus.DELETE("/:id", bc.MenuHandler.DeleteMenu)
-		menus.GET("/permissions", bc.MenuHandler.GetMenuPermissions)
+		menus.POST("", can(constants.PermissionMenusWrite), bc.MenuHandler.CreateMenu)
+		menus.GET("/:id", can(constants.PermissionMenusRead), bc.MenuHandler.GetMenu)
+		menus.PUT("/:id", can(constants.PermissionMenusWrite), bc.MenuHandler.UpdateMenu)
+		menus.DELETE("/:id", can(constants.PermissionMenusDelete), bc.MenuHandler.DeleteMenu)
+		menus.GET("/permissions", can(constants.PermissionMenusRead), bc.MenuHandler.GetMenuPermissions)
 	}
 
+	// User meta routes, ownership and key visibility are enforced by the use case
 	userMeta := api.Group("/user-meta")
 	{
+		userMeta.GET("/keys", can(constants.PermissionUserMetaManage), bc.UserMetaHandler.GetKeyPolicies)
+		userMeta.PUT("/keys/:key", can(constants.PermissionUserMetaManage), bc.UserMetaHandler.SetKeyPolicy)
+		userMeta.DELETE("/keys/:key", can(constants.PermissionUserMetaManage), bc.UserMetaHandler.DeleteKeyPolicy)
 		userMeta.POST("", bc.UserMetaHandler.CreateOrUpdate)
 		userMeta.GET("/:user_id", bc.UserMetaHandler.GetAllByUserID)
 		userMeta.GET("/:user_id/:key", bc.UserMetaHandler.GetByKey)
@@ -77,24 +108,124 @@ func (s *Server) setupRoutes() {
 	}
 
 	// Setting routes
-	settings := api.Group("/settings").Use(bc.AuthMiddleware.RequireRole("admin"))
+	settings := api.Group("/settings")
+	{
+		settings.POST("", can(constants.PermissionSettingsWrite), bc.SettingHandler.CreateOrUpdate)
+		settings.GET("", can(constants.PermissionSettingsRead), bc.SettingHandler.GetAll)
+		settings.GET("/:key", can(constants.PermissionSettingsRead), bc.SettingHandler.GetByKey)
+		settings.DELETE("/:key", can(constants.PermissionSettingsDelete), bc.SettingHandler.Delete)
+	}
+
+	// Audit routes
+	audit := api.Group("/audit")
+	{
+		audit.GET("", can(constants.PermissionAuditRead), bc.AuditHandler.GetAuditEvents)
+	}
+
+	// Access policy routes
+	policies := api.Group("/policies")
+	{
+		policies.GET("", can(constants.PermissionPoliciesRead), bc.PolicyHandler.GetAllPolicies)
+		policies.POST("", can(constants.PermissionPoliciesWrite), bc.PolicyHandler.CreatePolicy)
+		policies.POST("/evaluate", can(constants.PermissionPoliciesRead), bc.PolicyHandler.EvaluatePolicy)
+		policies.GET("/:id", can(constants.PermissionPoliciesRead), bc.PolicyHandler.GetPolicy)
+		policies.PUT("/:id", can(constants.PermissionPoliciesWrite), bc.PolicyHandler.UpdatePolicy)
+		policies.DELETE("/:id", can(constants.PermissionPoliciesDelete), bc.PolicyHandler.DeletePolicy)
+	}
+
+	// Group routes
+	groups := api.Group("/groups")
+	{
+		groups.GET("", can(constants.PermissionGroupsRead), bc.GroupHandler.GetAllGroups)
+		groups.POST("", can(constants.PermissionGroupsWrite), bc.GroupHandler.CreateGroup)
+		groups.GET("/:id", can(constants.PermissionGroupsRead), bc.GroupHandler.GetGroup)
+		groups.PUT("/:id", can(constants.PermissionGroupsWrite), bc.GroupHandler.UpdateGroup)
+		groups.DELETE("/:id", can(constants.PermissionGroupsDelete), bc.GroupHandler.DeleteGroup)
+		groups.GET("/:id/members", can(constants.PermissionGroupsRead), bc.GroupHandler.GetGroupMembers)
+		groups.POST("/:id/members", can(constants.PermissionGroupsWrite), bc.GroupHandler.AddGroupMembers)
+		groups.DELETE("/:id/members/:userId", can(constants.PermissionGroupsWrite), bc.GroupHandler.RemoveGroupMember)
+		groups.POST("/:id/roles", can(constants.PermissionGroupsWrite), bc.GroupHandler.AssignGroupRoles)
+		groups.POST("/:id/parents", can(constants.PermissionGroupsWrite), bc.GroupHandler.AssignGroupParents)
+	}
+
+	// Separation-of-duties constraint routes
+	roleConstraints := api.Group("/role-constraints")
+	{
+		roleConstraints.GET("", can(constants.PermissionRoleConstraintsRead), bc.RoleConstraintHandler.GetAllRoleConstraints)
+		roleConstraints.POST("", can(constants.PermissionRoleConstraintsWrite), bc.RoleConstraintHandler.CreateRoleConstraint)
+		roleConstraints.GET("/:id", can(constants.PermissionRoleConstraintsRead), bc.RoleConstraintHandler.GetRoleConstraint)
+		roleConstraints.PUT("/:id", can(constants.PermissionRoleConstraintsWrite), bc.RoleConstraintHandler.UpdateRoleConstraint)
+		roleConstraints.DELETE("/:id", can(constants.PermissionRoleConstraintsDelete), bc.RoleConstraintHandler.DeleteRoleConstraint)
+		roleConstraints.GET("/:id/violations", can(constants.PermissionRoleConstraintsRead), bc.RoleConstraintHandler.GetRoleConstraintViolations)
+	}
+
+	// Rules granting roles automatically from user attributes
+	roleRules := api.Group("/role-assignment-rules")
+	{
+		roleRules.GET("", can(constants.PermissionRoleAssignmentRulesRead), bc.RoleAssignmentRuleHandler.GetAllRoleAssignmentRules)
+		roleRules.POST("", can(constants.PermissionRoleAssignmentRulesWrite), bc.RoleAssignmentRuleHandler.CreateRoleAssignmentRule)
+		roleRules.POST("/sync", can(constants.PermissionRoleAssignmentRulesWrite), bc.RoleAssignmentRuleHandler.SyncRoleAssignmentRules)
+		roleRules.GET("/:id", can(constants.PermissionRoleAssignmentRulesRead), bc.RoleAssignmentRuleHandler.GetRoleAssignmentRule)
+		roleRules.PUT("/:id", can(constants.PermissionRoleAssignmentRulesWrite), bc.RoleAssignmentRuleHandler.UpdateRoleAssignmentRule)
+		roleRules.DELETE("/:id", can(constants.PermissionRoleAssignmentRulesDelete), bc.RoleAssignmentRuleHandler.DeleteRoleAssignmentRule)
+	}
+
+	// Self-service access request routes, approvers are checked per role by the use case
+	accessRequests := api.Group("/access-requests")
+	{
+		accessRequests.POST("", bc.AccessRequestHandler.CreateAccessRequest)
+		accessRequests.GET("/mine", bc.AccessRequestHandler.GetMyAccessRequests)
+		accessRequests.GET("/approvals", bc.AccessRequestHandler.GetApprovalAccessRequests)
+		accessRequests.GET("/:id", bc.AccessRequestHandler.GetAccessRequest)
+		accessRequests.POST("/:id/approve", bc.AccessRequestHandler.ApproveAccessRequest)
+		accessRequests.POST("/:id/reject", bc.AccessRequestHandler.RejectAccessRequest)
+		accessRequests.POST("/:id/cancel", bc.AccessRequestHandler.CancelAccessRequest)
+	}
+
+	// Access review campaign routes, reviewers are checked per item by the use case
+	accessReviews := api.Group("/access-reviews")
+	{
+		accessReviews.GET("/assigned", bc.AccessReviewHandler.GetMyAccessReviewItems)
+		accessReviews.POST("/items/:itemId/decision", bc.AccessReviewHandler.DecideAccessReviewItem)
+
+		accessReviews.GET("", can(constants.PermissionAccessReviewsRead), bc.AccessReviewHandler.GetAllAccessReviews)
+		accessReviews.POST("", can(constants.PermissionAccessReviewsWrite), bc.AccessReviewHandler.CreateAccessReview)
+		accessReviews.GET("/:id", can(constants.PermissionAccessReviewsRead), bc.AccessReviewHandler.GetAccessReview)
+		accessReviews.GET("/:id/items", can(constants.PermissionAccessReviewsRead), bc.AccessReviewHandler.GetAccessReviewItems)
+		accessReviews.GET("/:id/report.csv", can(constants.PermissionAccessReviewsRead), bc.AccessReviewHandler.ExportAccessReview)
+		accessReviews.POST("/:id/close", can(constants.PermissionAccessReviewsWrite), bc.AccessReviewHandler.CloseAccessReview)
+	}
+
+	// Access simulation routes
+	accessSimulations := api.Group("/access-simulations")
+	{
+		accessSimulations.POST("", can(constants.PermissionUsersRead, constants.PermissionRolesRead), bc.AccessSimulationHandler.Simulate)
+	}
+
+	// Organization routes
+	organizations := api.Group("/organizations")
 	{
-		settings.POST("", bc.SettingHandler.CreateOrUpdate)
-		settings.GET("", bc.SettingHandler.GetAll)
-		settings.GET("/:key", bc.SettingHandler.GetByKey)
-		settings.DELETE("/:key", bc.SettingHandler.Delete)
+		organizations.GET("", can(constants.PermissionOrganizationsRead), bc.OrganizationHandler.GetAllOrganizations)
+		organizations.POST("", can(constants.PermissionOrganizationsWrite), bc.OrganizationHandler.CreateOrganization)
+		organizations.GET("/:id", can(constants.PermissionOrganizationsRead), bc.OrganizationHandler.GetOrganization)
+		organizations.PUT("/:id", can(constants.PermissionOrganizationsWrite), bc.OrganizationHandler.UpdateOrganization)
+		organizations.DELETE("/:id", can(constants.PermissionOrganizationsDelete), bc.OrganizationHandler.DeleteOrganization)
+		organizations.GET("/:id/members", can(constants.PermissionOrganizationsRead), bc.OrganizationHandler.GetOrganizationMembers)
+		organizations.POST("/:id/members", can(constants.PermissionOrganizationsWrite), bc.OrganizationHandler.AddOrganizationMembers)
+		organizations.DELETE("/:id/members/:userId", can(constants.PermissionOrganizationsWrite), bc.OrganizationHandler.RemoveOrganizationMember)
 	}
 
 	notifications := api.Group("/notifications")
-	notifications.Use(bc.AuthMiddleware.RequireAuth())
 	{
 		notifications.POST("/send-to-me", bc.NotificationHandler.SendToMe)
+		notifications.GET("/inbox", bc.NotificationHandler.GetInbox)
+		notifications.POST("/inbox/read-all", bc.NotificationHandler.MarkAllAsRead)
+		notifications.POST("/inbox/:id/read", bc.NotificationHandler.MarkAsRead)
+		notifications.POST("/:id/opened", bc.NotificationHandler.MarkOpened)
 
-		// Admin/Superuser only routes
-		adminNotif := notifications.Group("")
-		adminNotif.Use(bc.AuthMiddleware.RequireRole("admin", "superuser"))
-		{
-			adminNotif.POST("/send", bc.NotificationHandler.SendNotification)
-		}
+		notifications.POST("/send", can(constants.PermissionNotificationsSend), bc.NotificationHandler.SendNotification)
+		notifications.GET("", can(constants.PermissionNotificationsRead), bc.NotificationHandler.ListNotifications)
+		notifications.GET("/:id/report", can(constants.PermissionNotificationsRead), bc.NotificationHandler.GetReport)
+		notifications.GET("/:id/deliveries", can(constants.PermissionNotificationsRead), bc.NotificationHandler.GetDeliveries)
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 	"usermanagement-api/internal/container"
+	"usermanagement-api/internal/delivery/http/middleware"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -33,7 +34,13 @@ func NewServer(appContainer *AppContainer, businessContainer *container.Business
 func (s *Server) Initialize() error {
 	// Initialize router
 	s.router = gin.Default()
+	// Client IPs are recorded in audit events and matched by access policies, only believe
+	// X-Forwarded-For from the configured proxies
+	if err := s.router.SetTrustedProxies(s.appContainer.Config.Server.TrustedProxies); err != nil {
+		return err
+	}
 	s.router.Use(s.businessContainer.CORSMiddleware.SetupCORS())
+	s.router.Use(middleware.RequestContext())
 
 	// Setup routes
 	s.setupRoutes()
@@ -80,6 +87,9 @@ func (s *Server) Run() error {
 		serverStopCtx()
 	}()
 
+	// Run periodic jobs until shutdown
+	s.startBackgroundJobs(serverCtx)
+
 	// Run the server
 	addr := fmt.Sprintf("%s:%d", s.appContainer.Config.Server.Host, s.appContainer.Config.Server.Port)
 	log.Info("Server is running", zap.String("addr", addr))
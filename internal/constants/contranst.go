@@ -3,10 +3,13 @@ package constants
 // Context keys
 const (
 	UserIDKey      = "userID"
+	UserKey        = "user"
 	UserRolesKey   = "userRoles"
 	PermissionsKey = "permissions"
-	AccessToken    = "access_token"
-	RefreshToken   = "refresh_token"
+	// DeniedPermissionsKey holds the caller's explicit denies from the user and its roles
+	DeniedPermissionsKey = "deniedPermissions"
+	AccessToken          = "access_token"
+	RefreshToken         = "refresh_token"
 )
 
 // Authentication errors
@@ -26,3 +29,212 @@ const (
 	ModelTypeRole = "role"
 	ModelTypeMenu = "menu"
 )
+
+// Notification stream
+const (
+	NotificationChannelPrefix    = "notifications:user:"
+	NotificationEventInboxItem   = "inbox_item"
+	NotificationEventUnreadCount = "unread_count"
+)
+
+// Notification delivery tracking
+const (
+	NotificationTargetUser  = "user"
+	NotificationTargetUsers = "users"
+	NotificationTargetTopic = "topic"
+	NotificationTargetAll   = "all"
+
+	DeliveryChannelPush  = "push"
+	DeliveryChannelInApp = "in_app"
+
+	DeliveryStatusSent   = "sent"
+	DeliveryStatusFailed = "failed"
+	DeliveryStatusOpened = "opened"
+	DeliveryStatusRead   = "read"
+
+	DeliveryStatusDeferred   = "deferred"
+	DeliveryStatusSuppressed = "suppressed"
+	// DeliveryStatusDispatching marks a deferred delivery claimed by the dispatch job
+	DeliveryStatusDispatching = "dispatching"
+
+	DeliveryErrorNoDeviceToken   = "no_device_token"
+	DeliveryErrorCategoryMuted   = "category_muted"
+	DeliveryErrorChannelDisabled = "channel_disabled"
+	DeliveryErrorQuietHours      = "quiet_hours"
+	DeliveryErrorDeviceLookup    = "device_lookup_error"
+)
+
+// Notification categories and preferences
+const (
+	NotificationCategoryGeneral   = "general"
+	NotificationCategoryAccount   = "account"
+	NotificationCategorySecurity  = "security"
+	NotificationCategoryMarketing = "marketing"
+	NotificationCategorySystem    = "system"
+
+	QuietHoursModeDefer    = "defer"
+	QuietHoursModeSuppress = "suppress"
+)
+
+// Audit actions and target types
+const (
+	AuditTargetUser            = "user"
+	AuditTargetRole            = "role"
+	AuditTargetPermission      = "permission"
+	AuditTargetMenu            = "menu"
+	AuditTargetSetting         = "setting"
+	AuditTargetModelPermission = "model_permission"
+	AuditTargetUserMetaKey     = "user_meta_key"
+	AuditTargetPolicy          = "policy"
+	AuditTargetOrganization    = "organization"
+	AuditTargetGroup           = "group"
+	AuditTargetRoleConstraint  = "role_constraint"
+	AuditTargetAccessRequest   = "access_request"
+	AuditTargetAccessReview    = "access_review"
+	AuditTargetRoleRule        = "role_assignment_rule"
+
+	AuditActionUserCreate            = "user.create"
+	AuditActionUserUpdate            = "user.update"
+	AuditActionUserDelete            = "user.delete"
+	AuditActionUserAssignRoles       = "user.assign_roles"
+	AuditActionUserAssignDenied      = "user.assign_denied_permissions"
+	AuditActionUserGrantRole         = "user.grant_role"
+	AuditActionUserRevokeRole        = "user.revoke_role"
+	AuditActionUserRoleExpired       = "user.role_expired"
+	AuditActionUserAutoGrantRole     = "user.auto_grant_role"
+	AuditActionUserAutoRevokeRole    = "user.auto_revoke_role"
+	AuditActionRoleCreate            = "role.create"
+	AuditActionRoleUpdate            = "role.update"
+	AuditActionRoleDelete            = "role.delete"
+	AuditActionRoleAssignPermissions = "role.assign_permissions"
+	AuditActionRoleAssignParents     = "role.assign_parents"
+	AuditActionRoleAssignDenied      = "role.assign_denied_permissions"
+	AuditActionRoleAssignApprovers   = "role.assign_approvers"
+	AuditActionRoleAssignDelegation  = "role.assign_delegation"
+	AuditActionPermissionCreate      = "permission.create"
+	AuditActionPermissionUpdate      = "permission.update"
+	AuditActionPermissionDelete      = "permission.delete"
+	AuditActionMenuCreate            = "menu.create"
+	AuditActionMenuUpdate            = "menu.update"
+	AuditActionMenuDelete            = "menu.delete"
+	AuditActionSettingUpsert         = "setting.upsert"
+	AuditActionSettingDelete         = "setting.delete"
+	AuditActionModelPermissionCreate = "model_permission.create"
+	AuditActionModelPermissionDelete = "model_permission.delete"
+	AuditActionModelPermissionSet    = "model_permission.replace"
+	AuditActionUserMetaKeyUpsert     = "user_meta_key.upsert"
+	AuditActionUserMetaKeyDelete     = "user_meta_key.delete"
+	AuditActionPolicyCreate          = "policy.create"
+	AuditActionPolicyUpdate          = "policy.update"
+	AuditActionPolicyDelete          = "policy.delete"
+	AuditActionOrganizationCreate    = "organization.create"
+	AuditActionOrganizationUpdate    = "organization.update"
+	AuditActionOrganizationDelete    = "organization.delete"
+	AuditActionOrganizationAddMember = "organization.add_member"
+	AuditActionOrganizationDelMember = "organization.remove_member"
+	AuditActionGroupCreate           = "group.create"
+	AuditActionGroupUpdate           = "group.update"
+	AuditActionGroupDelete           = "group.delete"
+	AuditActionGroupAddMembers       = "group.add_members"
+	AuditActionGroupRemoveMember     = "group.remove_member"
+	AuditActionGroupAssignRoles      = "group.assign_roles"
+	AuditActionGroupAssignParents    = "group.assign_parents"
+	AuditActionRoleConstraintCreate  = "role_constraint.create"
+	AuditActionRoleConstraintUpdate  = "role_constraint.update"
+	AuditActionRoleConstraintDelete  = "role_constraint.delete"
+	AuditActionAccessRequestCreate   = "access_request.create"
+	AuditActionAccessRequestApprove  = "access_request.approve"
+	AuditActionAccessRequestReject   = "access_request.reject"
+	AuditActionAccessRequestCancel   = "access_request.cancel"
+	AuditActionAccessRequestExpire   = "access_request.expire"
+	AuditActionAccessReviewCreate    = "access_review.create"
+	AuditActionAccessReviewDecide    = "access_review.decide"
+	AuditActionAccessReviewEscalate  = "access_review.escalate"
+	AuditActionAccessReviewClose     = "access_review.close"
+	AuditActionRoleRuleCreate        = "role_assignment_rule.create"
+	AuditActionRoleRuleUpdate        = "role_assignment_rule.update"
+	AuditActionRoleRuleDelete        = "role_assignment_rule.delete"
+)
+
+// User meta key visibility
+const (
+	// MetaVisibilityDefault keys can be managed by their owner and by user_meta.manage holders
+	MetaVisibilityDefault = "default"
+	// MetaVisibilityPrivate keys can only be read or written by their owner
+	MetaVisibilityPrivate = "private"
+	// MetaVisibilityAdminOnly keys can only be read or written by user_meta.manage holders
+	MetaVisibilityAdminOnly = "admin_only"
+)
+
+// DefaultMetaVisibility applies to keys without a stored visibility policy
+var DefaultMetaVisibility = map[string]string{
+	"fcm_token": MetaVisibilityPrivate,
+}
+
+// Permission decision sources reported by the check API
+const (
+	DecisionSourceSuperuser       = "superuser"
+	DecisionSourceRole            = "role"
+	DecisionSourceModelPermission = "model_permission"
+	DecisionSourcePolicy          = "policy"
+	DecisionSourceNone            = "none"
+	DecisionSourceUserDeny        = "user_deny"
+	DecisionSourceRoleDeny        = "role_deny"
+)
+
+// Role assignment statuses
+const (
+	RoleAssignmentActive    = "active"
+	RoleAssignmentScheduled = "scheduled"
+	RoleAssignmentExpired   = "expired"
+)
+
+// Access request statuses, only pending requests can change
+const (
+	AccessRequestPending   = "pending"
+	AccessRequestApproved  = "approved"
+	AccessRequestRejected  = "rejected"
+	AccessRequestCancelled = "cancelled"
+	AccessRequestExpired   = "expired"
+)
+
+// Access review campaign statuses
+const (
+	AccessReviewOpen   = "open"
+	AccessReviewClosed = "closed"
+)
+
+// Access review item decisions
+const (
+	AccessReviewPending = "pending"
+	AccessReviewKeep    = "keep"
+	AccessReviewRevoke  = "revoke"
+)
+
+// Attributes a role assignment rule can match on, meta attributes are "meta.<key>"
+const (
+	RoleRuleAttributeEmailDomain = "email_domain"
+	RoleRuleAttributeMetaPrefix  = "meta."
+)
+
+// Users a delegated admin role lets its holders manage
+const (
+	// ManageableUsersOrganization covers the members of the organization the holder acts in
+	ManageableUsersOrganization = "organization"
+	// ManageableUsersGroup covers the members of the holder's groups and of groups nested in them
+	ManageableUsersGroup = "group"
+)
+
+// Ways a user can hold a role
+const (
+	RoleSourceDirect = "direct"
+	RoleSourceGroup  = "group"
+)
+
+// Ways a user can receive a permission, as listed by the effective permissions view
+const (
+	PermissionSourceRole          = "role"
+	PermissionSourceInheritedRole = "inherited_role"
+	PermissionSourceGroup         = "group"
+	PermissionSourceSuperuser     = "superuser"
+)
@@ -0,0 +1,150 @@
+package constants
+
+import (
+	"sort"
+	"strings"
+)
+
+// Permission names checked by route guards. They are created on startup if missing,
+// and granted to the admin role when first created.
+const (
+	PermissionUsersRead   = "users.read"
+	PermissionUsersWrite  = "users.write"
+	PermissionUsersDelete = "users.delete"
+
+	PermissionRolesRead   = "roles.read"
+	PermissionRolesWrite  = "roles.write"
+	PermissionRolesDelete = "roles.delete"
+
+	PermissionPermissionsRead   = "permissions.read"
+	PermissionPermissionsWrite  = "permissions.write"
+	PermissionPermissionsDelete = "permissions.delete"
+	PermissionPermissionsCheck  = "permissions.check"
+
+	PermissionMenusRead   = "menus.read"
+	PermissionMenusWrite  = "menus.write"
+	PermissionMenusDelete = "menus.delete"
+
+	PermissionSettingsRead   = "settings.read"
+	PermissionSettingsWrite  = "settings.write"
+	PermissionSettingsDelete = "settings.delete"
+
+	PermissionModelPermissionsRead  = "model_permissions.read"
+	PermissionModelPermissionsWrite = "model_permissions.write"
+
+	PermissionNotificationsSend = "notifications.send"
+	PermissionNotificationsRead = "notifications.read"
+
+	PermissionAuditRead = "audit.read"
+
+	PermissionUserMetaManage = "user_meta.manage"
+
+	PermissionPoliciesRead   = "policies.read"
+	PermissionPoliciesWrite  = "policies.write"
+	PermissionPoliciesDelete = "policies.delete"
+
+	PermissionOrganizationsRead   = "organizations.read"
+	PermissionOrganizationsWrite  = "organizations.write"
+	PermissionOrganizationsDelete = "organizations.delete"
+
+	PermissionGroupsRead   = "groups.read"
+	PermissionGroupsWrite  = "groups.write"
+	PermissionGroupsDelete = "groups.delete"
+
+	PermissionRoleConstraintsRead   = "role_constraints.read"
+	PermissionRoleConstraintsWrite  = "role_constraints.write"
+	PermissionRoleConstraintsDelete = "role_constraints.delete"
+
+	PermissionAccessReviewsRead  = "access_reviews.read"
+	PermissionAccessReviewsWrite = "access_reviews.write"
+
+	PermissionRoleAssignmentRulesRead   = "role_assignment_rules.read"
+	PermissionRoleAssignmentRulesWrite  = "role_assignment_rules.write"
+	PermissionRoleAssignmentRulesDelete = "role_assignment_rules.delete"
+)
+
+// AdminRoleName is the role that receives every built-in permission when it is first seeded
+const AdminRoleName = "admin"
+
+// globalPermissionResources are the resources whose permissions act on every organization at
+// once. Roles of an organization cannot carry them.
+var globalPermissionResources = map[string]bool{
+	"permissions":      true,
+	"organizations":    true,
+	"policies":         true,
+	"role_constraints": true,
+	"access_reviews":   true,
+}
+
+// IsGlobalPermission reports whether the permission belongs to a resource shared by every organization
+func IsGlobalPermission(name string) bool {
+	resource, _, _ := strings.Cut(name, ".")
+	return globalPermissionResources[resource]
+}
+
+// BuiltinPermissions lists every permission used by route guards, with its description
+var BuiltinPermissions = map[string]string{
+	PermissionUsersRead:   "View users",
+	PermissionUsersWrite:  "Create and update users and their roles",
+	PermissionUsersDelete: "Delete users",
+
+	PermissionRolesRead:   "View roles",
+	PermissionRolesWrite:  "Create and update roles and their permissions",
+	PermissionRolesDelete: "Delete roles",
+
+	PermissionPermissionsRead:   "View permissions",
+	PermissionPermissionsWrite:  "Create and update permissions",
+	PermissionPermissionsDelete: "Delete permissions",
+	PermissionPermissionsCheck:  "Check permissions on behalf of other users",
+
+	PermissionMenusRead:   "View menus",
+	PermissionMenusWrite:  "Create and update menus",
+	PermissionMenusDelete: "Delete menus",
+
+	PermissionSettingsRead:   "View settings",
+	PermissionSettingsWrite:  "Create and update settings",
+	PermissionSettingsDelete: "Delete settings",
+
+	PermissionModelPermissionsRead:  "View model permissions",
+	PermissionModelPermissionsWrite: "Grant model permissions",
+
+	PermissionNotificationsSend: "Send notifications to other users",
+	PermissionNotificationsRead: "View sent notifications and delivery reports",
+
+	PermissionAuditRead: "View the audit log",
+
+	PermissionUserMetaManage: "Manage other users' meta and meta key visibility",
+
+	PermissionPoliciesRead:   "View access policies and evaluate them",
+	PermissionPoliciesWrite:  "Create and update access policies",
+	PermissionPoliciesDelete: "Delete access policies",
+
+	PermissionOrganizationsRead:   "View organizations and their members",
+	PermissionOrganizationsWrite:  "Create and update organizations and manage their members",
+	PermissionOrganizationsDelete: "Delete organizations",
+
+	PermissionGroupsRead:   "View groups and their members",
+	PermissionGroupsWrite:  "Create and update groups, their members and their roles",
+	PermissionGroupsDelete: "Delete groups",
+
+	PermissionRoleConstraintsRead:   "View separation-of-duties constraints and their violations",
+	PermissionRoleConstraintsWrite:  "Create and update separation-of-duties constraints",
+	PermissionRoleConstraintsDelete: "Delete separation-of-duties constraints",
+
+	PermissionAccessReviewsRead:  "View access review campaigns and export their reports",
+	PermissionAccessReviewsWrite: "Launch and close access review campaigns",
+
+	PermissionRoleAssignmentRulesRead:   "View automatic role assignment rules",
+	PermissionRoleAssignmentRulesWrite:  "Create and update automatic role assignment rules",
+	PermissionRoleAssignmentRulesDelete: "Delete automatic role assignment rules",
+}
+
+// BuiltinPermissionNames returns the names in BuiltinPermissions, sorted
+func BuiltinPermissionNames() []string {
+	names := make([]string, 0, len(BuiltinPermissions))
+	for name := range BuiltinPermissions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,13 +1,16 @@
 package container
 
 import (
+	"time"
 	"usermanagement-api/config"
 	"usermanagement-api/domain/repositories"
 	"usermanagement-api/internal/delivery/http/handlers"
 	"usermanagement-api/internal/delivery/http/middleware"
 	"usermanagement-api/internal/usecase"
+	"usermanagement-api/pkg/auditlog"
 	"usermanagement-api/pkg/cache"
-	"usermanagement-api/pkg/firebase"
+	"usermanagement-api/pkg/pubsub"
+	"usermanagement-api/pkg/push"
 
 	"gorm.io/gorm"
 )
@@ -15,33 +18,68 @@ import (
 // BusinessContainer holds all business logic dependencies
 type BusinessContainer struct {
 	// Repositories
-	UserRepository            repositories.UserRepository
-	RoleRepository            repositories.RoleRepository
-	PermissionRepository      repositories.PermissionRepository
-	MenuRepository            repositories.MenuRepository
-	ModelPermissionRepository repositories.ModelPermissionRepository
-	UserMetaRepository        repositories.UserMetaRepository
-	SettingRepository         repositories.SettingRepository
+	UserRepository                   repositories.UserRepository
+	RoleRepository                   repositories.RoleRepository
+	PermissionRepository             repositories.PermissionRepository
+	MenuRepository                   repositories.MenuRepository
+	ModelPermissionRepository        repositories.ModelPermissionRepository
+	UserMetaRepository               repositories.UserMetaRepository
+	SettingRepository                repositories.SettingRepository
+	InboxRepository                  repositories.InboxRepository
+	NotificationRepository           repositories.NotificationRepository
+	NotificationPreferenceRepository repositories.NotificationPreferenceRepository
+	AuditRepository                  repositories.AuditRepository
+	UserMetaKeyPolicyRepository      repositories.UserMetaKeyPolicyRepository
+	AccessPolicyRepository           repositories.AccessPolicyRepository
+	OrganizationRepository           repositories.OrganizationRepository
+	GroupRepository                  repositories.GroupRepository
+	RoleConstraintRepository         repositories.RoleConstraintRepository
+	AccessRequestRepository          repositories.AccessRequestRepository
+	AccessReviewRepository           repositories.AccessReviewRepository
+	RoleAssignmentRuleRepository     repositories.RoleAssignmentRuleRepository
 
 	// Use Cases
-	UserUseCase         usecase.UserUseCase
-	RoleUseCase         usecase.RoleUseCase
-	PermissionUseCase   usecase.PermissionUseCase
-	MenuUseCase         usecase.MenuUseCase
-	AuthUseCase         usecase.AuthUseCase
-	UserMetaUseCase     usecase.UserMetaUseCase
-	SettingUseCase      usecase.SettingUseCase
-	NotificationUseCase usecase.NotificationUseCase
+	UserUseCase               usecase.UserUseCase
+	RoleUseCase               usecase.RoleUseCase
+	PermissionUseCase         usecase.PermissionUseCase
+	MenuUseCase               usecase.MenuUseCase
+	AuthUseCase               usecase.AuthUseCase
+	UserMetaUseCase           usecase.UserMetaUseCase
+	SettingUseCase            usecase.SettingUseCase
+	NotificationUseCase       usecase.NotificationUseCase
+	AuditUseCase              usecase.AuditUseCase
+	PolicyUseCase             usecase.PolicyUseCase
+	AuthorizationUseCase      usecase.AuthorizationUseCase
+	RoleAssignmentUseCase     usecase.RoleAssignmentUseCase
+	OrganizationUseCase       usecase.OrganizationUseCase
+	GroupUseCase              usecase.GroupUseCase
+	RoleConstraintUseCase     usecase.RoleConstraintUseCase
+	AccessRequestUseCase      usecase.AccessRequestUseCase
+	AccessReviewUseCase       usecase.AccessReviewUseCase
+	AccessSimulationUseCase   usecase.AccessSimulationUseCase
+	ModelTypeRegistry         usecase.ModelTypeRegistry
+	RoleAssignmentRuleUseCase usecase.RoleAssignmentRuleUseCase
 
 	// Handlers
-	UserHandler         *handlers.UserHandler
-	RoleHandler         *handlers.RoleHandler
-	PermissionHandler   *handlers.PermissionHandler
-	MenuHandler         *handlers.MenuHandler
-	AuthHandler         *handlers.AuthHandler
-	UserMetaHandler     *handlers.UserMetaHandler
-	SettingHandler      *handlers.SettingHandler
-	NotificationHandler *handlers.NotificationHandler
+	UserHandler               *handlers.UserHandler
+	RoleHandler               *handlers.RoleHandler
+	PermissionHandler         *handlers.PermissionHandler
+	MenuHandler               *handlers.MenuHandler
+	AuthHandler               *handlers.AuthHandler
+	UserMetaHandler           *handlers.UserMetaHandler
+	SettingHandler            *handlers.SettingHandler
+	NotificationHandler       *handlers.NotificationHandler
+	AuditHandler              *handlers.AuditHandler
+	PolicyHandler             *handlers.PolicyHandler
+	AuthorizationHandler      *handlers.AuthorizationHandler
+	RoleAssignmentHandler     *handlers.RoleAssignmentHandler
+	OrganizationHandler       *handlers.OrganizationHandler
+	GroupHandler              *handlers.GroupHandler
+	RoleConstraintHandler     *handlers.RoleConstraintHandler
+	AccessRequestHandler      *handlers.AccessRequestHandler
+	AccessReviewHandler       *handlers.AccessReviewHandler
+	AccessSimulationHandler   *handlers.AccessSimulationHandler
+	RoleAssignmentRuleHandler *handlers.RoleAssignmentRuleHandler
 
 	// Middleware
 	AuthMiddleware middleware.AuthMiddleware
@@ -49,7 +87,15 @@ type BusinessContainer struct {
 }
 
 // NewBusinessContainer creates and initializes a new BusinessContainer
-func NewBusinessContainer(db *gorm.DB, cache cache.Cache, fcmClient firebase.FCMClient, corsConfig config.CORSConfig) *BusinessContainer {
+func NewBusinessContainer(
+	db *gorm.DB,
+	cache cache.Cache,
+	pubsub pubsub.PubSub,
+	pushDriver push.Driver,
+	auditCheckpoints *auditlog.CheckpointFile,
+	corsConfig config.CORSConfig,
+	notificationConfig config.NotificationConfig,
+) *BusinessContainer {
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	roleRepo := repositories.NewRoleRepository(db)
@@ -58,19 +104,43 @@ func NewBusinessContainer(db *gorm.DB, cache cache.Cache, fcmClient firebase.FCM
 	modelPermissionRepo := repositories.NewModelPermissionRepository(db)
 	userMetaRepo := repositories.NewUserMetaRepository(db)
 	settingRepo := repositories.NewSettingRepository(db)
+	inboxRepo := repositories.NewInboxRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	notificationPreferenceRepo := repositories.NewNotificationPreferenceRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	userMetaKeyPolicyRepo := repositories.NewUserMetaKeyPolicyRepository(db)
+	accessPolicyRepo := repositories.NewAccessPolicyRepository(db)
+	organizationRepo := repositories.NewOrganizationRepository(db)
+	groupRepo := repositories.NewGroupRepository(db)
+	roleConstraintRepo := repositories.NewRoleConstraintRepository(db)
+	accessRequestRepo := repositories.NewAccessRequestRepository(db)
+	accessReviewRepo := repositories.NewAccessReviewRepository(db)
+	roleAssignmentRuleRepo := repositories.NewRoleAssignmentRuleRepository(db)
 
 	// Initialize use cases
-	userUseCase := usecase.NewUserUseCase(userRepo, roleRepo, userMetaRepo)
-	roleUseCase := usecase.NewRoleUseCase(roleRepo, permissionRepo)
-	permissionUseCase := usecase.NewPermissionUseCase(permissionRepo)
-	menuUseCase := usecase.NewMenuUseCase(menuRepo)
-	authUseCase := usecase.NewAuthUseCase(userRepo, roleRepo, menuRepo, modelPermissionRepo, userMetaRepo, fcmClient)
-	userMetaUseCase := usecase.NewUserMetaUseCase(userMetaRepo, cache)
-	settingUseCase := usecase.NewSettingUseCase(settingRepo, cache)
-	notificationUseCase := usecase.NewNotificationUseCase(userMetaRepo, fcmClient)
+	auditUseCase := usecase.NewAuditUseCase(auditRepo, auditCheckpoints)
+	roleAssignmentRuleUseCase := usecase.NewRoleAssignmentRuleUseCase(roleAssignmentRuleRepo, userRepo, roleRepo, userMetaRepo, userMetaKeyPolicyRepo, organizationRepo, roleConstraintRepo, auditUseCase)
+	userUseCase := usecase.NewUserUseCase(userRepo, roleRepo, userMetaRepo, userMetaKeyPolicyRepo, organizationRepo, roleConstraintRepo, groupRepo, roleAssignmentRuleUseCase, auditUseCase)
+	roleUseCase := usecase.NewRoleUseCase(roleRepo, permissionRepo, userRepo, auditUseCase)
+	permissionUseCase := usecase.NewPermissionUseCase(permissionRepo, auditUseCase)
+	menuUseCase := usecase.NewMenuUseCase(menuRepo, auditUseCase)
+	modelTypeRegistry := usecase.NewModelTypeRegistry(roleRepo, menuRepo)
+	authUseCase := usecase.NewAuthUseCase(userRepo, roleRepo, permissionRepo, menuRepo, modelPermissionRepo, userMetaRepo, userMetaKeyPolicyRepo, organizationRepo, modelTypeRegistry, roleAssignmentRuleUseCase, pushDriver, auditUseCase)
+	userMetaUseCase := usecase.NewUserMetaUseCase(userMetaRepo, userMetaKeyPolicyRepo, cache, roleAssignmentRuleUseCase, auditUseCase)
+	settingUseCase := usecase.NewSettingUseCase(settingRepo, cache, auditUseCase)
+	policyUseCase := usecase.NewPolicyUseCase(accessPolicyRepo, userRepo, roleRepo, userMetaRepo, userMetaKeyPolicyRepo, auditUseCase)
+	authorizationUseCase := usecase.NewAuthorizationUseCase(userRepo, roleRepo, groupRepo, permissionRepo, modelPermissionRepo, policyUseCase, authUseCase)
+	notificationUseCase := usecase.NewNotificationUseCase(userMetaRepo, inboxRepo, notificationRepo, notificationPreferenceRepo, pushDriver, pubsub)
+	roleAssignmentUseCase := usecase.NewRoleAssignmentUseCase(userRepo, roleRepo, roleConstraintRepo, groupRepo, organizationRepo, notificationUseCase, auditUseCase)
+	organizationUseCase := usecase.NewOrganizationUseCase(organizationRepo, userRepo, auditUseCase)
+	groupUseCase := usecase.NewGroupUseCase(groupRepo, userRepo, roleRepo, roleConstraintRepo, organizationRepo, auditUseCase)
+	roleConstraintUseCase := usecase.NewRoleConstraintUseCase(roleConstraintRepo, userRepo, roleRepo, auditUseCase)
+	accessRequestUseCase := usecase.NewAccessRequestUseCase(accessRequestRepo, userRepo, roleRepo, roleAssignmentUseCase, notificationUseCase, auditUseCase)
+	accessReviewUseCase := usecase.NewAccessReviewUseCase(accessReviewRepo, userRepo, roleRepo, roleAssignmentUseCase, notificationUseCase, auditUseCase)
+	accessSimulationUseCase := usecase.NewAccessSimulationUseCase(userRepo, roleRepo, permissionRepo, menuRepo)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(userRepo, roleRepo, permissionRepo, modelPermissionRepo)
+	authMiddleware := middleware.NewAuthMiddleware(userRepo, roleRepo, permissionRepo, modelPermissionRepo, organizationRepo, policyUseCase)
 	corsMiddleware := middleware.NewCORSMiddleware(corsConfig)
 
 	// Initialize handlers
@@ -81,37 +151,83 @@ func NewBusinessContainer(db *gorm.DB, cache cache.Cache, fcmClient firebase.FCM
 	authHandler := handlers.NewAuthHandler(authUseCase)
 	userMetaHandler := handlers.NewUserMetaHandler(userMetaUseCase)
 	settingHandler := handlers.NewSettingHandler(settingUseCase)
-	notificationHandler := handlers.NewNotificationHandler(notificationUseCase)
+	auditHandler := handlers.NewAuditHandler(auditUseCase)
+	policyHandler := handlers.NewPolicyHandler(policyUseCase)
+	authorizationHandler := handlers.NewAuthorizationHandler(authorizationUseCase)
+	roleAssignmentHandler := handlers.NewRoleAssignmentHandler(roleAssignmentUseCase)
+	organizationHandler := handlers.NewOrganizationHandler(organizationUseCase)
+	groupHandler := handlers.NewGroupHandler(groupUseCase)
+	roleConstraintHandler := handlers.NewRoleConstraintHandler(roleConstraintUseCase)
+	accessRequestHandler := handlers.NewAccessRequestHandler(accessRequestUseCase)
+	accessReviewHandler := handlers.NewAccessReviewHandler(accessReviewUseCase)
+	accessSimulationHandler := handlers.NewAccessSimulationHandler(accessSimulationUseCase)
+	roleAssignmentRuleHandler := handlers.NewRoleAssignmentRuleHandler(roleAssignmentRuleUseCase)
+	notificationHandler := handlers.NewNotificationHandler(notificationUseCase, time.Duration(notificationConfig.StreamHeartbeat)*time.Second)
 
 	return &BusinessContainer{
 		// Repositories
-		UserRepository:            userRepo,
-		RoleRepository:            roleRepo,
-		PermissionRepository:      permissionRepo,
-		MenuRepository:            menuRepo,
-		ModelPermissionRepository: modelPermissionRepo,
-		UserMetaRepository:        userMetaRepo,
-		SettingRepository:         settingRepo,
+		UserRepository:                   userRepo,
+		RoleRepository:                   roleRepo,
+		PermissionRepository:             permissionRepo,
+		MenuRepository:                   menuRepo,
+		ModelPermissionRepository:        modelPermissionRepo,
+		UserMetaRepository:               userMetaRepo,
+		SettingRepository:                settingRepo,
+		InboxRepository:                  inboxRepo,
+		NotificationRepository:           notificationRepo,
+		NotificationPreferenceRepository: notificationPreferenceRepo,
+		AuditRepository:                  auditRepo,
+		UserMetaKeyPolicyRepository:      userMetaKeyPolicyRepo,
+		AccessPolicyRepository:           accessPolicyRepo,
+		OrganizationRepository:           organizationRepo,
+		GroupRepository:                  groupRepo,
+		RoleConstraintRepository:         roleConstraintRepo,
+		AccessRequestRepository:          accessRequestRepo,
+		AccessReviewRepository:           accessReviewRepo,
+		RoleAssignmentRuleRepository:     roleAssignmentRuleRepo,
 
 		// Use Cases
-		UserUseCase:         userUseCase,
-		RoleUseCase:         roleUseCase,
-		PermissionUseCase:   permissionUseCase,
-		MenuUseCase:         menuUseCase,
-		AuthUseCase:         authUseCase,
-		UserMetaUseCase:     userMetaUseCase,
-		SettingUseCase:      settingUseCase,
-		NotificationUseCase: notificationUseCase,
+		UserUseCase:               userUseCase,
+		RoleUseCase:               roleUseCase,
+		PermissionUseCase:         permissionUseCase,
+		MenuUseCase:               menuUseCase,
+		AuthUseCase:               authUseCase,
+		UserMetaUseCase:           userMetaUseCase,
+		SettingUseCase:            settingUseCase,
+		NotificationUseCase:       notificationUseCase,
+		AuditUseCase:              auditUseCase,
+		PolicyUseCase:             policyUseCase,
+		AuthorizationUseCase:      authorizationUseCase,
+		RoleAssignmentUseCase:     roleAssignmentUseCase,
+		OrganizationUseCase:       organizationUseCase,
+		GroupUseCase:              groupUseCase,
+		RoleConstraintUseCase:     roleConstraintUseCase,
+		AccessRequestUseCase:      accessRequestUseCase,
+		AccessReviewUseCase:       accessReviewUseCase,
+		AccessSimulationUseCase:   accessSimulationUseCase,
+		ModelTypeRegistry:         modelTypeRegistry,
+		RoleAssignmentRuleUseCase: roleAssignmentRuleUseCase,
 
 		// Handlers
-		UserHandler:         userHandler,
-		RoleHandler:         roleHandler,
-		PermissionHandler:   permissionHandler,
-		MenuHandler:         menuHandler,
-		AuthHandler:         authHandler,
-		UserMetaHandler:     userMetaHandler,
-		SettingHandler:      settingHandler,
-		NotificationHandler: notificationHandler,
+		UserHandler:               userHandler,
+		RoleHandler:               roleHandler,
+		PermissionHandler:         permissionHandler,
+		MenuHandler:               menuHandler,
+		AuthHandler:               authHandler,
+		UserMetaHandler:           userMetaHandler,
+		SettingHandler:            settingHandler,
+		NotificationHandler:       notificationHandler,
+		AuditHandler:              auditHandler,
+		PolicyHandler:             policyHandler,
+		AuthorizationHandler:      authorizationHandler,
+		RoleAssignmentHandler:     roleAssignmentHandler,
+		OrganizationHandler:       organizationHandler,
+		GroupHandler:              groupHandler,
+		RoleConstraintHandler:     roleConstraintHandler,
+		AccessRequestHandler:      accessRequestHandler,
+		AccessReviewHandler:       accessReviewHandler,
+		AccessSimulationHandler:   accessSimulationHandler,
+		RoleAssignmentRuleHandler: roleAssignmentRuleHandler,
 
 		// Middleware
 		AuthMiddleware: authMiddleware,
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AccessRequestHandler struct {
+	accessRequestUseCase usecase.AccessRequestUseCase
+}
+
+func NewAccessRequestHandler(accessRequestUseCase usecase.AccessRequestUseCase) *AccessRequestHandler {
+	return &AccessRequestHandler{
+		accessRequestUseCase: accessRequestUseCase,
+	}
+}
+
+// CreateAccessRequest godoc
+// @Summary Request a role
+// @Description Ask for a role with a justification, the role's approvers are notified. The request expires if nobody decides it within 7 days.
+// @Tags access-requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateAccessRequestRequest true "Access request"
+// @Success 201 {object} dto.AccessRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /access-requests [post]
+func (h *AccessRequestHandler) CreateAccessRequest(c *gin.Context) {
+	var req dto.CreateAccessRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.accessRequestUseCase.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(accessRequestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetMyAccessRequests godoc
+// @Summary Get my access requests
+// @Description List the caller's access requests, newest first
+// @Tags access-requests
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, approved, rejected, cancelled or expired"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /access-requests/mine [get]
+func (h *AccessRequestHandler) GetMyAccessRequests(c *gin.Context) {
+	h.list(c, h.accessRequestUseCase.ListMine)
+}
+
+// GetApprovalAccessRequests godoc
+// @Summary Get access requests to decide
+// @Description List the access requests for the roles the caller approves, newest first
+// @Tags access-requests
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, approved, rejected, cancelled or expired"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /access-requests/approvals [get]
+func (h *AccessRequestHandler) GetApprovalAccessRequests(c *gin.Context) {
+	h.list(c, h.accessRequestUseCase.ListForApproval)
+}
+
+// GetAccessRequest godoc
+// @Summary Get access request
+// @Description Get an access request, visible to its requester, the role's approvers and superusers
+// @Tags access-requests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Access request ID"
+// @Success 200 {object} dto.AccessRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /access-requests/{id} [get]
+func (h *AccessRequestHandler) GetAccessRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access request id"})
+		return
+	}
+
+	resp, err := h.accessRequestUseCase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(accessRequestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ApproveAccessRequest godoc
+// @Summary Approve access request
+// @Description Approve a pending request and grant the role, until role_expires_at when given or requested. Requesters cannot approve their own requests.
+// @Tags access-requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Access request ID"
+// @Param decision body dto.ApproveAccessRequestRequest true "Decision"
+// @Success 200 {object} dto.AccessRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /access-requests/{id}/approve [post]
+func (h *AccessRequestHandler) ApproveAccessRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access request id"})
+		return
+	}
+
+	var req dto.ApproveAccessRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.accessRequestUseCase.Approve(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(accessRequestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RejectAccessRequest godoc
+// @Summary Reject access request
+// @Description Reject a pending request
+// @Tags access-requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Access request ID"
+// @Param decision body dto.RejectAccessRequestRequest true "Decision"
+// @Success 200 {object} dto.AccessRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /access-requests/{id}/reject [post]
+func (h *AccessRequestHandler) RejectAccessRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access request id"})
+		return
+	}
+
+	var req dto.RejectAccessRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.accessRequestUseCase.Reject(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(accessRequestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CancelAccessRequest godoc
+// @Summary Cancel access request
+// @Description Withdraw one of the caller's pending requests
+// @Tags access-requests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Access request ID"
+// @Success 200 {object} dto.AccessRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /access-requests/{id}/cancel [post]
+func (h *AccessRequestHandler) CancelAccessRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access request id"})
+		return
+	}
+
+	resp, err := h.accessRequestUseCase.Cancel(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(accessRequestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *AccessRequestHandler) list(c *gin.Context, find func(ctx context.Context, status string, page, pageSize int) ([]*dto.AccessRequestResponse, int64, error)) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	requests, total, err := find(c.Request.Context(), c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": requests,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// accessRequestErrorStatus is errorStatus with missing requests and roles reported as 404
+func accessRequestErrorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return errorStatus(err, http.StatusBadRequest)
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AccessReviewHandler struct {
+	accessReviewUseCase usecase.AccessReviewUseCase
+}
+
+func NewAccessReviewHandler(accessReviewUseCase usecase.AccessReviewUseCase) *AccessReviewHandler {
+	return &AccessReviewHandler{
+		accessReviewUseCase: accessReviewUseCase,
+	}
+}
+
+// CreateAccessReview godoc
+// @Summary Launch access review
+// @Description Launch a campaign over the current direct role assignments of role_ids and/or user_ids. Items go to an approver of their role, or to reviewer_id, never to the user under review; undecided items escalate to escalation_reviewer_id after due_at.
+// @Tags access-reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param campaign body dto.CreateAccessReviewRequest true "Campaign"
+// @Success 201 {object} dto.AccessReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /access-reviews [post]
+func (h *AccessReviewHandler) CreateAccessReview(c *gin.Context) {
+	var req dto.CreateAccessReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.accessReviewUseCase.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetAllAccessReviews godoc
+// @Summary Get all access reviews
+// @Description Get all access review campaigns with their item count per decision, newest first
+// @Tags access-reviews
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /access-reviews [get]
+func (h *AccessReviewHandler) GetAllAccessReviews(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	campaigns, total, err := h.accessReviewUseCase.GetAll(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": campaigns,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// GetAccessReview godoc
+// @Summary Get access review
+// @Description Get an access review campaign with its item count per decision
+// @Tags access-reviews
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} dto.AccessReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /access-reviews/{id} [get]
+func (h *AccessReviewHandler) GetAccessReview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid campaign id"})
+		return
+	}
+
+	resp, err := h.accessReviewUseCase.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetAccessReviewItems godoc
+// @Summary Get access review items
+// @Description List the items of a campaign
+// @Tags access-reviews
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Param decision query string false "pending, keep or revoke"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /access-reviews/{id}/items [get]
+func (h *AccessReviewHandler) GetAccessReviewItems(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid campaign id"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	items, total, err := h.accessReviewUseCase.GetItems(id, c.Query("decision"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// GetMyAccessReviewItems godoc
+// @Summary Get my access review items
+// @Description List the items of open campaigns the caller has to review
+// @Tags access-reviews
+// @Produce json
+// @Security BearerAuth
+// @Param decision query string false "pending, keep or revoke"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /access-reviews/assigned [get]
+func (h *AccessReviewHandler) GetMyAccessReviewItems(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	items, total, err := h.accessReviewUseCase.GetMyItems(c.Request.Context(), c.Query("decision"), page, pageSize)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// DecideAccessReviewItem godoc
+// @Summary Decide access review item
+// @Description Keep or revoke a role assignment under review. Only the item's reviewer may decide, and may change the decision until the campaign is closed.
+// @Tags access-reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param itemId path string true "Item ID"
+// @Param decision body dto.DecideAccessReviewItemRequest true "Decision"
+// @Success 200 {object} dto.AccessReviewItemResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /access-reviews/items/{itemId}/decision [post]
+func (h *AccessReviewHandler) DecideAccessReviewItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id"})
+		return
+	}
+
+	var req dto.DecideAccessReviewItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.accessReviewUseCase.Decide(c.Request.Context(), id, &req)
+	if err != nil {
+		status := errorStatus(err, http.StatusBadRequest)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CloseAccessReview godoc
+// @Summary Close access review
+// @Description Close a campaign, revoking every assignment decided as revoke, and the undecided ones when revoke_undecided is set. If a revoke fails the campaign stays open and can be closed again.
+// @Tags access-reviews
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} dto.AccessReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /access-reviews/{id}/close [post]
+func (h *AccessReviewHandler) CloseAccessReview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid campaign id"})
+		return
+	}
+
+	resp, err := h.accessReviewUseCase.Close(c.Request.Context(), id)
+	if err != nil {
+		status := errorStatus(err, http.StatusBadRequest)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ExportAccessReview godoc
+// @Summary Export access review report
+// @Description Download every item of a campaign with its reviewer, decision and outcome as CSV
+// @Tags access-reviews
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 200 {string} string "CSV report"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /access-reviews/{id}/report.csv [get]
+func (h *AccessReviewHandler) ExportAccessReview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid campaign id"})
+		return
+	}
+
+	report, err := h.accessReviewUseCase.ExportCSV(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=access-review-%s.csv", id))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", report)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessSimulationHandler struct {
+	accessSimulationUseCase usecase.AccessSimulationUseCase
+}
+
+func NewAccessSimulationHandler(accessSimulationUseCase usecase.AccessSimulationUseCase) *AccessSimulationHandler {
+	return &AccessSimulationHandler{
+		accessSimulationUseCase: accessSimulationUseCase,
+	}
+}
+
+// Simulate godoc
+// @Summary Simulate access changes
+// @Description Preview which users would gain or lose which permissions and menus if permissions were added to or removed from roles, or roles assigned to or removed from users. Nothing is saved.
+// @Tags access-simulations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param changes body dto.SimulationRequest true "Proposed changes"
+// @Success 200 {object} dto.SimulationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /access-simulations [post]
+func (h *AccessSimulationHandler) Simulate(c *gin.Context) {
+	var req dto.SimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.accessSimulationUseCase.Simulate(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
@@ -65,6 +67,59 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	c.JSON(http.StatusCreated, utils.BuildResponseSuccess("Register Success", resp, nil))
 }
 
+// SwitchOrganization godoc
+// @Summary Switch organization
+// @Description Issue a new token pair acting in another organization the caller belongs to
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param organization body dto.SwitchOrganizationRequest true "Organization to act in"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /auth/switch-org [post]
+func (h *AuthHandler) SwitchOrganization(c *gin.Context) {
+	var req dto.SwitchOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authUseCase.SwitchOrganization(c.Request.Context(), req.OrganizationID)
+	if err != nil {
+		status := errorStatus(err, http.StatusBadRequest)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetOrganizations godoc
+// @Summary Get my organizations
+// @Description List the active organizations the current user belongs to
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.OrganizationResponse
+// @Failure 401 {object} map[string]string
+// @Router /auth/organizations [get]
+func (h *AuthHandler) GetOrganizations(c *gin.Context) {
+	resp, err := h.authUseCase.GetOrganizations(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetUserPermissions godoc
 // @Summary Get user permissions
 // @Description Get permissions for the currently authenticated user
@@ -88,7 +143,7 @@ func (h *AuthHandler) GetUserPermissions(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid userID format"})
 		return
 	}
-	permissions, err := h.authUseCase.GetUserPermissions(userUUID)
+	permissions, err := h.authUseCase.GetUserPermissions(c.Request.Context(), userUUID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -107,7 +162,7 @@ func (h *AuthHandler) GetUserPermissions(c *gin.Context) {
 
 // CreateModelPermission godoc
 // @Summary Create model permission
-// @Description Assign a permission to a model (role or menu)
+// @Description Assign a permission to a model of a registered type (role, menu or a type registered by the application). The model must exist.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -124,7 +179,7 @@ func (h *AuthHandler) CreateModelPermission(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authUseCase.CreateModelPermission(&req)
+	resp, err := h.authUseCase.CreateModelPermission(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -135,13 +190,13 @@ func (h *AuthHandler) CreateModelPermission(c *gin.Context) {
 
 // GetModelPermissions godoc
 // @Summary Get model permissions
-// @Description Get permissions for a specific model (role or menu)
+// @Description Get permissions for a specific model, with the model's resolved name
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param model_type query string true "Model type (role or menu)"
-// @Param model_id query int true "Model ID"
+// @Param model_type query string true "Model type (role, menu or a registered type)"
+// @Param model_id query string true "Model ID"
 // @Success 200 {array} dto.ModelPermissionResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
@@ -162,15 +217,74 @@ func (h *AuthHandler) GetModelPermissions(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid model_id format"})
 		return
 	}
-	permissions, err := h.authUseCase.GetModelPermissions(modelType, modelUUID)
+	permissions, err := h.authUseCase.GetModelPermissions(c.Request.Context(), modelType, modelUUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, permissions)
 }
 
+// ReplaceModelPermissions godoc
+// @Summary Replace model permissions
+// @Description Make permission_ids the exact set of permissions attached to a model, an empty list removes them all
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param model-permissions body dto.ReplaceModelPermissionsRequest true "Model and permissions"
+// @Success 200 {array} dto.ModelPermissionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/model-permissions [put]
+func (h *AuthHandler) ReplaceModelPermissions(c *gin.Context) {
+	var req dto.ReplaceModelPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authUseCase.ReplaceModelPermissions(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteModelPermission godoc
+// @Summary Delete model permission
+// @Description Detach a permission from a model
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Model permission ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /auth/model-permissions/{id} [delete]
+func (h *AuthHandler) DeleteModelPermission(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid model permission id"})
+		return
+	}
+
+	if err := h.authUseCase.DeleteModelPermission(c.Request.Context(), id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // GetUser
 func (h *AuthHandler) GetUser(c *gin.Context) {
 
@@ -192,7 +306,7 @@ func (h *AuthHandler) GetUser(c *gin.Context) {
 		token = authHeader[7:]
 	}
 
-	auth, err := h.authUseCase.GetUser(userUUID, token)
+	auth, err := h.authUseCase.GetUser(c.Request.Context(), userUUID, token)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
 	}
@@ -219,9 +333,9 @@ func (h *AuthHandler) CreateMeta(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authUseCase.CreateMetaData(userUUID, &req)
+	resp, err := h.authUseCase.CreateMetaData(c.Request.Context(), userUUID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, utils.BuildResponseSuccess("Create Meta Success", resp, nil))
@@ -239,7 +353,7 @@ func (h *AuthHandler) GetUserMeta(c *gin.Context) {
 		return
 	}
 
-	metaData, err := h.authUseCase.GetMetaData(userUUID)
+	metaData, err := h.authUseCase.GetMetaData(c.Request.Context(), userUUID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
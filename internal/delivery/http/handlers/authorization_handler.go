@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuthorizationHandler struct {
+	authorizationUseCase usecase.AuthorizationUseCase
+}
+
+func NewAuthorizationHandler(authorizationUseCase usecase.AuthorizationUseCase) *AuthorizationHandler {
+	return &AuthorizationHandler{
+		authorizationUseCase: authorizationUseCase,
+	}
+}
+
+// CheckPermissions godoc
+// @Summary Check permissions
+// @Description Check a batch of permissions for the current user, or for any user with permissions.check. allowed follows the route guards, model permissions are reported separately in model_permission.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param explain query bool false "Include the granting source or the denial reason"
+// @Param request body dto.PermissionCheckRequest true "Permissions to check"
+// @Success 200 {object} dto.PermissionCheckResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /auth/check [post]
+func (h *AuthorizationHandler) CheckPermissions(c *gin.Context) {
+	var req dto.PermissionCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authorizationUseCase.Check(c.Request.Context(), &req, c.Query("explain") == "true")
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ExplainRole godoc
+// @Summary Explain role
+// @Description Show every way a user holds a role: a direct assignment, group membership with the group path, or a role inheriting from it
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param roleId path string true "Role ID"
+// @Success 200 {object} dto.RoleExplanation
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/roles/{roleId}/explain [get]
+func (h *AuthorizationHandler) ExplainRole(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	roleID, err := uuid.Parse(c.Param("roleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	resp, err := h.authorizationUseCase.ExplainRole(c.Request.Context(), id, roleID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetEffectivePermissions godoc
+// @Summary Get effective permissions
+// @Description List every permission the user holds in the caller's organization with its sources (role, inherited_role, group or superuser), the permissions withheld by explicit denies, and the menus they unlock
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.EffectivePermissions
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/effective-permissions [get]
+func (h *AuthorizationHandler) GetEffectivePermissions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resp, err := h.authorizationUseCase.EffectivePermissions(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
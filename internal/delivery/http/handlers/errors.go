@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"usermanagement-api/internal/usecase"
+)
+
+// errorStatus maps authorization failures from the use cases to 403, separation-of-duties
+// conflicts and access request and review state conflicts to 409 and anything else to fallback
+func errorStatus(err error, fallback int) int {
+	if errors.Is(err, usecase.ErrForbidden) {
+		return http.StatusForbidden
+	}
+	var conflict *usecase.RoleConflictError
+	if errors.As(err, &conflict) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, usecase.ErrAccessRequestNotPending) || errors.Is(err, usecase.ErrAccessRequestExists) ||
+		errors.Is(err, usecase.ErrAccessReviewClosed) {
+		return http.StatusConflict
+	}
+	return fallback
+}
@@ -39,7 +39,7 @@ func (h *MenuHandler) CreateMenu(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.menuUseCase.Create(&req)
+	resp, err := h.menuUseCase.Create(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -68,7 +68,7 @@ func (h *MenuHandler) GetMenu(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.menuUseCase.GetByID(id)
+	resp, err := h.menuUseCase.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "menu not found"})
 		return
@@ -103,7 +103,7 @@ func (h *MenuHandler) GetAllMenus(c *gin.Context) {
 		pageSize = 10
 	}
 
-	menus, total, err := h.menuUseCase.GetAll(page, pageSize)
+	menus, total, err := h.menuUseCase.GetAll(c.Request.Context(), page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -131,7 +131,7 @@ func (h *MenuHandler) GetAllMenus(c *gin.Context) {
 // @Failure 401 {object} map[string]string
 // @Router /menus/active [get]
 func (h *MenuHandler) GetActiveMenus(c *gin.Context) {
-	menus, err := h.menuUseCase.GetAllActive()
+	menus, err := h.menuUseCase.GetAllActive(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -167,9 +167,9 @@ func (h *MenuHandler) UpdateMenu(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.menuUseCase.Update(id, &req)
+	resp, err := h.menuUseCase.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -195,8 +195,8 @@ func (h *MenuHandler) DeleteMenu(c *gin.Context) {
 		return
 	}
 
-	if err := h.menuUseCase.Delete(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.menuUseCase.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -2,22 +2,34 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"usermanagement-api/domain/entities"
+	"strconv"
+	"time"
 	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
 	"usermanagement-api/internal/usecase"
+	"usermanagement-api/pkg/logger"
+	"usermanagement-api/pkg/push"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type NotificationHandler struct {
 	notificationUseCase usecase.NotificationUseCase
+	streamHeartbeat     time.Duration
 }
 
-func NewNotificationHandler(notificationUseCase usecase.NotificationUseCase) *NotificationHandler {
+func NewNotificationHandler(notificationUseCase usecase.NotificationUseCase, streamHeartbeat time.Duration) *NotificationHandler {
 	return &NotificationHandler{
 		notificationUseCase: notificationUseCase,
+		streamHeartbeat:     streamHeartbeat,
 	}
 }
 
@@ -33,6 +45,7 @@ func NewNotificationHandler(notificationUseCase usecase.NotificationUseCase) *No
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 403 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /notifications/send [post]
 func (h *NotificationHandler) SendNotification(c *gin.Context) {
 	var req dto.SendNotificationRequest
@@ -41,23 +54,36 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 		return
 	}
 
+	senderID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	msg := &dto.NotificationMessage{
+		Title:    req.Title,
+		Body:     req.Body,
+		Data:     req.Data,
+		Category: req.Category,
+		Critical: req.Critical,
+	}
+
 	var response *dto.NotificationResponse
 	var err error
 
 	// Send based on the provided parameters
 	if req.Topic != "" {
 		// Send to topic
-		response, err = h.notificationUseCase.SendToTopic(req.Topic, req.Title, req.Body, req.Data)
+		response, err = h.notificationUseCase.SendToTopic(&senderID, req.Topic, msg)
 	} else if len(req.UserIDs) > 0 {
 		// Send to specific users
-		response, err = h.notificationUseCase.SendToUsers(req.UserIDs, req.Title, req.Body, req.Data)
+		response, err = h.notificationUseCase.SendToUsers(&senderID, req.UserIDs, msg)
 	} else {
 		// Send to all
-		response, err = h.notificationUseCase.SendToAll(req.Title, req.Body, req.Data)
+		response, err = h.notificationUseCase.SendToAll(&senderID, msg)
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.sendError(c, err)
 		return
 	}
 
@@ -66,7 +92,7 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 
 // SendToMe godoc
 // @Summary Send notification to self
-// @Description Send a push notification to the authenticated user
+// @Description Send a push notification to the authenticated user. Answers 503 when push is disabled and the notification reached no device.
 // @Tags notifications
 // @Accept json
 // @Produce json
@@ -75,6 +101,7 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 // @Success 200 {object} dto.NotificationResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /notifications/send-to-me [post]
 func (h *NotificationHandler) SendToMe(c *gin.Context) {
 	var req dto.SendNotificationRequest
@@ -84,18 +111,466 @@ func (h *NotificationHandler) SendToMe(c *gin.Context) {
 	}
 
 	// Get authenticated user
-	user, exists := c.Get(constants.UserIDKey)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
+	userID, ok := h.currentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Send notification to authenticated user
-	response, err := h.notificationUseCase.SendToUser(user.(*entities.User).ID, req.Title, req.Body, req.Data)
+	response, err := h.notificationUseCase.SendToUser(&userID, userID, &dto.NotificationMessage{
+		Title:    req.Title,
+		Body:     req.Body,
+		Data:     req.Data,
+		Category: req.Category,
+		Critical: req.Critical,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.sendError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// ListNotifications godoc
+// @Summary List sent notifications
+// @Description Get all sent notifications with pagination
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	page, pageSize := h.pagination(c)
+
+	notifications, total, err := h.notificationUseCase.GetAll(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": notifications,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// GetReport godoc
+// @Summary Get notification delivery report
+// @Description Get aggregated delivery outcomes of a notification per status, channel and error code
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} dto.NotificationReportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notifications/{id}/report [get]
+func (h *NotificationHandler) GetReport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	report, err := h.notificationUseCase.GetReport(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetDeliveries godoc
+// @Summary Get notification deliveries
+// @Description Get per-recipient, per-device outcomes of a notification with pagination
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Param status query string false "Filter by status (sent, failed, opened, read)"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /notifications/{id}/deliveries [get]
+func (h *NotificationHandler) GetDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	page, pageSize := h.pagination(c)
+
+	deliveries, total, err := h.notificationUseCase.GetDeliveries(id, c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": deliveries,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// MarkOpened godoc
+// @Summary Mark notification as opened
+// @Description Report that the authenticated user opened a push notification
+// @Tags notifications
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /notifications/{id}/opened [post]
+func (h *NotificationHandler) MarkOpened(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	if err := h.notificationUseCase.MarkOpened(userID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetPreferences godoc
+// @Summary Get notification preferences
+// @Description Get the muted categories, channels and quiet hours of the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.NotificationPreferenceResponse
+// @Failure 401 {object} map[string]string
+// @Router /auth/notification-preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notificationUseCase.GetPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences godoc
+// @Summary Update notification preferences
+// @Description Replace the muted categories, channels and quiet hours of the authenticated user.
+// @Description Critical notifications are always delivered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body dto.NotificationPreferenceRequest true "Notification preferences"
+// @Success 200 {object} dto.NotificationPreferenceResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/notification-preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.NotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := h.notificationUseCase.UpdatePreferences(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// GetInbox godoc
+// @Summary Get inbox
+// @Description Get in-app notifications of the authenticated user with pagination
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /notifications/inbox [get]
+func (h *NotificationHandler) GetInbox(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := h.pagination(c)
+
+	items, total, err := h.notificationUseCase.GetInbox(userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	unread, err := h.notificationUseCase.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": gin.H{
+			"page":         page,
+			"page_size":    pageSize,
+			"total":        total,
+			"total_page":   (total + int64(pageSize) - 1) / int64(pageSize),
+			"unread_count": unread,
+		},
+	})
+}
+
+// MarkAsRead godoc
+// @Summary Mark inbox item as read
+// @Tags notifications
+// @Security BearerAuth
+// @Param id path int true "Inbox item ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /notifications/inbox/{id}/read [post]
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid inbox item id"})
+		return
+	}
+
+	if err := h.notificationUseCase.MarkAsRead(userID, uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllAsRead godoc
+// @Summary Mark all inbox items as read
+// @Tags notifications
+// @Security BearerAuth
+// @Success 204 {object} nil
+// @Failure 401 {object} map[string]string
+// @Router /notifications/inbox/read-all [post]
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationUseCase.MarkAllAsRead(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Stream godoc
+// @Summary Stream notifications
+// @Description Push new inbox items and unread counts over Server-Sent Events.
+// @Description Send Last-Event-ID to replay items missed while disconnected.
+// @Description EventSource cannot set headers, so this route also accepts the token as access_token.
+// @Tags notifications
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} dto.NotificationStreamEvent
+// @Failure 401 {object} map[string]string
+// @Router /notifications/stream [get]
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// EventSource sends Last-Event-ID on reconnect, the query param covers manual resumes
+	lastEventIDStr := c.GetHeader("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = c.Query("last_event_id")
+	}
+	var lastEventID uint64
+	if lastEventIDStr != "" {
+		parsed, err := strconv.ParseUint(lastEventIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+			return
+		}
+		lastEventID = parsed
+	}
+
+	// Subscribe before replaying so nothing published in between is lost
+	ctx := c.Request.Context()
+	subscription := h.notificationUseCase.Subscribe(ctx, userID)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable nginx response buffering
+
+	unread, err := h.notificationUseCase.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if lastEventID > 0 {
+		missed, err := h.notificationUseCase.GetMissedEvents(userID, uint(lastEventID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, item := range missed {
+			h.renderEvent(c, &dto.NotificationStreamEvent{
+				Type:        constants.NotificationEventInboxItem,
+				Item:        item,
+				UnreadCount: unread,
+			})
+			lastEventID = uint64(item.ID)
+		}
+	}
+
+	h.renderEvent(c, &dto.NotificationStreamEvent{
+		Type:        constants.NotificationEventUnreadCount,
+		UnreadCount: unread,
+	})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			// SSE comment line, ignored by EventSource but keeps proxies from timing out
+			_, err := fmt.Fprintf(w, ": heartbeat %d\n\n", time.Now().Unix())
+			return err == nil
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				return false
+			}
+
+			var event dto.NotificationStreamEvent
+			if err := json.Unmarshal([]byte(message), &event); err != nil {
+				logger.Warn("Dropping malformed notification event", zap.Error(err))
+				return true
+			}
+
+			// Skip items already sent during replay
+			if event.Item != nil {
+				if uint64(event.Item.ID) <= lastEventID {
+					return true
+				}
+				lastEventID = uint64(event.Item.ID)
+			}
+
+			h.renderEvent(c, &event)
+			return true
+		}
+	})
+}
+
+func (h *NotificationHandler) renderEvent(c *gin.Context, event *dto.NotificationStreamEvent) {
+	sseEvent := sse.Event{
+		Event: event.Type,
+		Data:  event,
+	}
+	if event.Item != nil {
+		sseEvent.Id = strconv.FormatUint(uint64(event.Item.ID), 10)
+	}
+	c.Render(-1, sseEvent)
+}
+
+// sendError reports a disabled push driver as 503 so clients can tell it apart from a failed send
+func (h *NotificationHandler) sendError(c *gin.Context, err error) {
+	if errors.Is(err, push.ErrDisabled) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+func (h *NotificationHandler) pagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	return page, pageSize
+}
+
+func (h *NotificationHandler) currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get(constants.UserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
+		return uuid.Nil, false
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid userID format"})
+		return uuid.Nil, false
+	}
+
+	return userUUID, true
+}
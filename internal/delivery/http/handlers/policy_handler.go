@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PolicyHandler struct {
+	policyUseCase usecase.PolicyUseCase
+}
+
+func NewPolicyHandler(policyUseCase usecase.PolicyUseCase) *PolicyHandler {
+	return &PolicyHandler{
+		policyUseCase: policyUseCase,
+	}
+}
+
+// CreatePolicy godoc
+// @Summary Create access policy
+// @Description Create an attribute-based access policy
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param policy body dto.CreatePolicyRequest true "Policy"
+// @Success 201 {object} dto.PolicyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /policies [post]
+func (h *PolicyHandler) CreatePolicy(c *gin.Context) {
+	var req dto.CreatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.policyUseCase.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetPolicy godoc
+// @Summary Get access policy
+// @Description Get access policy by ID
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Policy ID"
+// @Success 200 {object} dto.PolicyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /policies/{id} [get]
+func (h *PolicyHandler) GetPolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy id"})
+		return
+	}
+
+	resp, err := h.policyUseCase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetAllPolicies godoc
+// @Summary Get all access policies
+// @Description Get all access policies with pagination
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /policies [get]
+func (h *PolicyHandler) GetAllPolicies(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	policies, total, err := h.policyUseCase.GetAll(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": policies,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// UpdatePolicy godoc
+// @Summary Update access policy
+// @Description Update access policy by ID
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Policy ID"
+// @Param policy body dto.UpdatePolicyRequest true "Policy"
+// @Success 200 {object} dto.PolicyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /policies/{id} [put]
+func (h *PolicyHandler) UpdatePolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy id"})
+		return
+	}
+
+	var req dto.UpdatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.policyUseCase.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeletePolicy godoc
+// @Summary Delete access policy
+// @Description Delete access policy by ID
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Policy ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /policies/{id} [delete]
+func (h *PolicyHandler) DeletePolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy id"})
+		return
+	}
+
+	if err := h.policyUseCase.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EvaluatePolicy godoc
+// @Summary Evaluate access policies
+// @Description Evaluate a stored policy, an unsaved policy or all enabled policies against sample input
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.EvaluatePolicyRequest true "Policy and sample input"
+// @Success 200 {object} policy.Result
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /policies/evaluate [post]
+func (h *PolicyHandler) EvaluatePolicy(c *gin.Context) {
+	var req dto.EvaluatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.policyUseCase.Evaluate(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RoleAssignmentHandler struct {
+	roleAssignmentUseCase usecase.RoleAssignmentUseCase
+}
+
+func NewRoleAssignmentHandler(roleAssignmentUseCase usecase.RoleAssignmentUseCase) *RoleAssignmentHandler {
+	return &RoleAssignmentHandler{
+		roleAssignmentUseCase: roleAssignmentUseCase,
+	}
+}
+
+// GetRoleAssignments godoc
+// @Summary Get role assignments
+// @Description List the user's role assignments with their grantor, validity window and status
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {array} dto.RoleAssignmentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/role-assignments [get]
+func (h *RoleAssignmentHandler) GetRoleAssignments(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resp, err := h.roleAssignmentUseCase.GetAssignments(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GrantRole godoc
+// @Summary Grant role
+// @Description Assign a role to the user, optionally from starts_at until expires_at. Granting an assigned role replaces its window and reason.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param assignment body dto.GrantRoleRequest true "Role assignment"
+// @Success 200 {object} dto.RoleAssignmentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /users/{id}/role-assignments [post]
+func (h *RoleAssignmentHandler) GrantRole(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req dto.GrantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.roleAssignmentUseCase.Grant(c.Request.Context(), id, &req)
+	if err != nil {
+		status := errorStatus(err, http.StatusBadRequest)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeRole godoc
+// @Summary Revoke role
+// @Description Remove a role assignment from the user
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param roleId path string true "Role ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/role-assignments/{roleId} [delete]
+func (h *RoleAssignmentHandler) RevokeRole(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	roleID, err := uuid.Parse(c.Param("roleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	if err := h.roleAssignmentUseCase.Revoke(c.Request.Context(), id, roleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "role assignment not found"})
+			return
+		}
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
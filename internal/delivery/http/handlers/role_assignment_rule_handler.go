@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RoleAssignmentRuleHandler struct {
+	roleAssignmentRuleUseCase usecase.RoleAssignmentRuleUseCase
+}
+
+func NewRoleAssignmentRuleHandler(roleAssignmentRuleUseCase usecase.RoleAssignmentRuleUseCase) *RoleAssignmentRuleHandler {
+	return &RoleAssignmentRuleHandler{
+		roleAssignmentRuleUseCase: roleAssignmentRuleUseCase,
+	}
+}
+
+// CreateRoleAssignmentRule godoc
+// @Summary Create role assignment rule
+// @Description Create a rule that grants a role to every user whose attribute matches one of its values. The attribute is email_domain or meta.<key>. Matching users get the role shortly, the rule is applied in the background.
+// @Tags role-assignment-rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rule body dto.CreateRoleAssignmentRuleRequest true "Rule"
+// @Success 201 {object} dto.RoleAssignmentRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /role-assignment-rules [post]
+func (h *RoleAssignmentRuleHandler) CreateRoleAssignmentRule(c *gin.Context) {
+	var req dto.CreateRoleAssignmentRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.roleAssignmentRuleUseCase.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetRoleAssignmentRule godoc
+// @Summary Get role assignment rule
+// @Description Get role assignment rule by ID
+// @Tags role-assignment-rules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule ID"
+// @Success 200 {object} dto.RoleAssignmentRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /role-assignment-rules/{id} [get]
+func (h *RoleAssignmentRuleHandler) GetRoleAssignmentRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	resp, err := h.roleAssignmentRuleUseCase.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetAllRoleAssignmentRules godoc
+// @Summary Get all role assignment rules
+// @Description Get all role assignment rules with pagination
+// @Tags role-assignment-rules
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /role-assignment-rules [get]
+func (h *RoleAssignmentRuleHandler) GetAllRoleAssignmentRules(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	rules, total, err := h.roleAssignmentRuleUseCase.GetAll(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": rules,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// UpdateRoleAssignmentRule godoc
+// @Summary Update role assignment rule
+// @Description Update role assignment rule by ID. Users are re-evaluated in the background, roles the rule no longer grants them are revoked.
+// @Tags role-assignment-rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule ID"
+// @Param rule body dto.UpdateRoleAssignmentRuleRequest true "Rule"
+// @Success 200 {object} dto.RoleAssignmentRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /role-assignment-rules/{id} [put]
+func (h *RoleAssignmentRuleHandler) UpdateRoleAssignmentRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	var req dto.UpdateRoleAssignmentRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.roleAssignmentRuleUseCase.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteRoleAssignmentRule godoc
+// @Summary Delete role assignment rule
+// @Description Delete role assignment rule by ID, the roles it granted automatically are revoked
+// @Tags role-assignment-rules
+// @Security BearerAuth
+// @Param id path string true "Rule ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /role-assignment-rules/{id} [delete]
+func (h *RoleAssignmentRuleHandler) DeleteRoleAssignmentRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	if err := h.roleAssignmentRuleUseCase.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SyncRoleAssignmentRules godoc
+// @Summary Sync role assignment rules
+// @Description Evaluate every rule against every user now, granting matching roles and revoking automatic grants that no longer match. Manually assigned roles are never touched.
+// @Tags role-assignment-rules
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.RoleAssignmentRuleSyncResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /role-assignment-rules/sync [post]
+func (h *RoleAssignmentRuleHandler) SyncRoleAssignmentRules(c *gin.Context) {
+	resp, err := h.roleAssignmentRuleUseCase.Sync(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
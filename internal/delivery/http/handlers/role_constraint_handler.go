@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RoleConstraintHandler struct {
+	roleConstraintUseCase usecase.RoleConstraintUseCase
+}
+
+func NewRoleConstraintHandler(roleConstraintUseCase usecase.RoleConstraintUseCase) *RoleConstraintHandler {
+	return &RoleConstraintHandler{
+		roleConstraintUseCase: roleConstraintUseCase,
+	}
+}
+
+// CreateRoleConstraint godoc
+// @Summary Create role constraint
+// @Description Create a separation-of-duties constraint: nobody may hold more than one of its roles. Users already holding several keep them and are listed by the violations report.
+// @Tags role-constraints
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param constraint body dto.CreateRoleConstraintRequest true "Constraint"
+// @Success 201 {object} dto.RoleConstraintResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /role-constraints [post]
+func (h *RoleConstraintHandler) CreateRoleConstraint(c *gin.Context) {
+	var req dto.CreateRoleConstraintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.roleConstraintUseCase.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetRoleConstraint godoc
+// @Summary Get role constraint
+// @Description Get role constraint by ID
+// @Tags role-constraints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Constraint ID"
+// @Success 200 {object} dto.RoleConstraintResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /role-constraints/{id} [get]
+func (h *RoleConstraintHandler) GetRoleConstraint(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid constraint id"})
+		return
+	}
+
+	resp, err := h.roleConstraintUseCase.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetAllRoleConstraints godoc
+// @Summary Get all role constraints
+// @Description Get all role constraints with pagination
+// @Tags role-constraints
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /role-constraints [get]
+func (h *RoleConstraintHandler) GetAllRoleConstraints(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	constraints, total, err := h.roleConstraintUseCase.GetAll(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": constraints,
+		"meta": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// UpdateRoleConstraint godoc
+// @Summary Update role constraint
+// @Description Update role constraint by ID, role_ids replaces the roles when given
+// @Tags role-constraints
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Constraint ID"
+// @Param constraint body dto.UpdateRoleConstraintRequest true "Constraint"
+// @Success 200 {object} dto.RoleConstraintResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /role-constraints/{id} [put]
+func (h *RoleConstraintHandler) UpdateRoleConstraint(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid constraint id"})
+		return
+	}
+
+	var req dto.UpdateRoleConstraintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.roleConstraintUseCase.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteRoleConstraint godoc
+// @Summary Delete role constraint
+// @Description Delete role constraint by ID
+// @Tags role-constraints
+// @Security BearerAuth
+// @Param id path string true "Constraint ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /role-constraints/{id} [delete]
+func (h *RoleConstraintHandler) DeleteRoleConstraint(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid constraint id"})
+		return
+	}
+
+	if err := h.roleConstraintUseCase.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetRoleConstraintViolations godoc
+// @Summary Get role constraint violations
+// @Description List the users who already hold more than one role of the constraint, directly, through groups or through role inheritance
+// @Tags role-constraints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Constraint ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /role-constraints/{id}/violations [get]
+func (h *RoleConstraintHandler) GetRoleConstraintViolations(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid constraint id"})
+		return
+	}
+
+	violations, err := h.roleConstraintUseCase.GetViolations(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": violations, "total": len(violations)})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"usermanagement-api/internal/dto"
@@ -39,9 +40,9 @@ func (h *RoleHandler) CreateRole(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.roleUseCase.Create(&req)
+	resp, err := h.roleUseCase.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -68,7 +69,7 @@ func (h *RoleHandler) GetRole(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.roleUseCase.GetByID(id)
+	resp, err := h.roleUseCase.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
 		return
@@ -103,7 +104,7 @@ func (h *RoleHandler) GetAllRoles(c *gin.Context) {
 		pageSize = 10
 	}
 
-	roles, total, err := h.roleUseCase.GetAll(page, pageSize)
+	roles, total, err := h.roleUseCase.GetAll(c.Request.Context(), page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -147,9 +148,9 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.roleUseCase.Update(id, &req)
+	resp, err := h.roleUseCase.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -175,8 +176,8 @@ func (h *RoleHandler) DeleteRole(c *gin.Context) {
 		return
 	}
 
-	if err := h.roleUseCase.Delete(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.roleUseCase.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -210,11 +211,158 @@ func (h *RoleHandler) AssignPermissions(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.roleUseCase.AssignPermissions(id, req.PermissionIDs)
+	resp, err := h.roleUseCase.AssignPermissions(c.Request.Context(), id, req.PermissionIDs)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AssignDeniedPermissions godoc
+// @Summary Assign denied permissions to role
+// @Description Replace the permissions explicitly denied to holders of the role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param permissions body dto.AssignDeniedPermissionsRequest true "Permission IDs"
+// @Success 200 {object} dto.RoleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /roles/{id}/denied-permissions [post]
+func (h *RoleHandler) AssignDeniedPermissions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	var req dto.AssignDeniedPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.roleUseCase.AssignDeniedPermissions(c.Request.Context(), id, req.PermissionIDs)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AssignParents godoc
+// @Summary Assign parent roles
+// @Description Replace the roles this role inherits permissions from
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param parents body dto.AssignParentsRequest true "Parent role IDs"
+// @Success 200 {object} dto.RoleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /roles/{id}/parents [post]
+func (h *RoleHandler) AssignParents(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	var req dto.AssignParentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.roleUseCase.AssignParents(c.Request.Context(), id, req.ParentIDs)
+	if err != nil {
+		status := errorStatus(err, http.StatusBadRequest)
+		if errors.Is(err, usecase.ErrRoleHierarchyCycle) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AssignApprovers godoc
+// @Summary Assign role approvers
+// @Description Replace the users who approve or reject access requests for the role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param approvers body dto.AssignApproversRequest true "Approver user IDs"
+// @Success 200 {object} dto.RoleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /roles/{id}/approvers [post]
+func (h *RoleHandler) AssignApprovers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	var req dto.AssignApproversRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	resp, err := h.roleUseCase.AssignApprovers(c.Request.Context(), id, req.UserIDs)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AssignDelegation godoc
+// @Summary Assign role delegation scope
+// @Description Make the role a delegated admin grant: its holders may only manage users within manageable_user_scope (organization or group) and only assign manageable_role_ids. An empty scope makes it an ordinary role again.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param delegation body dto.AssignDelegationRequest true "Delegation scope"
+// @Success 200 {object} dto.RoleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /roles/{id}/delegation [post]
+func (h *RoleHandler) AssignDelegation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	var req dto.AssignDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.roleUseCase.AssignDelegation(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
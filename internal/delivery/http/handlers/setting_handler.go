@@ -26,7 +26,7 @@ func (h *SettingHandler) CreateOrUpdate(c *gin.Context) {
 		return
 	}
 
-	if err := h.settingUseCase.CreateOrUpdate(req.Key, req.Value); err != nil {
+	if err := h.settingUseCase.CreateOrUpdate(c.Request.Context(), req.Key, req.Value); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -37,7 +37,7 @@ func (h *SettingHandler) CreateOrUpdate(c *gin.Context) {
 func (h *SettingHandler) GetByKey(c *gin.Context) {
 	key := c.Param("key")
 
-	resp, err := h.settingUseCase.GetByKey(key)
+	resp, err := h.settingUseCase.GetByKey(c.Request.Context(), key)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "setting not found"})
 		return
@@ -47,7 +47,7 @@ func (h *SettingHandler) GetByKey(c *gin.Context) {
 }
 
 func (h *SettingHandler) GetAll(c *gin.Context) {
-	settingsMap, err := h.settingUseCase.GetAll()
+	settingsMap, err := h.settingUseCase.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -59,7 +59,7 @@ func (h *SettingHandler) GetAll(c *gin.Context) {
 func (h *SettingHandler) Delete(c *gin.Context) {
 	key := c.Param("key")
 
-	if err := h.settingUseCase.Delete(key); err != nil {
+	if err := h.settingUseCase.Delete(c.Request.Context(), key); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
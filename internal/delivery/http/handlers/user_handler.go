@@ -31,6 +31,7 @@ func NewUserHandler(userUseCase usecase.UserUseCase) *UserHandler {
 // @Success 201 {object} dto.UserResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req dto.CreateUserRequest
@@ -39,9 +40,9 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.userUseCase.Create(&req)
+	resp, err := h.userUseCase.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -68,7 +69,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.userUseCase.GetByID(id)
+	resp, err := h.userUseCase.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
@@ -103,7 +104,7 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 		pageSize = 10
 	}
 
-	users, total, err := h.userUseCase.GetAll(page, pageSize)
+	users, total, err := h.userUseCase.GetAll(c.Request.Context(), page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -133,6 +134,7 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -147,9 +149,9 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.userUseCase.Update(id, &req)
+	resp, err := h.userUseCase.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -175,8 +177,8 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userUseCase.Delete(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.userUseCase.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -197,11 +199,46 @@ func (h *UserHandler) AssignRoles(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.userUseCase.AssignRoles(id, req.RoleIDs)
+	resp, err := h.userUseCase.AssignRoles(c.Request.Context(), id, req.RoleIDs)
 	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AssignDeniedPermissions godoc
+// @Summary Assign denied permissions to user
+// @Description Replace the permissions explicitly denied to the user, they override role grants and superuser status
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param permissions body dto.AssignDeniedPermissionsRequest true "Permission IDs"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /users/{id}/denied-permissions [post]
+func (h *UserHandler) AssignDeniedPermissions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req dto.AssignDeniedPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	resp, err := h.userUseCase.AssignDeniedPermissions(c.Request.Context(), id, req.PermissionIDs)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"usermanagement-api/internal/dto"
 	"usermanagement-api/internal/usecase"
@@ -27,8 +28,8 @@ func (h *UserMetaHandler) CreateOrUpdate(c *gin.Context) {
 		return
 	}
 
-	if err := h.userMetaUseCase.CreateOrUpdate(req.UserID, req.Key, req.Value); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.userMetaUseCase.CreateOrUpdate(c.Request.Context(), req.UserID, req.Key, req.Value); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -45,9 +46,13 @@ func (h *UserMetaHandler) GetByKey(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.userMetaUseCase.GetByKey(userID, key)
+	resp, err := h.userMetaUseCase.GetByKey(c.Request.Context(), userID, key)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user meta not found"})
+		status := errorStatus(err, http.StatusNotFound)
+		if status == http.StatusNotFound {
+			err = errors.New("user meta not found")
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -63,7 +68,7 @@ func (h *UserMetaHandler) GetAllByUserID(c *gin.Context) {
 		return
 	}
 
-	metaMap, err := h.userMetaUseCase.GetAllByUserID(userID)
+	metaMap, err := h.userMetaUseCase.GetAllByUserID(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -82,10 +87,45 @@ func (h *UserMetaHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.userMetaUseCase.Delete(userID, key); err != nil {
+	if err := h.userMetaUseCase.Delete(c.Request.Context(), userID, key); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *UserMetaHandler) GetKeyPolicies(c *gin.Context) {
+	policies, err := h.userMetaUseCase.GetKeyPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policies})
+}
+
+func (h *UserMetaHandler) SetKeyPolicy(c *gin.Context) {
+	var req dto.SetUserMetaKeyPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.userMetaUseCase.SetKeyPolicy(c.Request.Context(), c.Param("key"), req.Visibility)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *UserMetaHandler) DeleteKeyPolicy(c *gin.Context) {
+	if err := h.userMetaUseCase.DeleteKeyPolicy(c.Request.Context(), c.Param("key")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user meta key policy not found"})
+		return
+	}
+
 	c.Status(http.StatusNoContent)
 }
@@ -6,7 +6,12 @@ import (
 	"usermanagement-api/domain/entities"
 	"usermanagement-api/domain/repositories"
 	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/internal/usecase"
 	"usermanagement-api/pkg/auth"
+	"usermanagement-api/pkg/permission"
+	"usermanagement-api/pkg/policy"
+	"usermanagement-api/pkg/requestctx"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,7 +19,7 @@ import (
 
 type AuthMiddleware interface {
 	RequireAuth() gin.HandlerFunc
-	RequirePermission(modelType string, modelID uuid.UUID, permissionName string) gin.HandlerFunc
+	RequirePermission(permissions ...string) gin.HandlerFunc
 	RequireRole(roles ...string) gin.HandlerFunc
 	RequireSuperuser() gin.HandlerFunc
 }
@@ -24,6 +29,8 @@ type authMiddleware struct {
 	roleRepo            repositories.RoleRepository
 	permissionRepo      repositories.PermissionRepository
 	modelPermissionRepo repositories.ModelPermissionRepository
+	organizationRepo    repositories.OrganizationRepository
+	policyUseCase       usecase.PolicyUseCase
 }
 
 func NewAuthMiddleware(
@@ -31,12 +38,16 @@ func NewAuthMiddleware(
 	roleRepo repositories.RoleRepository,
 	permissionRepo repositories.PermissionRepository,
 	modelPermissionRepo repositories.ModelPermissionRepository,
+	organizationRepo repositories.OrganizationRepository,
+	policyUseCase usecase.PolicyUseCase,
 ) AuthMiddleware {
 	return &authMiddleware{
 		userRepo:            userRepo,
 		roleRepo:            roleRepo,
 		permissionRepo:      permissionRepo,
 		modelPermissionRepo: modelPermissionRepo,
+		organizationRepo:    organizationRepo,
+		policyUseCase:       policyUseCase,
 	}
 }
 
@@ -83,13 +94,34 @@ func (m *authMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Get user roles
-		roles, err := m.roleRepo.FindRolesByUserID(user.ID)
+		// The organization claim only holds while the user still belongs to it
+		if claims.OrganizationID != nil && !user.IsSuperuser {
+			member, err := m.organizationRepo.IsMember(*claims.OrganizationID, user.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve organization membership"})
+				c.Abort()
+				return
+			}
+			if !member {
+				c.JSON(http.StatusForbidden, gin.H{"error": "not a member of the organization"})
+				c.Abort()
+				return
+			}
+		}
+
+		// Get user roles, tenant roles only count inside their own organization
+		assigned, err := m.roleRepo.FindRolesByUserID(user.ID, claims.OrganizationID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve user roles"})
 			c.Abort()
 			return
 		}
+		var roles []*entities.Role
+		for _, role := range assigned {
+			if role.AppliesIn(claims.OrganizationID) {
+				roles = append(roles, role)
+			}
+		}
 
 		// Get role IDs
 		var roleIDs []uuid.UUID
@@ -97,107 +129,131 @@ func (m *authMiddleware) RequireAuth() gin.HandlerFunc {
 			roleIDs = append(roleIDs, role.ID)
 		}
 
-		// Get permissions
-		permissions, err := m.roleRepo.FindPermissionsByRoleIDs(roleIDs)
+		// Get permissions, including those inherited from parent roles
+		permissions, err := m.roleRepo.FindEffectivePermissionsByRoleIDs(roleIDs)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve user permissions"})
 			c.Abort()
 			return
 		}
 
+		// Explicit denies come from the user and from its roles and their ancestors
+		denied, err := m.roleRepo.FindEffectiveDeniedPermissionsByRoleIDs(roleIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve user permissions"})
+			c.Abort()
+			return
+		}
+		denied = append(denied, user.DeniedPermissions...)
+
 		c.Set(constants.AccessToken, tokenString)
 
+		// Expose the caller to use cases through the request context
+		info := requestctx.FromContext(c.Request.Context())
+		info.UserID = &user.ID
+		info.ImpersonatorID = claims.ImpersonatorID
+		info.OrganizationID = claims.OrganizationID
+		info.IsSuperuser = user.IsSuperuser
+		info.Permissions = permission.NewSet(nil)
+		for _, p := range permissions {
+			info.Permissions.Add(p.Name)
+		}
+		info.Denied = permission.NewSet(nil)
+		for _, p := range denied {
+			info.Denied.Add(p.Name)
+		}
+		c.Request = c.Request.WithContext(requestctx.WithInfo(c.Request.Context(), info))
+
 		// Store user ID in the context
 		c.Set(constants.UserIDKey, user.ID)
 
+		// Store the user itself so guards don't need to reload it
+		c.Set(constants.UserKey, user)
+
 		// Store user roles in the context
 		c.Set(constants.UserRolesKey, roles)
 
 		// Store user permissions in the context
 		c.Set(constants.PermissionsKey, permissions)
 
+		// Store explicit denies in the context
+		c.Set(constants.DeniedPermissionsKey, denied)
+
 		c.Next()
 	}
 }
 
-func (m *authMiddleware) RequirePermission(modelType string, modelID uuid.UUID, permissionName string) gin.HandlerFunc {
+// RequirePermission allows the request when every named permission is granted. Access
+// policies are evaluated first: a deny policy always wins. Explicit denies on the user or
+// its roles come next and apply to superusers as well. An allow policy then grants the
+// permission, otherwise the caller's effective permission set must cover it, directly or
+// through a wildcard; superusers hold every permission.
+func (m *authMiddleware) RequirePermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get user ID from context
-		userID, exists := c.Get(constants.UserIDKey)
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
-			c.Abort()
-			return
-		}
-
-		userUUID, ok := userID.(uuid.UUID)
+		user, ok := currentUser(c)
 		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
 			c.Abort()
 			return
 		}
-		user, err := m.userRepo.FindByID(userUUID)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
-			c.Abort()
-			return
-		}
 
-		// If user is superuser, allow access immediately
-		if user.IsSuperuser {
-			c.Next()
-			return
+		granted := permission.NewSet(nil)
+		if value, exists := c.Get(constants.PermissionsKey); exists {
+			for _, p := range value.([]*entities.Permission) {
+				granted.Add(p.Name)
+			}
 		}
-
-		// Get roles from context
-		roles, exists := c.Get(constants.UserRolesKey)
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
-			c.Abort()
-			return
+		denied := permission.NewSet(nil)
+		if value, exists := c.Get(constants.DeniedPermissionsKey); exists {
+			for _, p := range value.([]*entities.Permission) {
+				denied.Add(p.Name)
+			}
 		}
 
-		// Get permission by name
-		permission, err := m.permissionRepo.FindByName(permissionName)
-		if err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": constants.ErrForbidden})
-			c.Abort()
-			return
-		}
+		for _, required := range permissions {
+			resourceType, _, _ := strings.Cut(required, ".")
+			result, err := m.policyUseCase.Decide(c.Request.Context(), required, dto.PolicyResource{
+				Type: resourceType,
+				ID:   c.Param("id"),
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to evaluate access policies"})
+				c.Abort()
+				return
+			}
 
-		// Check if user has permission
-		hasPermission := false
+			if result.Decision == policy.DecisionDeny {
+				resp := gin.H{"error": constants.ErrForbidden, "required_permission": required}
+				if deciding := result.DecidingPolicy(); deciding != nil {
+					resp["policy"] = deciding.Name
+				}
+				c.JSON(http.StatusForbidden, resp)
+				c.Abort()
+				return
+			}
 
-		// Option 1: Check through model permissions
-		for range roles.([]*entities.Role) {
-			hasAccess, err := m.modelPermissionRepo.CheckPermission(modelType, modelID, permission.ID)
-			if err == nil && hasAccess {
-				hasPermission = true
-				break
+			if deniedBy := denied.MatchedBy(required); deniedBy != "" {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":               constants.ErrForbidden,
+					"required_permission": required,
+					"denied_by":           deniedBy,
+				})
+				c.Abort()
+				return
 			}
-		}
 
-		// Option 2: Check through role permissions
-		if !hasPermission {
-			userRoles := roles.([]*entities.Role)
-			for _, role := range userRoles {
-				// Check if the role has the required permission
-				for _, rolePermission := range role.Permissions {
-					if rolePermission.ID == permission.ID {
-						hasPermission = true
-						break
-					}
-				}
-				if hasPermission {
-					break
-				}
+			if result.Decision == policy.DecisionAllow {
+				continue
 			}
-		}
 
-		if !hasPermission {
-			c.JSON(http.StatusForbidden, gin.H{"error": constants.ErrForbidden})
-			c.Abort()
-			return
+			if !user.IsSuperuser && !granted.Has(required) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":               constants.ErrForbidden,
+					"required_permission": required,
+				})
+				c.Abort()
+				return
+			}
 		}
 
 		c.Next()
@@ -206,27 +262,13 @@ func (m *authMiddleware) RequirePermission(modelType string, modelID uuid.UUID,
 
 func (m *authMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID, exists := c.Get(constants.UserIDKey)
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
-			c.Abort()
-			return
-		}
-
-		userUUID, ok := userID.(uuid.UUID)
+		user, ok := currentUser(c)
 		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
 			c.Abort()
 			return
 		}
 
-		user, err := m.userRepo.FindByID(userUUID)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
-			c.Abort()
-			return
-		}
-
 		// If user is superuser, allow access immediately
 		if user.IsSuperuser {
 			c.Next()
@@ -270,15 +312,13 @@ func (m *authMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 func (m *authMiddleware) RequireSuperuser() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user from context
-		userInterface, exists := c.Get(constants.UserIDKey)
-		if !exists {
+		user, ok := currentUser(c)
+		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": constants.ErrUnauthorized})
 			c.Abort()
 			return
 		}
 
-		user := userInterface.(*entities.User)
-
 		// Check if user is superuser
 		if !user.IsSuperuser {
 			c.JSON(http.StatusForbidden, gin.H{"error": "superuser access required"})
@@ -289,3 +329,13 @@ func (m *authMiddleware) RequireSuperuser() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// currentUser returns the user loaded by RequireAuth
+func currentUser(c *gin.Context) (*entities.User, bool) {
+	value, exists := c.Get(constants.UserKey)
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*entities.User)
+	return user, ok && user != nil
+}
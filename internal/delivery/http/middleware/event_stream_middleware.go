@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"usermanagement-api/internal/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventStreamToken lets RequireAuth read the access token from the access_token query param.
+// Browsers cannot set headers on EventSource, so it is only used on the notification stream;
+// other routes never accept tokens in the URL.
+func EventStreamToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query(constants.AccessToken); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestContext attaches a request ID, client IP and user agent to the request context.
+// RequireAuth fills in the authenticated user on the same Info.
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.New().String()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		info := &requestctx.Info{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: requestID,
+		}
+		c.Request = c.Request.WithContext(requestctx.WithInfo(c.Request.Context(), info))
+
+		c.Next()
+	}
+}
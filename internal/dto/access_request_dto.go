@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateAccessRequestRequest asks for a role, role_expires_at requests a time-bound grant
+type CreateAccessRequestRequest struct {
+	RoleID        uuid.UUID  `json:"role_id" binding:"required"`
+	Justification string     `json:"justification" binding:"required,max=1000"`
+	RoleExpiresAt *time.Time `json:"role_expires_at"`
+}
+
+// ApproveAccessRequestRequest decides a request, role_expires_at overrides the requested expiry
+type ApproveAccessRequestRequest struct {
+	Note          string     `json:"note" binding:"max=255"`
+	RoleExpiresAt *time.Time `json:"role_expires_at"`
+}
+
+type RejectAccessRequestRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+type AccessRequestResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	User          UserSimple `json:"user"`
+	Role          RoleSimple `json:"role"`
+	Justification string     `json:"justification"`
+	Status        string     `json:"status"` // pending, approved, rejected, cancelled or expired
+	RoleExpiresAt *string    `json:"role_expires_at"`
+	ExpiresAt     string     `json:"expires_at"`
+	DecidedBy     *uuid.UUID `json:"decided_by"`
+	DecidedAt     *string    `json:"decided_at"`
+	DecisionNote  string     `json:"decision_note,omitempty"`
+	CreatedAt     string     `json:"created_at"`
+	UpdatedAt     string     `json:"updated_at"`
+}
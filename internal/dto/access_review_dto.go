@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateAccessReviewRequest launches a campaign over the current assignments of role_ids, of
+// user_ids, or of both combined. At least one of them is required.
+type CreateAccessReviewRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description"`
+	RoleIDs     []uuid.UUID `json:"role_ids"`
+	UserIDs     []uuid.UUID `json:"user_ids"`
+	// ReviewerID reviews the items of roles without approvers
+	ReviewerID           uuid.UUID `json:"reviewer_id" binding:"required"`
+	EscalationReviewerID uuid.UUID `json:"escalation_reviewer_id" binding:"required"`
+	DueAt                time.Time `json:"due_at" binding:"required"`
+	RevokeUndecided      bool      `json:"revoke_undecided"`
+}
+
+type DecideAccessReviewItemRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=keep revoke"`
+	Comment  string `json:"comment" binding:"max=255"`
+}
+
+type AccessReviewResponse struct {
+	ID                 uuid.UUID        `json:"id"`
+	Name               string           `json:"name"`
+	Description        string           `json:"description"`
+	Status             string           `json:"status"` // open or closed
+	DueAt              string           `json:"due_at"`
+	EscalationReviewer UserSimple       `json:"escalation_reviewer"`
+	RevokeUndecided    bool             `json:"revoke_undecided"`
+	Items              map[string]int64 `json:"items"` // item count per decision
+	CreatedBy          *uuid.UUID       `json:"created_by"`
+	ClosedBy           *uuid.UUID       `json:"closed_by"`
+	ClosedAt           *string          `json:"closed_at"`
+	CreatedAt          string           `json:"created_at"`
+	UpdatedAt          string           `json:"updated_at"`
+}
+
+type AccessReviewItemResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	CampaignID  uuid.UUID  `json:"campaign_id"`
+	Campaign    string     `json:"campaign"`
+	User        UserSimple `json:"user"`
+	Role        RoleSimple `json:"role"`
+	Reviewer    UserSimple `json:"reviewer"`
+	Decision    string     `json:"decision"` // pending, keep or revoke
+	Comment     string     `json:"comment,omitempty"`
+	DecidedAt   *string    `json:"decided_at"`
+	EscalatedAt *string    `json:"escalated_at"`
+	RevokedAt   *string    `json:"revoked_at"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditFilterRequest struct {
+	ActorID    string     `form:"actor_id" binding:"omitempty,uuid"`
+	Action     string     `form:"action"`
+	TargetType string     `form:"target_type"`
+	TargetID   string     `form:"target_id"`
+	RequestID  string     `form:"request_id"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type AuditChangeResponse struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type AuditEventResponse struct {
+	ID             uuid.UUID                      `json:"id"`
+	Sequence       int64                          `json:"sequence"`
+	Actor          *UserSimple                    `json:"actor"`
+	ImpersonatorID *uuid.UUID                     `json:"impersonator_id,omitempty"`
+	Action         string                         `json:"action"`
+	TargetType     string                         `json:"target_type"`
+	TargetID       string                         `json:"target_id"`
+	Before         map[string]interface{}         `json:"before"`
+	After          map[string]interface{}         `json:"after"`
+	Changes        map[string]AuditChangeResponse `json:"changes"`
+	IP             string                         `json:"ip"`
+	UserAgent      string                         `json:"user_agent"`
+	RequestID      string                         `json:"request_id"`
+	CreatedAt      string                         `json:"created_at"`
+	PrevHash       string                         `json:"prev_hash"`
+	Hash           string                         `json:"hash"`
+
+	OrganizationID *uuid.UUID `json:"organization_id"`
+}
+
+// AuditBrokenLink is the first place where the audit chain stops verifying
+type AuditBrokenLink struct {
+	Sequence int64      `json:"sequence"`
+	EventID  *uuid.UUID `json:"event_id,omitempty"`
+	Reason   string     `json:"reason"`
+}
+
+type AuditVerifyResponse struct {
+	Valid              bool             `json:"valid"`
+	EventsChecked      int64            `json:"events_checked"`
+	LastSequence       int64            `json:"last_sequence"`
+	LastHash           string           `json:"last_hash"`
+	CheckpointsChecked int              `json:"checkpoints_checked"`
+	BrokenLink         *AuditBrokenLink `json:"broken_link,omitempty"`
+}
@@ -17,6 +17,9 @@ type AuthResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	Type         string `json:"type"`
+
+	// OrganizationID is the organization the tokens act in
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
 }
 
 type RegisterRequest struct {
@@ -33,10 +36,19 @@ type ModelPermissionRequest struct {
 	PermissionID uuid.UUID `json:"permission_id" binding:"required"`
 }
 
+// ReplaceModelPermissionsRequest sets the exact permissions attached to a model, an empty list
+// removes them all
+type ReplaceModelPermissionsRequest struct {
+	ModelID       uuid.UUID   `json:"model_id" binding:"required"`
+	ModelType     string      `json:"model_type" binding:"required"`
+	PermissionIDs []uuid.UUID `json:"permission_ids" binding:"max=500"`
+}
+
 type ModelPermissionResponse struct {
 	ID           uuid.UUID        `json:"id"`
 	ModelID      uuid.UUID        `json:"model_id"`
 	ModelType    string           `json:"model_type"`
+	ModelName    string           `json:"model_name"` // empty when the model no longer exists
 	PermissionID uuid.UUID        `json:"permission_id"`
 	Permission   PermissionSimple `json:"permission"`
 	CreatedAt    string           `json:"created_at"`
@@ -54,11 +66,19 @@ type SendNotificationRequest struct {
 	Data    map[string]string `json:"data"`
 	UserIDs []uuid.UUID       `json:"user_ids,omitempty"`
 	Topic   string            `json:"topic,omitempty"`
+	// Category lets recipients mute it, critical messages bypass mutes and quiet hours
+	Category string `json:"category,omitempty" binding:"omitempty,oneof=general account security marketing system"`
+	Critical bool   `json:"critical,omitempty"`
 }
 
 type NotificationResponse struct {
-	Success      bool   `json:"success"`
-	SuccessCount int    `json:"success_count,omitempty"`
-	MessageID    string `json:"message_id,omitempty"`
-	Error        string `json:"error,omitempty"`
+	Success         bool      `json:"success"`
+	NotificationID  uuid.UUID `json:"notification_id,omitempty"`
+	SuccessCount    int       `json:"success_count,omitempty"`
+	FailureCount    int       `json:"failure_count,omitempty"`
+	InboxCount      int       `json:"inbox_count,omitempty"`
+	DeferredCount   int       `json:"deferred_count,omitempty"`
+	SuppressedCount int       `json:"suppressed_count,omitempty"`
+	MessageID       string    `json:"message_id,omitempty"`
+	Error           string    `json:"error,omitempty"`
 }
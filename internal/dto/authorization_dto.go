@@ -0,0 +1,102 @@
+package dto
+
+import "github.com/google/uuid"
+
+type PermissionCheckItem struct {
+	Permission string `json:"permission" binding:"required"`
+	// ResourceType defaults to the first segment of Permission
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+}
+
+// PermissionCheckRequest checks permissions for the caller, or for UserID when the caller
+// holds permissions.check
+type PermissionCheckRequest struct {
+	UserID *uuid.UUID            `json:"user_id"`
+	Checks []PermissionCheckItem `json:"checks" binding:"required,min=1,max=100,dive"`
+}
+
+type PermissionCheckResponse struct {
+	UserID  uuid.UUID               `json:"user_id"`
+	Results []PermissionCheckResult `json:"results"`
+}
+
+type PermissionCheckResult struct {
+	Permission   string                 `json:"permission"`
+	ResourceType string                 `json:"resource_type,omitempty"`
+	ResourceID   string                 `json:"resource_id,omitempty"`
+	Allowed      bool                   `json:"allowed"`
+	Explanation  *PermissionExplanation `json:"explanation,omitempty"`
+	// ModelPermission is set when the permission is not allowed but a model permission of the
+	// user or one of its roles grants it. Route guards ignore model permissions, so it does not
+	// make Allowed true; applications check it for the model itself.
+	ModelPermission *PermissionExplanation `json:"model_permission,omitempty"`
+}
+
+// PermissionExplanation says which source decided a permission check
+type PermissionExplanation struct {
+	Source string `json:"source"` // superuser, role, policy, user_deny, role_deny or none; model_permission for ModelPermission
+	// Role holds the permission, AssignedRole is set when Role is inherited through it
+	Role         *RoleSimple `json:"role,omitempty"`
+	AssignedRole *RoleSimple `json:"assigned_role,omitempty"`
+	// Groups is the group path the assigned role is held through, from the user's own group outwards
+	Groups []GroupSimple `json:"groups,omitempty"`
+	// GrantedBy is the granted name, it differs from the checked one for wildcard grants
+	GrantedBy string `json:"granted_by,omitempty"`
+	// DeniedBy is the explicit deny that matched, possibly a wildcard
+	DeniedBy string `json:"denied_by,omitempty"`
+	Policy   string `json:"policy,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// RoleExplanation says how a user holds a role
+type RoleExplanation struct {
+	UserID  uuid.UUID    `json:"user_id"`
+	Role    RoleSimple   `json:"role"`
+	HasRole bool         `json:"has_role"`
+	Sources []RoleSource `json:"sources"`
+}
+
+// RoleSource is one way the user holds a role
+type RoleSource struct {
+	Source string `json:"source"` // direct or group
+	// AssignedRole is set when the role is inherited through the role the user holds
+	AssignedRole *RoleSimple `json:"assigned_role,omitempty"`
+	// Groups runs from a group the user is a member of, through parent groups, to the group
+	// the role is assigned to
+	Groups []GroupSimple `json:"groups,omitempty"`
+}
+
+// EffectivePermissions is everything a user is granted in the caller's organization, with
+// where each permission comes from
+type EffectivePermissions struct {
+	UserID      uuid.UUID             `json:"user_id"`
+	IsSuperuser bool                  `json:"is_superuser"`
+	IsActive    bool                  `json:"is_active"`
+	Permissions []EffectivePermission `json:"permissions"`
+	// Denied lists granted permissions withheld by an explicit deny
+	Denied []DeniedPermission `json:"denied"`
+	Menus  []MenuResponse     `json:"menus"`
+}
+
+type EffectivePermission struct {
+	Name    string             `json:"name"`
+	Sources []PermissionSource `json:"sources"`
+}
+
+// PermissionSource is one way the user receives a permission
+type PermissionSource struct {
+	Source string `json:"source"` // role, inherited_role, group or superuser
+	// Role holds the permission, AssignedRole is set when Role is inherited through it
+	Role         *RoleSimple `json:"role,omitempty"`
+	AssignedRole *RoleSimple `json:"assigned_role,omitempty"`
+	// Groups is the group path the assigned role is held through
+	Groups []GroupSimple `json:"groups,omitempty"`
+	// GrantedBy is the granted name, it differs from Name for wildcard grants
+	GrantedBy string `json:"granted_by,omitempty"`
+}
+
+type DeniedPermission struct {
+	Name        string                 `json:"name"`
+	Explanation *PermissionExplanation `json:"explanation"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import "github.com/google/uuid"
+
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type UpdateGroupRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+type GroupResponse struct {
+	ID             uuid.UUID     `json:"id"`
+	Name           string        `json:"name"`
+	Description    string        `json:"description"`
+	Roles          []RoleSimple  `json:"roles"`
+	Parents        []GroupSimple `json:"parents"`
+	OrganizationID *uuid.UUID    `json:"organization_id"`
+	CreatedAt      string        `json:"created_at"`
+	UpdatedAt      string        `json:"updated_at"`
+}
+
+type GroupSimple struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type AddGroupMembersRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" binding:"required,min=1"`
+}
+
+type AssignGroupRolesRequest struct {
+	RoleIDs []uuid.UUID `json:"role_ids" binding:"required"`
+}
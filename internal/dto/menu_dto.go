@@ -36,6 +36,8 @@ type MenuResponse struct {
 	CreatedAt   string        `json:"created_at"`
 	UpdatedAt   string        `json:"updated_at"`
 	DeletedAt   string        `json:"delete_at"`
+
+	OrganizationID *uuid.UUID `json:"organization_id"`
 }
 
 type MenuSimple struct {
@@ -0,0 +1,92 @@
+// internal/dto/notification_dto.go
+package dto
+
+import "github.com/google/uuid"
+
+type InboxItemResponse struct {
+	ID        uint              `json:"id"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Data      map[string]string `json:"data,omitempty"`
+	IsRead    bool              `json:"is_read"`
+	ReadAt    *string           `json:"read_at"`
+	CreatedAt string            `json:"created_at"`
+}
+
+// NotificationStreamEvent is the payload published to a user's channel and
+// forwarded to the browser over Server-Sent Events.
+type NotificationStreamEvent struct {
+	Type        string             `json:"type"`
+	Item        *InboxItemResponse `json:"item,omitempty"`
+	UnreadCount int64              `json:"unread_count"`
+}
+
+// NotificationMessage is the content of a notification, independent of its recipients
+type NotificationMessage struct {
+	Title    string
+	Body     string
+	Data     map[string]string
+	Category string
+	Critical bool // delivered regardless of the recipient's mutes and quiet hours
+}
+
+type NotificationPreferenceRequest struct {
+	MutedCategories   []string `json:"muted_categories" binding:"dive,oneof=general account security marketing system"`
+	Channels          []string `json:"channels" binding:"omitempty,dive,oneof=push in_app"`
+	QuietHoursEnabled bool     `json:"quiet_hours_enabled"`
+	QuietHoursStart   string   `json:"quiet_hours_start" example:"22:00"`
+	QuietHoursEnd     string   `json:"quiet_hours_end" example:"07:00"`
+	QuietHoursMode    string   `json:"quiet_hours_mode" binding:"omitempty,oneof=defer suppress"`
+	Timezone          string   `json:"timezone" example:"Asia/Jakarta"`
+}
+
+type NotificationPreferenceResponse struct {
+	MutedCategories   []string `json:"muted_categories"`
+	Channels          []string `json:"channels"`
+	QuietHoursEnabled bool     `json:"quiet_hours_enabled"`
+	QuietHoursStart   string   `json:"quiet_hours_start"`
+	QuietHoursEnd     string   `json:"quiet_hours_end"`
+	QuietHoursMode    string   `json:"quiet_hours_mode"`
+	Timezone          string   `json:"timezone"`
+	InQuietHours      bool     `json:"in_quiet_hours"`
+	UpdatedAt         *string  `json:"updated_at"`
+}
+
+type NotificationSummaryResponse struct {
+	ID             uuid.UUID         `json:"id"`
+	SenderID       *uuid.UUID        `json:"sender_id"`
+	Title          string            `json:"title"`
+	Body           string            `json:"body"`
+	Data           map[string]string `json:"data,omitempty"`
+	Category       string            `json:"category"`
+	Critical       bool              `json:"critical"`
+	TargetType     string            `json:"target_type"`
+	Topic          string            `json:"topic,omitempty"`
+	RecipientCount int               `json:"recipient_count"`
+	CreatedAt      string            `json:"created_at"`
+}
+
+type NotificationReportResponse struct {
+	Notification        NotificationSummaryResponse `json:"notification"`
+	Recipients          int64                       `json:"recipients"`
+	RecipientsReached   int64                       `json:"recipients_reached"`
+	RecipientsUnreached int64                       `json:"recipients_unreached"`
+	RecipientsOpened    int64                       `json:"recipients_opened"`
+	ByStatus            map[string]int64            `json:"by_status"`
+	ByChannel           map[string]map[string]int64 `json:"by_channel"`
+	ErrorCodes          map[string]int64            `json:"error_codes"`
+}
+
+type NotificationDeliveryResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       *uuid.UUID `json:"user_id"`
+	Channel      string     `json:"channel"`
+	Device       string     `json:"device,omitempty"`
+	Status       string     `json:"status"`
+	ErrorCode    string     `json:"error_code,omitempty"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	MessageID    string     `json:"message_id,omitempty"`
+	SentAt       *string    `json:"sent_at"`
+	OpenedAt     *string    `json:"opened_at"`
+	ReadAt       *string    `json:"read_at"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "github.com/google/uuid"
+
+type CreateOrganizationRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Slug        string `json:"slug" binding:"required"`
+	Description string `json:"description"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
+	Description *string `json:"description"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type OrganizationResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   string    `json:"created_at"`
+	UpdatedAt   string    `json:"updated_at"`
+}
+
+type AddOrganizationMembersRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" binding:"required,min=1"`
+}
+
+type OrganizationMemberResponse struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	User           UserSimple `json:"user"`
+	JoinedAt       string     `json:"joined_at"`
+}
+
+// SwitchOrganizationRequest selects the organization the caller acts in
+type SwitchOrganizationRequest struct {
+	OrganizationID uuid.UUID `json:"organization_id" binding:"required"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"usermanagement-api/pkg/policy"
+
+	"github.com/google/uuid"
+)
+
+type CreatePolicyRequest struct {
+	Name        string             `json:"name" binding:"required"`
+	Description string             `json:"description"`
+	Effect      string             `json:"effect" binding:"required,oneof=allow deny"`
+	Actions     []string           `json:"actions" binding:"required,min=1"`
+	Conditions  []policy.Condition `json:"conditions"`
+	Enabled     *bool              `json:"enabled"`
+}
+
+type UpdatePolicyRequest struct {
+	Name        string             `json:"name"`
+	Description *string            `json:"description"`
+	Effect      string             `json:"effect" binding:"omitempty,oneof=allow deny"`
+	Actions     []string           `json:"actions"`
+	Conditions  []policy.Condition `json:"conditions"`
+	Enabled     *bool              `json:"enabled"`
+}
+
+type PolicyResponse struct {
+	ID          uuid.UUID          `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Effect      string             `json:"effect"`
+	Actions     []string           `json:"actions"`
+	Conditions  []policy.Condition `json:"conditions"`
+	Enabled     bool               `json:"enabled"`
+	CreatedAt   string             `json:"created_at"`
+	UpdatedAt   string             `json:"updated_at"`
+
+	OrganizationID *uuid.UUID `json:"organization_id"`
+}
+
+// EvaluatePolicyRequest evaluates sample input against a stored policy, an unsaved policy,
+// or every enabled policy when neither is given.
+type EvaluatePolicyRequest struct {
+	PolicyID *uuid.UUID           `json:"policy_id"`
+	Policy   *CreatePolicyRequest `json:"policy"`
+	Input    policy.Input         `json:"input" binding:"required"`
+}
+
+// PolicyResource identifies the resource a request acts on, Type is the first segment of
+// the permission name (e.g. "users") and ID the route's :id parameter.
+type PolicyResource struct {
+	Type string
+	ID   string
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GrantRoleRequest assigns a single role, optionally limited to a validity window
+type GrantRoleRequest struct {
+	RoleID    uuid.UUID  `json:"role_id" binding:"required"`
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Reason    string     `json:"reason" binding:"max=255"`
+}
+
+type RoleAssignmentResponse struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Role      RoleSimple `json:"role"`
+	GrantedBy *uuid.UUID `json:"granted_by"`
+	GrantedAt string     `json:"granted_at"`
+	StartsAt  *string    `json:"starts_at"`
+	ExpiresAt *string    `json:"expires_at"`
+	Reason    string     `json:"reason,omitempty"`
+	Status    string     `json:"status"` // active, scheduled or expired
+	// RuleID is set on assignments made by a role assignment rule
+	RuleID *uuid.UUID `json:"rule_id,omitempty"`
+	// OrganizationID is the organization the role was assigned in, absent for assignments that count everywhere
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CreateRoleAssignmentRuleRequest grants role_id to users whose attribute equals one of values.
+// attribute is "email_domain" or "meta.<key>" of an admin_only key, values are compared case-insensitively.
+type CreateRoleAssignmentRuleRequest struct {
+	Name        string    `json:"name" binding:"required"`
+	Description string    `json:"description"`
+	Attribute   string    `json:"attribute" binding:"required"`
+	Values      []string  `json:"values" binding:"required,min=1,max=100"`
+	RoleID      uuid.UUID `json:"role_id" binding:"required"`
+	Enabled     *bool     `json:"enabled"`
+}
+
+type UpdateRoleAssignmentRuleRequest struct {
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	Attribute   string     `json:"attribute"`
+	Values      []string   `json:"values" binding:"omitempty,min=1,max=100"`
+	RoleID      *uuid.UUID `json:"role_id"`
+	Enabled     *bool      `json:"enabled"`
+}
+
+type RoleAssignmentRuleResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Attribute   string     `json:"attribute"`
+	Values      []string   `json:"values"`
+	Role        RoleSimple `json:"role"`
+	Enabled     bool       `json:"enabled"`
+	CreatedAt   string     `json:"created_at"`
+	UpdatedAt   string     `json:"updated_at"`
+}
+
+// RoleAssignmentRuleSyncResponse counts the assignments a re-evaluation changed
+type RoleAssignmentRuleSyncResponse struct {
+	EvaluatedUsers int `json:"evaluated_users"`
+	Granted        int `json:"granted"`
+	Revoked        int `json:"revoked"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "github.com/google/uuid"
+
+type CreateRoleConstraintRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description"`
+	RoleIDs     []uuid.UUID `json:"role_ids" binding:"required,min=2"`
+}
+
+type UpdateRoleConstraintRequest struct {
+	Name        string      `json:"name"`
+	Description *string     `json:"description"`
+	RoleIDs     []uuid.UUID `json:"role_ids"`
+}
+
+type RoleConstraintResponse struct {
+	ID          uuid.UUID    `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Roles       []RoleSimple `json:"roles"`
+	CreatedAt   string       `json:"created_at"`
+	UpdatedAt   string       `json:"updated_at"`
+}
+
+// RoleConstraintViolation is a user who already holds more than one role of a constraint
+type RoleConstraintViolation struct {
+	User  UserSimple   `json:"user"`
+	Roles []RoleSimple `json:"roles"`
+}
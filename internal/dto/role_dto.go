@@ -5,6 +5,9 @@ import "github.com/google/uuid"
 type CreateRoleRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	// OrganizationID creates a tenant role, only superusers may set it. Callers acting in an
+	// organization always create roles inside it.
+	OrganizationID *uuid.UUID `json:"organization_id"`
 }
 
 type UpdateRoleRequest struct {
@@ -18,8 +21,21 @@ type RoleResponse struct {
 	Description string             `json:"description"`
 	Users       []UserSimple       `json:"users,omitempty"`
 	Permissions []PermissionSimple `json:"permissions,omitempty"`
-	CreatedAt   string             `json:"created_at"`
-	UpdatedAt   string             `json:"updated_at"`
+	// DeniedPermissions are withheld from holders of the role even when granted elsewhere
+	DeniedPermissions []PermissionSimple `json:"denied_permissions,omitempty"`
+	Parents           []RoleSimple       `json:"parents,omitempty"`
+	OrganizationID    *uuid.UUID         `json:"organization_id"`
+	// InheritedPermissions is only filled when a single role is fetched
+	InheritedPermissions []InheritedPermission `json:"inherited_permissions,omitempty"`
+	CreatedAt            string                `json:"created_at"`
+	UpdatedAt            string                `json:"updated_at"`
+
+	// Approvers decide access requests for the role
+	Approvers []UserSimple `json:"approvers,omitempty"`
+
+	// ManageableUserScope and ManageableRoles limit holders of a delegated admin role
+	ManageableUserScope string       `json:"manageable_user_scope,omitempty"`
+	ManageableRoles     []RoleSimple `json:"manageable_roles,omitempty"`
 }
 
 type RoleSimple struct {
@@ -30,3 +46,29 @@ type RoleSimple struct {
 type AssignPermissionsRequest struct {
 	PermissionIDs []uuid.UUID `json:"permission_ids" binding:"required"`
 }
+
+type AssignDeniedPermissionsRequest struct {
+	PermissionIDs []uuid.UUID `json:"permission_ids" binding:"required"`
+}
+
+type AssignApproversRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" binding:"required"`
+}
+
+// AssignDelegationRequest makes a role a delegated admin grant, an empty user scope makes it an
+// ordinary role again
+type AssignDelegationRequest struct {
+	ManageableUserScope string      `json:"manageable_user_scope" binding:"omitempty,oneof=organization group"`
+	ManageableRoleIDs   []uuid.UUID `json:"manageable_role_ids"`
+}
+
+type AssignParentsRequest struct {
+	ParentIDs []uuid.UUID `json:"parent_ids" binding:"required"`
+}
+
+// InheritedPermission is a permission a role receives from one or more ancestor roles
+type InheritedPermission struct {
+	ID            uuid.UUID    `json:"id"`
+	Name          string       `json:"name"`
+	InheritedFrom []RoleSimple `json:"inherited_from"`
+}
@@ -1,6 +1,8 @@
 // internal/dto/setting_dto.go
 package dto
 
+import "github.com/google/uuid"
+
 type CreateSettingRequest struct {
 	Key   string `json:"key" binding:"required"`
 	Value string `json:"value" binding:"required"`
@@ -13,4 +15,6 @@ type UpdateSettingRequest struct {
 type SettingResponse struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	// OrganizationID is set when the value is an organization override
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
 }
@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/google/uuid"
+
+// SimulationRequest describes proposed changes to preview, nothing is saved
+type SimulationRequest struct {
+	Roles []RolePermissionChange `json:"roles" binding:"max=50,dive"`
+	Users []UserRoleChange       `json:"users" binding:"max=50,dive"`
+}
+
+// RolePermissionChange adds permissions to, or removes them from, a role
+type RolePermissionChange struct {
+	RoleID              uuid.UUID   `json:"role_id" binding:"required"`
+	AddPermissionIDs    []uuid.UUID `json:"add_permission_ids"`
+	RemovePermissionIDs []uuid.UUID `json:"remove_permission_ids"`
+}
+
+// UserRoleChange assigns roles to, or removes direct assignments from, a user
+type UserRoleChange struct {
+	UserID        uuid.UUID   `json:"user_id" binding:"required"`
+	AddRoleIDs    []uuid.UUID `json:"add_role_ids"`
+	RemoveRoleIDs []uuid.UUID `json:"remove_role_ids"`
+}
+
+type SimulationResponse struct {
+	// AffectedUsers lists the users whose permissions or menus would change
+	AffectedUsers []UserImpact `json:"affected_users"`
+	// EvaluatedUsers is how many users the changes could reach
+	EvaluatedUsers int `json:"evaluated_users"`
+}
+
+type UserImpact struct {
+	User              UserSimple   `json:"user"`
+	GainedPermissions []string     `json:"gained_permissions"`
+	LostPermissions   []string     `json:"lost_permissions"`
+	GainedMenus       []MenuSimple `json:"gained_menus"`
+	LostMenus         []MenuSimple `json:"lost_menus"`
+}
@@ -10,6 +10,9 @@ type CreateUserRequest struct {
 	LastName  string            `json:"last_name"`
 	RoleIDs   []uuid.UUID       `json:"role_ids"`
 	MetaData  map[string]string `json:"meta_data"`
+
+	// EmailVerified marks the email as verified, email_domain role assignment rules only match verified emails
+	EmailVerified bool `json:"email_verified"`
 }
 
 type UpdateUserRequest struct {
@@ -21,6 +24,9 @@ type UpdateUserRequest struct {
 	Active    *bool             `json:"active"`
 	RoleIDs   []uuid.UUID       `json:"role_ids"`
 	MetaData  map[string]string `json:"meta_data"`
+
+	// EmailVerified sets or clears the verification, changing the email clears it unless set
+	EmailVerified *bool `json:"email_verified"`
 }
 
 type UserResponse struct {
@@ -38,6 +44,12 @@ type UserResponse struct {
 	AvatarUrl   string            `json:"avatar_url"`
 	CreatedAt   string            `json:"created_at"`
 	UpdatedAt   string            `json:"updated_at"`
+
+	// DeniedPermissions are explicitly withheld from the user whatever its roles grant
+	DeniedPermissions []PermissionSimple `json:"denied_permissions,omitempty"`
+
+	// EmailVerified reports whether an administrator verified the email
+	EmailVerified bool `json:"email_verified"`
 }
 
 type UserSimple struct {
@@ -19,3 +19,15 @@ type UserMetaResponse struct {
 	Value  string    `json:"value"`
 	UserID uuid.UUID `json:"user_id"`
 }
+
+type SetUserMetaKeyPolicyRequest struct {
+	Visibility string `json:"visibility" binding:"required,oneof=default private admin_only"`
+}
+
+type UserMetaKeyPolicyResponse struct {
+	Key        string `json:"key"`
+	Visibility string `json:"visibility"`
+	// BuiltIn is true for visibilities that apply without a stored policy
+	BuiltIn   bool   `json:"built_in"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
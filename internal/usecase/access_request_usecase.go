@@ -0,0 +1,436 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// accessRequestLifetime is how long a request stays pending before it expires
+const accessRequestLifetime = 7 * 24 * time.Hour
+
+var (
+	// ErrAccessRequestNotPending is returned when a request has already been decided, cancelled or has expired
+	ErrAccessRequestNotPending = errors.New("access request is no longer pending")
+	// ErrAccessRequestExists is returned when the user already has a pending request for the role
+	ErrAccessRequestExists = errors.New("a pending access request for this role already exists")
+)
+
+// AccessRequestUseCase lets users ask for a role and the role's approvers decide. Approval grants
+// the role through RoleAssignmentUseCase, so separation-of-duties constraints still apply.
+type AccessRequestUseCase interface {
+	Create(ctx context.Context, req *dto.CreateAccessRequestRequest) (*dto.AccessRequestResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.AccessRequestResponse, error)
+	ListMine(ctx context.Context, status string, page, pageSize int) ([]*dto.AccessRequestResponse, int64, error)
+	ListForApproval(ctx context.Context, status string, page, pageSize int) ([]*dto.AccessRequestResponse, int64, error)
+	Approve(ctx context.Context, id uuid.UUID, req *dto.ApproveAccessRequestRequest) (*dto.AccessRequestResponse, error)
+	Reject(ctx context.Context, id uuid.UUID, req *dto.RejectAccessRequestRequest) (*dto.AccessRequestResponse, error)
+	Cancel(ctx context.Context, id uuid.UUID) (*dto.AccessRequestResponse, error)
+	ExpireRequests(ctx context.Context) (int, error)
+}
+
+type accessRequestUseCase struct {
+	requestRepo           repositories.AccessRequestRepository
+	userRepo              repositories.UserRepository
+	roleRepo              repositories.RoleRepository
+	roleAssignmentUseCase RoleAssignmentUseCase
+	notificationUseCase   NotificationUseCase
+	audit                 AuditUseCase
+}
+
+func NewAccessRequestUseCase(
+	requestRepo repositories.AccessRequestRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	roleAssignmentUseCase RoleAssignmentUseCase,
+	notificationUseCase NotificationUseCase,
+	audit AuditUseCase,
+) AccessRequestUseCase {
+	return &accessRequestUseCase{
+		requestRepo:           requestRepo,
+		userRepo:              userRepo,
+		roleRepo:              roleRepo,
+		roleAssignmentUseCase: roleAssignmentUseCase,
+		notificationUseCase:   notificationUseCase,
+		audit:                 audit,
+	}
+}
+
+// Create opens a request for the caller and notifies the role's approvers. Only roles with
+// approvers can be requested.
+func (uc *accessRequestUseCase) Create(ctx context.Context, req *dto.CreateAccessRequestRequest) (*dto.AccessRequestResponse, error) {
+	callerID := requestctx.FromContext(ctx).UserID
+	if callerID == nil {
+		return nil, ErrForbidden
+	}
+
+	now := time.Now()
+	if req.RoleExpiresAt != nil && !req.RoleExpiresAt.After(now) {
+		return nil, ErrInvalidAssignmentWindow
+	}
+
+	// Tenant scoping keeps roles of other organizations out of reach
+	role, err := uc.roleRepo.WithContext(ctx).FindByID(req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	approverIDs, err := uc.roleRepo.FindApproverIDs(role.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(approverIDs) == 0 {
+		return nil, errors.New("role does not accept access requests")
+	}
+
+	organizationID := assignmentOrganization(ctx)
+	if assignment, err := uc.userRepo.FindRoleAssignment(*callerID, role.ID, organizationID); err == nil {
+		if assignment.ExpiresAt == nil || assignment.ExpiresAt.After(now) {
+			return nil, errors.New("role already assigned")
+		}
+	}
+	if _, err := uc.requestRepo.FindPending(*callerID, role.ID); err == nil {
+		return nil, ErrAccessRequestExists
+	}
+
+	request := &entities.AccessRequest{
+		UserID:         *callerID,
+		RoleID:         role.ID,
+		OrganizationID: organizationID,
+		Justification:  req.Justification,
+		Status:         constants.AccessRequestPending,
+		RoleExpiresAt:  req.RoleExpiresAt,
+		ExpiresAt:      now.Add(accessRequestLifetime),
+	}
+	if err := uc.requestRepo.Create(request); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.requestRepo.FindByID(request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToAccessRequestResponse(created)
+	uc.audit.Record(ctx, constants.AuditActionAccessRequestCreate, constants.AuditTargetAccessRequest, request.ID.String(), nil, response)
+
+	// Approvers cannot decide their own requests, so there is no point telling them
+	var recipients []uuid.UUID
+	for _, approverID := range approverIDs {
+		if approverID != *callerID {
+			recipients = append(recipients, approverID)
+		}
+	}
+	if len(recipients) > 0 {
+		_, err := uc.notificationUseCase.SendToUsers(callerID, recipients, &dto.NotificationMessage{
+			Title:    "Access request",
+			Body:     fmt.Sprintf("%s requested the %s role.", response.User.Username, role.Name),
+			Category: constants.NotificationCategoryAccount,
+			Data: map[string]string{
+				"type":       "access_request_created",
+				"request_id": request.ID.String(),
+				"role_id":    role.ID.String(),
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to notify approvers about access request %s: %v", request.ID, err)
+		}
+	}
+
+	return response, nil
+}
+
+// GetByID returns a request to its requester, the role's approvers and superusers
+func (uc *accessRequestUseCase) GetByID(ctx context.Context, id uuid.UUID) (*dto.AccessRequestResponse, error) {
+	request, err := uc.requestRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	caller := requestctx.FromContext(ctx)
+	if !caller.IsUser(request.UserID) {
+		if err := uc.authorizeApprover(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+
+	return mapToAccessRequestResponse(request), nil
+}
+
+// ListMine lists the caller's own requests, an empty status lists all of them
+func (uc *accessRequestUseCase) ListMine(ctx context.Context, status string, page, pageSize int) ([]*dto.AccessRequestResponse, int64, error) {
+	callerID := requestctx.FromContext(ctx).UserID
+	if callerID == nil {
+		return nil, 0, ErrForbidden
+	}
+
+	requests, total, err := uc.requestRepo.FindByUserID(*callerID, status, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mapToAccessRequestResponses(requests), total, nil
+}
+
+// ListForApproval lists the requests for the roles the caller approves, an empty status lists all of them
+func (uc *accessRequestUseCase) ListForApproval(ctx context.Context, status string, page, pageSize int) ([]*dto.AccessRequestResponse, int64, error) {
+	callerID := requestctx.FromContext(ctx).UserID
+	if callerID == nil {
+		return nil, 0, ErrForbidden
+	}
+
+	roleIDs, err := uc.roleRepo.FindRoleIDsByApprover(*callerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	requests, total, err := uc.requestRepo.FindByRoleIDs(roleIDs, status, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mapToAccessRequestResponses(requests), total, nil
+}
+
+// Approve grants the requested role, for the requested period unless the approver sets another
+// expiry. If the grant fails, for example on a separation-of-duties conflict, the request stays pending.
+func (uc *accessRequestUseCase) Approve(ctx context.Context, id uuid.UUID, req *dto.ApproveAccessRequestRequest) (*dto.AccessRequestResponse, error) {
+	request, err := uc.findDecidable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.RoleExpiresAt != nil && !req.RoleExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAssignmentWindow
+	}
+	before := mapToAccessRequestResponse(request)
+	requestedExpiry := request.RoleExpiresAt
+
+	if req.RoleExpiresAt != nil {
+		request.RoleExpiresAt = req.RoleExpiresAt
+	}
+	if err := uc.decide(ctx, request, constants.AccessRequestApproved, req.Note); err != nil {
+		return nil, err
+	}
+
+	// The role is granted in the organization it was requested in
+	_, err = uc.roleAssignmentUseCase.Grant(actingIn(ctx, request.OrganizationID), request.UserID, &dto.GrantRoleRequest{
+		RoleID:    request.RoleID,
+		ExpiresAt: request.RoleExpiresAt,
+		Reason:    fmt.Sprintf("access request %s", request.ID),
+	})
+	if err != nil {
+		// Reopen the request so it can still be decided once the problem is fixed
+		reopened := *request
+		reopened.Status = constants.AccessRequestPending
+		reopened.RoleExpiresAt = requestedExpiry
+		reopened.DecidedBy, reopened.DecidedAt, reopened.DecisionNote = nil, nil, ""
+		if revertErr := uc.requestRepo.Transition(&reopened, constants.AccessRequestApproved); revertErr != nil {
+			log.Printf("Failed to reopen access request %s: %v", request.ID, revertErr)
+		}
+		return nil, err
+	}
+
+	return uc.finish(ctx, request, constants.AuditActionAccessRequestApprove, before, "Access request approved",
+		fmt.Sprintf("Your request for the %s role was approved.", request.Role.Name))
+}
+
+// Reject closes the request without granting the role
+func (uc *accessRequestUseCase) Reject(ctx context.Context, id uuid.UUID, req *dto.RejectAccessRequestRequest) (*dto.AccessRequestResponse, error) {
+	request, err := uc.findDecidable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := mapToAccessRequestResponse(request)
+
+	if err := uc.decide(ctx, request, constants.AccessRequestRejected, req.Note); err != nil {
+		return nil, err
+	}
+
+	return uc.finish(ctx, request, constants.AuditActionAccessRequestReject, before, "Access request rejected",
+		fmt.Sprintf("Your request for the %s role was rejected.", request.Role.Name))
+}
+
+// Cancel withdraws a pending request, only the requester may cancel it
+func (uc *accessRequestUseCase) Cancel(ctx context.Context, id uuid.UUID) (*dto.AccessRequestResponse, error) {
+	request, err := uc.requestRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !requestctx.FromContext(ctx).IsUser(request.UserID) {
+		return nil, ErrForbidden
+	}
+	if request.Status != constants.AccessRequestPending {
+		return nil, ErrAccessRequestNotPending
+	}
+	before := mapToAccessRequestResponse(request)
+
+	if err := uc.decide(ctx, request, constants.AccessRequestCancelled, ""); err != nil {
+		return nil, err
+	}
+
+	response := mapToAccessRequestResponse(request)
+	uc.audit.Record(ctx, constants.AuditActionAccessRequestCancel, constants.AuditTargetAccessRequest, id.String(), before, response)
+	return response, nil
+}
+
+// ExpireRequests closes requests left pending past their deadline and tells each requester
+func (uc *accessRequestUseCase) ExpireRequests(ctx context.Context) (int, error) {
+	requests, err := uc.requestRepo.FindExpiredPending(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, request := range requests {
+		before := mapToAccessRequestResponse(request)
+		request.Status = constants.AccessRequestExpired
+		if err := uc.requestRepo.Transition(request, constants.AccessRequestPending); err != nil {
+			// Decided in the meantime
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+
+		uc.audit.Record(ctx, constants.AuditActionAccessRequestExpire, constants.AuditTargetAccessRequest, request.ID.String(), before, mapToAccessRequestResponse(request))
+
+		// The role itself may have been deleted since the request was made
+		if request.Role == nil {
+			continue
+		}
+		uc.notifyRequester(request, "Access request expired",
+			fmt.Sprintf("Your request for the %s role expired before anyone decided it.", request.Role.Name))
+	}
+
+	return expired, nil
+}
+
+// findDecidable loads a pending request the caller may approve or reject
+func (uc *accessRequestUseCase) findDecidable(ctx context.Context, id uuid.UUID) (*entities.AccessRequest, error) {
+	request, err := uc.requestRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Role == nil {
+		return nil, errors.New("role not found")
+	}
+
+	// Nobody approves their own request, superusers included
+	if requestctx.FromContext(ctx).IsUser(request.UserID) {
+		return nil, ErrForbidden
+	}
+	if err := uc.authorizeApprover(ctx, request); err != nil {
+		return nil, err
+	}
+
+	if request.Status != constants.AccessRequestPending || !request.ExpiresAt.After(time.Now()) {
+		return nil, ErrAccessRequestNotPending
+	}
+	return request, nil
+}
+
+// authorizeApprover returns ErrForbidden unless the caller is a superuser or approves the request's role
+func (uc *accessRequestUseCase) authorizeApprover(ctx context.Context, request *entities.AccessRequest) error {
+	caller := requestctx.FromContext(ctx)
+	if caller.IsSuperuser {
+		return nil
+	}
+	if caller.UserID == nil {
+		return ErrForbidden
+	}
+
+	approverIDs, err := uc.roleRepo.FindApproverIDs(request.RoleID)
+	if err != nil {
+		return err
+	}
+	for _, approverID := range approverIDs {
+		if approverID == *caller.UserID {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// decide moves a pending request to status, recording the caller as the decider
+func (uc *accessRequestUseCase) decide(ctx context.Context, request *entities.AccessRequest, status, note string) error {
+	now := time.Now()
+	request.Status = status
+	request.DecidedBy = requestctx.FromContext(ctx).UserID
+	request.DecidedAt = &now
+	request.DecisionNote = note
+	request.UpdatedAt = now
+
+	if err := uc.requestRepo.Transition(request, constants.AccessRequestPending); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccessRequestNotPending
+		}
+		return err
+	}
+	return nil
+}
+
+// finish audits a decision and tells the requester about it
+func (uc *accessRequestUseCase) finish(ctx context.Context, request *entities.AccessRequest, action string, before *dto.AccessRequestResponse, title, body string) (*dto.AccessRequestResponse, error) {
+	response := mapToAccessRequestResponse(request)
+	uc.audit.Record(ctx, action, constants.AuditTargetAccessRequest, request.ID.String(), before, response)
+	uc.notifyRequester(request, title, body)
+	return response, nil
+}
+
+func (uc *accessRequestUseCase) notifyRequester(request *entities.AccessRequest, title, body string) {
+	_, err := uc.notificationUseCase.SendToUser(request.DecidedBy, request.UserID, &dto.NotificationMessage{
+		Title:    title,
+		Body:     body,
+		Category: constants.NotificationCategoryAccount,
+		Data: map[string]string{
+			"type":       "access_request_" + request.Status,
+			"request_id": request.ID.String(),
+			"role_id":    request.RoleID.String(),
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to notify user %s about access request %s: %v", request.UserID, request.ID, err)
+	}
+}
+
+func mapToAccessRequestResponses(requests []*entities.AccessRequest) []*dto.AccessRequestResponse {
+	response := []*dto.AccessRequestResponse{}
+	for _, request := range requests {
+		response = append(response, mapToAccessRequestResponse(request))
+	}
+	return response
+}
+
+func mapToAccessRequestResponse(request *entities.AccessRequest) *dto.AccessRequestResponse {
+	resp := &dto.AccessRequestResponse{
+		ID:            request.ID,
+		User:          dto.UserSimple{ID: request.UserID},
+		Role:          dto.RoleSimple{ID: request.RoleID},
+		Justification: request.Justification,
+		Status:        request.Status,
+		RoleExpiresAt: formatOptionalTime(request.RoleExpiresAt),
+		ExpiresAt:     request.ExpiresAt.Format(time.RFC3339),
+		DecidedBy:     request.DecidedBy,
+		DecidedAt:     formatOptionalTime(request.DecidedAt),
+		DecisionNote:  request.DecisionNote,
+		CreatedAt:     request.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     request.UpdatedAt.Format(time.RFC3339),
+	}
+	if request.User != nil {
+		resp.User.Username = request.User.Username
+		resp.User.Email = request.User.Email
+	}
+	if request.Role != nil {
+		resp.Role.Name = request.Role.Name
+	}
+	return resp
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+type accessRequestFixture struct {
+	uc       *accessRequestUseCase
+	requests *fakeAccessRequestRepository
+	roles    *fakeRoleRepository
+	grants   *fakeRoleGrants
+
+	request             *entities.AccessRequest
+	requester, approver uuid.UUID
+}
+
+// newAccessRequestFixture stores a pending request for a role with a single approver
+func newAccessRequestFixture() *accessRequestFixture {
+	f := &accessRequestFixture{
+		requests:  newFakeAccessRequestRepository(),
+		roles:     newFakeRoleRepository(),
+		grants:    &fakeRoleGrants{},
+		requester: uuid.New(),
+		approver:  uuid.New(),
+	}
+	f.uc = &accessRequestUseCase{
+		requestRepo:           f.requests,
+		roleRepo:              f.roles,
+		roleAssignmentUseCase: f.grants,
+		notificationUseCase:   newFakeNotifications(),
+		audit:                 &fakeAudit{},
+	}
+
+	role := f.roles.add("on-call admin")
+	f.roles.approvers[role.ID] = []uuid.UUID{f.approver}
+	roleExpiresAt := time.Now().Add(24 * time.Hour)
+	f.request = &entities.AccessRequest{
+		ID:            uuid.New(),
+		UserID:        f.requester,
+		RoleID:        role.ID,
+		Role:          role,
+		Status:        constants.AccessRequestPending,
+		RoleExpiresAt: &roleExpiresAt,
+		ExpiresAt:     time.Now().Add(accessRequestLifetime),
+	}
+	f.requests.requests[f.request.ID] = f.request
+	return f
+}
+
+// callerContext returns the context of the user, a superuser when superuser is set
+func callerContext(userID uuid.UUID, superuser bool) context.Context {
+	return requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &userID, IsSuperuser: superuser})
+}
+
+func TestApproveRequiresAnApproverOtherThanTheRequester(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     func(f *accessRequestFixture) context.Context
+		wantErr error
+	}{
+		{"approver", func(f *accessRequestFixture) context.Context { return callerContext(f.approver, false) }, nil},
+		{"superuser", func(f *accessRequestFixture) context.Context { return callerContext(uuid.New(), true) }, nil},
+		{"someone else", func(f *accessRequestFixture) context.Context { return callerContext(uuid.New(), false) }, ErrForbidden},
+		{"no caller", func(f *accessRequestFixture) context.Context { return context.Background() }, ErrForbidden},
+		{"requester", func(f *accessRequestFixture) context.Context { return callerContext(f.requester, false) }, ErrForbidden},
+		{"requester who is an approver", func(f *accessRequestFixture) context.Context {
+			f.roles.approvers[f.request.RoleID] = []uuid.UUID{f.approver, f.requester}
+			return callerContext(f.requester, false)
+		}, ErrForbidden},
+		{"requester who is a superuser", func(f *accessRequestFixture) context.Context { return callerContext(f.requester, true) }, ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccessRequestFixture()
+
+			_, err := f.uc.Approve(tt.ctx(f), f.request.ID, &dto.ApproveAccessRequestRequest{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Approve() = %v, want %v", err, tt.wantErr)
+			}
+
+			wantStatus, wantGrants := constants.AccessRequestApproved, 1
+			if tt.wantErr != nil {
+				wantStatus, wantGrants = constants.AccessRequestPending, 0
+			}
+			if f.request.Status != wantStatus {
+				t.Errorf("status = %q, want %q", f.request.Status, wantStatus)
+			}
+			if len(f.grants.granted) != wantGrants {
+				t.Errorf("granted %d roles, want %d", len(f.grants.granted), wantGrants)
+			}
+		})
+	}
+}
+
+func TestRejectRefusesTheRequester(t *testing.T) {
+	f := newAccessRequestFixture()
+	f.roles.approvers[f.request.RoleID] = []uuid.UUID{f.requester}
+
+	if _, err := f.uc.Reject(callerContext(f.requester, false), f.request.ID, &dto.RejectAccessRequestRequest{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Reject() by the requester = %v, want ErrForbidden", err)
+	}
+	if f.request.Status != constants.AccessRequestPending {
+		t.Errorf("status = %q, want pending", f.request.Status)
+	}
+}
+
+func TestApproveReopensTheRequestWhenTheGrantFails(t *testing.T) {
+	f := newAccessRequestFixture()
+	requestedExpiry := *f.request.RoleExpiresAt
+	conflict := errors.New("separation of duties conflict")
+	f.grants.err = conflict
+
+	override := time.Now().Add(time.Hour)
+	_, err := f.uc.Approve(callerContext(f.approver, false), f.request.ID, &dto.ApproveAccessRequestRequest{Note: "ok", RoleExpiresAt: &override})
+	if !errors.Is(err, conflict) {
+		t.Fatalf("Approve() = %v, want the grant error", err)
+	}
+	if f.request.Status != constants.AccessRequestPending {
+		t.Errorf("status = %q, want pending again", f.request.Status)
+	}
+	if f.request.DecidedBy != nil || f.request.DecidedAt != nil || f.request.DecisionNote != "" {
+		t.Errorf("the failed decision was kept: by %v at %v note %q", f.request.DecidedBy, f.request.DecidedAt, f.request.DecisionNote)
+	}
+	if f.request.RoleExpiresAt == nil || !f.request.RoleExpiresAt.Equal(requestedExpiry) {
+		t.Errorf("role expiry = %v, want the requested %v", f.request.RoleExpiresAt, requestedExpiry)
+	}
+
+	// Once the conflict is resolved the request can still be approved
+	f.grants.err = nil
+	resp, err := f.uc.Approve(callerContext(f.approver, false), f.request.ID, &dto.ApproveAccessRequestRequest{RoleExpiresAt: &override})
+	if err != nil {
+		t.Fatalf("second Approve(): %v", err)
+	}
+	if resp.Status != constants.AccessRequestApproved || f.request.Status != constants.AccessRequestApproved {
+		t.Errorf("status = %q, stored %q, want approved", resp.Status, f.request.Status)
+	}
+	if len(f.grants.granted) != 1 || !f.grants.granted[0].ExpiresAt.Equal(override) {
+		t.Errorf("granted %+v, want one grant expiring at the approver's override", f.grants.granted)
+	}
+}
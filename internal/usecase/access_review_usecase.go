@@ -0,0 +1,551 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrAccessReviewClosed is returned when deciding an item of, or closing, a campaign that is already closed
+var ErrAccessReviewClosed = errors.New("access review campaign is closed")
+
+// AccessReviewUseCase runs access review campaigns: every direct role assignment in scope becomes
+// an item its reviewer keeps or revokes. Undecided items escalate after the due date, and revokes
+// apply when the campaign is closed. Campaigns span every organization, so only callers outside
+// any organization may launch or close them.
+type AccessReviewUseCase interface {
+	Create(ctx context.Context, req *dto.CreateAccessReviewRequest) (*dto.AccessReviewResponse, error)
+	GetByID(id uuid.UUID) (*dto.AccessReviewResponse, error)
+	GetAll(page, pageSize int) ([]*dto.AccessReviewResponse, int64, error)
+	GetItems(id uuid.UUID, decision string, page, pageSize int) ([]*dto.AccessReviewItemResponse, int64, error)
+	GetMyItems(ctx context.Context, decision string, page, pageSize int) ([]*dto.AccessReviewItemResponse, int64, error)
+	Decide(ctx context.Context, itemID uuid.UUID, req *dto.DecideAccessReviewItemRequest) (*dto.AccessReviewItemResponse, error)
+	Close(ctx context.Context, id uuid.UUID) (*dto.AccessReviewResponse, error)
+	ExportCSV(id uuid.UUID) ([]byte, error)
+	EscalateOverdue(ctx context.Context) (int, error)
+}
+
+type accessReviewUseCase struct {
+	reviewRepo            repositories.AccessReviewRepository
+	userRepo              repositories.UserRepository
+	roleRepo              repositories.RoleRepository
+	roleAssignmentUseCase RoleAssignmentUseCase
+	notificationUseCase   NotificationUseCase
+	audit                 AuditUseCase
+}
+
+func NewAccessReviewUseCase(
+	reviewRepo repositories.AccessReviewRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	roleAssignmentUseCase RoleAssignmentUseCase,
+	notificationUseCase NotificationUseCase,
+	audit AuditUseCase,
+) AccessReviewUseCase {
+	return &accessReviewUseCase{
+		reviewRepo:            reviewRepo,
+		userRepo:              userRepo,
+		roleRepo:              roleRepo,
+		roleAssignmentUseCase: roleAssignmentUseCase,
+		notificationUseCase:   notificationUseCase,
+		audit:                 audit,
+	}
+}
+
+// Create snapshots the current assignments in scope into items. Each item goes to an approver of
+// its role, or to the campaign's reviewer when the role has none, never to the user under review.
+func (uc *accessReviewUseCase) Create(ctx context.Context, req *dto.CreateAccessReviewRequest) (*dto.AccessReviewResponse, error) {
+	if activeTenant(ctx) != nil {
+		return nil, ErrForbidden
+	}
+
+	if len(req.RoleIDs) == 0 && len(req.UserIDs) == 0 {
+		return nil, errors.New("role_ids or user_ids is required")
+	}
+	if !req.DueAt.After(time.Now()) {
+		return nil, errors.New("due_at must be in the future")
+	}
+	if _, err := uc.userRepo.FindByID(req.ReviewerID); err != nil {
+		return nil, errors.New("reviewer not found")
+	}
+	if _, err := uc.userRepo.FindByID(req.EscalationReviewerID); err != nil {
+		return nil, errors.New("escalation reviewer not found")
+	}
+	if len(req.RoleIDs) > 0 {
+		if err := uc.checkRoles(req.RoleIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	assignments, err := uc.userRepo.FindCurrentRoleAssignments(req.RoleIDs, req.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	approvers := make(map[uuid.UUID][]uuid.UUID)
+	var items []*entities.AccessReviewItem
+	for _, assignment := range assignments {
+		// Deleted roles are not preloaded
+		if assignment.Role == nil {
+			continue
+		}
+
+		roleApprovers, ok := approvers[assignment.RoleID]
+		if !ok {
+			if roleApprovers, err = uc.roleRepo.FindApproverIDs(assignment.RoleID); err != nil {
+				return nil, err
+			}
+			approvers[assignment.RoleID] = roleApprovers
+		}
+
+		candidates := append(append([]uuid.UUID{}, roleApprovers...), req.ReviewerID, req.EscalationReviewerID)
+		reviewerID, err := pickReviewer(assignment.UserID, candidates)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &entities.AccessReviewItem{
+			UserID:         assignment.UserID,
+			RoleID:         assignment.RoleID,
+			OrganizationID: assignment.OrganizationID,
+			ReviewerID:     reviewerID,
+			Decision:       constants.AccessReviewPending,
+		})
+	}
+	if len(items) == 0 {
+		return nil, errors.New("no role assignments to review")
+	}
+
+	campaign := &entities.AccessReviewCampaign{
+		Name:                 req.Name,
+		Description:          req.Description,
+		Status:               constants.AccessReviewOpen,
+		DueAt:                req.DueAt,
+		EscalationReviewerID: req.EscalationReviewerID,
+		RevokeUndecided:      req.RevokeUndecided,
+		CreatedBy:            requestctx.FromContext(ctx).UserID,
+	}
+	if err := uc.reviewRepo.CreateCampaign(campaign, items); err != nil {
+		return nil, err
+	}
+
+	response, err := uc.GetByID(campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, constants.AuditActionAccessReviewCreate, constants.AuditTargetAccessReview, campaign.ID.String(), nil, response)
+
+	uc.notifyReviewers(campaign, items, "Access review",
+		func(count int) string {
+			return fmt.Sprintf("You have %d role assignments to review in %s by %s.", count, campaign.Name, campaign.DueAt.Format(time.RFC1123))
+		})
+
+	return response, nil
+}
+
+func (uc *accessReviewUseCase) GetByID(id uuid.UUID) (*dto.AccessReviewResponse, error) {
+	campaign, err := uc.reviewRepo.FindCampaignByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return uc.mapToAccessReviewResponse(campaign)
+}
+
+func (uc *accessReviewUseCase) GetAll(page, pageSize int) ([]*dto.AccessReviewResponse, int64, error) {
+	campaigns, total, err := uc.reviewRepo.FindCampaigns(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	response := []*dto.AccessReviewResponse{}
+	for _, campaign := range campaigns {
+		item, err := uc.mapToAccessReviewResponse(campaign)
+		if err != nil {
+			return nil, 0, err
+		}
+		response = append(response, item)
+	}
+	return response, total, nil
+}
+
+// GetItems lists the campaign's items, an empty decision lists all of them
+func (uc *accessReviewUseCase) GetItems(id uuid.UUID, decision string, page, pageSize int) ([]*dto.AccessReviewItemResponse, int64, error) {
+	if _, err := uc.reviewRepo.FindCampaignByID(id); err != nil {
+		return nil, 0, err
+	}
+
+	items, total, err := uc.reviewRepo.FindItems(id, decision, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mapToAccessReviewItemResponses(items), total, nil
+}
+
+// GetMyItems lists the items of open campaigns assigned to the caller, an empty decision lists all of them
+func (uc *accessReviewUseCase) GetMyItems(ctx context.Context, decision string, page, pageSize int) ([]*dto.AccessReviewItemResponse, int64, error) {
+	callerID := requestctx.FromContext(ctx).UserID
+	if callerID == nil {
+		return nil, 0, ErrForbidden
+	}
+
+	items, total, err := uc.reviewRepo.FindItemsByReviewer(*callerID, decision, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mapToAccessReviewItemResponses(items), total, nil
+}
+
+// Decide records the reviewer's decision. It can be changed until the campaign is closed.
+func (uc *accessReviewUseCase) Decide(ctx context.Context, itemID uuid.UUID, req *dto.DecideAccessReviewItemRequest) (*dto.AccessReviewItemResponse, error) {
+	item, err := uc.reviewRepo.FindItemByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	caller := requestctx.FromContext(ctx)
+	if !caller.IsUser(item.ReviewerID) || caller.IsUser(item.UserID) {
+		return nil, ErrForbidden
+	}
+	if item.Campaign == nil || item.Campaign.Status != constants.AccessReviewOpen {
+		return nil, ErrAccessReviewClosed
+	}
+	before := mapToAccessReviewItemResponse(item)
+
+	now := time.Now()
+	item.Decision = req.Decision
+	item.Comment = req.Comment
+	item.DecidedAt = &now
+	if err := uc.reviewRepo.UpdateItem(item); err != nil {
+		return nil, err
+	}
+
+	response := mapToAccessReviewItemResponse(item)
+	uc.audit.Record(ctx, constants.AuditActionAccessReviewDecide, constants.AuditTargetAccessReview, item.CampaignID.String(), before, response)
+
+	return response, nil
+}
+
+// Close revokes every assignment the reviewers decided to revoke, and the undecided ones when the
+// campaign says so, then closes the campaign. Assignments removed in the meantime are skipped. If a
+// revoke fails the campaign stays open, so closing again picks up where it stopped.
+func (uc *accessReviewUseCase) Close(ctx context.Context, id uuid.UUID) (*dto.AccessReviewResponse, error) {
+	if activeTenant(ctx) != nil {
+		return nil, ErrForbidden
+	}
+
+	campaign, err := uc.reviewRepo.FindCampaignByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != constants.AccessReviewOpen {
+		return nil, ErrAccessReviewClosed
+	}
+	before, err := uc.mapToAccessReviewResponse(campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := uc.reviewRepo.FindAllItems(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		revoke := item.Decision == constants.AccessReviewRevoke ||
+			(item.Decision == constants.AccessReviewPending && campaign.RevokeUndecided)
+		if !revoke || item.RevokedAt != nil {
+			continue
+		}
+
+		if err := uc.roleAssignmentUseCase.Revoke(actingIn(ctx, item.OrganizationID), item.UserID, item.RoleID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("revoke role %s from user %s: %w", item.RoleID, item.UserID, err)
+		}
+
+		now := time.Now()
+		item.RevokedAt = &now
+		if err := uc.reviewRepo.UpdateItem(item); err != nil {
+			return nil, err
+		}
+		uc.notifyRevoked(campaign, item)
+	}
+
+	now := time.Now()
+	campaign.Status = constants.AccessReviewClosed
+	campaign.ClosedBy = requestctx.FromContext(ctx).UserID
+	campaign.ClosedAt = &now
+	if err := uc.reviewRepo.UpdateCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	response, err := uc.mapToAccessReviewResponse(campaign)
+	if err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, constants.AuditActionAccessReviewClose, constants.AuditTargetAccessReview, id.String(), before, response)
+
+	return response, nil
+}
+
+// ExportCSV renders every item of the campaign as CSV, one row per user and role
+func (uc *accessReviewUseCase) ExportCSV(id uuid.UUID) ([]byte, error) {
+	campaign, err := uc.reviewRepo.FindCampaignByID(id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := uc.reviewRepo.FindAllItems(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{
+		"campaign", "campaign_status", "user_id", "username", "email", "role_id", "role",
+		"reviewer", "decision", "comment", "decided_at", "escalated_at", "revoked_at",
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		resp := mapToAccessReviewItemResponse(item)
+		if err := w.Write(csvCells(
+			campaign.Name,
+			campaign.Status,
+			resp.User.ID.String(),
+			resp.User.Username,
+			resp.User.Email,
+			resp.Role.ID.String(),
+			resp.Role.Name,
+			resp.Reviewer.Username,
+			resp.Decision,
+			resp.Comment,
+			optionalString(resp.DecidedAt),
+			optionalString(resp.EscalatedAt),
+			optionalString(resp.RevokedAt),
+		)); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EscalateOverdue hands the undecided items of campaigns past their due date to the campaign's
+// escalation reviewer and notifies them. Items of the escalation reviewer's own roles keep their reviewer.
+func (uc *accessReviewUseCase) EscalateOverdue(ctx context.Context) (int, error) {
+	items, err := uc.reviewRepo.FindItemsToEscalate(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	escalated := 0
+	byCampaign := make(map[uuid.UUID][]*entities.AccessReviewItem)
+	campaigns := make(map[uuid.UUID]*entities.AccessReviewCampaign)
+	for _, item := range items {
+		if item.Campaign == nil {
+			continue
+		}
+		before := mapToAccessReviewItemResponse(item)
+
+		now := time.Now()
+		item.EscalatedAt = &now
+		if item.UserID != item.Campaign.EscalationReviewerID {
+			item.ReviewerID = item.Campaign.EscalationReviewerID
+			item.Reviewer = nil
+		}
+		if err := uc.reviewRepo.UpdateItem(item); err != nil {
+			return escalated, err
+		}
+		escalated++
+
+		uc.audit.Record(ctx, constants.AuditActionAccessReviewEscalate, constants.AuditTargetAccessReview, item.CampaignID.String(), before, mapToAccessReviewItemResponse(item))
+
+		byCampaign[item.CampaignID] = append(byCampaign[item.CampaignID], item)
+		campaigns[item.CampaignID] = item.Campaign
+	}
+
+	for campaignID, campaignItems := range byCampaign {
+		campaign := campaigns[campaignID]
+		uc.notifyReviewers(campaign, campaignItems, "Access review escalated",
+			func(count int) string {
+				return fmt.Sprintf("%d overdue role assignments in %s were escalated to you for review.", count, campaign.Name)
+			})
+	}
+
+	return escalated, nil
+}
+
+// checkRoles requires every role to exist
+func (uc *accessReviewUseCase) checkRoles(roleIDs []uuid.UUID) error {
+	unique := make(map[uuid.UUID]bool)
+	for _, roleID := range roleIDs {
+		unique[roleID] = true
+	}
+
+	roles, err := uc.roleRepo.FindByIDs(roleIDs)
+	if err != nil {
+		return err
+	}
+	if len(roles) != len(unique) {
+		return errors.New("role not found")
+	}
+	return nil
+}
+
+// notifyReviewers sends each reviewer of items one notification with their item count
+func (uc *accessReviewUseCase) notifyReviewers(campaign *entities.AccessReviewCampaign, items []*entities.AccessReviewItem, title string, body func(count int) string) {
+	counts := make(map[uuid.UUID]int)
+	var reviewerIDs []uuid.UUID
+	for _, item := range items {
+		if counts[item.ReviewerID] == 0 {
+			reviewerIDs = append(reviewerIDs, item.ReviewerID)
+		}
+		counts[item.ReviewerID]++
+	}
+
+	for _, reviewerID := range reviewerIDs {
+		_, err := uc.notificationUseCase.SendToUser(campaign.CreatedBy, reviewerID, &dto.NotificationMessage{
+			Title:    title,
+			Body:     body(counts[reviewerID]),
+			Category: constants.NotificationCategoryAccount,
+			Data: map[string]string{
+				"type":        "access_review",
+				"campaign_id": campaign.ID.String(),
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to notify reviewer %s about access review %s: %v", reviewerID, campaign.ID, err)
+		}
+	}
+}
+
+func (uc *accessReviewUseCase) notifyRevoked(campaign *entities.AccessReviewCampaign, item *entities.AccessReviewItem) {
+	if item.Role == nil {
+		return
+	}
+	_, err := uc.notificationUseCase.SendToUser(nil, item.UserID, &dto.NotificationMessage{
+		Title:    "Role revoked",
+		Body:     fmt.Sprintf("Your %s role was revoked by the %s access review.", item.Role.Name, campaign.Name),
+		Category: constants.NotificationCategoryAccount,
+		Data: map[string]string{
+			"type":        "role_revoked",
+			"campaign_id": campaign.ID.String(),
+			"role_id":     item.RoleID.String(),
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to notify user %s about revoked role %s: %v", item.UserID, item.RoleID, err)
+	}
+}
+
+func (uc *accessReviewUseCase) mapToAccessReviewResponse(campaign *entities.AccessReviewCampaign) (*dto.AccessReviewResponse, error) {
+	counts, err := uc.reviewRepo.CountItemsByDecision(campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.AccessReviewResponse{
+		ID:                 campaign.ID,
+		Name:               campaign.Name,
+		Description:        campaign.Description,
+		Status:             campaign.Status,
+		DueAt:              campaign.DueAt.Format(time.RFC3339),
+		EscalationReviewer: dto.UserSimple{ID: campaign.EscalationReviewerID},
+		RevokeUndecided:    campaign.RevokeUndecided,
+		Items:              counts,
+		CreatedBy:          campaign.CreatedBy,
+		ClosedBy:           campaign.ClosedBy,
+		ClosedAt:           formatOptionalTime(campaign.ClosedAt),
+		CreatedAt:          campaign.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          campaign.UpdatedAt.Format(time.RFC3339),
+	}
+	if campaign.EscalationReviewer != nil {
+		resp.EscalationReviewer.Username = campaign.EscalationReviewer.Username
+		resp.EscalationReviewer.Email = campaign.EscalationReviewer.Email
+	}
+	return resp, nil
+}
+
+// pickReviewer returns the first candidate who is not the user under review
+func pickReviewer(userID uuid.UUID, candidates []uuid.UUID) (uuid.UUID, error) {
+	for _, candidate := range candidates {
+		if candidate != userID {
+			return candidate, nil
+		}
+	}
+	return uuid.Nil, fmt.Errorf("no reviewer available for user %s other than themselves", userID)
+}
+
+// csvCells neutralizes cells a spreadsheet would evaluate as a formula. Usernames, role names and
+// comments are user supplied, so a leading =, +, -, @, tab or carriage return is escaped with a quote.
+func csvCells(cells ...string) []string {
+	for i, cell := range cells {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cells[i] = "'" + cell
+		}
+	}
+	return cells
+}
+
+func optionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func mapToAccessReviewItemResponses(items []*entities.AccessReviewItem) []*dto.AccessReviewItemResponse {
+	response := []*dto.AccessReviewItemResponse{}
+	for _, item := range items {
+		response = append(response, mapToAccessReviewItemResponse(item))
+	}
+	return response
+}
+
+func mapToAccessReviewItemResponse(item *entities.AccessReviewItem) *dto.AccessReviewItemResponse {
+	resp := &dto.AccessReviewItemResponse{
+		ID:          item.ID,
+		CampaignID:  item.CampaignID,
+		User:        dto.UserSimple{ID: item.UserID},
+		Role:        dto.RoleSimple{ID: item.RoleID},
+		Reviewer:    dto.UserSimple{ID: item.ReviewerID},
+		Decision:    item.Decision,
+		Comment:     item.Comment,
+		DecidedAt:   formatOptionalTime(item.DecidedAt),
+		EscalatedAt: formatOptionalTime(item.EscalatedAt),
+		RevokedAt:   formatOptionalTime(item.RevokedAt),
+	}
+	if item.Campaign != nil {
+		resp.Campaign = item.Campaign.Name
+	}
+	if item.User != nil {
+		resp.User.Username = item.User.Username
+		resp.User.Email = item.User.Email
+	}
+	if item.Role != nil {
+		resp.Role.Name = item.Role.Name
+	}
+	if item.Reviewer != nil {
+		resp.Reviewer.Username = item.Reviewer.Username
+		resp.Reviewer.Email = item.Reviewer.Email
+	}
+	return resp
+}
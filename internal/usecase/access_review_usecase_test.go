@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/internal/constants"
+
+	"github.com/google/uuid"
+)
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	reviews := newFakeAccessReviewRepository()
+	campaign := &entities.AccessReviewCampaign{ID: uuid.New(), Name: "=HYPERLINK(\"http://evil\")", Status: constants.AccessReviewOpen}
+	reviews.campaigns[campaign.ID] = campaign
+	reviews.items = append(reviews.items, &entities.AccessReviewItem{
+		ID:         uuid.New(),
+		CampaignID: campaign.ID,
+		UserID:     uuid.New(),
+		User:       &entities.User{Username: "@alice", Email: "alice@example.com"},
+		RoleID:     uuid.New(),
+		Role:       &entities.Role{Name: "+admin"},
+		ReviewerID: uuid.New(),
+		Reviewer:   &entities.User{Username: "bob"},
+		Decision:   constants.AccessReviewPending,
+		Comment:    "-1+1",
+	}, &entities.AccessReviewItem{
+		ID:         uuid.New(),
+		CampaignID: campaign.ID,
+		UserID:     uuid.New(),
+		User:       &entities.User{Username: "\tcarol"},
+		RoleID:     uuid.New(),
+		Role:       &entities.Role{Name: "viewer"},
+		ReviewerID: uuid.New(),
+		Reviewer:   &entities.User{Username: "\rdave"},
+		Decision:   constants.AccessReviewPending,
+	})
+	uc := NewAccessReviewUseCase(reviews, newFakeUserRepository(), newFakeRoleRepository(), nil, nil, &fakeAudit{})
+
+	out, err := uc.ExportCSV(campaign.ID)
+	if err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading the export: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want a header and 2 items", len(rows))
+	}
+
+	cases := []struct {
+		row, column int
+		want        string
+	}{
+		{1, 0, "'=HYPERLINK(\"http://evil\")"},
+		{1, 3, "'@alice"},
+		{1, 4, "alice@example.com"},
+		{1, 6, "'+admin"},
+		{1, 7, "bob"},
+		{1, 9, "'-1+1"},
+		{2, 3, "'\tcarol"},
+		{2, 6, "viewer"},
+		{2, 7, "'\rdave"},
+	}
+	for _, c := range cases {
+		if got := rows[c.row][c.column]; got != c.want {
+			t.Errorf("row %d column %s = %q, want %q", c.row, rows[0][c.column], got, c.want)
+		}
+	}
+}
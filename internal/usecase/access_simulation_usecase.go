@@ -0,0 +1,389 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/permission"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccessSimulationUseCase previews how proposed role and assignment changes would alter users'
+// permissions and menus. Nothing is saved.
+type AccessSimulationUseCase interface {
+	Simulate(ctx context.Context, req *dto.SimulationRequest) (*dto.SimulationResponse, error)
+}
+
+type accessSimulationUseCase struct {
+	userRepo       repositories.UserRepository
+	roleRepo       repositories.RoleRepository
+	permissionRepo repositories.PermissionRepository
+	menuRepo       repositories.MenuRepository
+}
+
+func NewAccessSimulationUseCase(
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	permissionRepo repositories.PermissionRepository,
+	menuRepo repositories.MenuRepository,
+) AccessSimulationUseCase {
+	return &accessSimulationUseCase{
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		menuRepo:       menuRepo,
+	}
+}
+
+// rolePermissionOverlay holds the permission names a simulation adds to and removes from a role
+type rolePermissionOverlay struct {
+	add    []string
+	remove map[string]bool
+}
+
+// simulation evaluates permissions under the current state and under the proposed changes. Roles
+// are loaded once and shared by both.
+type simulation struct {
+	roleRepo repositories.RoleRepository
+	roles    map[uuid.UUID]*entities.Role
+	overlays map[uuid.UUID]*rolePermissionOverlay
+	// menuPermissions maps each menu to the permissions that unlock it
+	menuPermissions map[uuid.UUID][]string
+}
+
+// Simulate compares, for every user the changes can reach, the permissions and menus they have
+// now with those they would have, in the caller's organization. Users reached are the holders of a
+// changed role or of a role inheriting from it, directly or through groups, and the users whose
+// assignments change. Superusers and inactive users are skipped since roles do not decide what
+// they can do.
+func (uc *accessSimulationUseCase) Simulate(ctx context.Context, req *dto.SimulationRequest) (*dto.SimulationResponse, error) {
+	if len(req.Roles) == 0 && len(req.Users) == 0 {
+		return nil, errors.New("roles or users is required")
+	}
+
+	sim := &simulation{
+		roleRepo: uc.roleRepo,
+		roles:    make(map[uuid.UUID]*entities.Role),
+		overlays: make(map[uuid.UUID]*rolePermissionOverlay),
+	}
+
+	var changedRoleIDs []uuid.UUID
+	for _, change := range req.Roles {
+		if _, err := uc.roleRepo.WithContext(ctx).FindByID(change.RoleID); err != nil {
+			return nil, fmt.Errorf("role %s not found", change.RoleID)
+		}
+		overlay, ok := sim.overlays[change.RoleID]
+		if !ok {
+			overlay = &rolePermissionOverlay{remove: make(map[string]bool)}
+			sim.overlays[change.RoleID] = overlay
+			changedRoleIDs = append(changedRoleIDs, change.RoleID)
+		}
+
+		for _, permissionID := range change.AddPermissionIDs {
+			p, err := uc.permissionRepo.FindByID(permissionID)
+			if err != nil {
+				return nil, fmt.Errorf("permission %s not found", permissionID)
+			}
+			overlay.add = append(overlay.add, p.Name)
+		}
+		for _, permissionID := range change.RemovePermissionIDs {
+			p, err := uc.permissionRepo.FindByID(permissionID)
+			if err != nil {
+				return nil, fmt.Errorf("permission %s not found", permissionID)
+			}
+			overlay.remove[p.Name] = true
+		}
+	}
+
+	userChanges := make(map[uuid.UUID][]dto.UserRoleChange)
+	var changedUserIDs []uuid.UUID
+	for _, change := range req.Users {
+		if _, err := uc.userRepo.WithContext(ctx).FindByID(change.UserID); err != nil {
+			return nil, fmt.Errorf("user %s not found", change.UserID)
+		}
+		for _, roleID := range change.AddRoleIDs {
+			if _, err := uc.roleRepo.WithContext(ctx).FindByID(roleID); err != nil {
+				return nil, fmt.Errorf("role %s not found", roleID)
+			}
+		}
+		if _, ok := userChanges[change.UserID]; !ok {
+			changedUserIDs = append(changedUserIDs, change.UserID)
+		}
+		userChanges[change.UserID] = append(userChanges[change.UserID], change)
+	}
+
+	descendantIDs, err := uc.roleRepo.FindDescendantIDs(changedRoleIDs)
+	if err != nil {
+		return nil, err
+	}
+	holderIDs, err := uc.roleRepo.FindUserIDsByRoleIDs(append(append([]uuid.UUID{}, changedRoleIDs...), descendantIDs...))
+	if err != nil {
+		return nil, err
+	}
+	userIDs := uniqueIDs(append(holderIDs, changedUserIDs...))
+
+	sim.menuPermissions, err = uc.menuRepo.FindMenuPermissionNames()
+	if err != nil {
+		return nil, err
+	}
+
+	organizationID := requestctx.FromContext(ctx).OrganizationID
+	menus := make(map[uuid.UUID]*entities.Menu)
+	response := &dto.SimulationResponse{AffectedUsers: []dto.UserImpact{}}
+	for _, userID := range userIDs {
+		// Users of other organizations are filtered out by tenant scoping
+		user, err := uc.userRepo.WithContext(ctx).FindByID(userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if user.IsSuperuser || !user.IsActive {
+			continue
+		}
+		response.EvaluatedUsers++
+
+		groupRoles, err := uc.roleRepo.FindGroupRolesByUserID(user.ID, organizationID)
+		if err != nil {
+			return nil, err
+		}
+
+		var directIDs, groupIDs []uuid.UUID
+		for _, role := range user.RolesIn(organizationID) {
+			directIDs = append(directIDs, role.ID)
+		}
+		for _, role := range groupRoles {
+			groupIDs = append(groupIDs, role.ID)
+		}
+		proposedIDs := applyRoleChanges(directIDs, userChanges[user.ID])
+
+		beforeRoleIDs, err := sim.applicableRoleIDs(append(directIDs, groupIDs...), organizationID)
+		if err != nil {
+			return nil, err
+		}
+		afterRoleIDs, err := sim.applicableRoleIDs(append(proposedIDs, groupIDs...), organizationID)
+		if err != nil {
+			return nil, err
+		}
+
+		before, beforeDenied, err := sim.permissions(user, beforeRoleIDs, false)
+		if err != nil {
+			return nil, err
+		}
+		after, afterDenied, err := sim.permissions(user, afterRoleIDs, true)
+		if err != nil {
+			return nil, err
+		}
+
+		impact := dto.UserImpact{
+			User:              dto.UserSimple{ID: user.ID, Username: user.Username, Email: user.Email},
+			GainedPermissions: difference(after, before),
+			LostPermissions:   difference(before, after),
+			GainedMenus:       []dto.MenuSimple{},
+			LostMenus:         []dto.MenuSimple{},
+		}
+
+		beforeMenus := sim.visibleMenus(before, beforeDenied)
+		afterMenus := sim.visibleMenus(after, afterDenied)
+		for _, menuID := range difference(afterMenus, beforeMenus) {
+			if menu := uc.findMenu(ctx, menus, menuID); menu != nil {
+				impact.GainedMenus = append(impact.GainedMenus, *menu)
+			}
+		}
+		for _, menuID := range difference(beforeMenus, afterMenus) {
+			if menu := uc.findMenu(ctx, menus, menuID); menu != nil {
+				impact.LostMenus = append(impact.LostMenus, *menu)
+			}
+		}
+
+		if len(impact.GainedPermissions)+len(impact.LostPermissions)+len(impact.GainedMenus)+len(impact.LostMenus) > 0 {
+			response.AffectedUsers = append(response.AffectedUsers, impact)
+		}
+	}
+
+	return response, nil
+}
+
+// findMenu returns the menu as the caller sees it, nil for menus of other organizations
+func (uc *accessSimulationUseCase) findMenu(ctx context.Context, menus map[uuid.UUID]*entities.Menu, id string) *dto.MenuSimple {
+	menuID, err := uuid.Parse(id)
+	if err != nil {
+		return nil
+	}
+	menu, ok := menus[menuID]
+	if !ok {
+		menu, _ = uc.menuRepo.WithContext(ctx).FindByID(menuID)
+		menus[menuID] = menu
+	}
+	if menu == nil {
+		return nil
+	}
+	return &dto.MenuSimple{ID: menu.ID, Name: menu.Name, Url: menu.Url}
+}
+
+// load caches the given roles with their permissions and denies, deleted roles are skipped
+func (s *simulation) load(roleIDs []uuid.UUID) ([]*entities.Role, error) {
+	var missing []uuid.UUID
+	for _, id := range roleIDs {
+		if _, ok := s.roles[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		found, err := s.roleRepo.FindByIDs(missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range missing {
+			s.roles[id] = nil
+		}
+		for _, role := range found {
+			s.roles[role.ID] = role
+		}
+	}
+
+	var roles []*entities.Role
+	for _, id := range uniqueIDs(roleIDs) {
+		if role := s.roles[id]; role != nil {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+// applicableRoleIDs keeps the roles that apply while acting in the organization
+func (s *simulation) applicableRoleIDs(roleIDs []uuid.UUID, organizationID *uuid.UUID) ([]uuid.UUID, error) {
+	roles, err := s.load(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for _, role := range rolesInOrganization(roles, organizationID) {
+		ids = append(ids, role.ID)
+	}
+	return ids, nil
+}
+
+// permissions returns the names granted through the roles and their ancestors minus the explicitly
+// denied ones, and the denies. With proposed set, the simulated role changes are applied.
+func (s *simulation) permissions(user *entities.User, roleIDs []uuid.UUID, proposed bool) ([]string, *permission.Set, error) {
+	ancestorIDs, err := s.roleRepo.FindAncestorIDs(roleIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles, err := s.load(append(append([]uuid.UUID{}, roleIDs...), ancestorIDs...))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	denied := permission.NewSet(nil)
+	for _, p := range user.DeniedPermissions {
+		denied.Add(p.Name)
+	}
+
+	granted := make(map[string]bool)
+	for _, role := range roles {
+		overlay := s.overlays[role.ID]
+		if !proposed {
+			overlay = nil
+		}
+
+		for _, p := range role.Permissions {
+			if overlay == nil || !overlay.remove[p.Name] {
+				granted[p.Name] = true
+			}
+		}
+		if overlay != nil {
+			for _, name := range overlay.add {
+				granted[name] = true
+			}
+		}
+		for _, p := range role.DeniedPermissions {
+			denied.Add(p.Name)
+		}
+	}
+
+	var names []string
+	for name := range granted {
+		if !denied.Has(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, denied, nil
+}
+
+// visibleMenus returns the menus unlocked by the permissions, with the rules of the menus endpoint
+func (s *simulation) visibleMenus(names []string, denied *permission.Set) []string {
+	granted := permission.NewSet(names)
+
+	var menuIDs []string
+	for menuID, required := range s.menuPermissions {
+		for _, name := range required {
+			if granted.Has(name) && !denied.Has(name) {
+				menuIDs = append(menuIDs, menuID.String())
+				break
+			}
+		}
+	}
+	sort.Strings(menuIDs)
+	return menuIDs
+}
+
+// applyRoleChanges returns the direct role IDs after the changes, removals applied before additions
+func applyRoleChanges(roleIDs []uuid.UUID, changes []dto.UserRoleChange) []uuid.UUID {
+	removed := make(map[uuid.UUID]bool)
+	var added []uuid.UUID
+	for _, change := range changes {
+		for _, id := range change.RemoveRoleIDs {
+			removed[id] = true
+		}
+		added = append(added, change.AddRoleIDs...)
+	}
+
+	var result []uuid.UUID
+	for _, id := range roleIDs {
+		if !removed[id] {
+			result = append(result, id)
+		}
+	}
+	return uniqueIDs(append(result, added...))
+}
+
+// difference returns the sorted values of a missing from b
+func difference(a, b []string) []string {
+	present := make(map[string]bool, len(b))
+	for _, value := range b {
+		present[value] = true
+	}
+
+	result := []string{}
+	for _, value := range a {
+		if !present[value] {
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	var unique []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
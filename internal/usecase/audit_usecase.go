@@ -0,0 +1,307 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/auditlog"
+	"usermanagement-api/pkg/logger"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// redactedAuditFields are never written to the audit log, even if a snapshot contains them
+var redactedAuditFields = map[string]bool{
+	"password": true,
+}
+
+type AuditUseCase interface {
+	// Record stores an audit event for the caller in ctx. before and after are
+	// snapshots of the target, either may be nil for creates and deletes.
+	Record(ctx context.Context, action, targetType, targetID string, before, after interface{})
+	// GetAll lists the events of the caller's organization, see activeTenant
+	GetAll(ctx context.Context, filter *dto.AuditFilterRequest, page, pageSize int) ([]*dto.AuditEventResponse, int64, error)
+	// WriteCheckpoint signs the current chain head, it reports false when nothing changed since the last one
+	WriteCheckpoint() (bool, error)
+	// Verify walks the whole chain and the checkpoints, stopping at the first broken link
+	Verify() (*dto.AuditVerifyResponse, error)
+}
+
+// auditVerifyBatchSize is how many events Verify loads at a time
+const auditVerifyBatchSize = 1000
+
+type auditUseCase struct {
+	auditRepo   repositories.AuditRepository
+	checkpoints *auditlog.CheckpointFile
+}
+
+// NewAuditUseCase creates the audit use case, checkpoints may be nil when no checkpoint secret is configured
+func NewAuditUseCase(auditRepo repositories.AuditRepository, checkpoints *auditlog.CheckpointFile) AuditUseCase {
+	return &auditUseCase{
+		auditRepo:   auditRepo,
+		checkpoints: checkpoints,
+	}
+}
+
+func (uc *auditUseCase) Record(ctx context.Context, action, targetType, targetID string, before, after interface{}) {
+	info := requestctx.FromContext(ctx)
+
+	event := &entities.AuditEvent{
+		ActorID:        info.UserID,
+		ImpersonatorID: info.ImpersonatorID,
+		Action:         action,
+		TargetType:     targetType,
+		TargetID:       targetID,
+		Before:         auditSnapshot(before),
+		After:          auditSnapshot(after),
+		IP:             info.IP,
+		UserAgent:      info.UserAgent,
+		RequestID:      info.RequestID,
+
+		OrganizationID: activeTenant(ctx),
+	}
+	event.Changes = auditDiff(event.Before, event.After)
+
+	// The change itself already succeeded, a failed audit write is logged rather than returned.
+	// The log entry carries who changed what, so the missing row can be reconstructed.
+	if err := uc.auditRepo.Create(event); err != nil {
+		logger.Error("Failed to record audit event",
+			zap.String("action", action),
+			zap.String("target_type", targetType),
+			zap.String("target_id", targetID),
+			zap.Any("actor_id", event.ActorID),
+			zap.Any("impersonator_id", event.ImpersonatorID),
+			zap.String("request_id", event.RequestID),
+			zap.String("ip", event.IP),
+			zap.Any("changes", event.Changes),
+			zap.Error(err),
+		)
+	}
+}
+
+func (uc *auditUseCase) GetAll(ctx context.Context, filter *dto.AuditFilterRequest, page, pageSize int) ([]*dto.AuditEventResponse, int64, error) {
+	var actorID *uuid.UUID
+	if filter.ActorID != "" {
+		parsed, err := uuid.Parse(filter.ActorID)
+		if err != nil {
+			return nil, 0, err
+		}
+		actorID = &parsed
+	}
+
+	events, total, err := uc.auditRepo.WithContext(ctx).FindAll(repositories.AuditFilter{
+		ActorID:    actorID,
+		Action:     filter.Action,
+		TargetType: filter.TargetType,
+		TargetID:   filter.TargetID,
+		RequestID:  filter.RequestID,
+		From:       filter.From,
+		To:         filter.To,
+	}, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response []*dto.AuditEventResponse
+	for _, event := range events {
+		response = append(response, uc.mapToAuditEventResponse(event))
+	}
+
+	return response, total, nil
+}
+
+func (uc *auditUseCase) WriteCheckpoint() (bool, error) {
+	if uc.checkpoints == nil {
+		return false, errors.New("audit checkpoints are disabled, set AUDIT_CHECKPOINT_SECRET")
+	}
+
+	head, err := uc.auditRepo.FindLast()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	last, err := uc.checkpoints.Last()
+	if err != nil {
+		return false, err
+	}
+	if last != nil && last.Sequence == head.Sequence {
+		return false, nil
+	}
+
+	if _, err := uc.checkpoints.Append(head.Sequence, head.Hash); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (uc *auditUseCase) Verify() (*dto.AuditVerifyResponse, error) {
+	result := &dto.AuditVerifyResponse{Valid: true}
+
+	// Checkpoints are matched against the chain while walking it
+	var checkpoints []*auditlog.Checkpoint
+	if uc.checkpoints != nil {
+		var err error
+		checkpoints, err = uc.checkpoints.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+	}
+	checkpointHashes := make(map[int64]string, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		if !uc.checkpoints.Valid(checkpoint) {
+			return uc.broken(result, checkpoint.Sequence, nil, "checkpoint signature is invalid"), nil
+		}
+		checkpointHashes[checkpoint.Sequence] = checkpoint.Hash
+	}
+
+	prevHash := ""
+	var sequence int64
+	for {
+		events, err := uc.auditRepo.FindAfterSequence(sequence, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			eventID := event.ID
+			switch {
+			case event.Sequence != sequence+1:
+				return uc.broken(result, sequence+1, nil, fmt.Sprintf("event %d is missing, next stored event is %d", sequence+1, event.Sequence)), nil
+			case event.PrevHash != prevHash:
+				return uc.broken(result, event.Sequence, &eventID, "previous hash does not match the preceding event"), nil
+			case event.ComputeHash() != event.Hash:
+				return uc.broken(result, event.Sequence, &eventID, "event content does not match its hash"), nil
+			}
+
+			if expected, ok := checkpointHashes[event.Sequence]; ok {
+				if expected != event.Hash {
+					return uc.broken(result, event.Sequence, &eventID, "event hash does not match the signed checkpoint"), nil
+				}
+				result.CheckpointsChecked++
+			}
+
+			sequence = event.Sequence
+			prevHash = event.Hash
+			result.EventsChecked++
+			result.LastSequence = event.Sequence
+			result.LastHash = event.Hash
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	// A checkpoint past the end means the newest events were removed
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Sequence > sequence {
+			return uc.broken(result, checkpoint.Sequence, nil, fmt.Sprintf("checkpoint covers event %d but the chain ends at %d", checkpoint.Sequence, sequence)), nil
+		}
+	}
+
+	return result, nil
+}
+
+func (uc *auditUseCase) broken(result *dto.AuditVerifyResponse, sequence int64, eventID *uuid.UUID, reason string) *dto.AuditVerifyResponse {
+	result.Valid = false
+	result.BrokenLink = &dto.AuditBrokenLink{
+		Sequence: sequence,
+		EventID:  eventID,
+		Reason:   reason,
+	}
+	return result
+}
+
+func (uc *auditUseCase) mapToAuditEventResponse(event *entities.AuditEvent) *dto.AuditEventResponse {
+	resp := &dto.AuditEventResponse{
+		ID:             event.ID,
+		Sequence:       event.Sequence,
+		ImpersonatorID: event.ImpersonatorID,
+		Action:         event.Action,
+		TargetType:     event.TargetType,
+		TargetID:       event.TargetID,
+		Before:         event.Before,
+		After:          event.After,
+		Changes:        make(map[string]dto.AuditChangeResponse, len(event.Changes)),
+		IP:             event.IP,
+		UserAgent:      event.UserAgent,
+		RequestID:      event.RequestID,
+		CreatedAt:      event.CreatedAt.Format(time.RFC3339),
+		PrevHash:       event.PrevHash,
+		Hash:           event.Hash,
+
+		OrganizationID: event.OrganizationID,
+	}
+
+	if event.Actor != nil {
+		resp.Actor = &dto.UserSimple{
+			ID:       event.Actor.ID,
+			Username: event.Actor.Username,
+			Email:    event.Actor.Email,
+		}
+	}
+
+	for field, change := range event.Changes {
+		resp.Changes[field] = dto.AuditChangeResponse{From: change.From, To: change.To}
+	}
+
+	return resp
+}
+
+// auditSnapshot turns a response or entity into a JSON object with sensitive fields removed
+func auditSnapshot(v interface{}) map[string]interface{} {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		// Not an object, e.g. a list of IDs
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil
+		}
+		return map[string]interface{}{"value": value}
+	}
+
+	for field := range snapshot {
+		if redactedAuditFields[field] {
+			delete(snapshot, field)
+		}
+	}
+	return snapshot
+}
+
+// auditDiff returns the top-level fields whose value differs between before and after
+func auditDiff(before, after map[string]interface{}) map[string]entities.AuditChange {
+	changes := make(map[string]entities.AuditChange)
+	for field, from := range before {
+		to, ok := after[field]
+		if !ok || !reflect.DeepEqual(from, to) {
+			changes[field] = entities.AuditChange{From: from, To: to}
+		}
+	}
+	for field, to := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = entities.AuditChange{From: nil, To: to}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+func TestAuditRecordStampsTheCallersOrganization(t *testing.T) {
+	organizationID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want *uuid.UUID
+	}{
+		{"tenant caller", tenantContext(&organizationID), &organizationID},
+		{"caller without an organization", tenantContext(nil), nil},
+		{"superuser", requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &userID, IsSuperuser: true, OrganizationID: &organizationID}), nil},
+		{"background work", context.Background(), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &fakeAuditRepository{}
+			uc := NewAuditUseCase(events, nil)
+
+			uc.Record(tt.ctx, "role.create", "role", uuid.NewString(), nil, map[string]string{"name": "support"})
+			if len(events.events) != 1 {
+				t.Fatalf("recorded %d events, want 1", len(events.events))
+			}
+			got := events.events[0].OrganizationID
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("OrganizationID = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditRecordKeepsTheImpersonator(t *testing.T) {
+	userID, impersonatorID := uuid.New(), uuid.New()
+	events := &fakeAuditRepository{}
+	uc := NewAuditUseCase(events, nil)
+
+	uc.Record(requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &userID, ImpersonatorID: &impersonatorID}), "user.update", "user", userID.String(), nil, nil)
+	uc.Record(requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &userID}), "user.update", "user", userID.String(), nil, nil)
+
+	if got := events.events[0].ImpersonatorID; got == nil || *got != impersonatorID {
+		t.Errorf("ImpersonatorID = %v, want %s", got, impersonatorID)
+	}
+	if got := events.events[1].ImpersonatorID; got != nil {
+		t.Errorf("ImpersonatorID = %v without impersonation, want nil", got)
+	}
+}
@@ -1,15 +1,20 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 	"usermanagement-api/domain/entities"
 	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
 	"usermanagement-api/pkg/auth"
-	"usermanagement-api/pkg/firebase"
 	"usermanagement-api/pkg/logger"
+	"usermanagement-api/pkg/permission"
+	"usermanagement-api/pkg/push"
+	"usermanagement-api/pkg/requestctx"
 	"usermanagement-api/pkg/utils"
 
 	"github.com/google/uuid"
@@ -20,41 +25,63 @@ import (
 type AuthUseCase interface {
 	Login(req *dto.LoginRequest) (*dto.AuthInfoResponse, error)
 	Register(req *dto.RegisterRequest) (*dto.UserResponse, error)
-	GetUserPermissions(userID uuid.UUID) ([]*entities.Permission, error)
-	CreateModelPermission(req *dto.ModelPermissionRequest) (*dto.ModelPermissionResponse, error)
-	GetModelPermissions(modelType string, modelID uuid.UUID) ([]*dto.ModelPermissionResponse, error)
+	SwitchOrganization(ctx context.Context, organizationID uuid.UUID) (*dto.AuthResponse, error)
+	GetOrganizations(ctx context.Context) ([]*dto.OrganizationResponse, error)
+	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]*entities.Permission, error)
+	GetUserMenus(ctx context.Context, userID uuid.UUID) ([]dto.MenuResponse, error)
+	CreateModelPermission(ctx context.Context, req *dto.ModelPermissionRequest) (*dto.ModelPermissionResponse, error)
+	GetModelPermissions(ctx context.Context, modelType string, modelID uuid.UUID) ([]*dto.ModelPermissionResponse, error)
+	ReplaceModelPermissions(ctx context.Context, req *dto.ReplaceModelPermissionsRequest) ([]*dto.ModelPermissionResponse, error)
+	DeleteModelPermission(ctx context.Context, id uuid.UUID) error
 	CheckPermission(modelType string, modelID uuid.UUID, permissionID uuid.UUID) (bool, error)
-	GetUser(userID uuid.UUID, token string) (*dto.AuthInfoResponse, error)
-	CreateMetaData(userID uuid.UUID, req *dto.CreateMetaDataRequest) (any, error)
-	GetMetaData(userID uuid.UUID) ([]*dto.UserMetaResponse, error)
+	GetUser(ctx context.Context, userID uuid.UUID, token string) (*dto.AuthInfoResponse, error)
+	CreateMetaData(ctx context.Context, userID uuid.UUID, req *dto.CreateMetaDataRequest) (any, error)
+	GetMetaData(ctx context.Context, userID uuid.UUID) ([]*dto.UserMetaResponse, error)
 	// SendToUser(userID uuid.UUID, title string, body string, data map[string]string) (any, error)
 }
 
 type authUseCase struct {
 	userRepo            repositories.UserRepository
 	roleRepo            repositories.RoleRepository
+	permissionRepo      repositories.PermissionRepository
 	menuRepo            repositories.MenuRepository
 	userMetaRepo        repositories.UserMetaRepository
 	modelPermissionRepo repositories.ModelPermissionRepository
-	fcmClient           firebase.FCMClient
+	organizationRepo    repositories.OrganizationRepository
+	modelTypes          ModelTypeRegistry
+	roleRules           RoleAssignmentRuleUseCase
+	metaAccess          *metaAccess
+	pushDriver          push.Driver
+	audit               AuditUseCase
 }
 
 func NewAuthUseCase(
 	userRepo repositories.UserRepository,
 	roleRepo repositories.RoleRepository,
+	permissionRepo repositories.PermissionRepository,
 	menuRepo repositories.MenuRepository,
 	modelPermissionRepo repositories.ModelPermissionRepository,
 	userMetaRepo repositories.UserMetaRepository,
-	fcmClient firebase.FCMClient,
-
+	metaKeyPolicyRepo repositories.UserMetaKeyPolicyRepository,
+	organizationRepo repositories.OrganizationRepository,
+	modelTypes ModelTypeRegistry,
+	roleRules RoleAssignmentRuleUseCase,
+	pushDriver push.Driver,
+	audit AuditUseCase,
 ) AuthUseCase {
 	return &authUseCase{
 		userRepo:            userRepo,
 		roleRepo:            roleRepo,
+		permissionRepo:      permissionRepo,
 		menuRepo:            menuRepo,
 		userMetaRepo:        userMetaRepo,
 		modelPermissionRepo: modelPermissionRepo,
-		fcmClient:           fcmClient,
+		organizationRepo:    organizationRepo,
+		modelTypes:          modelTypes,
+		roleRules:           roleRules,
+		metaAccess:          &metaAccess{policyRepo: metaKeyPolicyRepo},
+		pushDriver:          pushDriver,
+		audit:               audit,
 	}
 }
 
@@ -75,16 +102,28 @@ func (uc *authUseCase) Login(req *dto.LoginRequest) (*dto.AuthInfoResponse, erro
 		return nil, errors.New("invalid credentials")
 	}
 
+	// Users start in their oldest organization, users outside any organization get a
+	// token without one
+	organizations, err := uc.organizationRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var organizationID *uuid.UUID
+	if len(organizations) > 0 {
+		organizationID = &organizations[0].ID
+	}
+
 	// Generate JWT token
-	token, err := auth.GenerateTokenPair(user.ID, user.Email)
+	token, err := auth.GenerateTokenPairForOrganization(user.ID, user.Email, organizationID)
 	if err != nil {
 		return nil, err
 	}
 
 	authResp := &dto.AuthResponse{
-		AccessToken:  token.AccessToken,
-		RefreshToken: token.RefreshToken,
-		Type:         "Bearer",
+		AccessToken:    token.AccessToken,
+		RefreshToken:   token.RefreshToken,
+		Type:           "Bearer",
+		OrganizationID: organizationID,
 	}
 
 	// Map user to response
@@ -100,13 +139,11 @@ func (uc *authUseCase) Login(req *dto.LoginRequest) (*dto.AuthInfoResponse, erro
 	}
 
 	// Map roles
-	if len(user.Roles) > 0 {
-		for _, role := range user.Roles {
-			userResp.Roles = append(userResp.Roles, dto.RoleSimple{
-				ID:   role.ID,
-				Name: role.Name,
-			})
-		}
+	for _, role := range user.RolesIn(organizationID) {
+		userResp.Roles = append(userResp.Roles, dto.RoleSimple{
+			ID:   role.ID,
+			Name: role.Name,
+		})
 	}
 
 	return &dto.AuthInfoResponse{
@@ -147,6 +184,10 @@ func (uc *authUseCase) Register(req *dto.RegisterRequest) (*dto.UserResponse, er
 		return nil, err
 	}
 
+	if err := uc.roleRules.EvaluateUser(context.Background(), user.ID); err != nil {
+		logger.Warn("Failed to apply role assignment rules", zap.String("user_id", user.ID.String()), zap.Error(err))
+	}
+
 	return &dto.UserResponse{
 		ID:        user.ID,
 		Username:  user.Username,
@@ -159,12 +200,73 @@ func (uc *authUseCase) Register(req *dto.RegisterRequest) (*dto.UserResponse, er
 	}, nil
 }
 
-func (uc *authUseCase) GetUserPermissions(userID uuid.UUID) ([]*entities.Permission, error) {
+// SwitchOrganization issues a new token pair for acting in another organization the caller
+// belongs to. Superusers may switch into any existing organization.
+func (uc *authUseCase) SwitchOrganization(ctx context.Context, organizationID uuid.UUID) (*dto.AuthResponse, error) {
+	info := requestctx.FromContext(ctx)
+	if info.UserID == nil {
+		return nil, ErrForbidden
+	}
+	user, err := uc.userRepo.FindByID(*info.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.organizationRepo.FindByID(organizationID); err != nil {
+		return nil, err
+	}
+	if !user.IsSuperuser {
+		member, err := uc.organizationRepo.IsMember(organizationID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, ErrForbidden
+		}
+	}
+
+	token, err := auth.GenerateTokenPairForOrganization(user.ID, user.Email, &organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		AccessToken:    token.AccessToken,
+		RefreshToken:   token.RefreshToken,
+		Type:           "Bearer",
+		OrganizationID: &organizationID,
+	}, nil
+}
+
+// GetOrganizations lists the active organizations the caller can switch to
+func (uc *authUseCase) GetOrganizations(ctx context.Context) ([]*dto.OrganizationResponse, error) {
+	info := requestctx.FromContext(ctx)
+	if info.UserID == nil {
+		return nil, ErrForbidden
+	}
+
+	organizations, err := uc.organizationRepo.FindByUserID(*info.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []*dto.OrganizationResponse{}
+	for _, organization := range organizations {
+		response = append(response, mapToOrganizationResponse(organization))
+	}
+	return response, nil
+}
+
+// GetUserPermissions returns the user's effective permissions through the roles that apply
+// in the organization the request acts in
+func (uc *authUseCase) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]*entities.Permission, error) {
 	// Get user roles
-	roles, err := uc.roleRepo.FindRolesByUserID(userID)
+	organizationID := requestctx.FromContext(ctx).OrganizationID
+	roles, err := uc.roleRepo.FindRolesByUserID(userID, organizationID)
 	if err != nil {
 		return nil, err
 	}
+	roles = rolesInOrganization(roles, organizationID)
 	logger.GetLogger().Info("Roles found")
 
 	// Get role IDs
@@ -173,18 +275,56 @@ func (uc *authUseCase) GetUserPermissions(userID uuid.UUID) ([]*entities.Permiss
 		roleIDs = append(roleIDs, role.ID)
 	}
 
-	// Get permissions for roles
-	permissions, err := uc.roleRepo.FindPermissionsByRoleIDs(roleIDs)
+	// Get permissions for roles and the roles they inherit from
+	permissions, err := uc.roleRepo.FindEffectivePermissionsByRoleIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop what is explicitly denied to the user or through its roles
+	denied, err := uc.deniedPermissions(userID, roleIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.GetLogger().Info("Permissions found", zap.Any("permissions", permissions))
+	var allowed []*entities.Permission
+	for _, p := range permissions {
+		if !denied.Has(p.Name) {
+			allowed = append(allowed, p)
+		}
+	}
+
+	logger.GetLogger().Info("Permissions found", zap.Any("permissions", allowed))
+
+	return allowed, nil
+}
+
+// deniedPermissions returns the explicit denies of the user and of its roles and their ancestors
+func (uc *authUseCase) deniedPermissions(userID uuid.UUID, roleIDs []uuid.UUID) (*permission.Set, error) {
+	denied, err := uc.roleRepo.FindEffectiveDeniedPermissionsByRoleIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
 
-	return permissions, nil
+	set := permission.NewSet(nil)
+	for _, p := range append(denied, user.DeniedPermissions...) {
+		set.Add(p.Name)
+	}
+	return set, nil
 }
 
-func (uc *authUseCase) CreateModelPermission(req *dto.ModelPermissionRequest) (*dto.ModelPermissionResponse, error) {
+func (uc *authUseCase) CreateModelPermission(ctx context.Context, req *dto.ModelPermissionRequest) (*dto.ModelPermissionResponse, error) {
+	if err := uc.validateModel(ctx, req.ModelType, req.ModelID); err != nil {
+		return nil, err
+	}
+	if _, err := uc.permissionRepo.FindByID(req.PermissionID); err != nil {
+		return nil, fmt.Errorf("permission %s not found", req.PermissionID)
+	}
+
 	// Create model permission
 	modelPermission := &entities.ModelPermission{
 		ModelID:      req.ModelID,
@@ -203,33 +343,112 @@ func (uc *authUseCase) CreateModelPermission(req *dto.ModelPermissionRequest) (*
 		return nil, err
 	}
 
-	return &dto.ModelPermissionResponse{
-		ID:           modelPermissionWithPermission.ID,
-		ModelID:      modelPermissionWithPermission.ModelID,
-		ModelType:    modelPermissionWithPermission.ModelType,
-		PermissionID: modelPermissionWithPermission.PermissionID,
-		Permission: dto.PermissionSimple{
-			ID:   modelPermissionWithPermission.Permission.ID,
-			Name: modelPermissionWithPermission.Permission.Name,
-		},
-		CreatedAt: modelPermissionWithPermission.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: modelPermissionWithPermission.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	responses, err := uc.mapToModelPermissionResponses(ctx, req.ModelType, req.ModelID, []*entities.ModelPermission{modelPermissionWithPermission})
+	if err != nil {
+		return nil, err
+	}
+	response := responses[0]
+	uc.audit.Record(ctx, constants.AuditActionModelPermissionCreate, constants.AuditTargetModelPermission, response.ID.String(), nil, response)
+
+	return response, nil
 }
 
-func (uc *authUseCase) GetModelPermissions(modelType string, modelID uuid.UUID) ([]*dto.ModelPermissionResponse, error) {
+func (uc *authUseCase) GetModelPermissions(ctx context.Context, modelType string, modelID uuid.UUID) ([]*dto.ModelPermissionResponse, error) {
+	if _, err := uc.modelTypes.Lookup(modelType); err != nil {
+		return nil, err
+	}
+
 	// Get model permissions
 	modelPermissions, err := uc.modelPermissionRepo.FindByModelTypeAndModelID(modelType, modelID)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []*dto.ModelPermissionResponse
+	return uc.mapToModelPermissionResponses(ctx, modelType, modelID, modelPermissions)
+}
+
+// ReplaceModelPermissions makes the listed permissions the exact set attached to the model
+func (uc *authUseCase) ReplaceModelPermissions(ctx context.Context, req *dto.ReplaceModelPermissionsRequest) ([]*dto.ModelPermissionResponse, error) {
+	if err := uc.validateModel(ctx, req.ModelType, req.ModelID); err != nil {
+		return nil, err
+	}
+	for _, permissionID := range req.PermissionIDs {
+		if _, err := uc.permissionRepo.FindByID(permissionID); err != nil {
+			return nil, fmt.Errorf("permission %s not found", permissionID)
+		}
+	}
+
+	before, err := uc.GetModelPermissions(ctx, req.ModelType, req.ModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.modelPermissionRepo.ReplaceForModel(req.ModelType, req.ModelID, req.PermissionIDs); err != nil {
+		return nil, err
+	}
+
+	after, err := uc.GetModelPermissions(ctx, req.ModelType, req.ModelID)
+	if err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, constants.AuditActionModelPermissionSet, constants.AuditTargetModelPermission, req.ModelID.String(), before, after)
+
+	return after, nil
+}
+
+func (uc *authUseCase) DeleteModelPermission(ctx context.Context, id uuid.UUID) error {
+	modelPermission, err := uc.modelPermissionRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.modelPermissionRepo.Delete(id); err != nil {
+		return err
+	}
+	uc.audit.Record(ctx, constants.AuditActionModelPermissionDelete, constants.AuditTargetModelPermission, id.String(), modelPermission, nil)
+
+	return nil
+}
+
+// validateModel checks that the model type is registered and that the model exists
+func (uc *authUseCase) validateModel(ctx context.Context, modelType string, modelID uuid.UUID) error {
+	registered, err := uc.modelTypes.Lookup(modelType)
+	if err != nil {
+		return err
+	}
+
+	exists, err := registered.Exists(ctx, modelID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s %s not found", modelType, modelID)
+	}
+	return nil
+}
+
+// mapToModelPermissionResponses maps the permissions attached to one model, resolving its display name
+func (uc *authUseCase) mapToModelPermissionResponses(ctx context.Context, modelType string, modelID uuid.UUID, modelPermissions []*entities.ModelPermission) ([]*dto.ModelPermissionResponse, error) {
+	var modelName string
+	if len(modelPermissions) > 0 {
+		registered, err := uc.modelTypes.Lookup(modelType)
+		if err != nil {
+			return nil, err
+		}
+		names, err := registered.Names(ctx, []uuid.UUID{modelID})
+		if err != nil {
+			return nil, err
+		}
+		modelName = names[modelID]
+	}
+
+	response := []*dto.ModelPermissionResponse{}
 	for _, mp := range modelPermissions {
 		response = append(response, &dto.ModelPermissionResponse{
 			ID:           mp.ID,
 			ModelID:      mp.ModelID,
 			ModelType:    mp.ModelType,
+			ModelName:    modelName,
 			PermissionID: mp.PermissionID,
 			Permission: dto.PermissionSimple{
 				ID:   mp.Permission.ID,
@@ -243,11 +462,32 @@ func (uc *authUseCase) GetModelPermissions(modelType string, modelID uuid.UUID)
 	return response, nil
 }
 
+// CheckPermission reports whether the model holds the permission, either exactly or through a wildcard grant
 func (uc *authUseCase) CheckPermission(modelType string, modelID uuid.UUID, permissionID uuid.UUID) (bool, error) {
-	return uc.modelPermissionRepo.CheckPermission(modelType, modelID, permissionID)
+	hasPermission, err := uc.modelPermissionRepo.CheckPermission(modelType, modelID, permissionID)
+	if err != nil || hasPermission {
+		return hasPermission, err
+	}
+
+	required, err := uc.permissionRepo.FindByID(permissionID)
+	if err != nil {
+		return false, err
+	}
+
+	modelPermissions, err := uc.modelPermissionRepo.FindByModelTypeAndModelID(modelType, modelID)
+	if err != nil {
+		return false, err
+	}
+	for _, mp := range modelPermissions {
+		if permission.Match(mp.Permission.Name, required.Name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
-func (uc *authUseCase) GetUser(userID uuid.UUID, token string) (*dto.AuthInfoResponse, error) {
+func (uc *authUseCase) GetUser(ctx context.Context, userID uuid.UUID, token string) (*dto.AuthInfoResponse, error) {
 
 	user, err := uc.userRepo.FindByID(userID)
 	if err != nil {
@@ -268,17 +508,15 @@ func (uc *authUseCase) GetUser(userID uuid.UUID, token string) (*dto.AuthInfoRes
 	}
 
 	// Map roles
-	if len(user.Roles) > 0 {
-		for _, role := range user.Roles {
-			userResp.Roles = append(userResp.Roles, dto.RoleSimple{
-				ID:   role.ID,
-				Name: role.Name,
-			})
-
-		}
+	organizationID := requestctx.FromContext(ctx).OrganizationID
+	for _, role := range user.RolesIn(organizationID) {
+		userResp.Roles = append(userResp.Roles, dto.RoleSimple{
+			ID:   role.ID,
+			Name: role.Name,
+		})
 	}
 
-	privileges, err := uc.getPrivilegesForUser(user.ID)
+	privileges, err := uc.getPrivilegesForUser(ctx, user.ID)
 	fmt.Println("Privileges:", privileges)
 	if err == nil && len(privileges) > 0 {
 		userResp.Privileges = privileges
@@ -293,7 +531,12 @@ func (uc *authUseCase) GetUser(userID uuid.UUID, token string) (*dto.AuthInfoRes
 	}, nil
 }
 
-func (uc *authUseCase) getPrivilegesForUser(userID uuid.UUID) ([]dto.MenuResponse, error) {
+// GetUserMenus returns the menus the user's permissions unlock in the organization the request acts in
+func (uc *authUseCase) GetUserMenus(ctx context.Context, userID uuid.UUID) ([]dto.MenuResponse, error) {
+	return uc.getPrivilegesForUser(ctx, userID)
+}
+
+func (uc *authUseCase) getPrivilegesForUser(ctx context.Context, userID uuid.UUID) ([]dto.MenuResponse, error) {
 	// This would need to be implemented based on your menu repository and how
 	// privileges are associated with users (through roles, etc.)
 
@@ -324,38 +567,50 @@ func (uc *authUseCase) getPrivilegesForUser(userID uuid.UUID) ([]dto.MenuRespons
 		return privileges, nil
 
 	}
-	roles, err := uc.roleRepo.FindRolesByUserID(userID)
+	permissions, err := uc.GetUserPermissions(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("User Roles:", userID)
-
-	// Get all menu IDs accessible by these roles
-	var menuIDs []uuid.UUID
-	for _, role := range roles {
-		// This assumes you have a way to get menu IDs by role
-		// You might need to add this method to your repository
-
-		roleMenus, err := uc.menuRepo.FindMenusByRoleID(role.ID)
-		if err != nil {
-			continue
-		}
-
-		for _, menu := range roleMenus {
-			menuIDs = append(menuIDs, menu.ID)
-		}
+	granted := permission.NewSet(nil)
+	for _, p := range permissions {
+		granted.Add(p.Name)
+	}
+	organizationID := requestctx.FromContext(ctx).OrganizationID
+	roles, err := uc.roleRepo.FindRolesByUserID(userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	var roleIDs []uuid.UUID
+	for _, role := range rolesInOrganization(roles, organizationID) {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	denied, err := uc.deniedPermissions(userID, roleIDs)
+	if err != nil {
+		return nil, err
 	}
 
-	// Remove duplicates
-	uniqueMenuIDs := make(map[uuid.UUID]bool)
-	for _, id := range menuIDs {
-		uniqueMenuIDs[id] = true
+	// A menu is visible when any of its required permissions is granted, directly or by
+	// wildcard, and not explicitly denied
+	menuPermissions, err := uc.menuRepo.FindMenuPermissionNames()
+	if err != nil {
+		return nil, err
 	}
 
-	// Get menu details
 	var privileges []dto.MenuResponse
-	for id := range uniqueMenuIDs {
-		menu, err := uc.menuRepo.FindByID(id)
+	for menuID, names := range menuPermissions {
+		allowed := false
+		for _, name := range names {
+			if granted.Has(name) && !denied.Has(name) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			continue
+		}
+
+		// Menus of other organizations are filtered out by tenant scoping
+		menu, err := uc.menuRepo.WithContext(ctx).FindByID(menuID)
 		if err != nil {
 			continue
 		}
@@ -371,49 +626,75 @@ func (uc *authUseCase) getPrivilegesForUser(userID uuid.UUID) ([]dto.MenuRespons
 			IsVisible: menu.IsVisible, // You might want to add this field to your menu entity
 			Sequence:  menu.Sequence,  // Assuming Order corresponds to Sequence
 			CreatedAt: menu.CreatedAt.Format(time.RFC3339),
-			// UpdatedAt: formatTimePointer(menu.UpdatedAt),
-			// DeletedAt: formatTimePointer(menu.DeletedAt.Time),
 		})
 	}
 
+	sort.Slice(privileges, func(i, j int) bool { return privileges[i].Sequence < privileges[j].Sequence })
 	return privileges, nil
 }
 
-func (uc *authUseCase) CreateMetaData(userID uuid.UUID, req *dto.CreateMetaDataRequest) (any, error) {
+func (uc *authUseCase) CreateMetaData(ctx context.Context, userID uuid.UUID, req *dto.CreateMetaDataRequest) (any, error) {
+	if err := uc.metaAccess.authorize(ctx, userID, req.Key); err != nil {
+		return nil, err
+	}
 
 	existingMeta, err := uc.userMetaRepo.FindByUserIDAndKey(userID, req.Key)
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
 
+	updatedBy := requestctx.FromContext(ctx).UserID
 	if existingMeta != nil {
 		// Update existing
 		existingMeta.Value = req.Value
+		existingMeta.UpdatedBy = updatedBy
 		if err := uc.userMetaRepo.Update(existingMeta); err != nil {
 			return nil, err
 		}
 	} else {
 		// Create new
 		userMeta := &entities.UserMeta{
-			Key:    req.Key,
-			Value:  req.Value,
-			UserID: userID,
+			Key:       req.Key,
+			Value:     req.Value,
+			UserID:    userID,
+			UpdatedBy: updatedBy,
 		}
 		if err := uc.userMetaRepo.Create(userMeta); err != nil {
 			return nil, err
 		}
 	}
 
+	// Rules ignore values users write to their own profile
+	if requestctx.FromContext(ctx).IsUser(userID) {
+		return existingMeta, nil
+	}
+	if err := uc.roleRules.EvaluateUser(ctx, userID); err != nil {
+		logger.Warn("Failed to apply role assignment rules", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+
 	return existingMeta, nil
 }
 
-func (uc *authUseCase) GetMetaData(userID uuid.UUID) ([]*dto.UserMetaResponse, error) {
+func (uc *authUseCase) GetMetaData(ctx context.Context, userID uuid.UUID) ([]*dto.UserMetaResponse, error) {
 	metas, err := uc.userMetaRepo.FindByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
+
+	values := make(map[string]string, len(metas))
+	for _, meta := range metas {
+		values[meta.Key] = meta.Value
+	}
+	visible, err := uc.metaAccess.filter(ctx, userID, values)
+	if err != nil {
+		return nil, err
+	}
+
 	var response []*dto.UserMetaResponse
 	for _, meta := range metas {
+		if _, ok := visible[meta.Key]; !ok {
+			continue
+		}
 		response = append(response, &dto.UserMetaResponse{
 			ID:     meta.ID,
 			Key:    meta.Key,
@@ -0,0 +1,406 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/permission"
+	"usermanagement-api/pkg/policy"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuthorizationUseCase answers "may this user do X" with the same rules as the route guards
+type AuthorizationUseCase interface {
+	Check(ctx context.Context, req *dto.PermissionCheckRequest, explain bool) (*dto.PermissionCheckResponse, error)
+	ExplainRole(ctx context.Context, userID, roleID uuid.UUID) (*dto.RoleExplanation, error)
+	EffectivePermissions(ctx context.Context, userID uuid.UUID) (*dto.EffectivePermissions, error)
+}
+
+type authorizationUseCase struct {
+	userRepo            repositories.UserRepository
+	roleRepo            repositories.RoleRepository
+	permissionRepo      repositories.PermissionRepository
+	modelPermissionRepo repositories.ModelPermissionRepository
+	policyUseCase       PolicyUseCase
+	authUseCase         AuthUseCase
+	grants              *grantResolver
+}
+
+func NewAuthorizationUseCase(
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	groupRepo repositories.GroupRepository,
+	permissionRepo repositories.PermissionRepository,
+	modelPermissionRepo repositories.ModelPermissionRepository,
+	policyUseCase PolicyUseCase,
+	authUseCase AuthUseCase,
+) AuthorizationUseCase {
+	return &authorizationUseCase{
+		userRepo:            userRepo,
+		roleRepo:            roleRepo,
+		permissionRepo:      permissionRepo,
+		modelPermissionRepo: modelPermissionRepo,
+		policyUseCase:       policyUseCase,
+		authUseCase:         authUseCase,
+		grants:              &grantResolver{roleRepo: roleRepo, groupRepo: groupRepo},
+	}
+}
+
+// Check evaluates every item for the caller, or for req.UserID when the caller holds
+// permissions.check. Deny policies decide first, then explicit denies on the user and its
+// roles, allow policies, superuser status and role grants (including inherited, group and
+// wildcard ones), as the route guards do. Model permissions of the user and its roles are
+// reported separately for items that are not allowed.
+func (uc *authorizationUseCase) Check(ctx context.Context, req *dto.PermissionCheckRequest, explain bool) (*dto.PermissionCheckResponse, error) {
+	info := requestctx.FromContext(ctx)
+	if info.UserID == nil {
+		return nil, ErrForbidden
+	}
+	subjectID := *info.UserID
+	if req.UserID != nil && !info.IsUser(*req.UserID) {
+		if !info.Can(constants.PermissionPermissionsCheck) {
+			return nil, ErrForbidden
+		}
+		subjectID = *req.UserID
+	}
+
+	user, err := uc.userRepo.WithContext(ctx).FindByID(subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decisions are made for the organization the caller acts in
+	sources, err := uc.grants.roleSourcesForUser(user, info.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	sources = sourcesInOrganization(sources, info.OrganizationID)
+
+	grants, err := uc.grants.grantsForSources(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	denials, err := uc.grants.denialsForUser(user, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.PermissionCheckResponse{UserID: user.ID, Results: []dto.PermissionCheckResult{}}
+	for _, item := range req.Checks {
+		resourceType := item.ResourceType
+		if resourceType == "" {
+			resourceType, _, _ = strings.Cut(item.Permission, ".")
+		}
+
+		allowed, explanation, err := uc.decide(ctx, user, sources, grants, denials, item.Permission, dto.PolicyResource{Type: resourceType, ID: item.ResourceID})
+		if err != nil {
+			return nil, err
+		}
+
+		result := dto.PermissionCheckResult{
+			Permission:   item.Permission,
+			ResourceType: item.ResourceType,
+			ResourceID:   item.ResourceID,
+			Allowed:      allowed,
+		}
+		if explain {
+			result.Explanation = explanation
+		}
+		if !allowed && user.IsActive && explanation.Source == constants.DecisionSourceNone {
+			if result.ModelPermission, err = uc.checkModelPermissions(user, sources, item.Permission); err != nil {
+				return nil, err
+			}
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+func (uc *authorizationUseCase) decide(ctx context.Context, user *entities.User, sources []roleSource, grants []permissionGrant, denials []permissionDenial, required string, resource dto.PolicyResource) (bool, *dto.PermissionExplanation, error) {
+	if !user.IsActive {
+		return false, &dto.PermissionExplanation{Source: constants.DecisionSourceNone, Reason: "user is inactive"}, nil
+	}
+
+	result, err := uc.policyUseCase.DecideFor(ctx, user.ID, required, resource)
+	if err != nil {
+		return false, nil, err
+	}
+	deciding := result.DecidingPolicy()
+	if deciding != nil && result.Decision == policy.DecisionDeny {
+		return false, &dto.PermissionExplanation{
+			Source: constants.DecisionSourcePolicy,
+			Policy: deciding.Name,
+			Reason: fmt.Sprintf("denied by policy %q", deciding.Name),
+		}, nil
+	}
+
+	if denial := matchDenial(denials, required); denial != nil {
+		return false, denyExplanation(denial), nil
+	}
+
+	if deciding != nil {
+		return true, &dto.PermissionExplanation{Source: constants.DecisionSourcePolicy, Policy: deciding.Name}, nil
+	}
+
+	if user.IsSuperuser {
+		return true, &dto.PermissionExplanation{Source: constants.DecisionSourceSuperuser}, nil
+	}
+
+	if grant := matchGrant(grants, required); grant != nil {
+		explanation := &dto.PermissionExplanation{
+			Source:    constants.DecisionSourceRole,
+			Role:      &dto.RoleSimple{ID: grant.Role.ID, Name: grant.Role.Name},
+			GrantedBy: grant.Permission.Name,
+		}
+		if grant.Inherited() {
+			explanation.AssignedRole = &dto.RoleSimple{ID: grant.AssignedRole.ID, Name: grant.AssignedRole.Name}
+		}
+		explanation.Groups = mapToGroupPath(grant.Groups)
+		return true, explanation, nil
+	}
+
+	return false, &dto.PermissionExplanation{
+		Source: constants.DecisionSourceNone,
+		Reason: fmt.Sprintf("no role grants %s", required),
+	}, nil
+}
+
+// checkModelPermissions looks for a model permission granting required to the user or one of its
+// roles. They are reported next to the decision, which follows the route guards and ignores them.
+func (uc *authorizationUseCase) checkModelPermissions(user *entities.User, sources []roleSource, required string) (*dto.PermissionExplanation, error) {
+	p, err := uc.permissionRepo.FindByName(required)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Unknown permissions cannot have model grants
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hasPermission, err := uc.modelPermissionRepo.CheckPermission("user", user.ID, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hasPermission {
+		return &dto.PermissionExplanation{Source: constants.DecisionSourceModelPermission, GrantedBy: p.Name}, nil
+	}
+
+	for _, source := range firstSourcePerRole(sources) {
+		role := source.Role
+		hasPermission, err := uc.modelPermissionRepo.CheckPermission("role", role.ID, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hasPermission {
+			return &dto.PermissionExplanation{
+				Source:    constants.DecisionSourceModelPermission,
+				Role:      &dto.RoleSimple{ID: role.ID, Name: role.Name},
+				Groups:    mapToGroupPath(source.Groups),
+				GrantedBy: p.Name,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func denyExplanation(denial *permissionDenial) *dto.PermissionExplanation {
+	if denial.Role == nil {
+		return &dto.PermissionExplanation{
+			Source:   constants.DecisionSourceUserDeny,
+			DeniedBy: denial.Permission.Name,
+			Reason:   fmt.Sprintf("%s is explicitly denied to the user", denial.Permission.Name),
+		}
+	}
+
+	explanation := &dto.PermissionExplanation{
+		Source:   constants.DecisionSourceRoleDeny,
+		Role:     &dto.RoleSimple{ID: denial.Role.ID, Name: denial.Role.Name},
+		DeniedBy: denial.Permission.Name,
+		Reason:   fmt.Sprintf("%s is explicitly denied by role %q", denial.Permission.Name, denial.Role.Name),
+	}
+	if denial.Role.ID != denial.AssignedRole.ID {
+		explanation.AssignedRole = &dto.RoleSimple{ID: denial.AssignedRole.ID, Name: denial.AssignedRole.Name}
+	}
+	explanation.Groups = mapToGroupPath(denial.Groups)
+	return explanation
+}
+
+// ExplainRole lists every way the user holds the role in the caller's organization: direct
+// assignments, group memberships with the group path, and roles that inherit from it.
+// Callers other than the user need users.read, which the route guard checks.
+func (uc *authorizationUseCase) ExplainRole(ctx context.Context, userID, roleID uuid.UUID) (*dto.RoleExplanation, error) {
+	user, err := uc.userRepo.WithContext(ctx).FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := uc.roleRepo.WithContext(ctx).FindByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationID := requestctx.FromContext(ctx).OrganizationID
+	sources, err := uc.grants.roleSourcesForUser(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	sources = sourcesInOrganization(sources, organizationID)
+
+	response := &dto.RoleExplanation{
+		UserID:  user.ID,
+		Role:    dto.RoleSimple{ID: role.ID, Name: role.Name},
+		Sources: []dto.RoleSource{},
+	}
+	for _, source := range sources {
+		roleSource := dto.RoleSource{Source: constants.RoleSourceDirect, Groups: mapToGroupPath(source.Groups)}
+		if len(source.Groups) > 0 {
+			roleSource.Source = constants.RoleSourceGroup
+		}
+
+		if source.Role.ID != role.ID {
+			ancestorIDs, err := uc.roleRepo.FindAncestorIDs([]uuid.UUID{source.Role.ID})
+			if err != nil {
+				return nil, err
+			}
+			if !containsID(ancestorIDs, role.ID) {
+				continue
+			}
+			roleSource.AssignedRole = &dto.RoleSimple{ID: source.Role.ID, Name: source.Role.Name}
+		}
+		response.Sources = append(response.Sources, roleSource)
+	}
+	response.HasRole = len(response.Sources) > 0
+
+	return response, nil
+}
+
+// EffectivePermissions lists every permission the user holds in the caller's organization, with
+// each role, inherited role, group path or superuser status granting it. Wildcard grants are
+// expanded to the known permissions they cover, and permissions withheld by an explicit deny
+// are listed separately. Inactive users hold nothing. Policies are evaluated per request and
+// are not reflected here.
+func (uc *authorizationUseCase) EffectivePermissions(ctx context.Context, userID uuid.UUID) (*dto.EffectivePermissions, error) {
+	user, err := uc.userRepo.WithContext(ctx).FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.EffectivePermissions{
+		UserID:      user.ID,
+		IsSuperuser: user.IsSuperuser,
+		IsActive:    user.IsActive,
+		Permissions: []dto.EffectivePermission{},
+		Denied:      []dto.DeniedPermission{},
+		Menus:       []dto.MenuResponse{},
+	}
+	if !user.IsActive {
+		return response, nil
+	}
+
+	organizationID := requestctx.FromContext(ctx).OrganizationID
+	sources, err := uc.grants.roleSourcesForUser(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	sources = sourcesInOrganization(sources, organizationID)
+
+	grants, err := uc.grants.grantsForSources(sources)
+	if err != nil {
+		return nil, err
+	}
+	denials, err := uc.grants.denialsForUser(user, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := uc.permissionRepo.FindAllNames()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var permissionSources []dto.PermissionSource
+		if user.IsSuperuser {
+			permissionSources = append(permissionSources, dto.PermissionSource{Source: constants.PermissionSourceSuperuser})
+		}
+		for _, grant := range grants {
+			if permission.Match(grant.Permission.Name, name) {
+				permissionSources = append(permissionSources, mapToPermissionSource(grant, name))
+			}
+		}
+		if len(permissionSources) == 0 {
+			continue
+		}
+
+		if denial := matchDenial(denials, name); denial != nil {
+			response.Denied = append(response.Denied, dto.DeniedPermission{Name: name, Explanation: denyExplanation(denial)})
+			continue
+		}
+		response.Permissions = append(response.Permissions, dto.EffectivePermission{Name: name, Sources: permissionSources})
+	}
+
+	menus, err := uc.authUseCase.GetUserMenus(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if menus != nil {
+		response.Menus = menus
+	}
+
+	return response, nil
+}
+
+func mapToPermissionSource(grant permissionGrant, name string) dto.PermissionSource {
+	source := dto.PermissionSource{
+		Source: constants.PermissionSourceRole,
+		Role:   &dto.RoleSimple{ID: grant.Role.ID, Name: grant.Role.Name},
+		Groups: mapToGroupPath(grant.Groups),
+	}
+	if grant.Inherited() {
+		source.Source = constants.PermissionSourceInheritedRole
+		source.AssignedRole = &dto.RoleSimple{ID: grant.AssignedRole.ID, Name: grant.AssignedRole.Name}
+	}
+	if len(grant.Groups) > 0 {
+		source.Source = constants.PermissionSourceGroup
+	}
+	if grant.Permission.Name != name {
+		source.GrantedBy = grant.Permission.Name
+	}
+	return source
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGrant prefers an exact grant over a wildcard one, and direct grants over inherited ones
+func matchGrant(grants []permissionGrant, required string) *permissionGrant {
+	var wildcard *permissionGrant
+	for i := range grants {
+		name := grants[i].Permission.Name
+		if name == required {
+			return &grants[i]
+		}
+		if wildcard == nil && permission.IsWildcard(name) && permission.Match(name, required) {
+			wildcard = &grants[i]
+		}
+	}
+	return wildcard
+}
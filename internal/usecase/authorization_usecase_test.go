@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// explanationPaths lists the sources as "source: group > group (assigned role)", sorted
+func explanationPaths(sources []dto.RoleSource) []string {
+	var paths []string
+	for _, source := range sources {
+		var names []string
+		for _, group := range source.Groups {
+			names = append(names, group.Name)
+		}
+		path := source.Source + ": " + strings.Join(names, " > ")
+		if source.AssignedRole != nil {
+			path += " (" + source.AssignedRole.Name + ")"
+		}
+		paths = append(paths, strings.TrimSuffix(path, ": "))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestExplainRoleShowsGroupPaths(t *testing.T) {
+	users := newFakeUserRepository()
+	roles := newFakeRoleRepository()
+	groups := newFakeGroupRepository(roles)
+	uc := &authorizationUseCase{userRepo: users, roleRepo: roles, grants: &grantResolver{roleRepo: roles, groupRepo: groups}}
+	organizationID := uuid.New()
+
+	viewer := roles.add("viewer")
+	editor := roles.add("editor")
+	roles.parents[editor.ID] = []uuid.UUID{viewer.ID}
+	user := &entities.User{ID: uuid.New(), RoleAssignments: []*entities.UserRole{{RoleID: viewer.ID, Role: viewer}}}
+	users.users[user.ID] = user
+
+	// engineering > staff > everyone carries viewer, oncall reaches staff too
+	everyone := groups.add("everyone", nil, viewer)
+	staff := groups.add("staff", &organizationID)
+	engineering := groups.add("engineering", &organizationID, editor)
+	oncall := groups.add("oncall", &organizationID)
+	groups.nest(engineering, staff)
+	groups.nest(oncall, staff)
+	groups.nest(staff, everyone)
+	// A cycle back to engineering must not loop
+	groups.nest(everyone, engineering)
+	groups.AddMembers(engineering.ID, []uuid.UUID{user.ID})
+	groups.AddMembers(oncall.ID, []uuid.UUID{user.ID})
+
+	ctx := requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &user.ID, OrganizationID: &organizationID})
+	explanation, err := uc.ExplainRole(ctx, user.ID, viewer.ID)
+	if err != nil {
+		t.Fatalf("ExplainRole: %v", err)
+	}
+	if !explanation.HasRole {
+		t.Error("HasRole = false, want true")
+	}
+
+	// everyone is reached once, through staff from whichever membership is walked first
+	got := explanationPaths(explanation.Sources)
+	if len(got) != 3 || got[0] != constants.RoleSourceDirect || got[1] != constants.RoleSourceGroup+": engineering (editor)" ||
+		!strings.HasSuffix(got[2], " > staff > everyone") {
+		t.Errorf("ExplainRole() sources = %v, want direct, engineering (editor) and one path through staff to everyone", got)
+	}
+
+	// Outside the organization only the shared group and the direct assignment count, and the
+	// tenant groups leading to the shared one are no path
+	explanation, err = uc.ExplainRole(context.Background(), user.ID, viewer.ID)
+	if err != nil {
+		t.Fatalf("ExplainRole outside the organization: %v", err)
+	}
+	if got := explanationPaths(explanation.Sources); !reflect.DeepEqual(got, []string{constants.RoleSourceDirect}) {
+		t.Errorf("ExplainRole() outside the organization = %v, want the direct assignment only", got)
+	}
+}
+
+func TestRoleSourcesForUserFollowsTheShortestPath(t *testing.T) {
+	roles := newFakeRoleRepository()
+	groups := newFakeGroupRepository(roles)
+	resolver := &grantResolver{roleRepo: roles, groupRepo: groups}
+
+	admin := roles.add("admin")
+	user := &entities.User{ID: uuid.New()}
+
+	// team > department > division > company carries admin, and team is also nested in
+	// company directly
+	company := groups.add("company", nil, admin)
+	division := groups.add("division", nil)
+	department := groups.add("department", nil)
+	team := groups.add("team", nil)
+	groups.nest(team, department)
+	groups.nest(department, division)
+	groups.nest(division, company)
+	groups.nest(team, company)
+	groups.AddMembers(team.ID, []uuid.UUID{user.ID})
+
+	sources, err := resolver.roleSourcesForUser(user, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sourceNames(sources); !reflect.DeepEqual(got, []string{"admin via team > company"}) {
+		t.Errorf("roleSourcesForUser() = %v, want admin via team > company", got)
+	}
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// delegatedAdministration limits callers holding delegated admin roles, roles with a manageable
+// user scope. Such callers may only change users within the scope of one of those roles, and only
+// add or remove the roles it makes manageable. They are limited even when their other roles grant
+// more. Superusers and background work are never limited.
+type delegatedAdministration struct {
+	userRepo         repositories.UserRepository
+	roleRepo         repositories.RoleRepository
+	groupRepo        repositories.GroupRepository
+	organizationRepo repositories.OrganizationRepository
+}
+
+// delegatedGrant is a delegated admin role held by the caller
+type delegatedGrant struct {
+	userScope       string
+	manageableRoles map[uuid.UUID]bool
+}
+
+// authorize rejects changes by a delegated admin to a user they may not manage, or to roles they
+// may not assign. roleIDs are the roles being added to or removed from the user, userID is
+// uuid.Nil for a user being created. Superusers can never be managed by delegated admins.
+func (d *delegatedAdministration) authorize(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	grants, err := d.grants(ctx)
+	if err != nil || len(grants) == 0 {
+		return err
+	}
+
+	var held []uuid.UUID
+	if userID != uuid.Nil {
+		user, err := d.userRepo.FindByID(userID)
+		if err != nil {
+			return err
+		}
+		if user.IsSuperuser {
+			return ErrForbidden
+		}
+		if held, err = d.heldRoleIDs(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	for _, grant := range grants {
+		if !grant.covers(held) || !grant.covers(roleIDs) {
+			continue
+		}
+		// A new user has no organization or group yet, only the roles given to them matter
+		if userID == uuid.Nil {
+			return nil
+		}
+		inScope, err := d.inScope(ctx, grant, userID)
+		if err != nil {
+			return err
+		}
+		if inScope {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// authorizeReplace is authorize for a change that makes roleIDs the user's direct role set in
+// the organization the caller acts in
+func (d *delegatedAdministration) authorizeReplace(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	assignments, err := d.userRepo.FindRoleAssignments(userID)
+	if err != nil {
+		return err
+	}
+
+	organizationID := assignmentOrganization(ctx)
+	var current []uuid.UUID
+	for _, assignment := range assignments {
+		if assignment.OrganizationID == organizationID {
+			current = append(current, assignment.RoleID)
+		}
+	}
+	return d.authorize(ctx, userID, changedIDs(current, roleIDs))
+}
+
+// changedIDs returns the IDs in only one of before and after, each once
+func changedIDs(before, after []uuid.UUID) []uuid.UUID {
+	inBefore := make(map[uuid.UUID]bool)
+	for _, id := range before {
+		inBefore[id] = true
+	}
+	inAfter := make(map[uuid.UUID]bool)
+	for _, id := range after {
+		inAfter[id] = true
+	}
+
+	var changed []uuid.UUID
+	for id := range inBefore {
+		if !inAfter[id] {
+			changed = append(changed, id)
+		}
+	}
+	for id := range inAfter {
+		if !inBefore[id] {
+			changed = append(changed, id)
+		}
+	}
+	return changed
+}
+
+// grants returns the delegated admin roles the caller holds in the organization they act in,
+// directly, through groups or by inheritance. It is empty when the caller is not limited.
+func (d *delegatedAdministration) grants(ctx context.Context) ([]*delegatedGrant, error) {
+	info := requestctx.FromContext(ctx)
+	if info.UserID == nil || info.IsSuperuser {
+		return nil, nil
+	}
+
+	caller, err := d.userRepo.FindByID(*info.UserID)
+	if err != nil {
+		return nil, err
+	}
+	groupRoles, err := d.roleRepo.FindGroupRolesByUserID(caller.ID, info.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var roleIDs []uuid.UUID
+	for _, role := range append(caller.RolesIn(info.OrganizationID), rolesInOrganization(groupRoles, info.OrganizationID)...) {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	ancestorIDs, err := d.roleRepo.FindAncestorIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := d.roleRepo.FindByIDs(append(roleIDs, ancestorIDs...))
+	if err != nil {
+		return nil, err
+	}
+
+	var grants []*delegatedGrant
+	for _, role := range roles {
+		if role.ManageableUserScope == "" {
+			continue
+		}
+		manageableIDs, err := d.roleRepo.FindManageableRoleIDs(role.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		grant := &delegatedGrant{userScope: role.ManageableUserScope, manageableRoles: make(map[uuid.UUID]bool)}
+		for _, id := range manageableIDs {
+			grant.manageableRoles[id] = true
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// heldRoleIDs returns the roles the user holds in the organization the caller acts in, assigned
+// directly, scheduled ones included, or through groups
+func (d *delegatedAdministration) heldRoleIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	organizationID := requestctx.FromContext(ctx).OrganizationID
+	assignments, err := d.userRepo.FindRoleAssignments(userID)
+	if err != nil {
+		return nil, err
+	}
+	groupRoles, err := d.roleRepo.FindGroupRolesByUserID(userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var held []uuid.UUID
+	for _, assignment := range assignments {
+		if assignment.AppliesIn(organizationID) && (assignment.ExpiresAt == nil || assignment.ExpiresAt.After(now)) {
+			held = append(held, assignment.RoleID)
+		}
+	}
+	for _, role := range groupRoles {
+		held = append(held, role.ID)
+	}
+	return held, nil
+}
+
+// inScope reports whether the user is among the users the grant lets the caller manage
+func (d *delegatedAdministration) inScope(ctx context.Context, grant *delegatedGrant, userID uuid.UUID) (bool, error) {
+	info := requestctx.FromContext(ctx)
+
+	switch grant.userScope {
+	case constants.ManageableUsersOrganization:
+		if info.OrganizationID == nil {
+			return false, nil
+		}
+		return d.organizationRepo.IsMember(*info.OrganizationID, userID)
+
+	case constants.ManageableUsersGroup:
+		callerGroups, err := d.groupRepo.FindByUserID(*info.UserID)
+		if err != nil {
+			return false, err
+		}
+		userGroups, err := d.groupRepo.FindByUserID(userID)
+		if err != nil {
+			return false, err
+		}
+
+		// Members of groups nested in one of the caller's groups are covered too
+		groupIDs := groupIDsOf(userGroups)
+		ancestorIDs, err := d.groupRepo.FindAncestorIDs(groupIDs)
+		if err != nil {
+			return false, err
+		}
+		reachable := make(map[uuid.UUID]bool)
+		for _, id := range append(groupIDs, ancestorIDs...) {
+			reachable[id] = true
+		}
+		for _, group := range groupsInOrganization(callerGroups, info.OrganizationID) {
+			if reachable[group.ID] {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// covers reports whether every role is manageable through the grant
+func (g *delegatedGrant) covers(roleIDs []uuid.UUID) bool {
+	for _, roleID := range roleIDs {
+		if !g.manageableRoles[roleID] {
+			return false
+		}
+	}
+	return true
+}
+
+func groupIDsOf(groups []*entities.Group) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.ID)
+	}
+	return ids
+}
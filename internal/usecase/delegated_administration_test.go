@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+type delegationFixture struct {
+	delegation    *delegatedAdministration
+	users         *fakeUserRepository
+	roles         *fakeRoleRepository
+	groups        *fakeGroupRepository
+	organizations *fakeOrganizationRepository
+
+	organizationID        uuid.UUID
+	caller, target        *entities.User
+	admin, agent, billing *entities.Role
+}
+
+// newDelegationFixture gives the caller a delegated admin role that may assign agent, but not
+// billing, to members of the organization
+func newDelegationFixture() *delegationFixture {
+	f := &delegationFixture{
+		users:          newFakeUserRepository(),
+		roles:          newFakeRoleRepository(),
+		organizations:  newFakeOrganizationRepository(),
+		organizationID: uuid.New(),
+		caller:         &entities.User{ID: uuid.New(), Username: "caller"},
+		target:         &entities.User{ID: uuid.New(), Username: "target"},
+	}
+	f.groups = newFakeGroupRepository(f.roles)
+	f.delegation = &delegatedAdministration{
+		userRepo:         f.users,
+		roleRepo:         f.roles,
+		groupRepo:        f.groups,
+		organizationRepo: f.organizations,
+	}
+	f.users.users[f.caller.ID] = f.caller
+	f.users.users[f.target.ID] = f.target
+
+	f.admin = f.roles.add("support admin")
+	f.admin.ManageableUserScope = constants.ManageableUsersOrganization
+	f.agent = f.roles.add("agent")
+	f.billing = f.roles.add("billing")
+	f.roles.manageable[f.admin.ID] = []uuid.UUID{f.agent.ID}
+
+	f.giveCaller(f.admin, uuid.Nil)
+	f.organizations.addMember(f.organizationID, f.target.ID)
+	return f
+}
+
+// giveCaller assigns the role to the caller in the organization
+func (f *delegationFixture) giveCaller(role *entities.Role, organizationID uuid.UUID) {
+	f.caller.RoleAssignments = append(f.caller.RoleAssignments, &entities.UserRole{
+		UserID:         f.caller.ID,
+		RoleID:         role.ID,
+		Role:           role,
+		OrganizationID: organizationID,
+	})
+}
+
+func (f *delegationFixture) assignTarget(role *entities.Role, organizationID uuid.UUID) {
+	f.users.assignments[f.target.ID] = append(f.users.assignments[f.target.ID], &entities.UserRole{
+		UserID:         f.target.ID,
+		RoleID:         role.ID,
+		OrganizationID: organizationID,
+	})
+}
+
+// context returns a request context for the caller acting in the fixture's organization
+func (f *delegationFixture) context() context.Context {
+	return requestctx.WithInfo(context.Background(), &requestctx.Info{
+		UserID:         &f.caller.ID,
+		OrganizationID: &f.organizationID,
+	})
+}
+
+func TestDelegatedAdministrationAuthorize(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(f *delegationFixture)
+		userID  func(f *delegationFixture) uuid.UUID
+		roleIDs func(f *delegationFixture) []uuid.UUID
+		wantErr error
+	}{
+		{
+			name:    "manageable role for a member",
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.agent.ID} },
+		},
+		{
+			name:    "role that is not manageable",
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.billing.ID} },
+			wantErr: ErrForbidden,
+		},
+		{
+			name:    "user holding a role that is not manageable",
+			setup:   func(f *delegationFixture) { f.assignTarget(f.billing, uuid.Nil) },
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.agent.ID} },
+			wantErr: ErrForbidden,
+		},
+		{
+			name:    "user outside the organization",
+			setup:   func(f *delegationFixture) { delete(f.organizations.members, f.organizationID) },
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.agent.ID} },
+			wantErr: ErrForbidden,
+		},
+		{
+			name:    "superuser",
+			setup:   func(f *delegationFixture) { f.target.IsSuperuser = true },
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return nil },
+			wantErr: ErrForbidden,
+		},
+		{
+			name:    "new user with manageable roles",
+			userID:  func(f *delegationFixture) uuid.UUID { return uuid.Nil },
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.agent.ID} },
+		},
+		{
+			name:    "new user with a role that is not manageable",
+			userID:  func(f *delegationFixture) uuid.UUID { return uuid.Nil },
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.billing.ID} },
+			wantErr: ErrForbidden,
+		},
+		{
+			name: "grant inherited from a parent role",
+			setup: func(f *delegationFixture) {
+				f.caller.RoleAssignments = nil
+				lead := f.roles.add("team lead")
+				f.roles.parents[lead.ID] = []uuid.UUID{f.admin.ID}
+				f.giveCaller(lead, uuid.Nil)
+			},
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.billing.ID} },
+			wantErr: ErrForbidden,
+		},
+		{
+			name: "grant held through a group",
+			setup: func(f *delegationFixture) {
+				f.caller.RoleAssignments = nil
+				group := f.groups.add("admins", &f.organizationID, f.admin)
+				f.groups.AddMembers(group.ID, []uuid.UUID{f.caller.ID})
+			},
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.billing.ID} },
+			wantErr: ErrForbidden,
+		},
+		{
+			name: "grant held through a group of another organization",
+			setup: func(f *delegationFixture) {
+				f.caller.RoleAssignments = nil
+				otherOrganizationID := uuid.New()
+				group := f.groups.add("admins", &otherOrganizationID, f.admin)
+				f.groups.AddMembers(group.ID, []uuid.UUID{f.caller.ID})
+			},
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.billing.ID} },
+		},
+		{
+			name: "user holding a role through a group of another organization",
+			setup: func(f *delegationFixture) {
+				otherOrganizationID := uuid.New()
+				group := f.groups.add("billing", &otherOrganizationID, f.billing)
+				f.groups.AddMembers(group.ID, []uuid.UUID{f.target.ID})
+			},
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.agent.ID} },
+		},
+		{
+			name: "user holding a role that is not manageable through a shared group",
+			setup: func(f *delegationFixture) {
+				group := f.groups.add("billing", nil, f.billing)
+				f.groups.AddMembers(group.ID, []uuid.UUID{f.target.ID})
+			},
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.agent.ID} },
+			wantErr: ErrForbidden,
+		},
+		{
+			name: "grant assigned in another organization",
+			setup: func(f *delegationFixture) {
+				f.caller.RoleAssignments = nil
+				f.giveCaller(f.admin, uuid.New())
+			},
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.billing.ID} },
+		},
+		{
+			name: "any grant covering the change",
+			setup: func(f *delegationFixture) {
+				finance := f.roles.add("finance admin")
+				finance.ManageableUserScope = constants.ManageableUsersOrganization
+				f.roles.manageable[finance.ID] = []uuid.UUID{f.billing.ID}
+				f.giveCaller(finance, uuid.Nil)
+			},
+			roleIDs: func(f *delegationFixture) []uuid.UUID { return []uuid.UUID{f.billing.ID} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDelegationFixture()
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			userID := f.target.ID
+			if tt.userID != nil {
+				userID = tt.userID(f)
+			}
+
+			err := f.delegation.authorize(f.context(), userID, tt.roleIDs(f))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("authorize() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDelegatedAdministrationUnlimitedCallers(t *testing.T) {
+	f := newDelegationFixture()
+	roleIDs := []uuid.UUID{f.billing.ID}
+
+	superuser := requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &f.caller.ID, IsSuperuser: true})
+	if err := f.delegation.authorize(superuser, f.target.ID, roleIDs); err != nil {
+		t.Errorf("superuser caller: %v", err)
+	}
+	if err := f.delegation.authorize(context.Background(), f.target.ID, roleIDs); err != nil {
+		t.Errorf("background work: %v", err)
+	}
+
+	f.caller.RoleAssignments = nil
+	f.giveCaller(f.agent, uuid.Nil)
+	if err := f.delegation.authorize(f.context(), f.target.ID, roleIDs); err != nil {
+		t.Errorf("caller without delegated admin roles: %v", err)
+	}
+}
+
+func TestDelegatedAdministrationGroupScope(t *testing.T) {
+	f := newDelegationFixture()
+	f.admin.ManageableUserScope = constants.ManageableUsersGroup
+	support := f.groups.add("support", &f.organizationID)
+	tier1 := f.groups.add("tier 1", &f.organizationID)
+	f.groups.nest(tier1, support)
+	f.groups.AddMembers(support.ID, []uuid.UUID{f.caller.ID})
+	roleIDs := []uuid.UUID{f.agent.ID}
+
+	if err := f.delegation.authorize(f.context(), f.target.ID, roleIDs); !errors.Is(err, ErrForbidden) {
+		t.Errorf("user outside the caller's groups: %v, want ErrForbidden", err)
+	}
+
+	f.groups.AddMembers(tier1.ID, []uuid.UUID{f.target.ID})
+	if err := f.delegation.authorize(f.context(), f.target.ID, roleIDs); err != nil {
+		t.Errorf("user in a nested group: %v", err)
+	}
+}
+
+func TestDelegatedAdministrationAuthorizeReplace(t *testing.T) {
+	f := newDelegationFixture()
+	f.assignTarget(f.agent, f.organizationID)
+
+	if err := f.delegation.authorizeReplace(f.context(), f.target.ID, []uuid.UUID{f.agent.ID}); err != nil {
+		t.Errorf("keeping the current roles: %v", err)
+	}
+	if err := f.delegation.authorizeReplace(f.context(), f.target.ID, nil); err != nil {
+		t.Errorf("removing a manageable role: %v", err)
+	}
+	if err := f.delegation.authorizeReplace(f.context(), f.target.ID, []uuid.UUID{f.agent.ID, f.billing.ID}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("adding a role that is not manageable: %v, want ErrForbidden", err)
+	}
+
+}
@@ -0,0 +1,9 @@
+package usecase
+
+import (
+	"errors"
+	"usermanagement-api/internal/constants"
+)
+
+// ErrForbidden is returned when the caller is authenticated but may not act on the resource
+var ErrForbidden = errors.New(constants.ErrForbidden)
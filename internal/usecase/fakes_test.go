@@ -0,0 +1,731 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/pubsub"
+	"usermanagement-api/pkg/push"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// The fakes below keep their data in memory. They embed the repository interface, so calling
+// a method a fake does not implement panics and points at the missing fake.
+
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users       map[uuid.UUID]*entities.User
+	assignments map[uuid.UUID][]*entities.UserRole
+}
+
+func newFakeUserRepository() *fakeUserRepository {
+	return &fakeUserRepository{
+		users:       make(map[uuid.UUID]*entities.User),
+		assignments: make(map[uuid.UUID][]*entities.UserRole),
+	}
+}
+
+func (r *fakeUserRepository) WithContext(ctx context.Context) repositories.UserRepository {
+	return r
+}
+
+func (r *fakeUserRepository) FindByID(id uuid.UUID) (*entities.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepository) FindRoleAssignments(userID uuid.UUID) ([]*entities.UserRole, error) {
+	return r.assignments[userID], nil
+}
+
+func (r *fakeUserRepository) FindRoleAssignment(userID, roleID, organizationID uuid.UUID) (*entities.UserRole, error) {
+	for _, assignment := range r.assignments[userID] {
+		if assignment.RoleID == roleID && assignment.OrganizationID == organizationID {
+			return assignment, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepository) SaveRoleAssignment(assignment *entities.UserRole) error {
+	assignments := r.assignments[assignment.UserID]
+	for i, existing := range assignments {
+		if existing.RoleID == assignment.RoleID && existing.OrganizationID == assignment.OrganizationID {
+			assignments[i] = assignment
+			return nil
+		}
+	}
+	r.assignments[assignment.UserID] = append(assignments, assignment)
+	return nil
+}
+
+func (r *fakeUserRepository) FindExpiredRoleAssignments(at time.Time) ([]*entities.UserRole, error) {
+	var expired []*entities.UserRole
+	for _, assignments := range r.assignments {
+		for _, assignment := range assignments {
+			if assignment.ExpiresAt != nil && !assignment.ExpiresAt.After(at) {
+				expired = append(expired, assignment)
+			}
+		}
+	}
+	return expired, nil
+}
+
+func (r *fakeUserRepository) DeleteRoleAssignment(userID, roleID, organizationID uuid.UUID) error {
+	var kept []*entities.UserRole
+	for _, assignment := range r.assignments[userID] {
+		if assignment.RoleID != roleID || assignment.OrganizationID != organizationID {
+			kept = append(kept, assignment)
+		}
+	}
+	r.assignments[userID] = kept
+	return nil
+}
+
+type fakeRoleRepository struct {
+	repositories.RoleRepository
+	roles      map[uuid.UUID]*entities.Role
+	parents    map[uuid.UUID][]uuid.UUID
+	manageable map[uuid.UUID][]uuid.UUID
+	approvers  map[uuid.UUID][]uuid.UUID
+	// groups, when set, provides the roles users hold through groups
+	groups *fakeGroupRepository
+}
+
+func newFakeRoleRepository() *fakeRoleRepository {
+	return &fakeRoleRepository{
+		roles:      make(map[uuid.UUID]*entities.Role),
+		parents:    make(map[uuid.UUID][]uuid.UUID),
+		manageable: make(map[uuid.UUID][]uuid.UUID),
+		approvers:  make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+// add stores a new role with the given name and returns it
+func (r *fakeRoleRepository) add(name string) *entities.Role {
+	role := &entities.Role{ID: uuid.New(), Name: name}
+	r.roles[role.ID] = role
+	return role
+}
+
+func (r *fakeRoleRepository) WithContext(ctx context.Context) repositories.RoleRepository {
+	return r
+}
+
+func (r *fakeRoleRepository) FindByID(id uuid.UUID) (*entities.Role, error) {
+	role, ok := r.roles[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeRoleRepository) FindByName(name string, organizationID *uuid.UUID) (*entities.Role, error) {
+	for _, role := range r.roles {
+		if role.Name == name && (organizationID == nil || role.AppliesIn(organizationID)) {
+			return role, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRoleRepository) Create(role *entities.Role) error {
+	role.ID = uuid.New()
+	r.roles[role.ID] = role
+	return nil
+}
+
+func (r *fakeRoleRepository) Update(role *entities.Role) error {
+	r.roles[role.ID] = role
+	return nil
+}
+
+func (r *fakeRoleRepository) Delete(id uuid.UUID) error {
+	delete(r.roles, id)
+	return nil
+}
+
+func (r *fakeRoleRepository) AssignPermissions(roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+	role := r.roles[roleID]
+	role.Permissions = nil
+	for _, id := range permissionIDs {
+		role.Permissions = append(role.Permissions, &entities.Permission{ID: id})
+	}
+	return nil
+}
+
+func (r *fakeRoleRepository) FindGroupRolesByUserID(userID uuid.UUID, organizationID *uuid.UUID) ([]*entities.Role, error) {
+	return r.groups.rolesOf(userID, func(g *entities.Group) bool { return g.AppliesIn(organizationID) }), nil
+}
+
+func (r *fakeRoleRepository) FindAllGroupRolesByUserID(userID uuid.UUID) ([]*entities.Role, error) {
+	return r.groups.rolesOf(userID, func(*entities.Group) bool { return true }), nil
+}
+
+func (r *fakeRoleRepository) FindByIDs(ids []uuid.UUID) ([]*entities.Role, error) {
+	var roles []*entities.Role
+	for _, id := range ids {
+		if role, ok := r.roles[id]; ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+func (r *fakeRoleRepository) FindManageableRoleIDs(roleID uuid.UUID) ([]uuid.UUID, error) {
+	return r.manageable[roleID], nil
+}
+
+func (r *fakeRoleRepository) FindApproverIDs(roleID uuid.UUID) ([]uuid.UUID, error) {
+	return r.approvers[roleID], nil
+}
+
+func (r *fakeRoleRepository) FindAncestorIDs(roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	return ancestorIDs(r.parents, roleIDs), nil
+}
+
+type fakeRoleConstraintRepository struct {
+	repositories.RoleConstraintRepository
+	constraints []*entities.RoleConstraint
+}
+
+// add stores a constraint keeping the given roles apart
+func (r *fakeRoleConstraintRepository) add(name string, roles ...*entities.Role) {
+	r.constraints = append(r.constraints, &entities.RoleConstraint{ID: uuid.New(), Name: name, Roles: roles})
+}
+
+func (r *fakeRoleConstraintRepository) FindByRoleIDs(roleIDs []uuid.UUID) ([]*entities.RoleConstraint, error) {
+	wanted := make(map[uuid.UUID]bool)
+	for _, id := range roleIDs {
+		wanted[id] = true
+	}
+
+	var found []*entities.RoleConstraint
+	for _, constraint := range r.constraints {
+		for _, role := range constraint.Roles {
+			if wanted[role.ID] {
+				found = append(found, constraint)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+type fakeOrganizationRepository struct {
+	repositories.OrganizationRepository
+	members map[uuid.UUID]map[uuid.UUID]bool
+}
+
+func newFakeOrganizationRepository() *fakeOrganizationRepository {
+	return &fakeOrganizationRepository{members: make(map[uuid.UUID]map[uuid.UUID]bool)}
+}
+
+// addMember adds the user to the organization
+func (r *fakeOrganizationRepository) addMember(organizationID, userID uuid.UUID) {
+	if r.members[organizationID] == nil {
+		r.members[organizationID] = make(map[uuid.UUID]bool)
+	}
+	r.members[organizationID][userID] = true
+}
+
+func (r *fakeOrganizationRepository) IsMember(organizationID, userID uuid.UUID) (bool, error) {
+	return r.members[organizationID][userID], nil
+}
+
+type fakeGroupRepository struct {
+	repositories.GroupRepository
+	groups  map[uuid.UUID]*entities.Group
+	members map[uuid.UUID][]uuid.UUID
+	roles   *fakeRoleRepository
+}
+
+// newFakeGroupRepository returns a group repository whose group roles the role repository reports
+func newFakeGroupRepository(roles *fakeRoleRepository) *fakeGroupRepository {
+	r := &fakeGroupRepository{
+		groups:  make(map[uuid.UUID]*entities.Group),
+		members: make(map[uuid.UUID][]uuid.UUID),
+		roles:   roles,
+	}
+	roles.groups = r
+	return r
+}
+
+// add stores a new group of the organization, nil for a shared one, carrying roles
+func (r *fakeGroupRepository) add(name string, organizationID *uuid.UUID, roles ...*entities.Role) *entities.Group {
+	group := &entities.Group{ID: uuid.New(), Name: name, OrganizationID: organizationID, Roles: roles}
+	r.groups[group.ID] = group
+	return group
+}
+
+// nest makes parent a parent group of group
+func (r *fakeGroupRepository) nest(group, parent *entities.Group) {
+	group.Parents = append(group.Parents, parent)
+}
+
+// rolesOf returns the roles the user holds through groups, walking up through the groups keep accepts
+func (r *fakeGroupRepository) rolesOf(userID uuid.UUID, keep func(*entities.Group) bool) []*entities.Role {
+	if r == nil {
+		return nil
+	}
+	groups, _ := r.FindByUserID(userID)
+	seen := make(map[uuid.UUID]bool)
+	held := make(map[uuid.UUID]bool)
+	var roles []*entities.Role
+	for len(groups) > 0 {
+		var parents []*entities.Group
+		for _, group := range groups {
+			if seen[group.ID] || !keep(group) {
+				continue
+			}
+			seen[group.ID] = true
+			for _, role := range group.Roles {
+				if !held[role.ID] {
+					held[role.ID] = true
+					roles = append(roles, role)
+				}
+			}
+			parents = append(parents, group.Parents...)
+		}
+		groups = parents
+	}
+	return roles
+}
+
+func (r *fakeGroupRepository) WithContext(ctx context.Context) repositories.GroupRepository {
+	return r
+}
+
+func (r *fakeGroupRepository) FindByID(id uuid.UUID) (*entities.Group, error) {
+	group, ok := r.groups[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return group, nil
+}
+
+func (r *fakeGroupRepository) FindByIDs(ids []uuid.UUID) ([]*entities.Group, error) {
+	var groups []*entities.Group
+	for _, id := range ids {
+		if group, ok := r.groups[id]; ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+func (r *fakeGroupRepository) FindByUserID(userID uuid.UUID) ([]*entities.Group, error) {
+	var groups []*entities.Group
+	for groupID, memberIDs := range r.members {
+		for _, memberID := range memberIDs {
+			if memberID == userID {
+				groups = append(groups, r.groups[groupID])
+			}
+		}
+	}
+	return groups, nil
+}
+
+func (r *fakeGroupRepository) FindAncestorIDs(groupIDs []uuid.UUID) ([]uuid.UUID, error) {
+	parents := make(map[uuid.UUID][]uuid.UUID)
+	for _, group := range r.groups {
+		parents[group.ID] = groupIDsOf(group.Parents)
+	}
+	return ancestorIDs(parents, groupIDs), nil
+}
+
+func (r *fakeGroupRepository) FindAllMemberIDs(groupID uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool)
+	var memberIDs []uuid.UUID
+	for id := range r.groups {
+		ancestors, _ := r.FindAncestorIDs([]uuid.UUID{id})
+		if id != groupID && !containsID(ancestors, groupID) {
+			continue
+		}
+		for _, userID := range r.members[id] {
+			if !seen[userID] {
+				seen[userID] = true
+				memberIDs = append(memberIDs, userID)
+			}
+		}
+	}
+	return memberIDs, nil
+}
+
+func (r *fakeGroupRepository) AddMembers(groupID uuid.UUID, userIDs []uuid.UUID) error {
+	for _, userID := range userIDs {
+		if !containsID(r.members[groupID], userID) {
+			r.members[groupID] = append(r.members[groupID], userID)
+		}
+	}
+	return nil
+}
+
+func (r *fakeGroupRepository) RemoveMember(groupID, userID uuid.UUID) error {
+	var kept []uuid.UUID
+	for _, memberID := range r.members[groupID] {
+		if memberID != userID {
+			kept = append(kept, memberID)
+		}
+	}
+	r.members[groupID] = kept
+	return nil
+}
+
+func (r *fakeGroupRepository) AssignRoles(groupID uuid.UUID, roleIDs []uuid.UUID) error {
+	roles, _ := r.roles.FindByIDs(roleIDs)
+	r.groups[groupID].Roles = roles
+	return nil
+}
+
+func (r *fakeGroupRepository) AssignParents(groupID uuid.UUID, parentIDs []uuid.UUID) error {
+	parents, _ := r.FindByIDs(parentIDs)
+	r.groups[groupID].Parents = parents
+	return nil
+}
+
+type fakePermissionRepository struct {
+	repositories.PermissionRepository
+	permissions map[uuid.UUID]*entities.Permission
+}
+
+// add stores a new permission with the given name and returns it
+func (r *fakePermissionRepository) add(name string) *entities.Permission {
+	if r.permissions == nil {
+		r.permissions = make(map[uuid.UUID]*entities.Permission)
+	}
+	permission := &entities.Permission{ID: uuid.New(), Name: name}
+	r.permissions[permission.ID] = permission
+	return permission
+}
+
+func (r *fakePermissionRepository) FindByID(id uuid.UUID) (*entities.Permission, error) {
+	permission, ok := r.permissions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return permission, nil
+}
+
+type fakeAccessPolicyRepository struct {
+	repositories.AccessPolicyRepository
+	policies []*entities.AccessPolicy
+}
+
+func (r *fakeAccessPolicyRepository) WithContext(ctx context.Context) repositories.AccessPolicyRepository {
+	return r
+}
+
+func (r *fakeAccessPolicyRepository) Create(policy *entities.AccessPolicy) error {
+	policy.ID = uuid.New()
+	r.policies = append(r.policies, policy)
+	return nil
+}
+
+func (r *fakeAccessPolicyRepository) FindByID(id uuid.UUID) (*entities.AccessPolicy, error) {
+	for _, policy := range r.policies {
+		if policy.ID == id {
+			return policy, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAccessPolicyRepository) FindByName(name string, organizationID *uuid.UUID) (*entities.AccessPolicy, error) {
+	for _, policy := range r.policies {
+		if policy.Name == name && (organizationID == nil || policy.AppliesIn(organizationID)) {
+			return policy, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAccessPolicyRepository) FindEnabled() ([]*entities.AccessPolicy, error) {
+	var enabled []*entities.AccessPolicy
+	for _, policy := range r.policies {
+		if policy.Enabled {
+			enabled = append(enabled, policy)
+		}
+	}
+	return enabled, nil
+}
+
+func (r *fakeAccessPolicyRepository) Update(policy *entities.AccessPolicy) error {
+	return nil
+}
+
+func (r *fakeAccessPolicyRepository) Delete(id uuid.UUID) error {
+	for i, policy := range r.policies {
+		if policy.ID == id {
+			r.policies = append(r.policies[:i], r.policies[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// fakeAccessRequestRepository hands out copies, so a request changed by the use case is only
+// stored through Transition
+type fakeAccessRequestRepository struct {
+	repositories.AccessRequestRepository
+	requests map[uuid.UUID]*entities.AccessRequest
+}
+
+func newFakeAccessRequestRepository() *fakeAccessRequestRepository {
+	return &fakeAccessRequestRepository{requests: make(map[uuid.UUID]*entities.AccessRequest)}
+}
+
+func (r *fakeAccessRequestRepository) FindByID(id uuid.UUID) (*entities.AccessRequest, error) {
+	request, ok := r.requests[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *request
+	return &found, nil
+}
+
+func (r *fakeAccessRequestRepository) Transition(request *entities.AccessRequest, from string) error {
+	stored, ok := r.requests[request.ID]
+	if !ok || stored.Status != from {
+		return gorm.ErrRecordNotFound
+	}
+	stored.Status = request.Status
+	stored.RoleExpiresAt = request.RoleExpiresAt
+	stored.DecidedBy = request.DecidedBy
+	stored.DecidedAt = request.DecidedAt
+	stored.DecisionNote = request.DecisionNote
+	return nil
+}
+
+type fakeAccessReviewRepository struct {
+	repositories.AccessReviewRepository
+	campaigns map[uuid.UUID]*entities.AccessReviewCampaign
+	items     []*entities.AccessReviewItem
+}
+
+func newFakeAccessReviewRepository() *fakeAccessReviewRepository {
+	return &fakeAccessReviewRepository{campaigns: make(map[uuid.UUID]*entities.AccessReviewCampaign)}
+}
+
+func (r *fakeAccessReviewRepository) FindCampaignByID(id uuid.UUID) (*entities.AccessReviewCampaign, error) {
+	campaign, ok := r.campaigns[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return campaign, nil
+}
+
+func (r *fakeAccessReviewRepository) FindAllItems(campaignID uuid.UUID) ([]*entities.AccessReviewItem, error) {
+	var items []*entities.AccessReviewItem
+	for _, item := range r.items {
+		if item.CampaignID == campaignID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+type fakeAuditRepository struct {
+	repositories.AuditRepository
+	events []*entities.AuditEvent
+}
+
+func (r *fakeAuditRepository) Create(event *entities.AuditEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+type fakeUserMetaRepository struct {
+	repositories.UserMetaRepository
+	metas map[uuid.UUID][]*entities.UserMeta
+}
+
+func (r *fakeUserMetaRepository) FindByUserID(userID uuid.UUID) ([]*entities.UserMeta, error) {
+	return r.metas[userID], nil
+}
+
+func (r *fakeUserMetaRepository) FindByUserIDAndKey(userID uuid.UUID, key string) (*entities.UserMeta, error) {
+	for _, meta := range r.metas[userID] {
+		if meta.Key == key {
+			return meta, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeUserMetaKeyPolicyRepository struct {
+	repositories.UserMetaKeyPolicyRepository
+	policies []*entities.UserMetaKeyPolicy
+}
+
+func (r *fakeUserMetaKeyPolicyRepository) FindAll() ([]*entities.UserMetaKeyPolicy, error) {
+	return r.policies, nil
+}
+
+type fakeNotificationRepository struct {
+	repositories.NotificationRepository
+	notifications map[uuid.UUID]*entities.Notification
+	deliveries    []*entities.NotificationDelivery
+	// due is handed out by ClaimDueDeferred, once
+	due []*entities.NotificationDelivery
+	// claimedBefore and staleClaimsBefore are the arguments of the last ClaimDueDeferred call
+	claimedBefore, staleClaimsBefore time.Time
+}
+
+func newFakeNotificationRepository() *fakeNotificationRepository {
+	return &fakeNotificationRepository{notifications: make(map[uuid.UUID]*entities.Notification)}
+}
+
+func (r *fakeNotificationRepository) Create(notification *entities.Notification) error {
+	notification.ID = uuid.New()
+	r.notifications[notification.ID] = notification
+	return nil
+}
+
+func (r *fakeNotificationRepository) FindByID(id uuid.UUID) (*entities.Notification, error) {
+	notification, ok := r.notifications[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return notification, nil
+}
+
+func (r *fakeNotificationRepository) CreateDeliveries(deliveries []*entities.NotificationDelivery) error {
+	r.deliveries = append(r.deliveries, deliveries...)
+	return nil
+}
+
+func (r *fakeNotificationRepository) ClaimDueDeferred(before, staleClaimsBefore time.Time, limit int) ([]*entities.NotificationDelivery, error) {
+	r.claimedBefore, r.staleClaimsBefore = before, staleClaimsBefore
+	due := r.due
+	r.due = nil
+	for _, delivery := range due {
+		delivery.Status = constants.DeliveryStatusDispatching
+	}
+	return due, nil
+}
+
+func (r *fakeNotificationRepository) UpdateDelivery(delivery *entities.NotificationDelivery) error {
+	return nil
+}
+
+type fakeNotificationPreferenceRepository struct {
+	repositories.NotificationPreferenceRepository
+	preferences []*entities.NotificationPreference
+}
+
+func (r *fakeNotificationPreferenceRepository) FindByUserIDs(userIDs []uuid.UUID) ([]*entities.NotificationPreference, error) {
+	return r.preferences, nil
+}
+
+type fakeInboxRepository struct {
+	repositories.InboxRepository
+	items []*entities.InboxItem
+}
+
+func (r *fakeInboxRepository) Create(item *entities.InboxItem) error {
+	r.items = append(r.items, item)
+	return nil
+}
+
+func (r *fakeInboxRepository) CountUnread(userID uuid.UUID) (int64, error) {
+	return int64(len(r.items)), nil
+}
+
+// fakePushDriver succeeds for every device and keeps the tokens it sent to, in order
+type fakePushDriver struct {
+	tokens []string
+}
+
+func (d *fakePushDriver) SendToDevice(token string, title, body string, data map[string]string) (string, error) {
+	d.tokens = append(d.tokens, token)
+	return "message-" + token, nil
+}
+
+func (d *fakePushDriver) SendToDevices(tokens []string, title, body string, data map[string]string) ([]push.SendResult, error) {
+	var results []push.SendResult
+	for _, token := range tokens {
+		messageID, _ := d.SendToDevice(token, title, body, data)
+		results = append(results, push.SendResult{Token: token, Success: true, MessageID: messageID})
+	}
+	return results, nil
+}
+
+func (d *fakePushDriver) SendToTopic(topic, title, body string, data map[string]string) (string, error) {
+	return "message-" + topic, nil
+}
+
+type fakePubSub struct {
+	pubsub.PubSub
+}
+
+func (p *fakePubSub) Publish(ctx context.Context, channel string, message interface{}) error {
+	return nil
+}
+
+// fakeAudit keeps the actions recorded, in order
+// fakeRoleGrants records the roles granted and fails every grant with err when it is set
+type fakeRoleGrants struct {
+	RoleAssignmentUseCase
+	granted []*dto.GrantRoleRequest
+	err     error
+}
+
+func (g *fakeRoleGrants) Grant(ctx context.Context, userID uuid.UUID, req *dto.GrantRoleRequest) (*dto.RoleAssignmentResponse, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	g.granted = append(g.granted, req)
+	return &dto.RoleAssignmentResponse{UserID: userID}, nil
+}
+
+// fakeNotifications records the messages sent to users
+type fakeNotifications struct {
+	NotificationUseCase
+	sent map[uuid.UUID][]*dto.NotificationMessage
+}
+
+func newFakeNotifications() *fakeNotifications {
+	return &fakeNotifications{sent: make(map[uuid.UUID][]*dto.NotificationMessage)}
+}
+
+func (n *fakeNotifications) SendToUser(senderID *uuid.UUID, userID uuid.UUID, msg *dto.NotificationMessage) (*dto.NotificationResponse, error) {
+	n.sent[userID] = append(n.sent[userID], msg)
+	return &dto.NotificationResponse{}, nil
+}
+
+type fakeAudit struct {
+	AuditUseCase
+	actions []string
+}
+
+func (a *fakeAudit) Record(ctx context.Context, action, targetType, targetID string, before, after interface{}) {
+	a.actions = append(a.actions, action)
+}
+
+// ancestorIDs walks parents up from ids, returning every ancestor once
+func ancestorIDs(parents map[uuid.UUID][]uuid.UUID, ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	var found []uuid.UUID
+	queue := append([]uuid.UUID{}, ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, parentID := range parents[id] {
+			if !seen[parentID] {
+				seen[parentID] = true
+				found = append(found, parentID)
+				queue = append(queue, parentID)
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,515 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// ErrGroupHierarchyCycle is returned when a parent assignment would nest a group inside itself
+var ErrGroupHierarchyCycle = errors.New("group hierarchy would contain a cycle")
+
+// GroupUseCase manages groups, their members, their roles and how they are nested
+type GroupUseCase interface {
+	Create(ctx context.Context, req *dto.CreateGroupRequest) (*dto.GroupResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.GroupResponse, error)
+	GetAll(ctx context.Context, page, pageSize int) ([]*dto.GroupResponse, int64, error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdateGroupRequest) (*dto.GroupResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetMembers(ctx context.Context, id uuid.UUID, page, pageSize int) ([]*dto.UserSimple, int64, error)
+	AddMembers(ctx context.Context, id uuid.UUID, userIDs []uuid.UUID) error
+	RemoveMember(ctx context.Context, id, userID uuid.UUID) error
+	AssignRoles(ctx context.Context, id uuid.UUID, roleIDs []uuid.UUID) (*dto.GroupResponse, error)
+	AssignParents(ctx context.Context, id uuid.UUID, parentIDs []uuid.UUID) (*dto.GroupResponse, error)
+}
+
+type groupUseCase struct {
+	groupRepo  repositories.GroupRepository
+	userRepo   repositories.UserRepository
+	roleRepo   repositories.RoleRepository
+	duties     *separationOfDuties
+	delegation *delegatedAdministration
+	audit      AuditUseCase
+}
+
+func NewGroupUseCase(
+	groupRepo repositories.GroupRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	roleConstraintRepo repositories.RoleConstraintRepository,
+	organizationRepo repositories.OrganizationRepository,
+	audit AuditUseCase,
+) GroupUseCase {
+	return &groupUseCase{
+		groupRepo:  groupRepo,
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		duties:     &separationOfDuties{constraintRepo: roleConstraintRepo, userRepo: userRepo, roleRepo: roleRepo},
+		delegation: &delegatedAdministration{userRepo: userRepo, roleRepo: roleRepo, groupRepo: groupRepo, organizationRepo: organizationRepo},
+		audit:      audit,
+	}
+}
+
+func (uc *groupUseCase) Create(ctx context.Context, req *dto.CreateGroupRequest) (*dto.GroupResponse, error) {
+	group := &entities.Group{
+		Name:           req.Name,
+		Description:    req.Description,
+		OrganizationID: activeTenant(ctx),
+	}
+
+	// Check if group name already exists in the organization
+	if _, err := uc.groupRepo.WithContext(ctx).FindByName(req.Name, group.OrganizationID); err == nil {
+		return nil, errors.New("group name already exists")
+	}
+	if err := uc.groupRepo.Create(group); err != nil {
+		return nil, err
+	}
+
+	response := mapToGroupResponse(group)
+	uc.audit.Record(ctx, constants.AuditActionGroupCreate, constants.AuditTargetGroup, group.ID.String(), nil, response)
+
+	return response, nil
+}
+
+func (uc *groupUseCase) GetByID(ctx context.Context, id uuid.UUID) (*dto.GroupResponse, error) {
+	group, err := uc.groupRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapToGroupResponse(group), nil
+}
+
+func (uc *groupUseCase) GetAll(ctx context.Context, page, pageSize int) ([]*dto.GroupResponse, int64, error) {
+	groups, total, err := uc.groupRepo.WithContext(ctx).FindAll(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response []*dto.GroupResponse
+	for _, group := range groups {
+		response = append(response, mapToGroupResponse(group))
+	}
+
+	return response, total, nil
+}
+
+func (uc *groupUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdateGroupRequest) (*dto.GroupResponse, error) {
+	group, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := mapToGroupResponse(group)
+
+	// Update fields if provided
+	if req.Name != "" && req.Name != group.Name {
+		if existing, err := uc.groupRepo.WithContext(ctx).FindByName(req.Name, group.OrganizationID); err == nil && existing.ID != id {
+			return nil, errors.New("group name already exists")
+		}
+		group.Name = req.Name
+	}
+
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+
+	if err := uc.groupRepo.WithContext(ctx).Update(group); err != nil {
+		return nil, err
+	}
+
+	response := mapToGroupResponse(group)
+	uc.audit.Record(ctx, constants.AuditActionGroupUpdate, constants.AuditTargetGroup, id.String(), before, response)
+
+	return response, nil
+}
+
+func (uc *groupUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	group, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.groupRepo.WithContext(ctx).Delete(id); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionGroupDelete, constants.AuditTargetGroup, id.String(), mapToGroupResponse(group), nil)
+	return nil
+}
+
+// GetMembers lists the direct members of the group
+func (uc *groupUseCase) GetMembers(ctx context.Context, id uuid.UUID, page, pageSize int) ([]*dto.UserSimple, int64, error) {
+	if _, err := uc.groupRepo.WithContext(ctx).FindByID(id); err != nil {
+		return nil, 0, err
+	}
+
+	users, total, err := uc.groupRepo.WithContext(ctx).FindMembers(id, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	response := []*dto.UserSimple{}
+	for _, user := range users {
+		response = append(response, &dto.UserSimple{ID: user.ID, Username: user.Username, Email: user.Email})
+	}
+
+	return response, total, nil
+}
+
+// AddMembers adds users to the group, rejecting users the group's roles would put in conflict
+// with a role they already hold and users a delegated admin may not give those roles
+func (uc *groupUseCase) AddMembers(ctx context.Context, id uuid.UUID, userIDs []uuid.UUID) error {
+	group, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	roleIDs, err := uc.carriedRoleIDs(group)
+	if err != nil {
+		return err
+	}
+
+	// Only users visible to the caller can be added
+	for _, userID := range userIDs {
+		if _, err := uc.userRepo.WithContext(ctx).FindByID(userID); err != nil {
+			return fmt.Errorf("user %s not found", userID)
+		}
+		if err := uc.duties.checkAddedRoles(userID, roleIDs); err != nil {
+			return fmt.Errorf("user %s: %w", userID, err)
+		}
+		if err := uc.delegation.authorize(ctx, userID, roleIDs); err != nil {
+			return fmt.Errorf("user %s: %w", userID, err)
+		}
+	}
+
+	if err := uc.groupRepo.AddMembers(id, userIDs); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionGroupAddMembers, constants.AuditTargetGroup, id.String(), nil, userIDs)
+	return nil
+}
+
+func (uc *groupUseCase) RemoveMember(ctx context.Context, id, userID uuid.UUID) error {
+	group, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	roleIDs, err := uc.carriedRoleIDs(group)
+	if err != nil {
+		return err
+	}
+	if err := uc.delegation.authorize(ctx, userID, roleIDs); err != nil {
+		return err
+	}
+
+	if err := uc.groupRepo.RemoveMember(id, userID); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionGroupRemoveMember, constants.AuditTargetGroup, id.String(), userID, nil)
+	return nil
+}
+
+// AssignRoles replaces the roles every member of the group, and of the groups nested in it, receives
+func (uc *groupUseCase) AssignRoles(ctx context.Context, id uuid.UUID, roleIDs []uuid.UUID) (*dto.GroupResponse, error) {
+	group, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := mapToGroupResponse(group)
+
+	unique := make(map[uuid.UUID]bool)
+	for _, roleID := range roleIDs {
+		unique[roleID] = true
+	}
+	roles, err := uc.roleRepo.WithContext(ctx).FindByIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) != len(unique) {
+		return nil, errors.New("role not found")
+	}
+	// Shared groups may only carry shared roles, tenant groups also their own organization's
+	for _, role := range roles {
+		if !role.AppliesIn(group.OrganizationID) {
+			return nil, errors.New("role belongs to another organization")
+		}
+	}
+
+	if err := uc.checkMembers(group, roleIDs, groupIDsOf(group.Parents)); err != nil {
+		return nil, err
+	}
+	if err := uc.authorizeMembers(ctx, group, changedIDs(roleIDsOf(group.Roles), roleIDs)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.groupRepo.AssignRoles(id, roleIDs); err != nil {
+		return nil, err
+	}
+
+	updatedGroup, err := uc.groupRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToGroupResponse(updatedGroup)
+	uc.audit.Record(ctx, constants.AuditActionGroupAssignRoles, constants.AuditTargetGroup, id.String(), before, response)
+
+	return response, nil
+}
+
+// AssignParents replaces the groups this group is nested in, rejecting changes that would create a cycle
+func (uc *groupUseCase) AssignParents(ctx context.Context, id uuid.UUID, parentIDs []uuid.UUID) (*dto.GroupResponse, error) {
+	group, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := mapToGroupResponse(group)
+
+	seen := make(map[uuid.UUID]bool)
+	var uniqueParentIDs []uuid.UUID
+	for _, parentID := range parentIDs {
+		if parentID == id {
+			return nil, ErrGroupHierarchyCycle
+		}
+		if seen[parentID] {
+			continue
+		}
+		seen[parentID] = true
+		uniqueParentIDs = append(uniqueParentIDs, parentID)
+	}
+
+	parents, err := uc.groupRepo.WithContext(ctx).FindByIDs(uniqueParentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(parents) != len(uniqueParentIDs) {
+		return nil, errors.New("parent group not found")
+	}
+	// A tenant group can only be nested in shared groups or groups of its own organization
+	for _, parent := range parents {
+		if parent.OrganizationID != nil && (group.OrganizationID == nil || *parent.OrganizationID != *group.OrganizationID) {
+			return nil, errors.New("parent group belongs to another organization")
+		}
+	}
+
+	// The group must not already be an ancestor of any new parent
+	ancestorIDs, err := uc.groupRepo.FindAncestorIDs(uniqueParentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancestorID := range ancestorIDs {
+		if ancestorID == id {
+			return nil, ErrGroupHierarchyCycle
+		}
+	}
+
+	if err := uc.checkMembers(group, roleIDsOf(group.Roles), uniqueParentIDs); err != nil {
+		return nil, err
+	}
+	currentParentRoleIDs, err := uc.parentRoleIDs(groupIDsOf(group.Parents))
+	if err != nil {
+		return nil, err
+	}
+	parentRoleIDs, err := uc.parentRoleIDs(uniqueParentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorizeMembers(ctx, group, changedIDs(currentParentRoleIDs, parentRoleIDs)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.groupRepo.AssignParents(id, uniqueParentIDs); err != nil {
+		return nil, err
+	}
+
+	updatedGroup, err := uc.groupRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToGroupResponse(updatedGroup)
+	uc.audit.Record(ctx, constants.AuditActionGroupAssignParents, constants.AuditTargetGroup, id.String(), before, response)
+
+	return response, nil
+}
+
+// carriedRoleIDs returns the roles members of the group receive, from the group itself and
+// every group it is nested in
+func (uc *groupUseCase) carriedRoleIDs(group *entities.Group) ([]uuid.UUID, error) {
+	ancestorIDs, err := uc.groupRepo.FindAncestorIDs([]uuid.UUID{group.ID})
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := uc.groupRepo.FindByIDs(ancestorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var roleIDs []uuid.UUID
+	for _, g := range append([]*entities.Group{group}, ancestors...) {
+		for _, role := range g.Roles {
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+	return roleIDs, nil
+}
+
+// parentRoleIDs returns the roles members of a group nested in parentIDs receive from the parents
+// and every group above them
+func (uc *groupUseCase) parentRoleIDs(parentIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+	ancestorIDs, err := uc.groupRepo.FindAncestorIDs(parentIDs)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := uc.groupRepo.FindByIDs(append(parentIDs, ancestorIDs...))
+	if err != nil {
+		return nil, err
+	}
+
+	var roleIDs []uuid.UUID
+	for _, g := range groups {
+		roleIDs = append(roleIDs, roleIDsOf(g.Roles)...)
+	}
+	return roleIDs, nil
+}
+
+// authorizeMembers runs the delegated administration check for every member of the group and of
+// the groups nested in it, for roles they gain or lose through the group
+func (uc *groupUseCase) authorizeMembers(ctx context.Context, group *entities.Group, changedRoleIDs []uuid.UUID) error {
+	if len(changedRoleIDs) == 0 {
+		return nil
+	}
+	memberIDs, err := uc.groupRepo.FindAllMemberIDs(group.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range memberIDs {
+		if err := uc.delegation.authorize(ctx, userID, changedRoleIDs); err != nil {
+			return fmt.Errorf("user %s: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// checkMembers runs the separation-of-duties check for every member of the group and of the
+// groups nested in it, as if the group carried roleIDs and was nested in parentIDs
+func (uc *groupUseCase) checkMembers(group *entities.Group, roleIDs, parentIDs []uuid.UUID) error {
+	memberIDs, err := uc.groupRepo.FindAllMemberIDs(group.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range memberIDs {
+		groupRoleIDs, err := uc.groupRoleIDsWith(userID, group.ID, roleIDs, parentIDs)
+		if err != nil {
+			return err
+		}
+		if err := uc.duties.checkGroupRoles(userID, groupRoleIDs); err != nil {
+			return fmt.Errorf("user %s: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// groupRoleIDsWith returns the roles the user holds through groups, walking up from the groups
+// they are a member of, as if the changed group carried roleIDs and was nested in parentIDs
+func (uc *groupUseCase) groupRoleIDsWith(userID, changedID uuid.UUID, roleIDs, parentIDs []uuid.UUID) ([]uuid.UUID, error) {
+	groups, err := uc.groupRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var held []uuid.UUID
+	for len(groups) > 0 {
+		var next []uuid.UUID
+		for _, g := range groups {
+			if seen[g.ID] {
+				continue
+			}
+			seen[g.ID] = true
+
+			groupRoleIDs, groupParentIDs := roleIDsOf(g.Roles), groupIDsOf(g.Parents)
+			if g.ID == changedID {
+				groupRoleIDs, groupParentIDs = roleIDs, parentIDs
+			}
+			held = append(held, groupRoleIDs...)
+			for _, parentID := range groupParentIDs {
+				if !seen[parentID] {
+					next = append(next, parentID)
+				}
+			}
+		}
+
+		if groups, err = uc.groupRepo.FindByIDs(next); err != nil {
+			return nil, err
+		}
+	}
+	return held, nil
+}
+
+// findManageable loads a group the caller may change. Shared groups are read-only for
+// callers acting in an organization.
+func (uc *groupUseCase) findManageable(ctx context.Context, id uuid.UUID) (*entities.Group, error) {
+	group, err := uc.groupRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if activeTenant(ctx) != nil && group.OrganizationID == nil {
+		return nil, ErrForbidden
+	}
+	return group, nil
+}
+
+func mapToGroupResponse(group *entities.Group) *dto.GroupResponse {
+	resp := &dto.GroupResponse{
+		ID:             group.ID,
+		Name:           group.Name,
+		Description:    group.Description,
+		Roles:          []dto.RoleSimple{},
+		Parents:        mapToGroupPath(group.Parents),
+		OrganizationID: group.OrganizationID,
+		CreatedAt:      group.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      group.UpdatedAt.Format(time.RFC3339),
+	}
+	if resp.Parents == nil {
+		resp.Parents = []dto.GroupSimple{}
+	}
+
+	for _, role := range group.Roles {
+		resp.Roles = append(resp.Roles, dto.RoleSimple{ID: role.ID, Name: role.Name})
+	}
+
+	return resp
+}
+
+// mapToGroupPath maps groups in order, it returns nil for an empty path
+func mapToGroupPath(groups []*entities.Group) []dto.GroupSimple {
+	var path []dto.GroupSimple
+	for _, group := range groups {
+		path = append(path, dto.GroupSimple{ID: group.ID, Name: group.Name})
+	}
+	return path
+}
+
+func roleIDsOf(roles []*entities.Role) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, role.ID)
+	}
+	return ids
+}
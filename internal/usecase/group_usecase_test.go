@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+// newDelegatedGroupUseCase returns a group use case for the delegation fixture's caller, who may
+// only hand out agent to members of the organization
+func newDelegatedGroupUseCase(f *delegationFixture) *groupUseCase {
+	return &groupUseCase{
+		groupRepo:  f.groups,
+		userRepo:   f.users,
+		roleRepo:   f.roles,
+		duties:     &separationOfDuties{constraintRepo: &fakeRoleConstraintRepository{}, userRepo: f.users, roleRepo: f.roles},
+		delegation: f.delegation,
+		audit:      &fakeAudit{},
+	}
+}
+
+func TestGroupAddMembersAuthorizesCarriedRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   func(f *delegationFixture) []*entities.Role
+		parent  func(f *delegationFixture) []*entities.Role
+		wantErr error
+	}{
+		{
+			name:  "manageable role",
+			roles: func(f *delegationFixture) []*entities.Role { return []*entities.Role{f.agent} },
+		},
+		{
+			name:    "role that is not manageable",
+			roles:   func(f *delegationFixture) []*entities.Role { return []*entities.Role{f.billing} },
+			wantErr: ErrForbidden,
+		},
+		{
+			name:    "role that is not manageable from a parent group",
+			roles:   func(f *delegationFixture) []*entities.Role { return []*entities.Role{f.agent} },
+			parent:  func(f *delegationFixture) []*entities.Role { return []*entities.Role{f.billing} },
+			wantErr: ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDelegationFixture()
+			uc := newDelegatedGroupUseCase(f)
+			group := f.groups.add("support", &f.organizationID, tt.roles(f)...)
+			if tt.parent != nil {
+				f.groups.nest(group, f.groups.add("finance", &f.organizationID, tt.parent(f)...))
+			}
+
+			err := uc.AddMembers(f.context(), group.ID, []uuid.UUID{f.target.ID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddMembers() = %v, want %v", err, tt.wantErr)
+			}
+			if added := containsID(f.groups.members[group.ID], f.target.ID); added != (tt.wantErr == nil) {
+				t.Errorf("member added = %v", added)
+			}
+		})
+	}
+}
+
+func TestGroupRemoveMemberAuthorizesCarriedRoles(t *testing.T) {
+	f := newDelegationFixture()
+	uc := newDelegatedGroupUseCase(f)
+	finance := f.groups.add("finance", &f.organizationID, f.billing)
+	f.groups.AddMembers(finance.ID, []uuid.UUID{f.target.ID})
+
+	if err := uc.RemoveMember(f.context(), finance.ID, f.target.ID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("RemoveMember() = %v, want ErrForbidden", err)
+	}
+}
+
+func TestGroupAssignRolesAuthorizesEveryMember(t *testing.T) {
+	f := newDelegationFixture()
+	uc := newDelegatedGroupUseCase(f)
+	support := f.groups.add("support", &f.organizationID)
+	tier1 := f.groups.add("tier 1", &f.organizationID)
+	f.groups.nest(tier1, support)
+	f.groups.AddMembers(tier1.ID, []uuid.UUID{f.target.ID})
+
+	if _, err := uc.AssignRoles(f.context(), support.ID, []uuid.UUID{f.agent.ID}); err != nil {
+		t.Fatalf("AssignRoles() of a manageable role: %v", err)
+	}
+	if _, err := uc.AssignRoles(f.context(), support.ID, []uuid.UUID{f.agent.ID, f.billing.ID}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("AssignRoles() adding a role that is not manageable to a nested member = %v, want ErrForbidden", err)
+	}
+
+	// Without members nobody gains the role
+	empty := f.groups.add("empty", &f.organizationID)
+	if _, err := uc.AssignRoles(f.context(), empty.ID, []uuid.UUID{f.billing.ID}); err != nil {
+		t.Errorf("AssignRoles() on a group without members: %v", err)
+	}
+}
+
+func TestGroupAssignParentsAuthorizesEveryMember(t *testing.T) {
+	f := newDelegationFixture()
+	uc := newDelegatedGroupUseCase(f)
+	support := f.groups.add("support", &f.organizationID)
+	agents := f.groups.add("agents", &f.organizationID, f.agent)
+	finance := f.groups.add("finance", &f.organizationID, f.billing)
+	f.groups.AddMembers(support.ID, []uuid.UUID{f.target.ID})
+
+	if _, err := uc.AssignParents(f.context(), support.ID, []uuid.UUID{agents.ID}); err != nil {
+		t.Fatalf("AssignParents() to a group carrying a manageable role: %v", err)
+	}
+	if _, err := uc.AssignParents(f.context(), support.ID, []uuid.UUID{agents.ID, finance.ID}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("AssignParents() to a group carrying a role that is not manageable = %v, want ErrForbidden", err)
+	}
+}
@@ -1,36 +1,40 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"time"
 	"usermanagement-api/domain/entities"
 	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
 
 	"github.com/google/uuid"
 )
 
 type MenuUseCase interface {
-	Create(req *dto.CreateMenuRequest) (*dto.MenuResponse, error)
-	GetByID(id uuid.UUID) (*dto.MenuResponse, error)
-	GetAll(page, pageSize int) ([]*dto.MenuResponse, int64, error)
-	GetAllActive() ([]*dto.MenuResponse, error)
-	Update(id uuid.UUID, req *dto.UpdateMenuRequest) (*dto.MenuResponse, error)
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, req *dto.CreateMenuRequest) (*dto.MenuResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.MenuResponse, error)
+	GetAll(ctx context.Context, page, pageSize int) ([]*dto.MenuResponse, int64, error)
+	GetAllActive(ctx context.Context) ([]*dto.MenuResponse, error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdateMenuRequest) (*dto.MenuResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
 	PermissionMenu() ([]*dto.MenuResponse, error)
 }
 
 type menuUseCase struct {
 	menuRepo repositories.MenuRepository
+	audit    AuditUseCase
 }
 
-func NewMenuUseCase(menuRepo repositories.MenuRepository) MenuUseCase {
+func NewMenuUseCase(menuRepo repositories.MenuRepository, audit AuditUseCase) MenuUseCase {
 	return &menuUseCase{
 		menuRepo: menuRepo,
+		audit:    audit,
 	}
 }
 
-func (uc *menuUseCase) Create(req *dto.CreateMenuRequest) (*dto.MenuResponse, error) {
+func (uc *menuUseCase) Create(ctx context.Context, req *dto.CreateMenuRequest) (*dto.MenuResponse, error) {
 	// Check if menu name already exists
 	if _, err := uc.menuRepo.FindByName(req.Name); err == nil {
 		return nil, errors.New("menu name already exists")
@@ -45,6 +49,8 @@ func (uc *menuUseCase) Create(req *dto.CreateMenuRequest) (*dto.MenuResponse, er
 		ParentID:    req.ParentID,
 		Sequence:    req.Sequence,
 		IsActive:    true,
+
+		OrganizationID: activeTenant(ctx),
 	}
 
 	// Save menu
@@ -58,11 +64,14 @@ func (uc *menuUseCase) Create(req *dto.CreateMenuRequest) (*dto.MenuResponse, er
 		return nil, err
 	}
 
-	return uc.mapToMenuResponse(loadedMenu), nil
+	response := uc.mapToMenuResponse(loadedMenu)
+	uc.audit.Record(ctx, constants.AuditActionMenuCreate, constants.AuditTargetMenu, menu.ID.String(), nil, uc.mapToMenuSimpleResponse(loadedMenu))
+
+	return response, nil
 }
 
-func (uc *menuUseCase) GetByID(id uuid.UUID) (*dto.MenuResponse, error) {
-	menu, err := uc.menuRepo.FindByID(id)
+func (uc *menuUseCase) GetByID(ctx context.Context, id uuid.UUID) (*dto.MenuResponse, error) {
+	menu, err := uc.menuRepo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -70,8 +79,8 @@ func (uc *menuUseCase) GetByID(id uuid.UUID) (*dto.MenuResponse, error) {
 	return uc.mapToMenuResponse(menu), nil
 }
 
-func (uc *menuUseCase) GetAll(page, pageSize int) ([]*dto.MenuResponse, int64, error) {
-	menus, total, err := uc.menuRepo.FindAll(page, pageSize)
+func (uc *menuUseCase) GetAll(ctx context.Context, page, pageSize int) ([]*dto.MenuResponse, int64, error) {
+	menus, total, err := uc.menuRepo.WithContext(ctx).FindAll(page, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -84,8 +93,8 @@ func (uc *menuUseCase) GetAll(page, pageSize int) ([]*dto.MenuResponse, int64, e
 	return response, total, nil
 }
 
-func (uc *menuUseCase) GetAllActive() ([]*dto.MenuResponse, error) {
-	menus, err := uc.menuRepo.FindAllActive()
+func (uc *menuUseCase) GetAllActive(ctx context.Context) ([]*dto.MenuResponse, error) {
+	menus, err := uc.menuRepo.WithContext(ctx).FindAllActive()
 	if err != nil {
 		return nil, err
 	}
@@ -98,11 +107,12 @@ func (uc *menuUseCase) GetAllActive() ([]*dto.MenuResponse, error) {
 	return response, nil
 }
 
-func (uc *menuUseCase) Update(id uuid.UUID, req *dto.UpdateMenuRequest) (*dto.MenuResponse, error) {
-	menu, err := uc.menuRepo.FindByID(id)
+func (uc *menuUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdateMenuRequest) (*dto.MenuResponse, error) {
+	menu, err := uc.findManageable(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := uc.mapToMenuSimpleResponse(menu)
 
 	// Update fields if provided
 	if req.Name != "" && req.Name != menu.Name {
@@ -138,7 +148,7 @@ func (uc *menuUseCase) Update(id uuid.UUID, req *dto.UpdateMenuRequest) (*dto.Me
 	}
 
 	// Update menu
-	if err := uc.menuRepo.Update(menu); err != nil {
+	if err := uc.menuRepo.WithContext(ctx).Update(menu); err != nil {
 		return nil, err
 	}
 
@@ -148,11 +158,13 @@ func (uc *menuUseCase) Update(id uuid.UUID, req *dto.UpdateMenuRequest) (*dto.Me
 		return nil, err
 	}
 
+	uc.audit.Record(ctx, constants.AuditActionMenuUpdate, constants.AuditTargetMenu, id.String(), before, uc.mapToMenuSimpleResponse(loadedMenu))
+
 	return uc.mapToMenuResponse(loadedMenu), nil
 }
 
-func (uc *menuUseCase) Delete(id uuid.UUID) error {
-	menu, err := uc.menuRepo.FindByID(id)
+func (uc *menuUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	menu, err := uc.findManageable(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -163,7 +175,25 @@ func (uc *menuUseCase) Delete(id uuid.UUID) error {
 	}
 
 	// Delete menu
-	return uc.menuRepo.Delete(id)
+	if err := uc.menuRepo.WithContext(ctx).Delete(id); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionMenuDelete, constants.AuditTargetMenu, id.String(), uc.mapToMenuSimpleResponse(menu), nil)
+	return nil
+}
+
+// findManageable loads a menu the caller may change. Shared menus are read-only for
+// callers acting in an organization.
+func (uc *menuUseCase) findManageable(ctx context.Context, id uuid.UUID) (*entities.Menu, error) {
+	menu, err := uc.menuRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if activeTenant(ctx) != nil && menu.OrganizationID == nil {
+		return nil, ErrForbidden
+	}
+	return menu, nil
 }
 
 func (uc *menuUseCase) mapToMenuSimpleResponse(menu *entities.Menu) *dto.MenuResponse {
@@ -178,6 +208,8 @@ func (uc *menuUseCase) mapToMenuSimpleResponse(menu *entities.Menu) *dto.MenuRes
 		IsActive:    menu.IsActive,
 		CreatedAt:   menu.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   menu.UpdatedAt.Format(time.RFC3339),
+
+		OrganizationID: menu.OrganizationID,
 	}
 	return resp
 
@@ -195,6 +227,8 @@ func (uc *menuUseCase) mapToMenuResponse(menu *entities.Menu) *dto.MenuResponse
 		IsActive:    menu.IsActive,
 		CreatedAt:   menu.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   menu.UpdatedAt.Format(time.RFC3339),
+
+		OrganizationID: menu.OrganizationID,
 	}
 
 	// Map parent if exists
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModelType is a kind of model that permissions can be attached to through model permissions
+type ModelType interface {
+	// Exists reports whether the model is present and visible to the caller
+	Exists(ctx context.Context, id uuid.UUID) (bool, error)
+	// Names returns the display name of each model found, keyed by ID
+	Names(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error)
+}
+
+// ModelTypeRegistry holds the model types model permissions accept. Roles and menus are
+// registered by default, applications register their own types at startup.
+type ModelTypeRegistry interface {
+	Register(name string, modelType ModelType) error
+	Lookup(name string) (ModelType, error)
+	Names() []string
+}
+
+type modelTypeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]ModelType
+}
+
+func NewModelTypeRegistry(roleRepo repositories.RoleRepository, menuRepo repositories.MenuRepository) ModelTypeRegistry {
+	return &modelTypeRegistry{
+		types: map[string]ModelType{
+			constants.ModelTypeRole: &roleModelType{roleRepo: roleRepo},
+			constants.ModelTypeMenu: &menuModelType{menuRepo: menuRepo},
+		},
+	}
+}
+
+func (r *modelTypeRegistry) Register(name string, modelType ModelType) error {
+	if name == "" || modelType == nil {
+		return errors.New("model type name and implementation are required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.types[name]; ok {
+		return fmt.Errorf("model type %q is already registered", name)
+	}
+	r.types[name] = modelType
+	return nil
+}
+
+func (r *modelTypeRegistry) Lookup(name string) (ModelType, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	modelType, ok := r.types[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown model type %q", name)
+	}
+	return modelType, nil
+}
+
+func (r *modelTypeRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.types))
+	for name := range r.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type roleModelType struct {
+	roleRepo repositories.RoleRepository
+}
+
+func (t *roleModelType) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	_, err := t.roleRepo.WithContext(ctx).FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (t *roleModelType) Names(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	roles, err := t.roleRepo.WithContext(ctx).FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uuid.UUID]string, len(roles))
+	for _, role := range roles {
+		names[role.ID] = role.Name
+	}
+	return names, nil
+}
+
+type menuModelType struct {
+	menuRepo repositories.MenuRepository
+}
+
+func (t *menuModelType) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	_, err := t.menuRepo.WithContext(ctx).FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (t *menuModelType) Names(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	menus, err := t.menuRepo.WithContext(ctx).FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uuid.UUID]string, len(menus))
+	for _, menu := range menus {
+		names[menu.ID] = menu.Name
+	}
+	return names, nil
+}
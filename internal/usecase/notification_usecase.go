@@ -2,116 +2,825 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"time"
+	"usermanagement-api/domain/entities"
 	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
-	"usermanagement-api/pkg/firebase"
+	"usermanagement-api/pkg/logger"
+	"usermanagement-api/pkg/pubsub"
+	"usermanagement-api/pkg/push"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// maxReplayItems caps how many missed inbox items are replayed on reconnect
+const maxReplayItems = 100
+
+// deferredClaimLease is how long a claimed deferred delivery may stay dispatching before
+// another run assumes its dispatcher died and claims it again
+const deferredClaimLease = 10 * time.Minute
+
 type NotificationUseCase interface {
-	SendToUser(userID uuid.UUID, title, body string, data map[string]string) (*dto.NotificationResponse, error)
-	SendToUsers(userIDs []uuid.UUID, title, body string, data map[string]string) (*dto.NotificationResponse, error)
-	SendToTopic(topic, title, body string, data map[string]string) (*dto.NotificationResponse, error)
-	SendToAll(title, body string, data map[string]string) (*dto.NotificationResponse, error)
+	SendToUser(senderID *uuid.UUID, userID uuid.UUID, msg *dto.NotificationMessage) (*dto.NotificationResponse, error)
+	SendToUsers(senderID *uuid.UUID, userIDs []uuid.UUID, msg *dto.NotificationMessage) (*dto.NotificationResponse, error)
+	SendToTopic(senderID *uuid.UUID, topic string, msg *dto.NotificationMessage) (*dto.NotificationResponse, error)
+	SendToAll(senderID *uuid.UUID, msg *dto.NotificationMessage) (*dto.NotificationResponse, error)
+	GetAll(page, pageSize int) ([]*dto.NotificationSummaryResponse, int64, error)
+	GetReport(notificationID uuid.UUID) (*dto.NotificationReportResponse, error)
+	GetDeliveries(notificationID uuid.UUID, status string, page, pageSize int) ([]*dto.NotificationDeliveryResponse, int64, error)
+	MarkOpened(userID, notificationID uuid.UUID) error
+	DispatchDeferred() (int, error)
+	GetPreferences(userID uuid.UUID) (*dto.NotificationPreferenceResponse, error)
+	UpdatePreferences(userID uuid.UUID, req *dto.NotificationPreferenceRequest) (*dto.NotificationPreferenceResponse, error)
+	GetInbox(userID uuid.UUID, page, pageSize int) ([]*dto.InboxItemResponse, int64, error)
+	GetUnreadCount(userID uuid.UUID) (int64, error)
+	MarkAsRead(userID uuid.UUID, itemID uint) error
+	MarkAllAsRead(userID uuid.UUID) error
+	GetMissedEvents(userID uuid.UUID, lastEventID uint) ([]*dto.InboxItemResponse, error)
+	Subscribe(ctx context.Context, userID uuid.UUID) pubsub.Subscription
 }
 
 type notificationUseCase struct {
-	userMetaRepo repositories.UserMetaRepository
-	fcmClient    firebase.FCMClient
+	userMetaRepo     repositories.UserMetaRepository
+	inboxRepo        repositories.InboxRepository
+	notificationRepo repositories.NotificationRepository
+	preferenceRepo   repositories.NotificationPreferenceRepository
+	pushDriver       push.Driver
+	pubsub           pubsub.PubSub
 }
 
 func NewNotificationUseCase(
 	userMetaRepo repositories.UserMetaRepository,
-	fcmClient firebase.FCMClient,
+	inboxRepo repositories.InboxRepository,
+	notificationRepo repositories.NotificationRepository,
+	preferenceRepo repositories.NotificationPreferenceRepository,
+	pushDriver push.Driver,
+	pubsub pubsub.PubSub,
 ) NotificationUseCase {
 	return &notificationUseCase{
-		userMetaRepo: userMetaRepo,
-		fcmClient:    fcmClient,
+		userMetaRepo:     userMetaRepo,
+		inboxRepo:        inboxRepo,
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		pushDriver:       pushDriver,
+		pubsub:           pubsub,
 	}
 }
 
-func (uc *notificationUseCase) SendToUser(userID uuid.UUID, title, body string, data map[string]string) (*dto.NotificationResponse, error) {
-	// Get user device tokens
-	deviceTokens, err := uc.userMetaRepo.FindByUserIDAndKey(userID, "fcm_token")
-	if err != nil {
-		return &dto.NotificationResponse{
-			Success: false,
-			Error:   "Failed to find user devices",
-		}, err
-	}
+func (uc *notificationUseCase) SendToUser(senderID *uuid.UUID, userID uuid.UUID, msg *dto.NotificationMessage) (*dto.NotificationResponse, error) {
+	return uc.sendToRecipients(senderID, constants.NotificationTargetUser, []uuid.UUID{userID}, msg)
+}
 
-	var tokens = deviceTokens.Value
+func (uc *notificationUseCase) SendToUsers(senderID *uuid.UUID, userIDs []uuid.UUID, msg *dto.NotificationMessage) (*dto.NotificationResponse, error) {
+	return uc.sendToRecipients(senderID, constants.NotificationTargetUsers, userIDs, msg)
+}
+
+func (uc *notificationUseCase) SendToTopic(senderID *uuid.UUID, topic string, msg *dto.NotificationMessage) (*dto.NotificationResponse, error) {
+	return uc.sendToTopic(senderID, constants.NotificationTargetTopic, topic, msg)
+}
+
+func (uc *notificationUseCase) SendToAll(senderID *uuid.UUID, msg *dto.NotificationMessage) (*dto.NotificationResponse, error) {
+	// For sending to all, we use a topic that all devices are subscribed to
+	// You would need to manage this subscription separately
+	return uc.sendToTopic(senderID, constants.NotificationTargetAll, "all_users", msg)
+}
 
-	// Send notification
-	successCount, err := uc.fcmClient.SendToDevices([]string{tokens}, title, body, data)
+// sendToRecipients persists the notification, delivers it to every recipient's inbox
+// and pushes it to their devices, recording the outcome per recipient and device.
+func (uc *notificationUseCase) sendToRecipients(senderID *uuid.UUID, targetType string, userIDs []uuid.UUID, msg *dto.NotificationMessage) (*dto.NotificationResponse, error) {
+	notification, err := uc.createNotification(senderID, targetType, "", len(userIDs), msg)
 	if err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   "Failed to store notification",
 		}, err
 	}
+	data := uc.withNotificationID(notification.ID, msg.Data)
 
-	return &dto.NotificationResponse{
-		Success:      true,
-		SuccessCount: successCount,
-	}, nil
-}
+	preferences := uc.loadPreferences(userIDs)
+	now := time.Now()
 
-func (uc *notificationUseCase) SendToUsers(userIDs []uuid.UUID, title, body string, data map[string]string) (*dto.NotificationResponse, error) {
+	var deliveries []*entities.NotificationDelivery
 	var allTokens []string
+	tokenOwners := make(map[string]uuid.UUID)
+	inboxCount := 0
+	deferredCount := 0
+	suppressedCount := 0
+	lookupFailures := 0
 
-	// Get device tokens for each user
 	for _, userID := range userIDs {
+		recipientID := userID
+		preference := preferences[userID]
+
+		// In-app inbox, silent so quiet hours don't apply
+		inApp := &entities.NotificationDelivery{
+			NotificationID: notification.ID,
+			UserID:         &recipientID,
+			Channel:        constants.DeliveryChannelInApp,
+		}
+		if reason := suppressReason(preference, msg, constants.DeliveryChannelInApp); reason != "" {
+			uc.markSuppressed(inApp, reason)
+		} else if err := uc.deliverToInbox(userID, &notification.ID, msg.Title, msg.Body, data); err != nil {
+			logger.Warn("Failed to store inbox item", zap.String("user_id", userID.String()), zap.Error(err))
+			uc.markFailed(inApp, "inbox_error", err.Error())
+		} else {
+			uc.markSent(inApp, "")
+			inboxCount++
+		}
+		deliveries = append(deliveries, inApp)
+
+		// Push devices
+		pushDelivery := &entities.NotificationDelivery{
+			NotificationID: notification.ID,
+			UserID:         &recipientID,
+			Channel:        constants.DeliveryChannelPush,
+		}
+		if reason := suppressReason(preference, msg, constants.DeliveryChannelPush); reason != "" {
+			uc.markSuppressed(pushDelivery, reason)
+			deliveries = append(deliveries, pushDelivery)
+			suppressedCount++
+			continue
+		}
+
 		deviceTokens, err := uc.userMetaRepo.FindByUserIDAndKey(userID, "fcm_token")
-		if err != nil {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error("Failed to load device token", zap.String("user_id", userID.String()), zap.Error(err))
+			uc.markFailed(pushDelivery, constants.DeliveryErrorDeviceLookup, err.Error())
+			deliveries = append(deliveries, pushDelivery)
+			lookupFailures++
+			continue
+		}
+		if deviceTokens == nil || deviceTokens.Value == "" {
+			uc.markFailed(pushDelivery, constants.DeliveryErrorNoDeviceToken, "no devices registered for user")
+			deliveries = append(deliveries, pushDelivery)
 			continue
 		}
 
-		if deviceTokens != nil {
-			allTokens = append(allTokens, deviceTokens.Value)
+		if !msg.Critical {
+			if until, quiet := quietHoursEnd(preference, now); quiet {
+				pushDelivery.DeviceToken = deviceTokens.Value
+				if preference.QuietHoursMode == constants.QuietHoursModeSuppress {
+					uc.markSuppressed(pushDelivery, constants.DeliveryErrorQuietHours)
+					suppressedCount++
+				} else {
+					pushDelivery.Status = constants.DeliveryStatusDeferred
+					pushDelivery.DeliverAfter = &until
+					deferredCount++
+				}
+				deliveries = append(deliveries, pushDelivery)
+				continue
+			}
 		}
+
+		allTokens = append(allTokens, deviceTokens.Value)
+		tokenOwners[deviceTokens.Value] = userID
+	}
+
+	response := &dto.NotificationResponse{
+		Success:         inboxCount > 0 || deferredCount > 0,
+		NotificationID:  notification.ID,
+		InboxCount:      inboxCount,
+		DeferredCount:   deferredCount,
+		SuppressedCount: suppressedCount,
 	}
 
+	var pushErr error
 	if len(allTokens) == 0 {
-		return &dto.NotificationResponse{
-			Success: false,
-			Error:   "No devices registered for users",
-		}, nil
+		switch {
+		case push.Disabled(uc.pushDriver):
+			// Nothing reached a device, and nothing could have
+			pushErr = push.ErrDisabled
+			response.Error = pushErr.Error()
+		case lookupFailures > 0:
+			response.Error = "Failed to load device tokens"
+		case deferredCount == 0 && suppressedCount == 0:
+			response.Error = "No devices registered for users"
+		}
+	} else {
+		results, err := uc.pushDriver.SendToDevices(allTokens, msg.Title, msg.Body, data)
+		if err != nil {
+			// The whole batch failed, record it against every device
+			for _, token := range allTokens {
+				deliveries = append(deliveries, uc.pushDelivery(notification.ID, tokenOwners[token], push.SendResult{
+					Token:     token,
+					ErrorCode: pushErrorCode(err),
+					Error:     err.Error(),
+				}))
+			}
+			pushErr = err
+			response.FailureCount = len(allTokens)
+			response.Error = err.Error()
+		} else {
+			for _, result := range results {
+				deliveries = append(deliveries, uc.pushDelivery(notification.ID, tokenOwners[result.Token], result))
+				if result.Success {
+					response.SuccessCount++
+				} else {
+					response.FailureCount++
+				}
+			}
+			response.Success = true
+		}
+	}
+
+	if err := uc.notificationRepo.CreateDeliveries(deliveries); err != nil {
+		logger.Error("Failed to store notification deliveries", zap.String("notification_id", notification.ID.String()), zap.Error(err))
+	}
+
+	// The in-app inbox still counts as delivered when push fails
+	if !response.Success && pushErr != nil {
+		return response, pushErr
 	}
+	return response, nil
+}
 
-	// Send notification
-	successCount, err := uc.fcmClient.SendToDevices(allTokens, title, body, data)
+func (uc *notificationUseCase) sendToTopic(senderID *uuid.UUID, targetType, topic string, msg *dto.NotificationMessage) (*dto.NotificationResponse, error) {
+	notification, err := uc.createNotification(senderID, targetType, topic, 0, msg)
 	if err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   "Failed to store notification",
 		}, err
 	}
 
+	delivery := &entities.NotificationDelivery{
+		NotificationID: notification.ID,
+		Channel:        constants.DeliveryChannelPush,
+		DeviceToken:    "topic:" + topic,
+	}
+
+	messageID, sendErr := uc.pushDriver.SendToTopic(topic, msg.Title, msg.Body, uc.withNotificationID(notification.ID, msg.Data))
+	if sendErr != nil {
+		uc.markFailed(delivery, pushErrorCode(sendErr), sendErr.Error())
+	} else {
+		uc.markSent(delivery, messageID)
+	}
+
+	if err := uc.notificationRepo.CreateDeliveries([]*entities.NotificationDelivery{delivery}); err != nil {
+		logger.Error("Failed to store notification delivery", zap.String("notification_id", notification.ID.String()), zap.Error(err))
+	}
+
+	if sendErr != nil {
+		return &dto.NotificationResponse{
+			Success:        false,
+			NotificationID: notification.ID,
+			Error:          sendErr.Error(),
+		}, sendErr
+	}
+
 	return &dto.NotificationResponse{
-		Success:      true,
-		SuccessCount: successCount,
+		Success:        true,
+		NotificationID: notification.ID,
+		MessageID:      messageID,
 	}, nil
 }
 
-func (uc *notificationUseCase) SendToTopic(topic, title, body string, data map[string]string) (*dto.NotificationResponse, error) {
-	messageID, err := uc.fcmClient.SendToTopic(topic, title, body, data)
+func (uc *notificationUseCase) GetAll(page, pageSize int) ([]*dto.NotificationSummaryResponse, int64, error) {
+	notifications, total, err := uc.notificationRepo.FindAll(page, pageSize)
 	if err != nil {
-		return &dto.NotificationResponse{
-			Success: false,
-			Error:   err.Error(),
-		}, err
+		return nil, 0, err
 	}
 
-	return &dto.NotificationResponse{
-		Success:   true,
-		MessageID: messageID,
-	}, nil
+	var response []*dto.NotificationSummaryResponse
+	for _, notification := range notifications {
+		response = append(response, uc.mapToNotificationSummaryResponse(notification))
+	}
+
+	return response, total, nil
 }
 
-func (uc *notificationUseCase) SendToAll(title, body string, data map[string]string) (*dto.NotificationResponse, error) {
-	// For sending to all, we use a topic that all devices are subscribed to
-	// You would need to manage this subscription separately
-	return uc.SendToTopic("all_users", title, body, data)
+func (uc *notificationUseCase) GetReport(notificationID uuid.UUID) (*dto.NotificationReportResponse, error) {
+	notification, err := uc.notificationRepo.FindByID(notificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	statusCounts, err := uc.notificationRepo.CountDeliveriesByStatus(notificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	errorCounts, err := uc.notificationRepo.CountDeliveriesByError(notificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients, err := uc.notificationRepo.CountRecipients(notificationID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reached, err := uc.notificationRepo.CountRecipients(notificationID, []string{
+		constants.DeliveryStatusSent,
+		constants.DeliveryStatusOpened,
+		constants.DeliveryStatusRead,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	opened, err := uc.notificationRepo.CountRecipients(notificationID, []string{
+		constants.DeliveryStatusOpened,
+		constants.DeliveryStatusRead,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.NotificationReportResponse{
+		Notification:        *uc.mapToNotificationSummaryResponse(notification),
+		Recipients:          recipients,
+		RecipientsReached:   reached,
+		RecipientsUnreached: recipients - reached,
+		RecipientsOpened:    opened,
+		ByStatus:            make(map[string]int64),
+		ByChannel:           make(map[string]map[string]int64),
+		ErrorCodes:          make(map[string]int64),
+	}
+
+	for _, sc := range statusCounts {
+		report.ByStatus[sc.Status] += sc.Count
+		if report.ByChannel[sc.Channel] == nil {
+			report.ByChannel[sc.Channel] = make(map[string]int64)
+		}
+		report.ByChannel[sc.Channel][sc.Status] = sc.Count
+	}
+
+	for _, ec := range errorCounts {
+		report.ErrorCodes[ec.ErrorCode] = ec.Count
+	}
+
+	return report, nil
+}
+
+func (uc *notificationUseCase) GetDeliveries(notificationID uuid.UUID, status string, page, pageSize int) ([]*dto.NotificationDeliveryResponse, int64, error) {
+	deliveries, total, err := uc.notificationRepo.FindDeliveries(notificationID, status, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response []*dto.NotificationDeliveryResponse
+	for _, delivery := range deliveries {
+		response = append(response, &dto.NotificationDeliveryResponse{
+			ID:           delivery.ID,
+			UserID:       delivery.UserID,
+			Channel:      delivery.Channel,
+			Device:       push.MaskToken(delivery.DeviceToken),
+			Status:       delivery.Status,
+			ErrorCode:    delivery.ErrorCode,
+			ErrorMessage: delivery.ErrorMessage,
+			MessageID:    delivery.MessageID,
+			SentAt:       formatOptionalTime(delivery.SentAt),
+			OpenedAt:     formatOptionalTime(delivery.OpenedAt),
+			ReadAt:       formatOptionalTime(delivery.ReadAt),
+		})
+	}
+
+	return response, total, nil
+}
+
+func (uc *notificationUseCase) MarkOpened(userID, notificationID uuid.UUID) error {
+	return uc.notificationRepo.MarkOpened(notificationID, userID)
+}
+
+// DispatchDeferred pushes deliveries held back by quiet hours that are now due,
+// and returns how many were dispatched. Deliveries are claimed first, so every instance
+// running the job dispatches a delivery at most once.
+func (uc *notificationUseCase) DispatchDeferred() (int, error) {
+	now := time.Now()
+	due, err := uc.notificationRepo.ClaimDueDeferred(now, now.Add(-deferredClaimLease), 500)
+	if err != nil {
+		return 0, err
+	}
+
+	notifications := make(map[uuid.UUID]*entities.Notification)
+	dispatched := 0
+	for _, delivery := range due {
+		notification, ok := notifications[delivery.NotificationID]
+		if !ok {
+			notification, err = uc.notificationRepo.FindByID(delivery.NotificationID)
+			if err != nil {
+				logger.Warn("Failed to load deferred notification", zap.String("notification_id", delivery.NotificationID.String()), zap.Error(err))
+				continue
+			}
+			notifications[delivery.NotificationID] = notification
+		}
+
+		data := uc.withNotificationID(notification.ID, notification.Data)
+		messageID, err := uc.pushDriver.SendToDevice(delivery.DeviceToken, notification.Title, notification.Body, data)
+		delivery.DeliverAfter = nil
+		if err != nil {
+			uc.markFailed(delivery, pushErrorCode(err), err.Error())
+		} else {
+			uc.markSent(delivery, messageID)
+			dispatched++
+		}
+
+		if err := uc.notificationRepo.UpdateDelivery(delivery); err != nil {
+			logger.Error("Failed to update deferred delivery", zap.String("delivery_id", delivery.ID.String()), zap.Error(err))
+		}
+	}
+
+	return dispatched, nil
+}
+
+func (uc *notificationUseCase) GetPreferences(userID uuid.UUID) (*dto.NotificationPreferenceResponse, error) {
+	preference, err := uc.preferenceRepo.FindByUserID(userID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		preference = entities.DefaultNotificationPreference(userID)
+	}
+
+	return uc.mapToNotificationPreferenceResponse(preference), nil
+}
+
+func (uc *notificationUseCase) UpdatePreferences(userID uuid.UUID, req *dto.NotificationPreferenceRequest) (*dto.NotificationPreferenceResponse, error) {
+	preference := entities.DefaultNotificationPreference(userID)
+
+	if req.MutedCategories != nil {
+		preference.MutedCategories = req.MutedCategories
+	}
+	if req.Channels != nil {
+		preference.Channels = req.Channels
+	}
+	if req.QuietHoursMode != "" {
+		preference.QuietHoursMode = req.QuietHoursMode
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return nil, errors.New("invalid timezone")
+		}
+		preference.Timezone = req.Timezone
+	}
+
+	preference.QuietHoursEnabled = req.QuietHoursEnabled
+	preference.QuietHoursStart = req.QuietHoursStart
+	preference.QuietHoursEnd = req.QuietHoursEnd
+	if preference.QuietHoursEnabled {
+		start, startErr := parseClock(req.QuietHoursStart)
+		end, endErr := parseClock(req.QuietHoursEnd)
+		if startErr != nil || endErr != nil {
+			return nil, errors.New("quiet hours start and end must be in HH:MM format")
+		}
+		if start == end {
+			return nil, errors.New("quiet hours start and end must differ")
+		}
+	}
+
+	if err := uc.preferenceRepo.Upsert(preference); err != nil {
+		return nil, err
+	}
+
+	return uc.mapToNotificationPreferenceResponse(preference), nil
+}
+
+func (uc *notificationUseCase) GetInbox(userID uuid.UUID, page, pageSize int) ([]*dto.InboxItemResponse, int64, error) {
+	items, total, err := uc.inboxRepo.FindByUserID(userID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	response := []*dto.InboxItemResponse{}
+	for _, item := range items {
+		response = append(response, uc.mapToInboxItemResponse(item))
+	}
+
+	return response, total, nil
+}
+
+func (uc *notificationUseCase) GetUnreadCount(userID uuid.UUID) (int64, error) {
+	return uc.inboxRepo.CountUnread(userID)
+}
+
+func (uc *notificationUseCase) MarkAsRead(userID uuid.UUID, itemID uint) error {
+	item, err := uc.inboxRepo.FindByID(itemID)
+	if err != nil {
+		return err
+	}
+	if item.UserID != userID {
+		return errors.New("inbox item not found")
+	}
+
+	if err := uc.inboxRepo.MarkRead(userID, itemID); err != nil {
+		return err
+	}
+
+	if item.NotificationID != nil {
+		if err := uc.notificationRepo.MarkRead(*item.NotificationID, userID); err != nil {
+			logger.Warn("Failed to track notification read", zap.String("notification_id", item.NotificationID.String()), zap.Error(err))
+		}
+	}
+
+	uc.publishUnreadCount(userID)
+	return nil
+}
+
+func (uc *notificationUseCase) MarkAllAsRead(userID uuid.UUID) error {
+	if err := uc.inboxRepo.MarkAllRead(userID); err != nil {
+		return err
+	}
+
+	if err := uc.notificationRepo.MarkAllRead(userID); err != nil {
+		logger.Warn("Failed to track notification reads", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+
+	uc.publishUnreadCount(userID)
+	return nil
+}
+
+func (uc *notificationUseCase) GetMissedEvents(userID uuid.UUID, lastEventID uint) ([]*dto.InboxItemResponse, error) {
+	items, err := uc.inboxRepo.FindByUserIDAfter(userID, lastEventID, maxReplayItems)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*dto.InboxItemResponse
+	for _, item := range items {
+		response = append(response, uc.mapToInboxItemResponse(item))
+	}
+
+	return response, nil
+}
+
+func (uc *notificationUseCase) Subscribe(ctx context.Context, userID uuid.UUID) pubsub.Subscription {
+	return uc.pubsub.Subscribe(ctx, uc.getStreamChannel(userID))
+}
+
+// loadPreferences returns the preference of every user, falling back to the defaults
+func (uc *notificationUseCase) loadPreferences(userIDs []uuid.UUID) map[uuid.UUID]*entities.NotificationPreference {
+	preferences := make(map[uuid.UUID]*entities.NotificationPreference, len(userIDs))
+	for _, userID := range userIDs {
+		preferences[userID] = entities.DefaultNotificationPreference(userID)
+	}
+
+	stored, err := uc.preferenceRepo.FindByUserIDs(userIDs)
+	if err != nil {
+		logger.Warn("Failed to load notification preferences, using defaults", zap.Error(err))
+		return preferences
+	}
+	for _, preference := range stored {
+		preferences[preference.UserID] = preference
+	}
+
+	return preferences
+}
+
+func (uc *notificationUseCase) createNotification(senderID *uuid.UUID, targetType, topic string, recipientCount int, msg *dto.NotificationMessage) (*entities.Notification, error) {
+	category := msg.Category
+	if category == "" {
+		category = constants.NotificationCategoryGeneral
+	}
+
+	notification := &entities.Notification{
+		SenderID:       senderID,
+		Title:          msg.Title,
+		Body:           msg.Body,
+		Data:           msg.Data,
+		Category:       category,
+		Critical:       msg.Critical,
+		TargetType:     targetType,
+		Topic:          topic,
+		RecipientCount: recipientCount,
+	}
+	if err := uc.notificationRepo.Create(notification); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+// withNotificationID copies the payload data and adds the notification ID,
+// so clients can report the notification as opened.
+func (uc *notificationUseCase) withNotificationID(notificationID uuid.UUID, data map[string]string) map[string]string {
+	result := make(map[string]string, len(data)+1)
+	for key, value := range data {
+		result[key] = value
+	}
+	result["notification_id"] = notificationID.String()
+	return result
+}
+
+func (uc *notificationUseCase) pushDelivery(notificationID, userID uuid.UUID, result push.SendResult) *entities.NotificationDelivery {
+	delivery := &entities.NotificationDelivery{
+		NotificationID: notificationID,
+		UserID:         &userID,
+		Channel:        constants.DeliveryChannelPush,
+		DeviceToken:    result.Token,
+	}
+	if result.Success {
+		uc.markSent(delivery, result.MessageID)
+	} else {
+		uc.markFailed(delivery, result.ErrorCode, result.Error)
+	}
+	return delivery
+}
+
+func (uc *notificationUseCase) markSent(delivery *entities.NotificationDelivery, messageID string) {
+	now := time.Now()
+	delivery.Status = constants.DeliveryStatusSent
+	delivery.MessageID = messageID
+	delivery.SentAt = &now
+}
+
+func (uc *notificationUseCase) markSuppressed(delivery *entities.NotificationDelivery, reason string) {
+	delivery.Status = constants.DeliveryStatusSuppressed
+	delivery.ErrorCode = reason
+}
+
+func (uc *notificationUseCase) markFailed(delivery *entities.NotificationDelivery, errorCode, message string) {
+	delivery.Status = constants.DeliveryStatusFailed
+	delivery.ErrorCode = errorCode
+	delivery.ErrorMessage = message
+}
+
+// deliverToInbox stores the inbox item and pushes it to any open streams of the user
+func (uc *notificationUseCase) deliverToInbox(userID uuid.UUID, notificationID *uuid.UUID, title, body string, data map[string]string) error {
+	item := &entities.InboxItem{
+		UserID:         userID,
+		NotificationID: notificationID,
+		Title:          title,
+		Body:           body,
+		Data:           data,
+	}
+	if err := uc.inboxRepo.Create(item); err != nil {
+		return err
+	}
+
+	unread, err := uc.inboxRepo.CountUnread(userID)
+	if err != nil {
+		logger.Warn("Failed to count unread inbox items", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+
+	uc.publish(userID, &dto.NotificationStreamEvent{
+		Type:        constants.NotificationEventInboxItem,
+		Item:        uc.mapToInboxItemResponse(item),
+		UnreadCount: unread,
+	})
+	return nil
+}
+
+func (uc *notificationUseCase) publishUnreadCount(userID uuid.UUID) {
+	unread, err := uc.inboxRepo.CountUnread(userID)
+	if err != nil {
+		logger.Warn("Failed to count unread inbox items", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+
+	uc.publish(userID, &dto.NotificationStreamEvent{
+		Type:        constants.NotificationEventUnreadCount,
+		UnreadCount: unread,
+	})
+}
+
+func (uc *notificationUseCase) publish(userID uuid.UUID, event *dto.NotificationStreamEvent) {
+	if err := uc.pubsub.Publish(context.Background(), uc.getStreamChannel(userID), event); err != nil {
+		logger.Warn("Failed to publish notification event", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+}
+
+func (uc *notificationUseCase) getStreamChannel(userID uuid.UUID) string {
+	return constants.NotificationChannelPrefix + userID.String()
+}
+
+func (uc *notificationUseCase) mapToNotificationSummaryResponse(notification *entities.Notification) *dto.NotificationSummaryResponse {
+	return &dto.NotificationSummaryResponse{
+		ID:             notification.ID,
+		SenderID:       notification.SenderID,
+		Title:          notification.Title,
+		Body:           notification.Body,
+		Data:           notification.Data,
+		Category:       notification.Category,
+		Critical:       notification.Critical,
+		TargetType:     notification.TargetType,
+		Topic:          notification.Topic,
+		RecipientCount: notification.RecipientCount,
+		CreatedAt:      notification.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (uc *notificationUseCase) mapToNotificationPreferenceResponse(preference *entities.NotificationPreference) *dto.NotificationPreferenceResponse {
+	_, inQuietHours := quietHoursEnd(preference, time.Now())
+
+	response := &dto.NotificationPreferenceResponse{
+		MutedCategories:   preference.MutedCategories,
+		Channels:          preference.Channels,
+		QuietHoursEnabled: preference.QuietHoursEnabled,
+		QuietHoursStart:   preference.QuietHoursStart,
+		QuietHoursEnd:     preference.QuietHoursEnd,
+		QuietHoursMode:    preference.QuietHoursMode,
+		Timezone:          preference.Timezone,
+		InQuietHours:      inQuietHours,
+	}
+	if !preference.UpdatedAt.IsZero() {
+		response.UpdatedAt = formatTimePointer(preference.UpdatedAt)
+	}
+
+	return response
+}
+
+func (uc *notificationUseCase) mapToInboxItemResponse(item *entities.InboxItem) *dto.InboxItemResponse {
+	return &dto.InboxItemResponse{
+		ID:        item.ID,
+		Title:     item.Title,
+		Body:      item.Body,
+		Data:      item.Data,
+		IsRead:    item.ReadAt != nil,
+		ReadAt:    formatOptionalTime(item.ReadAt),
+		CreatedAt: item.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// suppressReason reports why a non-critical message must not go out on a channel,
+// or an empty string when the recipient's preference allows it.
+func suppressReason(preference *entities.NotificationPreference, msg *dto.NotificationMessage, channel string) string {
+	if msg.Critical {
+		return ""
+	}
+
+	category := msg.Category
+	if category == "" {
+		category = constants.NotificationCategoryGeneral
+	}
+	for _, muted := range preference.MutedCategories {
+		if muted == category {
+			return constants.DeliveryErrorCategoryMuted
+		}
+	}
+
+	for _, enabled := range preference.Channels {
+		if enabled == channel {
+			return ""
+		}
+	}
+	return constants.DeliveryErrorChannelDisabled
+}
+
+// quietHoursEnd reports whether now falls within the recipient's quiet hours
+// and, if so, when they end.
+func quietHoursEnd(preference *entities.NotificationPreference, now time.Time) (time.Time, bool) {
+	if !preference.QuietHoursEnabled {
+		return time.Time{}, false
+	}
+
+	start, err := parseClock(preference.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(preference.QuietHoursEnd)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	location, err := time.LoadLocation(preference.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	current := local.Hour()*60 + local.Minute()
+
+	// A window such as 22:00-07:00 spans midnight
+	var quiet bool
+	if start < end {
+		quiet = current >= start && current < end
+	} else {
+		quiet = current >= start || current < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, location)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// parseClock parses HH:MM into minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func pushErrorCode(err error) string {
+	if errors.Is(err, push.ErrDisabled) {
+		return push.ErrorCodeDisabled
+	}
+	return push.ErrorCodeUnknown
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	return formatTimePointer(*t)
 }
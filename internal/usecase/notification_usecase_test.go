@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+func TestQuietHoursEnd(t *testing.T) {
+	now := time.Date(2024, 5, 1, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		disabled  bool
+		start     string
+		end       string
+		timezone  string
+		wantQuiet bool
+		wantUntil time.Time
+	}{
+		{"disabled", true, "09:00", "17:00", "UTC", false, time.Time{}},
+		{"inside a daytime window", false, "09:00", "17:00", "UTC", true, time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)},
+		{"before a daytime window", false, "16:00", "17:00", "UTC", false, time.Time{}},
+		{"at the end of a window", false, "14:00", "15:30", "UTC", false, time.Time{}},
+		{"outside a window spanning midnight", false, "22:00", "07:00", "UTC", false, time.Time{}},
+		{"before midnight in the recipient's time zone", false, "22:00", "07:00", "Asia/Jakarta", true, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"after midnight in the recipient's time zone", false, "22:00", "07:00", "Asia/Tokyo", true, time.Date(2024, 5, 1, 22, 0, 0, 0, time.UTC)},
+		{"unknown time zone falls back to UTC", false, "15:00", "16:00", "Mars/Olympus", true, time.Date(2024, 5, 1, 16, 0, 0, 0, time.UTC)},
+		{"empty window", false, "15:00", "15:00", "UTC", false, time.Time{}},
+		{"invalid clock", false, "25:00", "07:00", "UTC", false, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preference := &entities.NotificationPreference{
+				QuietHoursEnabled: !tt.disabled,
+				QuietHoursStart:   tt.start,
+				QuietHoursEnd:     tt.end,
+				Timezone:          tt.timezone,
+			}
+
+			until, quiet := quietHoursEnd(preference, now)
+			if quiet != tt.wantQuiet || !until.Equal(tt.wantUntil) {
+				t.Errorf("quietHoursEnd() = %s, %v, want %s, %v", until, quiet, tt.wantUntil, tt.wantQuiet)
+			}
+		})
+	}
+}
+
+type notificationFixture struct {
+	uc            *notificationUseCase
+	notifications *fakeNotificationRepository
+	preferences   *fakeNotificationPreferenceRepository
+	push          *fakePushDriver
+
+	userID uuid.UUID
+}
+
+// newNotificationFixture has a user with one device whose quiet hours started an hour ago
+func newNotificationFixture() *notificationFixture {
+	f := &notificationFixture{
+		notifications: newFakeNotificationRepository(),
+		preferences:   &fakeNotificationPreferenceRepository{},
+		push:          &fakePushDriver{},
+		userID:        uuid.New(),
+	}
+	metas := &fakeUserMetaRepository{metas: map[uuid.UUID][]*entities.UserMeta{
+		f.userID: {{UserID: f.userID, Key: "fcm_token", Value: "device-token"}},
+	}}
+	f.uc = &notificationUseCase{
+		userMetaRepo:     metas,
+		inboxRepo:        &fakeInboxRepository{},
+		notificationRepo: f.notifications,
+		preferenceRepo:   f.preferences,
+		pushDriver:       f.push,
+		pubsub:           &fakePubSub{},
+	}
+
+	now := time.Now().UTC()
+	preference := entities.DefaultNotificationPreference(f.userID)
+	preference.QuietHoursEnabled = true
+	preference.QuietHoursStart = now.Add(-time.Hour).Format("15:04")
+	preference.QuietHoursEnd = now.Add(time.Hour).Format("15:04")
+	f.preferences.preferences = []*entities.NotificationPreference{preference}
+	return f
+}
+
+// pushDelivery returns the push delivery stored for the user
+func (f *notificationFixture) pushDelivery(t *testing.T) *entities.NotificationDelivery {
+	t.Helper()
+	for _, delivery := range f.notifications.deliveries {
+		if delivery.Channel == constants.DeliveryChannelPush {
+			return delivery
+		}
+	}
+	t.Fatal("no push delivery stored")
+	return nil
+}
+
+func TestSendToUserDuringQuietHours(t *testing.T) {
+	tests := []struct {
+		name       string
+		critical   bool
+		mode       string
+		wantStatus string
+		wantPushed bool
+	}{
+		{"deferred", false, constants.QuietHoursModeDefer, constants.DeliveryStatusDeferred, false},
+		{"suppressed", false, constants.QuietHoursModeSuppress, constants.DeliveryStatusSuppressed, false},
+		{"critical bypasses deferral", true, constants.QuietHoursModeDefer, constants.DeliveryStatusSent, true},
+		{"critical bypasses suppression", true, constants.QuietHoursModeSuppress, constants.DeliveryStatusSent, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newNotificationFixture()
+			f.preferences.preferences[0].QuietHoursMode = tt.mode
+
+			if _, err := f.uc.SendToUser(nil, f.userID, &dto.NotificationMessage{Title: "Hi", Body: "There", Critical: tt.critical}); err != nil {
+				t.Fatal(err)
+			}
+
+			delivery := f.pushDelivery(t)
+			if delivery.Status != tt.wantStatus {
+				t.Errorf("push delivery status = %s, want %s", delivery.Status, tt.wantStatus)
+			}
+			if pushed := len(f.push.tokens) > 0; pushed != tt.wantPushed {
+				t.Errorf("pushed = %v, want %v", pushed, tt.wantPushed)
+			}
+			if tt.wantStatus == constants.DeliveryStatusDeferred && (delivery.DeliverAfter == nil || !delivery.DeliverAfter.After(time.Now())) {
+				t.Errorf("DeliverAfter = %v, want the end of the quiet hours", delivery.DeliverAfter)
+			}
+		})
+	}
+}
+
+func TestDispatchDeferredSendsClaimedDeliveries(t *testing.T) {
+	f := newNotificationFixture()
+	notification := &entities.Notification{Title: "Hi", Body: "There"}
+	f.notifications.Create(notification)
+	deliverAfter := time.Now().Add(-time.Minute)
+	f.notifications.due = []*entities.NotificationDelivery{{
+		ID:             uuid.New(),
+		NotificationID: notification.ID,
+		UserID:         &f.userID,
+		Channel:        constants.DeliveryChannelPush,
+		DeviceToken:    "device-token",
+		Status:         constants.DeliveryStatusDeferred,
+		DeliverAfter:   &deliverAfter,
+	}}
+	delivery := f.notifications.due[0]
+
+	dispatched, err := f.uc.DispatchDeferred()
+	if err != nil || dispatched != 1 {
+		t.Fatalf("DispatchDeferred() = %d, %v, want 1, nil", dispatched, err)
+	}
+	if delivery.Status != constants.DeliveryStatusSent || delivery.DeliverAfter != nil {
+		t.Errorf("delivery = %s, deliver after %v, want sent with no deliver after", delivery.Status, delivery.DeliverAfter)
+	}
+	if lease := f.notifications.claimedBefore.Sub(f.notifications.staleClaimsBefore); lease != deferredClaimLease {
+		t.Errorf("stale claims are taken over after %s, want %s", lease, deferredClaimLease)
+	}
+
+	// Claimed deliveries are not handed out again
+	if dispatched, _ := f.uc.DispatchDeferred(); dispatched != 0 || len(f.push.tokens) != 1 {
+		t.Errorf("second DispatchDeferred() = %d, pushed %v, want nothing", dispatched, f.push.tokens)
+	}
+}
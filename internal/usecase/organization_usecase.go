@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationUseCase manages tenants and their members. Callers acting in an organization
+// only see and manage that organization; creating and deleting organizations is left to
+// callers outside any organization.
+type OrganizationUseCase interface {
+	Create(ctx context.Context, req *dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.OrganizationResponse, error)
+	GetAll(ctx context.Context, page, pageSize int) ([]*dto.OrganizationResponse, int64, error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdateOrganizationRequest) (*dto.OrganizationResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetMembers(ctx context.Context, id uuid.UUID, page, pageSize int) ([]*dto.OrganizationMemberResponse, int64, error)
+	AddMembers(ctx context.Context, id uuid.UUID, req *dto.AddOrganizationMembersRequest) error
+	RemoveMember(ctx context.Context, id, userID uuid.UUID) error
+}
+
+type organizationUseCase struct {
+	organizationRepo repositories.OrganizationRepository
+	userRepo         repositories.UserRepository
+	audit            AuditUseCase
+}
+
+func NewOrganizationUseCase(
+	organizationRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	audit AuditUseCase,
+) OrganizationUseCase {
+	return &organizationUseCase{
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+		audit:            audit,
+	}
+}
+
+func (uc *organizationUseCase) Create(ctx context.Context, req *dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	if activeTenant(ctx) != nil {
+		return nil, ErrForbidden
+	}
+
+	organization := &entities.Organization{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if err := uc.organizationRepo.Create(organization); err != nil {
+		return nil, errors.New("organization name or slug already exists")
+	}
+
+	response := mapToOrganizationResponse(organization)
+	uc.audit.Record(ctx, constants.AuditActionOrganizationCreate, constants.AuditTargetOrganization, organization.ID.String(), nil, response)
+
+	return response, nil
+}
+
+func (uc *organizationUseCase) GetByID(ctx context.Context, id uuid.UUID) (*dto.OrganizationResponse, error) {
+	organization, err := uc.findAccessible(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapToOrganizationResponse(organization), nil
+}
+
+func (uc *organizationUseCase) GetAll(ctx context.Context, page, pageSize int) ([]*dto.OrganizationResponse, int64, error) {
+	if tenant := activeTenant(ctx); tenant != nil {
+		organization, err := uc.organizationRepo.FindByID(*tenant)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*dto.OrganizationResponse{mapToOrganizationResponse(organization)}, 1, nil
+	}
+
+	organizations, total, err := uc.organizationRepo.FindAll(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response []*dto.OrganizationResponse
+	for _, organization := range organizations {
+		response = append(response, mapToOrganizationResponse(organization))
+	}
+
+	return response, total, nil
+}
+
+func (uc *organizationUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	organization, err := uc.findAccessible(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := mapToOrganizationResponse(organization)
+
+	// Update fields if provided
+	if req.Name != "" {
+		organization.Name = req.Name
+	}
+
+	if req.Slug != "" {
+		organization.Slug = req.Slug
+	}
+
+	if req.Description != nil {
+		organization.Description = *req.Description
+	}
+
+	// Only operators outside the organization may deactivate it, it locks every member out
+	if req.IsActive != nil {
+		if activeTenant(ctx) != nil && !*req.IsActive {
+			return nil, ErrForbidden
+		}
+		organization.IsActive = *req.IsActive
+	}
+
+	if err := uc.organizationRepo.Update(organization); err != nil {
+		return nil, errors.New("organization name or slug already exists")
+	}
+
+	response := mapToOrganizationResponse(organization)
+	uc.audit.Record(ctx, constants.AuditActionOrganizationUpdate, constants.AuditTargetOrganization, id.String(), before, response)
+
+	return response, nil
+}
+
+func (uc *organizationUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	if activeTenant(ctx) != nil {
+		return ErrForbidden
+	}
+
+	organization, err := uc.organizationRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.organizationRepo.Delete(id); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionOrganizationDelete, constants.AuditTargetOrganization, id.String(), mapToOrganizationResponse(organization), nil)
+	return nil
+}
+
+func (uc *organizationUseCase) GetMembers(ctx context.Context, id uuid.UUID, page, pageSize int) ([]*dto.OrganizationMemberResponse, int64, error) {
+	if _, err := uc.findAccessible(ctx, id); err != nil {
+		return nil, 0, err
+	}
+
+	members, total, err := uc.organizationRepo.FindMembers(id, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	response := []*dto.OrganizationMemberResponse{}
+	for _, member := range members {
+		// Soft-deleted users keep their membership row
+		if member.User == nil {
+			continue
+		}
+		response = append(response, &dto.OrganizationMemberResponse{
+			OrganizationID: member.OrganizationID,
+			User: dto.UserSimple{
+				ID:       member.User.ID,
+				Username: member.User.Username,
+				Email:    member.User.Email,
+			},
+			JoinedAt: member.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return response, total, nil
+}
+
+// AddMembers adds existing users to the organization. Users are looked up without tenant
+// scoping, so an organization admin can invite users that are not members yet.
+func (uc *organizationUseCase) AddMembers(ctx context.Context, id uuid.UUID, req *dto.AddOrganizationMembersRequest) error {
+	if _, err := uc.findAccessible(ctx, id); err != nil {
+		return err
+	}
+
+	for _, userID := range req.UserIDs {
+		if _, err := uc.userRepo.FindByID(userID); err != nil {
+			return fmt.Errorf("user %s not found", userID)
+		}
+	}
+
+	if err := uc.organizationRepo.AddMembers(id, req.UserIDs); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionOrganizationAddMember, constants.AuditTargetOrganization, id.String(), nil, req.UserIDs)
+	return nil
+}
+
+func (uc *organizationUseCase) RemoveMember(ctx context.Context, id, userID uuid.UUID) error {
+	if _, err := uc.findAccessible(ctx, id); err != nil {
+		return err
+	}
+
+	if err := uc.organizationRepo.RemoveMember(id, userID); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionOrganizationDelMember, constants.AuditTargetOrganization, id.String(), userID, nil)
+	return nil
+}
+
+// findAccessible loads an organization the caller may act on
+func (uc *organizationUseCase) findAccessible(ctx context.Context, id uuid.UUID) (*entities.Organization, error) {
+	if tenant := activeTenant(ctx); tenant != nil && *tenant != id {
+		return nil, ErrForbidden
+	}
+	return uc.organizationRepo.FindByID(id)
+}
+
+func mapToOrganizationResponse(organization *entities.Organization) *dto.OrganizationResponse {
+	return &dto.OrganizationResponse{
+		ID:          organization.ID,
+		Name:        organization.Name,
+		Slug:        organization.Slug,
+		Description: organization.Description,
+		IsActive:    organization.IsActive,
+		CreatedAt:   organization.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   organization.UpdatedAt.Format(time.RFC3339),
+	}
+}
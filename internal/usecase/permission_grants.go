@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"sort"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/pkg/permission"
+
+	"github.com/google/uuid"
+)
+
+// permissionGrant is one permission a user receives through a role
+type permissionGrant struct {
+	Permission *entities.Permission
+	// Role holds the permission, AssignedRole is the user's role it was reached from;
+	// they differ when the permission is inherited through the hierarchy.
+	Role         *entities.Role
+	AssignedRole *entities.Role
+	// Groups is the group path AssignedRole is held through, empty for direct assignments
+	Groups []*entities.Group
+}
+
+// Inherited reports whether the grant comes from an ancestor of the assigned role
+func (g permissionGrant) Inherited() bool {
+	return g.Role.ID != g.AssignedRole.ID
+}
+
+// grantResolver expands a user's roles through groups and the role hierarchy and records
+// where each permission comes from
+type grantResolver struct {
+	roleRepo  repositories.RoleRepository
+	groupRepo repositories.GroupRepository
+}
+
+// roleSource is one way a user holds a role
+type roleSource struct {
+	Role *entities.Role
+	// Groups leads from a group the user is a member of, through parent groups, to the
+	// group the role is assigned to; it is empty for direct assignments
+	Groups []*entities.Group
+}
+
+// roleSourcesForUser lists how the user holds each of its roles: direct assignments counting in
+// the organization first, then group paths, shortest first. A role reached through several
+// groups is listed once per group holding it. Paths only run through shared groups and groups
+// of the organization.
+func (r *grantResolver) roleSourcesForUser(user *entities.User, organizationID *uuid.UUID) ([]roleSource, error) {
+	var sources []roleSource
+	for _, role := range user.RolesIn(organizationID) {
+		sources = append(sources, roleSource{Role: role})
+	}
+
+	groups, err := r.groupRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups = groupsInOrganization(groups, organizationID)
+
+	// Walk up the group hierarchy one level at a time so each group is reached by its shortest path
+	paths := make(map[uuid.UUID][]*entities.Group)
+	for _, group := range groups {
+		paths[group.ID] = []*entities.Group{group}
+	}
+	for len(groups) > 0 {
+		var parentIDs []uuid.UUID
+		for _, group := range groups {
+			path := paths[group.ID]
+			for _, role := range group.Roles {
+				sources = append(sources, roleSource{Role: role, Groups: path})
+			}
+			for _, parent := range group.Parents {
+				if _, seen := paths[parent.ID]; seen {
+					continue
+				}
+				paths[parent.ID] = append(append([]*entities.Group{}, path...), parent)
+				parentIDs = append(parentIDs, parent.ID)
+			}
+		}
+
+		groups, err = r.groupRepo.FindByIDs(parentIDs)
+		if err != nil {
+			return nil, err
+		}
+		groups = groupsInOrganization(groups, organizationID)
+	}
+
+	return sources, nil
+}
+
+// groupsInOrganization keeps the groups whose roles members receive while acting in the organization
+func groupsInOrganization(groups []*entities.Group, organizationID *uuid.UUID) []*entities.Group {
+	var applicable []*entities.Group
+	for _, group := range groups {
+		if group.AppliesIn(organizationID) {
+			applicable = append(applicable, group)
+		}
+	}
+	return applicable
+}
+
+// sourcesInOrganization keeps the sources whose role applies while acting in the organization
+func sourcesInOrganization(sources []roleSource, organizationID *uuid.UUID) []roleSource {
+	var applicable []roleSource
+	for _, source := range sources {
+		if source.Role.AppliesIn(organizationID) {
+			applicable = append(applicable, source)
+		}
+	}
+	return applicable
+}
+
+// firstSourcePerRole keeps the preferred source of every role, sources must be ordered as
+// roleSourcesForUser returns them
+func firstSourcePerRole(sources []roleSource) []roleSource {
+	seen := make(map[uuid.UUID]bool)
+	var first []roleSource
+	for _, source := range sources {
+		if seen[source.Role.ID] {
+			continue
+		}
+		seen[source.Role.ID] = true
+		first = append(first, source)
+	}
+	return first
+}
+
+// grantsForSources returns the grants of the user's roles, direct grants before inherited
+// ones, each group sorted by permission name
+func (r *grantResolver) grantsForSources(sources []roleSource) ([]permissionGrant, error) {
+	var grants []permissionGrant
+	for _, source := range firstSourcePerRole(sources) {
+		assignedRole := source.Role
+		ancestorIDs, err := r.roleRepo.FindAncestorIDs([]uuid.UUID{assignedRole.ID})
+		if err != nil {
+			return nil, err
+		}
+
+		roles, err := r.roleRepo.FindByIDs(append([]uuid.UUID{assignedRole.ID}, ancestorIDs...))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, role := range roles {
+			for _, p := range role.Permissions {
+				grants = append(grants, permissionGrant{Permission: p, Role: role, AssignedRole: assignedRole, Groups: source.Groups})
+			}
+		}
+	}
+
+	sort.SliceStable(grants, func(i, j int) bool {
+		if grants[i].Inherited() != grants[j].Inherited() {
+			return !grants[i].Inherited()
+		}
+		return grants[i].Permission.Name < grants[j].Permission.Name
+	})
+	return grants, nil
+}
+
+// permissionDenial is one explicit deny withheld from a user, either on the user itself
+// (Role is nil) or through one of its roles
+type permissionDenial struct {
+	Permission   *entities.Permission
+	Role         *entities.Role
+	AssignedRole *entities.Role
+	Groups       []*entities.Group
+}
+
+// denialsForUser returns the user's own denies followed by those of its roles and their ancestors
+func (r *grantResolver) denialsForUser(user *entities.User, sources []roleSource) ([]permissionDenial, error) {
+	var denials []permissionDenial
+	for _, p := range user.DeniedPermissions {
+		denials = append(denials, permissionDenial{Permission: p})
+	}
+
+	for _, source := range firstSourcePerRole(sources) {
+		assignedRole := source.Role
+		ancestorIDs, err := r.roleRepo.FindAncestorIDs([]uuid.UUID{assignedRole.ID})
+		if err != nil {
+			return nil, err
+		}
+
+		roles, err := r.roleRepo.FindByIDs(append([]uuid.UUID{assignedRole.ID}, ancestorIDs...))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, role := range roles {
+			for _, p := range role.DeniedPermissions {
+				denials = append(denials, permissionDenial{Permission: p, Role: role, AssignedRole: assignedRole, Groups: source.Groups})
+			}
+		}
+	}
+	return denials, nil
+}
+
+// matchDenial returns the first deny covering required, directly or through a wildcard
+func matchDenial(denials []permissionDenial, required string) *permissionDenial {
+	for i := range denials {
+		if permission.Match(denials[i].Permission.Name, required) {
+			return &denials[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+// sourceNames lists the roles of the sources as "role via group > group", sorted
+func sourceNames(sources []roleSource) []string {
+	var names []string
+	for _, source := range sources {
+		name := source.Role.Name
+		for i, group := range source.Groups {
+			if i == 0 {
+				name += " via "
+			} else {
+				name += " > "
+			}
+			name += group.Name
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRoleSourcesForUserKeepsGroupsOfTheOrganization(t *testing.T) {
+	roles := newFakeRoleRepository()
+	groups := newFakeGroupRepository(roles)
+	resolver := &grantResolver{roleRepo: roles, groupRepo: groups}
+	orgA, orgB := uuid.New(), uuid.New()
+
+	admin := roles.add("admin")
+	viewer := roles.add("viewer")
+	billing := roles.add("billing")
+	user := &entities.User{ID: uuid.New()}
+
+	// A tenant group of org A carrying the shared admin role, nested in a shared group
+	ops := groups.add("ops", &orgA, admin)
+	staff := groups.add("staff", nil, viewer)
+	groups.nest(ops, staff)
+	// A shared group nested in a tenant group of org B
+	everyone := groups.add("everyone", nil)
+	bTeam := groups.add("b team", &orgB, billing)
+	groups.nest(everyone, bTeam)
+	groups.AddMembers(ops.ID, []uuid.UUID{user.ID})
+	groups.AddMembers(everyone.ID, []uuid.UUID{user.ID})
+
+	tests := []struct {
+		name           string
+		organizationID *uuid.UUID
+		want           []string
+	}{
+		{"org A", &orgA, []string{"admin via ops", "viewer via ops > staff"}},
+		{"org B", &orgB, []string{"billing via everyone > b team"}},
+		{"no organization", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := resolver.roleSourcesForUser(user, tt.organizationID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sourceNames(sources); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("roleSourcesForUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleSourcesForUserListsDirectRolesFirst(t *testing.T) {
+	roles := newFakeRoleRepository()
+	groups := newFakeGroupRepository(roles)
+	resolver := &grantResolver{roleRepo: roles, groupRepo: groups}
+	organizationID := uuid.New()
+
+	admin := roles.add("admin")
+	viewer := roles.add("viewer")
+	user := &entities.User{ID: uuid.New(), RoleAssignments: []*entities.UserRole{
+		{RoleID: viewer.ID, Role: viewer},
+		{RoleID: admin.ID, Role: admin, OrganizationID: uuid.New()},
+	}}
+	group := groups.add("viewers", &organizationID, viewer)
+	groups.AddMembers(group.ID, []uuid.UUID{user.ID})
+
+	sources, err := resolver.roleSourcesForUser(user, &organizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 || sources[0].Groups != nil || sources[1].Groups[0].ID != group.ID {
+		t.Fatalf("roleSourcesForUser() = %v, want the direct viewer assignment, then the group", sourceNames(sources))
+	}
+	if first := firstSourcePerRole(sources); len(first) != 1 || first[0].Groups != nil {
+		t.Errorf("firstSourcePerRole() = %v, want the direct assignment only", sourceNames(first))
+	}
+}
@@ -1,39 +1,49 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 	"usermanagement-api/domain/entities"
 	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/permission"
 
 	"github.com/google/uuid"
 )
 
 type PermissionUseCase interface {
-	Create(req *dto.CreatePermissionRequest) (*dto.PermissionResponse, error)
+	Create(ctx context.Context, req *dto.CreatePermissionRequest) (*dto.PermissionResponse, error)
 	GetByID(id uuid.UUID) (*dto.PermissionResponse, error)
 	GetAll(page, pageSize int) ([]*dto.PermissionResponse, int64, error)
-	Update(id uuid.UUID, req *dto.UpdatePermissionRequest) (*dto.PermissionResponse, error)
-	Delete(id uuid.UUID) error
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdatePermissionRequest) (*dto.PermissionResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type permissionUseCase struct {
 	permissionRepo repositories.PermissionRepository
+	audit          AuditUseCase
 }
 
-func NewPermissionUseCase(permissionRepo repositories.PermissionRepository) PermissionUseCase {
+func NewPermissionUseCase(permissionRepo repositories.PermissionRepository, audit AuditUseCase) PermissionUseCase {
 	return &permissionUseCase{
 		permissionRepo: permissionRepo,
+		audit:          audit,
 	}
 }
 
-func (uc *permissionUseCase) Create(req *dto.CreatePermissionRequest) (*dto.PermissionResponse, error) {
+func (uc *permissionUseCase) Create(ctx context.Context, req *dto.CreatePermissionRequest) (*dto.PermissionResponse, error) {
 	// Check if permission name already exists
 	if _, err := uc.permissionRepo.FindByName(req.Name); err == nil {
 		return nil, errors.New("permission name already exists")
 	}
 
+	if err := uc.validateName(req.Name); err != nil {
+		return nil, err
+	}
+
 	// Create permission
 	permission := &entities.Permission{
 		Name:        req.Name,
@@ -45,7 +55,10 @@ func (uc *permissionUseCase) Create(req *dto.CreatePermissionRequest) (*dto.Perm
 		return nil, err
 	}
 
-	return uc.mapToPermissionResponse(permission), nil
+	response := uc.mapToPermissionResponse(permission)
+	uc.audit.Record(ctx, constants.AuditActionPermissionCreate, constants.AuditTargetPermission, permission.ID.String(), nil, response)
+
+	return response, nil
 }
 
 func (uc *permissionUseCase) GetByID(id uuid.UUID) (*dto.PermissionResponse, error) {
@@ -71,11 +84,12 @@ func (uc *permissionUseCase) GetAll(page, pageSize int) ([]*dto.PermissionRespon
 	return response, total, nil
 }
 
-func (uc *permissionUseCase) Update(id uuid.UUID, req *dto.UpdatePermissionRequest) (*dto.PermissionResponse, error) {
+func (uc *permissionUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdatePermissionRequest) (*dto.PermissionResponse, error) {
 	permission, err := uc.permissionRepo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
+	before := uc.mapToPermissionResponse(permission)
 
 	// Update fields if provided
 	if req.Name != "" && req.Name != permission.Name {
@@ -83,6 +97,9 @@ func (uc *permissionUseCase) Update(id uuid.UUID, req *dto.UpdatePermissionReque
 		if existingPermission, err := uc.permissionRepo.FindByName(req.Name); err == nil && existingPermission.ID != id {
 			return nil, errors.New("permission name already exists")
 		}
+		if err := uc.validateName(req.Name); err != nil {
+			return nil, err
+		}
 		permission.Name = req.Name
 	}
 
@@ -95,11 +112,46 @@ func (uc *permissionUseCase) Update(id uuid.UUID, req *dto.UpdatePermissionReque
 		return nil, err
 	}
 
-	return uc.mapToPermissionResponse(permission), nil
+	response := uc.mapToPermissionResponse(permission)
+	uc.audit.Record(ctx, constants.AuditActionPermissionUpdate, constants.AuditTargetPermission, id.String(), before, response)
+
+	return response, nil
+}
+
+func (uc *permissionUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	permission, err := uc.permissionRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.permissionRepo.Delete(id); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionPermissionDelete, constants.AuditTargetPermission, id.String(), uc.mapToPermissionResponse(permission), nil)
+	return nil
 }
 
-func (uc *permissionUseCase) Delete(id uuid.UUID) error {
-	return uc.permissionRepo.Delete(id)
+// validateName rejects malformed names and wildcard grants that would not cover any existing permission
+func (uc *permissionUseCase) validateName(name string) error {
+	if err := permission.Validate(name); err != nil {
+		return err
+	}
+	if !permission.IsWildcard(name) {
+		return nil
+	}
+
+	names, err := uc.permissionRepo.FindAllNames()
+	if err != nil {
+		return err
+	}
+	for _, existing := range names {
+		if !permission.IsWildcard(existing) && permission.Match(name, existing) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("wildcard permission %q does not match any existing permission", name)
 }
 
 func (uc *permissionUseCase) mapToPermissionResponse(permission *entities.Permission) *dto.PermissionResponse {
@@ -0,0 +1,432 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/policy"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// policyCacheTTL bounds how long other instances keep serving a changed policy set
+const policyCacheTTL = 30 * time.Second
+
+type PolicyUseCase interface {
+	Create(ctx context.Context, req *dto.CreatePolicyRequest) (*dto.PolicyResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.PolicyResponse, error)
+	GetAll(ctx context.Context, page, pageSize int) ([]*dto.PolicyResponse, int64, error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdatePolicyRequest) (*dto.PolicyResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Evaluate(ctx context.Context, req *dto.EvaluatePolicyRequest) (*policy.Result, error)
+	Decide(ctx context.Context, action string, resource dto.PolicyResource) (*policy.Result, error)
+	DecideFor(ctx context.Context, userID uuid.UUID, action string, resource dto.PolicyResource) (*policy.Result, error)
+}
+
+type policyUseCase struct {
+	policyRepo   repositories.AccessPolicyRepository
+	userRepo     repositories.UserRepository
+	roleRepo     repositories.RoleRepository
+	userMetaRepo repositories.UserMetaRepository
+	metaAccess   *metaAccess
+	audit        AuditUseCase
+
+	mu       sync.Mutex
+	cached   []*entities.AccessPolicy
+	cachedAt time.Time
+}
+
+func NewPolicyUseCase(
+	policyRepo repositories.AccessPolicyRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	userMetaRepo repositories.UserMetaRepository,
+	metaKeyPolicyRepo repositories.UserMetaKeyPolicyRepository,
+	audit AuditUseCase,
+) PolicyUseCase {
+	return &policyUseCase{
+		policyRepo:   policyRepo,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		userMetaRepo: userMetaRepo,
+		metaAccess:   &metaAccess{policyRepo: metaKeyPolicyRepo},
+		audit:        audit,
+	}
+}
+
+func (uc *policyUseCase) Create(ctx context.Context, req *dto.CreatePolicyRequest) (*dto.PolicyResponse, error) {
+	// Callers acting in an organization create policies that only apply there
+	entity := &entities.AccessPolicy{
+		Name:           req.Name,
+		Description:    req.Description,
+		Effect:         req.Effect,
+		Actions:        req.Actions,
+		Conditions:     toEntityConditions(req.Conditions),
+		Enabled:        req.Enabled == nil || *req.Enabled,
+		OrganizationID: activeTenant(ctx),
+	}
+	if _, err := uc.policyRepo.WithContext(ctx).FindByName(req.Name, entity.OrganizationID); err == nil {
+		return nil, errors.New("policy name already exists")
+	}
+	if err := policy.Validate(toPolicy(entity)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.policyRepo.Create(entity); err != nil {
+		return nil, err
+	}
+	uc.invalidate()
+
+	response := uc.mapToPolicyResponse(entity)
+	uc.audit.Record(ctx, constants.AuditActionPolicyCreate, constants.AuditTargetPolicy, entity.ID.String(), nil, response)
+
+	return response, nil
+}
+
+func (uc *policyUseCase) GetByID(ctx context.Context, id uuid.UUID) (*dto.PolicyResponse, error) {
+	entity, err := uc.policyRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.mapToPolicyResponse(entity), nil
+}
+
+func (uc *policyUseCase) GetAll(ctx context.Context, page, pageSize int) ([]*dto.PolicyResponse, int64, error) {
+	policies, total, err := uc.policyRepo.WithContext(ctx).FindAll(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response []*dto.PolicyResponse
+	for _, entity := range policies {
+		response = append(response, uc.mapToPolicyResponse(entity))
+	}
+
+	return response, total, nil
+}
+
+func (uc *policyUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdatePolicyRequest) (*dto.PolicyResponse, error) {
+	entity, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := uc.mapToPolicyResponse(entity)
+
+	// Update fields if provided
+	if req.Name != "" && req.Name != entity.Name {
+		if existing, err := uc.policyRepo.WithContext(ctx).FindByName(req.Name, entity.OrganizationID); err == nil && existing.ID != id {
+			return nil, errors.New("policy name already exists")
+		}
+		entity.Name = req.Name
+	}
+	if req.Description != nil {
+		entity.Description = *req.Description
+	}
+	if req.Effect != "" {
+		entity.Effect = req.Effect
+	}
+	if req.Actions != nil {
+		entity.Actions = req.Actions
+	}
+	if req.Conditions != nil {
+		entity.Conditions = toEntityConditions(req.Conditions)
+	}
+	if req.Enabled != nil {
+		entity.Enabled = *req.Enabled
+	}
+
+	if err := policy.Validate(toPolicy(entity)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.policyRepo.Update(entity); err != nil {
+		return nil, err
+	}
+	uc.invalidate()
+
+	response := uc.mapToPolicyResponse(entity)
+	uc.audit.Record(ctx, constants.AuditActionPolicyUpdate, constants.AuditTargetPolicy, id.String(), before, response)
+
+	return response, nil
+}
+
+func (uc *policyUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	entity, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.policyRepo.Delete(id); err != nil {
+		return err
+	}
+	uc.invalidate()
+
+	uc.audit.Record(ctx, constants.AuditActionPolicyDelete, constants.AuditTargetPolicy, id.String(), uc.mapToPolicyResponse(entity), nil)
+	return nil
+}
+
+// Evaluate runs sample input through a stored policy, an unsaved one, or every enabled policy
+// that applies in the caller's organization. Nothing is persisted.
+func (uc *policyUseCase) Evaluate(ctx context.Context, req *dto.EvaluatePolicyRequest) (*policy.Result, error) {
+	var policies []policy.Policy
+	switch {
+	case req.PolicyID != nil:
+		entity, err := uc.policyRepo.WithContext(ctx).FindByID(*req.PolicyID)
+		if err != nil {
+			return nil, err
+		}
+		policies = []policy.Policy{toPolicy(entity)}
+	case req.Policy != nil:
+		p := policy.Policy{
+			Name:       req.Policy.Name,
+			Effect:     policy.Effect(req.Policy.Effect),
+			Actions:    req.Policy.Actions,
+			Conditions: req.Policy.Conditions,
+		}
+		if err := policy.Validate(p); err != nil {
+			return nil, err
+		}
+		policies = []policy.Policy{p}
+	default:
+		var err error
+		if policies, err = uc.enabledPolicies(requestctx.FromContext(ctx).OrganizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := policy.Evaluate(policies, req.Input)
+	return &result, nil
+}
+
+// Decide evaluates the enabled policies for the caller in ctx. Subject and resource attributes
+// are only loaded when at least one policy targets the action.
+func (uc *policyUseCase) Decide(ctx context.Context, action string, resource dto.PolicyResource) (*policy.Result, error) {
+	return uc.decide(ctx, requestctx.FromContext(ctx).UserID, action, resource)
+}
+
+// DecideFor is Decide with another user as the subject, the environment still comes from ctx
+func (uc *policyUseCase) DecideFor(ctx context.Context, userID uuid.UUID, action string, resource dto.PolicyResource) (*policy.Result, error) {
+	return uc.decide(ctx, &userID, action, resource)
+}
+
+func (uc *policyUseCase) decide(ctx context.Context, userID *uuid.UUID, action string, resource dto.PolicyResource) (*policy.Result, error) {
+	info := requestctx.FromContext(ctx)
+	policies, err := uc.enabledPolicies(info.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var applicable []policy.Policy
+	for _, p := range policies {
+		if p.Applies(action) {
+			applicable = append(applicable, p)
+		}
+	}
+	if len(applicable) == 0 {
+		return &policy.Result{Decision: policy.DecisionNotApplicable, Policies: []policy.PolicyResult{}}, nil
+	}
+
+	input := policy.Input{
+		Action:      action,
+		Subject:     map[string]interface{}{},
+		Resource:    uc.resourceAttributes(resource, info.OrganizationID),
+		Environment: environmentAttributes(info, time.Now()),
+	}
+	if userID != nil {
+		if input.Subject, err = uc.userAttributes(*userID, info.OrganizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := policy.Evaluate(applicable, input)
+	return &result, nil
+}
+
+// enabledPolicies returns the enabled policies that apply in the organization: the shared ones
+// and the organization's own
+func (uc *policyUseCase) enabledPolicies(organizationID *uuid.UUID) ([]policy.Policy, error) {
+	stored, err := uc.enabledEntities()
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]policy.Policy, 0, len(stored))
+	for _, entity := range stored {
+		if entity.AppliesIn(organizationID) {
+			policies = append(policies, toPolicy(entity))
+		}
+	}
+	return policies, nil
+}
+
+// enabledEntities returns the enabled policies of every organization, cached for policyCacheTTL
+func (uc *policyUseCase) enabledEntities() ([]*entities.AccessPolicy, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.cached != nil && time.Since(uc.cachedAt) < policyCacheTTL {
+		return uc.cached, nil
+	}
+
+	stored, err := uc.policyRepo.FindEnabled()
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		stored = []*entities.AccessPolicy{}
+	}
+	uc.cached = stored
+	uc.cachedAt = time.Now()
+
+	return stored, nil
+}
+
+// findManageable loads a policy the caller may change. Shared policies are read-only for
+// callers acting in an organization.
+func (uc *policyUseCase) findManageable(ctx context.Context, id uuid.UUID) (*entities.AccessPolicy, error) {
+	entity, err := uc.policyRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if activeTenant(ctx) != nil && entity.OrganizationID == nil {
+		return nil, ErrForbidden
+	}
+	return entity, nil
+}
+
+func (uc *policyUseCase) invalidate() {
+	uc.mu.Lock()
+	uc.cached = nil
+	uc.mu.Unlock()
+}
+
+// userAttributes exposes a user's fields, names of the roles that apply in the organization
+// (including roles held through groups) and trusted meta (see metaAccess.trusted) as policy
+// attributes. An allow policy grants without the role check, so meta users write themselves
+// must not count.
+func (uc *policyUseCase) userAttributes(userID uuid.UUID, organizationID *uuid.UUID) (map[string]interface{}, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	userRoles, err := uc.roleRepo.FindRolesByUserID(userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]interface{}, 0, len(userRoles))
+	for _, role := range rolesInOrganization(userRoles, organizationID) {
+		roles = append(roles, role.Name)
+	}
+
+	metas, err := uc.userMetaRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := uc.metaAccess.trusted(userID, metas)
+	if err != nil {
+		return nil, err
+	}
+	metaAttributes := make(map[string]interface{}, len(meta))
+	for key, value := range meta {
+		metaAttributes[key] = value
+	}
+
+	return map[string]interface{}{
+		"id":           user.ID.String(),
+		"username":     user.Username,
+		"email":        user.Email,
+		"first_name":   user.FirstName,
+		"last_name":    user.LastName,
+		"is_active":    user.IsActive,
+		"is_superuser": user.IsSuperuser,
+		"roles":        roles,
+		"meta":         metaAttributes,
+	}, nil
+}
+
+func (uc *policyUseCase) resourceAttributes(resource dto.PolicyResource, organizationID *uuid.UUID) map[string]interface{} {
+	attributes := map[string]interface{}{"type": resource.Type}
+	if resource.ID == "" {
+		return attributes
+	}
+	attributes["id"] = resource.ID
+
+	// Users are the one resource type with attributes worth comparing against
+	if resource.Type == "users" {
+		if id, err := uuid.Parse(resource.ID); err == nil {
+			if user, err := uc.userAttributes(id, organizationID); err == nil {
+				for key, value := range user {
+					attributes[key] = value
+				}
+			}
+		}
+	}
+	return attributes
+}
+
+func environmentAttributes(info *requestctx.Info, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"time":    now.UTC().Format(time.RFC3339),
+		"hour":    now.UTC().Hour(),
+		"weekday": strings.ToLower(now.UTC().Weekday().String()),
+		"ip":      info.IP,
+	}
+}
+
+func toPolicy(entity *entities.AccessPolicy) policy.Policy {
+	conditions := make([]policy.Condition, 0, len(entity.Conditions))
+	for _, c := range entity.Conditions {
+		conditions = append(conditions, policy.Condition{
+			Attribute: c.Attribute,
+			Operator:  c.Operator,
+			Value:     c.Value,
+			ValueFrom: c.ValueFrom,
+		})
+	}
+
+	return policy.Policy{
+		ID:         entity.ID.String(),
+		Name:       entity.Name,
+		Effect:     policy.Effect(entity.Effect),
+		Actions:    entity.Actions,
+		Conditions: conditions,
+	}
+}
+
+func toEntityConditions(conditions []policy.Condition) []entities.AccessPolicyCondition {
+	result := make([]entities.AccessPolicyCondition, 0, len(conditions))
+	for _, c := range conditions {
+		result = append(result, entities.AccessPolicyCondition{
+			Attribute: c.Attribute,
+			Operator:  c.Operator,
+			Value:     c.Value,
+			ValueFrom: c.ValueFrom,
+		})
+	}
+	return result
+}
+
+func (uc *policyUseCase) mapToPolicyResponse(entity *entities.AccessPolicy) *dto.PolicyResponse {
+	return &dto.PolicyResponse{
+		ID:          entity.ID,
+		Name:        entity.Name,
+		Description: entity.Description,
+		Effect:      entity.Effect,
+		Actions:     entity.Actions,
+		Conditions:  toPolicy(entity).Conditions,
+		Enabled:     entity.Enabled,
+		CreatedAt:   entity.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   entity.UpdatedAt.Format(time.RFC3339),
+
+		OrganizationID: entity.OrganizationID,
+	}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/policy"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// tenantContext returns a request context for a tenant-scoped caller acting in the organization
+func tenantContext(organizationID *uuid.UUID) context.Context {
+	userID := uuid.New()
+	return requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &userID, OrganizationID: organizationID})
+}
+
+func newTestPolicyUseCase(policies *fakeAccessPolicyRepository) *policyUseCase {
+	return &policyUseCase{policyRepo: policies, audit: &fakeAudit{}}
+}
+
+func denyPolicy(name string, organizationID *uuid.UUID) *entities.AccessPolicy {
+	return &entities.AccessPolicy{
+		ID:             uuid.New(),
+		Name:           name,
+		Effect:         string(policy.EffectDeny),
+		Actions:        []string{"users.delete"},
+		Enabled:        true,
+		OrganizationID: organizationID,
+	}
+}
+
+func TestPolicyCreateBelongsToTheCallersOrganization(t *testing.T) {
+	policies := &fakeAccessPolicyRepository{}
+	uc := newTestPolicyUseCase(policies)
+	organizationID := uuid.New()
+	req := &dto.CreatePolicyRequest{Name: "no deletes", Effect: string(policy.EffectDeny), Actions: []string{"users.delete"}}
+
+	resp, err := uc.Create(tenantContext(&organizationID), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.OrganizationID == nil || *resp.OrganizationID != organizationID {
+		t.Errorf("OrganizationID = %v, want %s", resp.OrganizationID, organizationID)
+	}
+
+	superuser := requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &organizationID, IsSuperuser: true, OrganizationID: &organizationID})
+	req.Name = "shared"
+	if resp, err := uc.Create(superuser, req); err != nil || resp.OrganizationID != nil {
+		t.Errorf("superuser Create() = %v, %v, want a shared policy", resp, err)
+	}
+}
+
+func TestPolicyCreateRejectsNamesVisibleInTheOrganization(t *testing.T) {
+	organizationID := uuid.New()
+	policies := &fakeAccessPolicyRepository{policies: []*entities.AccessPolicy{denyPolicy("shared", nil)}}
+	uc := newTestPolicyUseCase(policies)
+	req := &dto.CreatePolicyRequest{Name: "shared", Effect: string(policy.EffectDeny), Actions: []string{"users.delete"}}
+
+	if _, err := uc.Create(tenantContext(&organizationID), req); err == nil {
+		t.Error("Create() took the name of a shared policy")
+	}
+}
+
+func TestPolicySharedPoliciesAreReadOnlyForTenants(t *testing.T) {
+	organizationID := uuid.New()
+	shared := denyPolicy("shared", nil)
+	own := denyPolicy("own", &organizationID)
+	policies := &fakeAccessPolicyRepository{policies: []*entities.AccessPolicy{shared, own}}
+	uc := newTestPolicyUseCase(policies)
+	ctx := tenantContext(&organizationID)
+	description := "changed"
+
+	if _, err := uc.Update(ctx, shared.ID, &dto.UpdatePolicyRequest{Description: &description}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Update() of a shared policy = %v, want ErrForbidden", err)
+	}
+	if err := uc.Delete(ctx, shared.ID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Delete() of a shared policy = %v, want ErrForbidden", err)
+	}
+	if _, err := uc.Update(ctx, own.ID, &dto.UpdatePolicyRequest{Description: &description}); err != nil {
+		t.Errorf("Update() of the organization's policy: %v", err)
+	}
+	if err := uc.Delete(ctx, own.ID); err != nil {
+		t.Errorf("Delete() of the organization's policy: %v", err)
+	}
+}
+
+func TestPolicyDecideAppliesPoliciesOfTheOrganization(t *testing.T) {
+	orgA, orgB := uuid.New(), uuid.New()
+	policies := &fakeAccessPolicyRepository{policies: []*entities.AccessPolicy{denyPolicy("org A", &orgA)}}
+	uc := newTestPolicyUseCase(policies)
+
+	tests := []struct {
+		name           string
+		organizationID *uuid.UUID
+		want           policy.Decision
+	}{
+		{"in the organization", &orgA, policy.DecisionDeny},
+		{"in another organization", &orgB, policy.DecisionNotApplicable},
+		{"outside any organization", nil, policy.DecisionNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := requestctx.WithInfo(context.Background(), &requestctx.Info{OrganizationID: tt.organizationID})
+			result, err := uc.Decide(ctx, "users.delete", dto.PolicyResource{Type: "users"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Decision != tt.want {
+				t.Errorf("Decide() = %s, want %s", result.Decision, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,518 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoleAssignmentRuleUseCase manages rules that assign roles automatically from user attributes.
+// Assignments made by a rule are tagged with it; manual assignments are never changed.
+type RoleAssignmentRuleUseCase interface {
+	Create(ctx context.Context, req *dto.CreateRoleAssignmentRuleRequest) (*dto.RoleAssignmentRuleResponse, error)
+	GetByID(id uuid.UUID) (*dto.RoleAssignmentRuleResponse, error)
+	GetAll(page, pageSize int) ([]*dto.RoleAssignmentRuleResponse, int64, error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdateRoleAssignmentRuleRequest) (*dto.RoleAssignmentRuleResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// EvaluateUser grants the user the roles of the rules they match and revokes the
+	// rule-made assignments they no longer match
+	EvaluateUser(ctx context.Context, userID uuid.UUID) error
+	// RuleRoles returns the roles the rules would give the user in the given state, with
+	// metaChanges applied on top of their stored meta. The user need not be saved yet.
+	RuleRoles(ctx context.Context, user *entities.User, metaChanges map[string]string) ([]uuid.UUID, error)
+	// Sync evaluates every user
+	Sync(ctx context.Context) (*dto.RoleAssignmentRuleSyncResponse, error)
+}
+
+type roleAssignmentRuleUseCase struct {
+	ruleRepo         repositories.RoleAssignmentRuleRepository
+	userRepo         repositories.UserRepository
+	roleRepo         repositories.RoleRepository
+	userMetaRepo     repositories.UserMetaRepository
+	organizationRepo repositories.OrganizationRepository
+	metaAccess       *metaAccess
+	duties           *separationOfDuties
+	audit            AuditUseCase
+
+	// syncMu serializes syncs, syncQueued is set while a requested sync has not started yet
+	syncMu     sync.Mutex
+	syncQueued atomic.Bool
+}
+
+func NewRoleAssignmentRuleUseCase(
+	ruleRepo repositories.RoleAssignmentRuleRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	userMetaRepo repositories.UserMetaRepository,
+	metaKeyPolicyRepo repositories.UserMetaKeyPolicyRepository,
+	organizationRepo repositories.OrganizationRepository,
+	roleConstraintRepo repositories.RoleConstraintRepository,
+	audit AuditUseCase,
+) RoleAssignmentRuleUseCase {
+	return &roleAssignmentRuleUseCase{
+		ruleRepo:         ruleRepo,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		userMetaRepo:     userMetaRepo,
+		organizationRepo: organizationRepo,
+		metaAccess:       &metaAccess{policyRepo: metaKeyPolicyRepo},
+		duties:           &separationOfDuties{constraintRepo: roleConstraintRepo, userRepo: userRepo, roleRepo: roleRepo},
+		audit:            audit,
+	}
+}
+
+// Create saves the rule and queues applying it to every user
+func (uc *roleAssignmentRuleUseCase) Create(ctx context.Context, req *dto.CreateRoleAssignmentRuleRequest) (*dto.RoleAssignmentRuleResponse, error) {
+	if _, err := uc.ruleRepo.FindByName(req.Name); err == nil {
+		return nil, errors.New("rule name already exists")
+	}
+
+	rule := &entities.RoleAssignmentRule{
+		Name:        req.Name,
+		Description: req.Description,
+		Attribute:   req.Attribute,
+		Values:      req.Values,
+		RoleID:      req.RoleID,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+	if err := uc.validate(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := uc.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+
+	saved, err := uc.ruleRepo.FindByID(rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToRuleResponse(saved)
+	uc.audit.Record(ctx, constants.AuditActionRoleRuleCreate, constants.AuditTargetRoleRule, rule.ID.String(), nil, response)
+
+	uc.syncAfterChange(ctx, rule.Name)
+	return response, nil
+}
+
+func (uc *roleAssignmentRuleUseCase) GetByID(id uuid.UUID) (*dto.RoleAssignmentRuleResponse, error) {
+	rule, err := uc.ruleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.mapToRuleResponse(rule), nil
+}
+
+func (uc *roleAssignmentRuleUseCase) GetAll(page, pageSize int) ([]*dto.RoleAssignmentRuleResponse, int64, error) {
+	rules, total, err := uc.ruleRepo.FindAll(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response []*dto.RoleAssignmentRuleResponse
+	for _, rule := range rules {
+		response = append(response, uc.mapToRuleResponse(rule))
+	}
+
+	return response, total, nil
+}
+
+// Update saves the rule and queues re-evaluating every user, so users who stop matching lose the role
+func (uc *roleAssignmentRuleUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdateRoleAssignmentRuleRequest) (*dto.RoleAssignmentRuleResponse, error) {
+	rule, err := uc.ruleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := uc.mapToRuleResponse(rule)
+
+	// Update fields if provided
+	if req.Name != "" && req.Name != rule.Name {
+		if existing, err := uc.ruleRepo.FindByName(req.Name); err == nil && existing.ID != id {
+			return nil, errors.New("rule name already exists")
+		}
+		rule.Name = req.Name
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if req.Attribute != "" {
+		rule.Attribute = req.Attribute
+	}
+	if req.Values != nil {
+		rule.Values = req.Values
+	}
+	if req.RoleID != nil {
+		rule.RoleID = *req.RoleID
+		rule.Role = nil
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := uc.validate(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := uc.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.ruleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToRuleResponse(updated)
+	uc.audit.Record(ctx, constants.AuditActionRoleRuleUpdate, constants.AuditTargetRoleRule, id.String(), before, response)
+
+	uc.syncAfterChange(ctx, rule.Name)
+	return response, nil
+}
+
+// Delete removes the rule and revokes the assignments it made, unless another rule grants the same role
+func (uc *roleAssignmentRuleUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	rule, err := uc.ruleRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	userIDs, err := uc.ruleRepo.FindUserIDsByRule(id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.ruleRepo.Delete(id); err != nil {
+		return err
+	}
+	uc.audit.Record(ctx, constants.AuditActionRoleRuleDelete, constants.AuditTargetRoleRule, id.String(), uc.mapToRuleResponse(rule), nil)
+
+	rules, err := uc.ruleRepo.FindEnabled()
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if _, _, err := uc.evaluate(ctx, rules, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *roleAssignmentRuleUseCase) EvaluateUser(ctx context.Context, userID uuid.UUID) error {
+	rules, err := uc.ruleRepo.FindEnabled()
+	if err != nil {
+		return err
+	}
+
+	_, _, err = uc.evaluate(ctx, rules, userID)
+	return err
+}
+
+func (uc *roleAssignmentRuleUseCase) Sync(ctx context.Context) (*dto.RoleAssignmentRuleSyncResponse, error) {
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+	// This sync sees every rule change made so far
+	uc.syncQueued.Store(false)
+
+	rules, err := uc.ruleRepo.FindEnabled()
+	if err != nil {
+		return nil, err
+	}
+	userIDs, err := uc.userRepo.FindAllIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.RoleAssignmentRuleSyncResponse{}
+	for _, userID := range userIDs {
+		granted, revoked, err := uc.evaluate(ctx, rules, userID)
+		if err != nil {
+			return response, err
+		}
+		response.EvaluatedUsers++
+		response.Granted += granted
+		response.Revoked += revoked
+	}
+	return response, nil
+}
+
+// syncAfterChange applies a changed rule to every user in the background, a sync already queued
+// covers it. A failure does not undo the change, the periodic sync catches up.
+func (uc *roleAssignmentRuleUseCase) syncAfterChange(ctx context.Context, name string) {
+	if !uc.syncQueued.CompareAndSwap(false, true) {
+		return
+	}
+
+	// Keep the caller for the audit trail, but outlive the request
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if _, err := uc.Sync(ctx); err != nil {
+			log.Printf("Failed to apply role assignment rule %s: %v", name, err)
+		}
+	}()
+}
+
+// evaluate brings the rule-made assignments of one user in line with the rules they match.
+// When several rules grant the same role, the assignment is tagged with the first by name.
+// Roles the user holds manually are left alone, and roles conflicting with a separation-of-duties
+// constraint are skipped.
+func (uc *roleAssignmentRuleUseCase) evaluate(ctx context.Context, rules []*entities.RoleAssignmentRule, userID uuid.UUID) (int, int, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	meta, err := uc.ruleMeta(userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	wanted, err := uc.matchingRules(ctx, rules, user, meta)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	assignments, err := uc.userRepo.FindRoleAssignments(userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	now := time.Now()
+	granted, revoked := 0, 0
+	held := make(map[uuid.UUID]bool)
+	for _, assignment := range assignments {
+		// Rules assign roles outside any organization, tenant roles still only apply in their own
+		if assignment.OrganizationID != uuid.Nil {
+			continue
+		}
+		held[assignment.RoleID] = true
+		if assignment.RuleID == nil {
+			continue
+		}
+
+		rule, ok := wanted[assignment.RoleID]
+		if !ok {
+			if err := uc.userRepo.DeleteRoleAssignment(userID, assignment.RoleID, uuid.Nil); err != nil {
+				return granted, revoked, err
+			}
+			uc.audit.Record(ctx, constants.AuditActionUserAutoRevokeRole, constants.AuditTargetUser, userID.String(), mapToRoleAssignmentResponse(assignment, now), nil)
+			revoked++
+			continue
+		}
+		if *assignment.RuleID != rule.ID {
+			assignment.RuleID = &rule.ID
+			assignment.Reason = ruleReason(rule)
+			if err := uc.userRepo.SaveRoleAssignment(assignment); err != nil {
+				return granted, revoked, err
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		if wanted[rule.RoleID] != rule || held[rule.RoleID] {
+			continue
+		}
+
+		if err := uc.duties.checkAddedRoles(userID, []uuid.UUID{rule.RoleID}); err != nil {
+			var conflict *RoleConflictError
+			if errors.As(err, &conflict) {
+				log.Printf("Role assignment rule %s skipped for user %s: %v", rule.Name, userID, err)
+				continue
+			}
+			return granted, revoked, err
+		}
+
+		ruleID := rule.ID
+		assignment := &entities.UserRole{
+			UserID:    userID,
+			RoleID:    rule.RoleID,
+			GrantedAt: now,
+			Reason:    ruleReason(rule),
+			RuleID:    &ruleID,
+		}
+		if err := uc.userRepo.SaveRoleAssignment(assignment); err != nil {
+			return granted, revoked, err
+		}
+		assignment.Role = rule.Role
+		uc.audit.Record(ctx, constants.AuditActionUserAutoGrantRole, constants.AuditTargetUser, userID.String(), nil, mapToRoleAssignmentResponse(assignment, now))
+		granted++
+	}
+
+	return granted, revoked, nil
+}
+
+func (uc *roleAssignmentRuleUseCase) RuleRoles(ctx context.Context, user *entities.User, metaChanges map[string]string) ([]uuid.UUID, error) {
+	rules, err := uc.ruleRepo.FindEnabled()
+	if err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string]string)
+	if user.ID != uuid.Nil {
+		if meta, err = uc.ruleMeta(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	// Values users write to their own profile never count, see ruleMeta
+	if len(metaChanges) > 0 && !requestctx.FromContext(ctx).IsUser(user.ID) {
+		visibilities, err := uc.metaAccess.visibilities()
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range metaChanges {
+			if visibilities[key] == constants.MetaVisibilityAdminOnly {
+				meta[key] = value
+			}
+		}
+	}
+
+	wanted, err := uc.matchingRules(ctx, rules, user, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]uuid.UUID, 0, len(wanted))
+	for roleID := range wanted {
+		roleIDs = append(roleIDs, roleID)
+	}
+	return roleIDs, nil
+}
+
+// matchingRules returns, for each role the user should get, the first rule by name granting it.
+// Rules for a tenant role only apply to the organization's members, a user not saved yet counts
+// as a member of the organization the caller acts in.
+func (uc *roleAssignmentRuleUseCase) matchingRules(ctx context.Context, rules []*entities.RoleAssignmentRule, user *entities.User, meta map[string]string) (map[uuid.UUID]*entities.RoleAssignmentRule, error) {
+	wanted := make(map[uuid.UUID]*entities.RoleAssignmentRule)
+	for _, rule := range rules {
+		if _, ok := wanted[rule.RoleID]; ok || !ruleMatches(rule, user, meta) {
+			continue
+		}
+		if rule.Role != nil && rule.Role.OrganizationID != nil {
+			var member bool
+			if user.ID == uuid.Nil {
+				tenant := activeTenant(ctx)
+				member = tenant != nil && *tenant == *rule.Role.OrganizationID
+			} else {
+				var err error
+				if member, err = uc.organizationRepo.IsMember(*rule.Role.OrganizationID, user.ID); err != nil {
+					return nil, err
+				}
+			}
+			if !member {
+				continue
+			}
+		}
+		wanted[rule.RoleID] = rule
+	}
+	return wanted, nil
+}
+
+// validate checks the attribute and values, normalizing email domains, and that the role is visible to the caller
+func (uc *roleAssignmentRuleUseCase) validate(ctx context.Context, rule *entities.RoleAssignmentRule) error {
+	switch {
+	case rule.Attribute == constants.RoleRuleAttributeEmailDomain:
+		for i, value := range rule.Values {
+			rule.Values[i] = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "@"))
+		}
+	case strings.HasPrefix(rule.Attribute, constants.RoleRuleAttributeMetaPrefix) && len(rule.Attribute) > len(constants.RoleRuleAttributeMetaPrefix):
+		// Users could grant themselves the role through a key they may write
+		visibilities, err := uc.metaAccess.visibilities()
+		if err != nil {
+			return err
+		}
+		key := strings.TrimPrefix(rule.Attribute, constants.RoleRuleAttributeMetaPrefix)
+		if visibilities[key] != constants.MetaVisibilityAdminOnly {
+			return fmt.Errorf("meta key %s must have %s visibility", key, constants.MetaVisibilityAdminOnly)
+		}
+	default:
+		return fmt.Errorf("attribute must be %s or %s<key>", constants.RoleRuleAttributeEmailDomain, constants.RoleRuleAttributeMetaPrefix)
+	}
+
+	for _, value := range rule.Values {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("values must not be empty")
+		}
+	}
+
+	if _, err := uc.roleRepo.WithContext(ctx).FindByID(rule.RoleID); err != nil {
+		return fmt.Errorf("role %s not found", rule.RoleID)
+	}
+	return nil
+}
+
+// ruleMeta returns the user meta rules may match on, see metaAccess.trusted. The visibility is
+// checked again as it may have changed since the rule was saved.
+func (uc *roleAssignmentRuleUseCase) ruleMeta(userID uuid.UUID) (map[string]string, error) {
+	metas, err := uc.userMetaRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.metaAccess.trusted(userID, metas)
+}
+
+func (uc *roleAssignmentRuleUseCase) mapToRuleResponse(rule *entities.RoleAssignmentRule) *dto.RoleAssignmentRuleResponse {
+	resp := &dto.RoleAssignmentRuleResponse{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Attribute:   rule.Attribute,
+		Values:      rule.Values,
+		Role:        dto.RoleSimple{ID: rule.RoleID},
+		Enabled:     rule.Enabled,
+		CreatedAt:   rule.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   rule.UpdatedAt.Format(time.RFC3339),
+	}
+	if rule.Role != nil {
+		resp.Role.Name = rule.Role.Name
+	}
+	return resp
+}
+
+// ruleMatches reports whether the user's attribute equals one of the rule's values, ignoring case.
+// Email domains only match verified emails, anyone can sign up with any address.
+func ruleMatches(rule *entities.RoleAssignmentRule, user *entities.User, meta map[string]string) bool {
+	var actual string
+	switch {
+	case rule.Attribute == constants.RoleRuleAttributeEmailDomain:
+		at := strings.LastIndex(user.Email, "@")
+		if at < 0 || user.EmailVerifiedAt == nil {
+			return false
+		}
+		actual = user.Email[at+1:]
+	case strings.HasPrefix(rule.Attribute, constants.RoleRuleAttributeMetaPrefix):
+		value, ok := meta[strings.TrimPrefix(rule.Attribute, constants.RoleRuleAttributeMetaPrefix)]
+		if !ok {
+			return false
+		}
+		actual = value
+	default:
+		return false
+	}
+
+	for _, value := range rule.Values {
+		if strings.EqualFold(strings.TrimSpace(actual), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleReason(rule *entities.RoleAssignmentRule) string {
+	return "Granted by role assignment rule " + rule.Name
+}
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/internal/constants"
+
+	"github.com/google/uuid"
+)
+
+func TestRuleMatches(t *testing.T) {
+	verified := time.Now()
+	emailRule := &entities.RoleAssignmentRule{Attribute: constants.RoleRuleAttributeEmailDomain, Values: []string{"example.com", "example.org"}}
+	metaRule := &entities.RoleAssignmentRule{Attribute: "meta.department", Values: []string{" Engineering "}}
+
+	tests := []struct {
+		name string
+		rule *entities.RoleAssignmentRule
+		user *entities.User
+		meta map[string]string
+		want bool
+	}{
+		{"verified email domain", emailRule, &entities.User{Email: "jane@example.org", EmailVerifiedAt: &verified}, nil, true},
+		{"email domain ignores case", emailRule, &entities.User{Email: "jane@EXAMPLE.com", EmailVerifiedAt: &verified}, nil, true},
+		{"unverified email", emailRule, &entities.User{Email: "jane@example.com"}, nil, false},
+		{"other email domain", emailRule, &entities.User{Email: "jane@example.net", EmailVerifiedAt: &verified}, nil, false},
+		{"subdomain", emailRule, &entities.User{Email: "jane@mail.example.com", EmailVerifiedAt: &verified}, nil, false},
+		{"email without domain", emailRule, &entities.User{Email: "jane", EmailVerifiedAt: &verified}, nil, false},
+		{"meta value", metaRule, &entities.User{}, map[string]string{"department": "engineering"}, true},
+		{"other meta value", metaRule, &entities.User{}, map[string]string{"department": "sales"}, false},
+		{"missing meta key", metaRule, &entities.User{}, map[string]string{"team": "engineering"}, false},
+		{"unknown attribute", &entities.RoleAssignmentRule{Attribute: "username", Values: []string{"jane"}}, &entities.User{Username: "jane"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleMatches(tt.rule, tt.user, tt.meta); got != tt.want {
+				t.Errorf("ruleMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type ruleFixture struct {
+	uc            *roleAssignmentRuleUseCase
+	users         *fakeUserRepository
+	roles         *fakeRoleRepository
+	metas         *fakeUserMetaRepository
+	organizations *fakeOrganizationRepository
+	constraints   *fakeRoleConstraintRepository
+	audit         *fakeAudit
+
+	user      *entities.User
+	developer *entities.Role
+}
+
+// newRuleFixture has a user with a verified example.com address and no roles
+func newRuleFixture() *ruleFixture {
+	verified := time.Now()
+	f := &ruleFixture{
+		users:         newFakeUserRepository(),
+		roles:         newFakeRoleRepository(),
+		metas:         &fakeUserMetaRepository{metas: make(map[uuid.UUID][]*entities.UserMeta)},
+		organizations: newFakeOrganizationRepository(),
+		constraints:   &fakeRoleConstraintRepository{},
+		audit:         &fakeAudit{},
+		user:          &entities.User{ID: uuid.New(), Email: "jane@example.com", EmailVerifiedAt: &verified},
+	}
+	policies := &fakeUserMetaKeyPolicyRepository{policies: []*entities.UserMetaKeyPolicy{
+		{Key: "department", Visibility: constants.MetaVisibilityAdminOnly},
+	}}
+	f.uc = &roleAssignmentRuleUseCase{
+		userRepo:         f.users,
+		roleRepo:         f.roles,
+		userMetaRepo:     f.metas,
+		organizationRepo: f.organizations,
+		metaAccess:       &metaAccess{policyRepo: policies},
+		duties:           &separationOfDuties{constraintRepo: f.constraints, userRepo: f.users, roleRepo: f.roles},
+		audit:            f.audit,
+	}
+	f.users.users[f.user.ID] = f.user
+	f.developer = f.roles.add("developer")
+	return f
+}
+
+// rule returns an enabled rule granting role to users with an example.com address
+func (f *ruleFixture) rule(name string, role *entities.Role) *entities.RoleAssignmentRule {
+	return &entities.RoleAssignmentRule{
+		ID:        uuid.New(),
+		Name:      name,
+		Attribute: constants.RoleRuleAttributeEmailDomain,
+		Values:    []string{"example.com"},
+		RoleID:    role.ID,
+		Role:      role,
+		Enabled:   true,
+	}
+}
+
+func (f *ruleFixture) assign(role *entities.Role, rule *entities.RoleAssignmentRule, organizationID uuid.UUID) {
+	assignment := &entities.UserRole{UserID: f.user.ID, RoleID: role.ID, OrganizationID: organizationID}
+	if rule != nil {
+		assignment.RuleID = &rule.ID
+	}
+	f.users.assignments[f.user.ID] = append(f.users.assignments[f.user.ID], assignment)
+}
+
+func (f *ruleFixture) evaluate(t *testing.T, rules ...*entities.RoleAssignmentRule) (int, int) {
+	t.Helper()
+	granted, revoked, err := f.uc.evaluate(context.Background(), rules, f.user.ID)
+	if err != nil {
+		t.Fatalf("evaluate() error = %v", err)
+	}
+	return granted, revoked
+}
+
+// ruleIDs returns the rule tagging each of the user's assignments outside any organization
+func (f *ruleFixture) ruleIDs() map[uuid.UUID]*uuid.UUID {
+	tags := make(map[uuid.UUID]*uuid.UUID)
+	for _, assignment := range f.users.assignments[f.user.ID] {
+		if assignment.OrganizationID == uuid.Nil {
+			tags[assignment.RoleID] = assignment.RuleID
+		}
+	}
+	return tags
+}
+
+func TestRoleAssignmentRuleEvaluateGrants(t *testing.T) {
+	f := newRuleFixture()
+	rule := f.rule("developers", f.developer)
+
+	if granted, revoked := f.evaluate(t, rule); granted != 1 || revoked != 0 {
+		t.Fatalf("evaluate() = %d granted, %d revoked, want 1, 0", granted, revoked)
+	}
+	if tags := f.ruleIDs(); !reflect.DeepEqual(tags, map[uuid.UUID]*uuid.UUID{f.developer.ID: &rule.ID}) {
+		t.Errorf("assignments = %v, want developer tagged with the rule", tags)
+	}
+	if !reflect.DeepEqual(f.audit.actions, []string{constants.AuditActionUserAutoGrantRole}) {
+		t.Errorf("audit = %v, want one automatic grant", f.audit.actions)
+	}
+
+	// Evaluating again changes nothing
+	if granted, revoked := f.evaluate(t, rule); granted != 0 || revoked != 0 {
+		t.Errorf("second evaluate() = %d granted, %d revoked, want 0, 0", granted, revoked)
+	}
+}
+
+func TestRoleAssignmentRuleEvaluateRevokes(t *testing.T) {
+	f := newRuleFixture()
+	rule := f.rule("developers", f.developer)
+	f.assign(f.developer, rule, uuid.Nil)
+	f.user.EmailVerifiedAt = nil
+
+	if granted, revoked := f.evaluate(t, rule); granted != 0 || revoked != 1 {
+		t.Fatalf("evaluate() = %d granted, %d revoked, want 0, 1", granted, revoked)
+	}
+	if tags := f.ruleIDs(); len(tags) != 0 {
+		t.Errorf("assignments = %v, want none", tags)
+	}
+	if !reflect.DeepEqual(f.audit.actions, []string{constants.AuditActionUserAutoRevokeRole}) {
+		t.Errorf("audit = %v, want one automatic revoke", f.audit.actions)
+	}
+}
+
+func TestRoleAssignmentRuleEvaluateLeavesManualAssignments(t *testing.T) {
+	f := newRuleFixture()
+	rule := f.rule("developers", f.developer)
+	f.assign(f.developer, nil, uuid.Nil)
+
+	if granted, revoked := f.evaluate(t, rule); granted != 0 || revoked != 0 {
+		t.Errorf("evaluate() = %d granted, %d revoked, want 0, 0", granted, revoked)
+	}
+
+	// Not even once the user stops matching
+	f.user.EmailVerifiedAt = nil
+	if granted, revoked := f.evaluate(t, rule); granted != 0 || revoked != 0 {
+		t.Errorf("evaluate() without a match = %d granted, %d revoked, want 0, 0", granted, revoked)
+	}
+	if tags := f.ruleIDs(); !reflect.DeepEqual(tags, map[uuid.UUID]*uuid.UUID{f.developer.ID: nil}) {
+		t.Errorf("assignments = %v, want the manual assignment", tags)
+	}
+}
+
+func TestRoleAssignmentRuleEvaluateIgnoresOrganizationAssignments(t *testing.T) {
+	f := newRuleFixture()
+	rule := f.rule("developers", f.developer)
+	f.assign(f.developer, nil, uuid.New())
+
+	if granted, _ := f.evaluate(t, rule); granted != 1 {
+		t.Errorf("evaluate() granted %d, want an assignment outside the organization", granted)
+	}
+	if len(f.users.assignments[f.user.ID]) != 2 {
+		t.Errorf("assignments = %v, want the organization's assignment kept", f.users.assignments[f.user.ID])
+	}
+}
+
+func TestRoleAssignmentRuleEvaluateTagsFirstRule(t *testing.T) {
+	f := newRuleFixture()
+	first := f.rule("a developers", f.developer)
+	second := f.rule("b developers", f.developer)
+	f.assign(f.developer, second, uuid.Nil)
+
+	if granted, revoked := f.evaluate(t, first, second); granted != 0 || revoked != 0 {
+		t.Fatalf("evaluate() = %d granted, %d revoked, want 0, 0", granted, revoked)
+	}
+	if tags := f.ruleIDs(); !reflect.DeepEqual(tags, map[uuid.UUID]*uuid.UUID{f.developer.ID: &first.ID}) {
+		t.Errorf("assignments = %v, want developer tagged with the first rule", tags)
+	}
+}
+
+func TestRoleAssignmentRuleEvaluateSkipsConflicts(t *testing.T) {
+	f := newRuleFixture()
+	auditor := f.roles.add("auditor")
+	f.constraints.add("review", auditor, f.developer)
+	f.assign(auditor, nil, uuid.Nil)
+
+	if granted, revoked := f.evaluate(t, f.rule("developers", f.developer)); granted != 0 || revoked != 0 {
+		t.Errorf("evaluate() = %d granted, %d revoked, want 0, 0", granted, revoked)
+	}
+}
+
+func TestRoleAssignmentRuleEvaluateTenantRoles(t *testing.T) {
+	f := newRuleFixture()
+	organizationID := uuid.New()
+	f.developer.OrganizationID = &organizationID
+	rule := f.rule("developers", f.developer)
+
+	if granted, _ := f.evaluate(t, rule); granted != 0 {
+		t.Errorf("evaluate() granted %d to a user outside the organization", granted)
+	}
+
+	f.organizations.addMember(organizationID, f.user.ID)
+	if granted, _ := f.evaluate(t, rule); granted != 1 {
+		t.Errorf("evaluate() granted %d to a member, want 1", granted)
+	}
+}
+
+func TestRoleAssignmentRuleEvaluateTrustedMeta(t *testing.T) {
+	f := newRuleFixture()
+	rule := f.rule("engineering", f.developer)
+	rule.Attribute = "meta.department"
+	rule.Values = []string{"engineering"}
+	adminID := uuid.New()
+
+	// Values users write themselves never count
+	f.metas.metas[f.user.ID] = []*entities.UserMeta{{Key: "department", Value: "engineering", UserID: f.user.ID, UpdatedBy: &f.user.ID}}
+	if granted, _ := f.evaluate(t, rule); granted != 0 {
+		t.Errorf("evaluate() granted %d on a value the user wrote", granted)
+	}
+
+	f.metas.metas[f.user.ID][0].UpdatedBy = &adminID
+	if granted, _ := f.evaluate(t, rule); granted != 1 {
+		t.Errorf("evaluate() granted %d on a value an admin wrote, want 1", granted)
+	}
+}
+
+func TestRoleAssignmentRuleEvaluateMissingUser(t *testing.T) {
+	f := newRuleFixture()
+
+	granted, revoked, err := f.uc.evaluate(context.Background(), []*entities.RoleAssignmentRule{f.rule("developers", f.developer)}, uuid.New())
+	if err != nil || granted != 0 || revoked != 0 {
+		t.Errorf("evaluate() = %d, %d, %v, want 0, 0, nil", granted, revoked, err)
+	}
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAssignmentWindow is returned when a role assignment would never be in effect
+var ErrInvalidAssignmentWindow = errors.New("expires_at must be in the future and after starts_at")
+
+// RoleAssignmentUseCase manages individual, optionally time-bound, role assignments
+type RoleAssignmentUseCase interface {
+	GetAssignments(userID uuid.UUID) ([]*dto.RoleAssignmentResponse, error)
+	Grant(ctx context.Context, userID uuid.UUID, req *dto.GrantRoleRequest) (*dto.RoleAssignmentResponse, error)
+	Revoke(ctx context.Context, userID, roleID uuid.UUID) error
+	ExpireAssignments(ctx context.Context) (int, error)
+}
+
+type roleAssignmentUseCase struct {
+	userRepo            repositories.UserRepository
+	roleRepo            repositories.RoleRepository
+	notificationUseCase NotificationUseCase
+	duties              *separationOfDuties
+	delegation          *delegatedAdministration
+	audit               AuditUseCase
+}
+
+func NewRoleAssignmentUseCase(
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	roleConstraintRepo repositories.RoleConstraintRepository,
+	groupRepo repositories.GroupRepository,
+	organizationRepo repositories.OrganizationRepository,
+	notificationUseCase NotificationUseCase,
+	audit AuditUseCase,
+) RoleAssignmentUseCase {
+	return &roleAssignmentUseCase{
+		userRepo:            userRepo,
+		roleRepo:            roleRepo,
+		notificationUseCase: notificationUseCase,
+		duties:              &separationOfDuties{constraintRepo: roleConstraintRepo, userRepo: userRepo, roleRepo: roleRepo},
+		delegation:          &delegatedAdministration{userRepo: userRepo, roleRepo: roleRepo, groupRepo: groupRepo, organizationRepo: organizationRepo},
+		audit:               audit,
+	}
+}
+
+// GetAssignments lists every assignment of the user, including scheduled and expired ones
+// the cleanup job has not removed yet
+func (uc *roleAssignmentUseCase) GetAssignments(userID uuid.UUID) ([]*dto.RoleAssignmentResponse, error) {
+	if _, err := uc.userRepo.FindByID(userID); err != nil {
+		return nil, err
+	}
+
+	assignments, err := uc.userRepo.FindRoleAssignments(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response := []*dto.RoleAssignmentResponse{}
+	for _, assignment := range assignments {
+		if assignment.Role == nil {
+			continue
+		}
+		response = append(response, mapToRoleAssignmentResponse(assignment, now))
+	}
+	return response, nil
+}
+
+// Grant assigns one role to the user in the organization the caller acts in, or replaces the
+// window and reason of an existing assignment there. The caller is recorded as the grantor.
+func (uc *roleAssignmentUseCase) Grant(ctx context.Context, userID uuid.UUID, req *dto.GrantRoleRequest) (*dto.RoleAssignmentResponse, error) {
+	now := time.Now()
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) || (req.StartsAt != nil && !req.ExpiresAt.After(*req.StartsAt)) {
+			return nil, ErrInvalidAssignmentWindow
+		}
+	}
+
+	if _, err := uc.userRepo.WithContext(ctx).FindByID(userID); err != nil {
+		return nil, err
+	}
+	// Tenant scoping keeps roles of other organizations out of reach
+	role, err := uc.roleRepo.WithContext(ctx).FindByID(req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.delegation.authorize(ctx, userID, []uuid.UUID{req.RoleID}); err != nil {
+		return nil, err
+	}
+	if err := uc.duties.checkAddedRoles(userID, []uuid.UUID{req.RoleID}); err != nil {
+		return nil, err
+	}
+
+	organizationID := assignmentOrganization(ctx)
+	var before *dto.RoleAssignmentResponse
+	if existing, err := uc.userRepo.FindRoleAssignment(userID, req.RoleID, organizationID); err == nil {
+		before = mapToRoleAssignmentResponse(existing, now)
+	}
+
+	assignment := &entities.UserRole{
+		UserID:         userID,
+		RoleID:         req.RoleID,
+		OrganizationID: organizationID,
+		GrantedBy:      requestctx.FromContext(ctx).UserID,
+		GrantedAt:      now,
+		StartsAt:       req.StartsAt,
+		ExpiresAt:      req.ExpiresAt,
+		Reason:         req.Reason,
+	}
+	if err := uc.userRepo.SaveRoleAssignment(assignment); err != nil {
+		return nil, err
+	}
+	assignment.Role = role
+
+	response := mapToRoleAssignmentResponse(assignment, now)
+	uc.audit.Record(ctx, constants.AuditActionUserGrantRole, constants.AuditTargetUser, userID.String(), before, response)
+
+	return response, nil
+}
+
+// Revoke removes the user's assignment to the role made in the organization the caller acts in
+func (uc *roleAssignmentUseCase) Revoke(ctx context.Context, userID, roleID uuid.UUID) error {
+	organizationID := assignmentOrganization(ctx)
+	assignment, err := uc.userRepo.FindRoleAssignment(userID, roleID, organizationID)
+	if err != nil {
+		return err
+	}
+	if err := uc.delegation.authorize(ctx, userID, []uuid.UUID{roleID}); err != nil {
+		return err
+	}
+
+	if err := uc.userRepo.DeleteRoleAssignment(userID, roleID, organizationID); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionUserRevokeRole, constants.AuditTargetUser, userID.String(), mapToRoleAssignmentResponse(assignment, time.Now()), nil)
+	return nil
+}
+
+// ExpireAssignments removes assignments past their expiry and tells each user which role
+// they lost. Authorization already ignores them, this only cleans up.
+func (uc *roleAssignmentUseCase) ExpireAssignments(ctx context.Context) (int, error) {
+	now := time.Now()
+	assignments, err := uc.userRepo.FindExpiredRoleAssignments(now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, assignment := range assignments {
+		if err := uc.userRepo.DeleteRoleAssignment(assignment.UserID, assignment.RoleID, assignment.OrganizationID); err != nil {
+			return expired, err
+		}
+		expired++
+
+		// The role itself may have been deleted since the assignment was made
+		if assignment.Role == nil {
+			continue
+		}
+		uc.audit.Record(ctx, constants.AuditActionUserRoleExpired, constants.AuditTargetUser, assignment.UserID.String(), mapToRoleAssignmentResponse(assignment, now), nil)
+
+		_, err := uc.notificationUseCase.SendToUser(nil, assignment.UserID, &dto.NotificationMessage{
+			Title:    "Role expired",
+			Body:     fmt.Sprintf("Your %s role has expired.", assignment.Role.Name),
+			Category: constants.NotificationCategoryAccount,
+			Data: map[string]string{
+				"type":    "role_expired",
+				"role_id": assignment.RoleID.String(),
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to notify user %s about expired role %s: %v", assignment.UserID, assignment.RoleID, err)
+		}
+	}
+
+	return expired, nil
+}
+
+func mapToRoleAssignmentResponse(assignment *entities.UserRole, now time.Time) *dto.RoleAssignmentResponse {
+	status := constants.RoleAssignmentActive
+	switch {
+	case assignment.ExpiresAt != nil && !assignment.ExpiresAt.After(now):
+		status = constants.RoleAssignmentExpired
+	case assignment.StartsAt != nil && assignment.StartsAt.After(now):
+		status = constants.RoleAssignmentScheduled
+	}
+
+	resp := &dto.RoleAssignmentResponse{
+		UserID:    assignment.UserID,
+		GrantedBy: assignment.GrantedBy,
+		GrantedAt: assignment.GrantedAt.Format(time.RFC3339),
+		StartsAt:  formatOptionalTime(assignment.StartsAt),
+		ExpiresAt: formatOptionalTime(assignment.ExpiresAt),
+		Reason:    assignment.Reason,
+		Status:    status,
+		RuleID:    assignment.RuleID,
+	}
+	if assignment.Role != nil {
+		resp.Role = dto.RoleSimple{ID: assignment.Role.ID, Name: assignment.Role.Name}
+	}
+	if assignment.OrganizationID != uuid.Nil {
+		organizationID := assignment.OrganizationID
+		resp.OrganizationID = &organizationID
+	}
+	return resp
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func newTestRoleAssignmentUseCase(users *fakeUserRepository, roles *fakeRoleRepository) *roleAssignmentUseCase {
+	organizations := newFakeOrganizationRepository()
+	return &roleAssignmentUseCase{
+		userRepo:   users,
+		roleRepo:   roles,
+		duties:     &separationOfDuties{constraintRepo: &fakeRoleConstraintRepository{}, userRepo: users, roleRepo: roles},
+		delegation: &delegatedAdministration{userRepo: users, roleRepo: roles, organizationRepo: organizations},
+		audit:      &fakeAudit{},
+	}
+}
+
+// adminIn returns the context of a stored caller without delegated roles acting in the organization
+func adminIn(users *fakeUserRepository, organizationID *uuid.UUID) context.Context {
+	caller := &entities.User{ID: uuid.New(), Username: "admin"}
+	users.users[caller.ID] = caller
+	return requestctx.WithInfo(context.Background(), &requestctx.Info{UserID: &caller.ID, OrganizationID: organizationID})
+}
+
+func TestGrantAndRevokeStayInTheCallersOrganization(t *testing.T) {
+	acme, globex := uuid.New(), uuid.New()
+	users := newFakeUserRepository()
+	roles := newFakeRoleRepository()
+	uc := newTestRoleAssignmentUseCase(users, roles)
+
+	target := &entities.User{ID: uuid.New(), Username: "target"}
+	users.users[target.ID] = target
+	agent := roles.add("agent")
+
+	acmeAdmin, globexAdmin := adminIn(users, &acme), adminIn(users, &globex)
+	for _, ctx := range []context.Context{acmeAdmin, globexAdmin, adminIn(users, nil)} {
+		if _, err := uc.Grant(ctx, target.ID, &dto.GrantRoleRequest{RoleID: agent.ID}); err != nil {
+			t.Fatalf("Grant: %v", err)
+		}
+	}
+
+	got := make(map[uuid.UUID]bool)
+	for _, assignment := range users.assignments[target.ID] {
+		got[assignment.OrganizationID] = true
+	}
+	if len(got) != 3 || !got[acme] || !got[globex] || !got[uuid.Nil] {
+		t.Fatalf("assignments made in %v, want one each in acme, globex and outside any organization", got)
+	}
+
+	if err := uc.Revoke(acmeAdmin, target.ID, agent.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := users.FindRoleAssignment(target.ID, agent.ID, acme); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("the acme assignment was not revoked")
+	}
+	for _, organizationID := range []uuid.UUID{globex, uuid.Nil} {
+		if _, err := users.FindRoleAssignment(target.ID, agent.ID, organizationID); err != nil {
+			t.Errorf("revoking in acme removed the assignment made in %s", organizationID)
+		}
+	}
+
+	// The acme assignment is gone, so revoking it again finds nothing
+	if err := uc.Revoke(acmeAdmin, target.ID, agent.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("second Revoke() = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestGrantChecksTheAssignmentWindow(t *testing.T) {
+	now := time.Now()
+	past, soon, later := now.Add(-time.Hour), now.Add(time.Hour), now.Add(2*time.Hour)
+
+	tests := []struct {
+		name       string
+		startsAt   *time.Time
+		expiresAt  *time.Time
+		wantErr    error
+		wantStatus string
+	}{
+		{"permanent", nil, nil, nil, constants.RoleAssignmentActive},
+		{"until later", nil, &later, nil, constants.RoleAssignmentActive},
+		{"started already", &past, &later, nil, constants.RoleAssignmentActive},
+		{"scheduled", &soon, &later, nil, constants.RoleAssignmentScheduled},
+		{"scheduled without expiry", &soon, nil, nil, constants.RoleAssignmentScheduled},
+		{"expired already", nil, &past, ErrInvalidAssignmentWindow, ""},
+		{"expires before it starts", &later, &soon, ErrInvalidAssignmentWindow, ""},
+		{"expires when it starts", &soon, &soon, ErrInvalidAssignmentWindow, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository()
+			roles := newFakeRoleRepository()
+			uc := newTestRoleAssignmentUseCase(users, roles)
+			target := &entities.User{ID: uuid.New(), Username: "contractor"}
+			users.users[target.ID] = target
+			role := roles.add("on-call admin")
+
+			resp, err := uc.Grant(adminIn(users, nil), target.ID, &dto.GrantRoleRequest{
+				RoleID:    role.ID,
+				StartsAt:  tt.startsAt,
+				ExpiresAt: tt.expiresAt,
+				Reason:    "incident 42",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Grant() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(users.assignments[target.ID]) != 0 {
+					t.Error("a rejected window was stored")
+				}
+				return
+			}
+
+			if resp.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", resp.Status, tt.wantStatus)
+			}
+			assignment, err := users.FindRoleAssignment(target.ID, role.ID, uuid.Nil)
+			if err != nil {
+				t.Fatalf("assignment not stored: %v", err)
+			}
+			if assignment.GrantedBy == nil || assignment.Reason != "incident 42" || assignment.GrantedAt.IsZero() {
+				t.Errorf("assignment = %+v, want the grantor, reason and grant time recorded", assignment)
+			}
+		})
+	}
+}
+
+func TestGrantIgnoresExpiredConflictingAssignments(t *testing.T) {
+	users := newFakeUserRepository()
+	roles := newFakeRoleRepository()
+	constraints := &fakeRoleConstraintRepository{}
+	uc := newTestRoleAssignmentUseCase(users, roles)
+	uc.duties.constraintRepo = constraints
+
+	target := &entities.User{ID: uuid.New(), Username: "target"}
+	users.users[target.ID] = target
+	requester, approver := roles.add("payment requester"), roles.add("payment approver")
+	constraints.add("payments", requester, approver)
+
+	expired := time.Now().Add(-time.Minute)
+	users.assignments[target.ID] = []*entities.UserRole{{UserID: target.ID, RoleID: requester.ID, Role: requester, ExpiresAt: &expired}}
+	if _, err := uc.Grant(adminIn(users, nil), target.ID, &dto.GrantRoleRequest{RoleID: approver.ID}); err != nil {
+		t.Errorf("Grant() next to an expired conflicting assignment: %v", err)
+	}
+
+	valid := time.Now().Add(time.Hour)
+	users.assignments[target.ID][0].ExpiresAt = &valid
+	if _, err := uc.Grant(adminIn(users, nil), target.ID, &dto.GrantRoleRequest{RoleID: approver.ID}); err == nil {
+		t.Error("Grant() next to a current conflicting assignment succeeded")
+	}
+}
+
+func TestExpireAssignmentsRemovesAndNotifies(t *testing.T) {
+	users := newFakeUserRepository()
+	roles := newFakeRoleRepository()
+	notifications := newFakeNotifications()
+	audit := &fakeAudit{}
+	uc := newTestRoleAssignmentUseCase(users, roles)
+	uc.notificationUseCase = notifications
+	uc.audit = audit
+
+	contractor, oncall, deletedRole := uuid.New(), uuid.New(), uuid.New()
+	admin := roles.add("admin")
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	users.assignments[contractor] = []*entities.UserRole{
+		{UserID: contractor, RoleID: admin.ID, Role: admin, ExpiresAt: &past},
+		{UserID: contractor, RoleID: deletedRole, ExpiresAt: &past},
+	}
+	users.assignments[oncall] = []*entities.UserRole{
+		{UserID: oncall, RoleID: admin.ID, Role: admin, ExpiresAt: &future},
+		{UserID: oncall, RoleID: admin.ID, Role: admin, OrganizationID: uuid.New()},
+	}
+
+	expired, err := uc.ExpireAssignments(context.Background())
+	if err != nil {
+		t.Fatalf("ExpireAssignments: %v", err)
+	}
+	if expired != 2 {
+		t.Errorf("expired %d assignments, want 2", expired)
+	}
+	if len(users.assignments[contractor]) != 0 {
+		t.Errorf("contractor still holds %d assignments", len(users.assignments[contractor]))
+	}
+	if len(users.assignments[oncall]) != 2 {
+		t.Errorf("unexpired assignments were removed, %d left", len(users.assignments[oncall]))
+	}
+
+	// Only the assignment whose role still exists is audited and notified
+	if len(audit.actions) != 1 || audit.actions[0] != constants.AuditActionUserRoleExpired {
+		t.Errorf("audited %v, want one %s", audit.actions, constants.AuditActionUserRoleExpired)
+	}
+	sent := notifications.sent[contractor]
+	if len(sent) != 1 || sent[0].Data["role_id"] != admin.ID.String() {
+		t.Errorf("contractor was sent %+v, want one notice about the admin role", sent)
+	}
+	if len(notifications.sent[oncall]) != 0 {
+		t.Error("a user without expired assignments was notified")
+	}
+}
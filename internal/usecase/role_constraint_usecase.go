@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// violationScanPageSize is how many users GetViolations loads at a time
+const violationScanPageSize = 200
+
+// RoleConstraintUseCase manages separation-of-duties constraints. Constraints apply across every
+// organization, so only callers outside any organization may change them.
+type RoleConstraintUseCase interface {
+	Create(ctx context.Context, req *dto.CreateRoleConstraintRequest) (*dto.RoleConstraintResponse, error)
+	GetByID(id uuid.UUID) (*dto.RoleConstraintResponse, error)
+	GetAll(page, pageSize int) ([]*dto.RoleConstraintResponse, int64, error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdateRoleConstraintRequest) (*dto.RoleConstraintResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetViolations(ctx context.Context, id uuid.UUID) ([]*dto.RoleConstraintViolation, error)
+}
+
+type roleConstraintUseCase struct {
+	constraintRepo repositories.RoleConstraintRepository
+	userRepo       repositories.UserRepository
+	roleRepo       repositories.RoleRepository
+	duties         *separationOfDuties
+	audit          AuditUseCase
+}
+
+func NewRoleConstraintUseCase(
+	constraintRepo repositories.RoleConstraintRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	audit AuditUseCase,
+) RoleConstraintUseCase {
+	return &roleConstraintUseCase{
+		constraintRepo: constraintRepo,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		duties:         &separationOfDuties{constraintRepo: constraintRepo, userRepo: userRepo, roleRepo: roleRepo},
+		audit:          audit,
+	}
+}
+
+// Create adds a constraint. Users who already hold several of its roles keep them and are listed
+// by GetViolations.
+func (uc *roleConstraintUseCase) Create(ctx context.Context, req *dto.CreateRoleConstraintRequest) (*dto.RoleConstraintResponse, error) {
+	if activeTenant(ctx) != nil {
+		return nil, ErrForbidden
+	}
+
+	// Check if constraint name already exists
+	if _, err := uc.constraintRepo.FindByName(req.Name); err == nil {
+		return nil, errors.New("constraint name already exists")
+	}
+
+	if err := uc.checkRoles(req.RoleIDs); err != nil {
+		return nil, err
+	}
+
+	constraint := &entities.RoleConstraint{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := uc.constraintRepo.Create(constraint); err != nil {
+		return nil, err
+	}
+	if err := uc.constraintRepo.AssignRoles(constraint.ID, req.RoleIDs); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.constraintRepo.FindByID(constraint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToRoleConstraintResponse(created)
+	uc.audit.Record(ctx, constants.AuditActionRoleConstraintCreate, constants.AuditTargetRoleConstraint, constraint.ID.String(), nil, response)
+
+	return response, nil
+}
+
+func (uc *roleConstraintUseCase) GetByID(id uuid.UUID) (*dto.RoleConstraintResponse, error) {
+	constraint, err := uc.constraintRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapToRoleConstraintResponse(constraint), nil
+}
+
+func (uc *roleConstraintUseCase) GetAll(page, pageSize int) ([]*dto.RoleConstraintResponse, int64, error) {
+	constraints, total, err := uc.constraintRepo.FindAll(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response []*dto.RoleConstraintResponse
+	for _, constraint := range constraints {
+		response = append(response, mapToRoleConstraintResponse(constraint))
+	}
+
+	return response, total, nil
+}
+
+func (uc *roleConstraintUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdateRoleConstraintRequest) (*dto.RoleConstraintResponse, error) {
+	if activeTenant(ctx) != nil {
+		return nil, ErrForbidden
+	}
+
+	constraint, err := uc.constraintRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := mapToRoleConstraintResponse(constraint)
+
+	// Update fields if provided
+	if req.Name != "" && req.Name != constraint.Name {
+		if existing, err := uc.constraintRepo.FindByName(req.Name); err == nil && existing.ID != id {
+			return nil, errors.New("constraint name already exists")
+		}
+		constraint.Name = req.Name
+	}
+
+	if req.Description != nil {
+		constraint.Description = *req.Description
+	}
+
+	if req.RoleIDs != nil {
+		if err := uc.checkRoles(req.RoleIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.constraintRepo.Update(constraint); err != nil {
+		return nil, err
+	}
+
+	if req.RoleIDs != nil {
+		if err := uc.constraintRepo.AssignRoles(id, req.RoleIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := uc.constraintRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToRoleConstraintResponse(updated)
+	uc.audit.Record(ctx, constants.AuditActionRoleConstraintUpdate, constants.AuditTargetRoleConstraint, id.String(), before, response)
+
+	return response, nil
+}
+
+func (uc *roleConstraintUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	if activeTenant(ctx) != nil {
+		return ErrForbidden
+	}
+
+	constraint, err := uc.constraintRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.constraintRepo.Delete(id); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionRoleConstraintDelete, constants.AuditTargetRoleConstraint, id.String(), mapToRoleConstraintResponse(constraint), nil)
+	return nil
+}
+
+// GetViolations lists the users who already hold more than one role of the constraint, for
+// example because they had both before it was added or received one through a group's roles
+// or a role's parents. Tenant-scoped callers only see members of their organization.
+func (uc *roleConstraintUseCase) GetViolations(ctx context.Context, id uuid.UUID) ([]*dto.RoleConstraintViolation, error) {
+	constraint, err := uc.constraintRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []*dto.RoleConstraintViolation{}
+	for page := 1; ; page++ {
+		users, _, err := uc.userRepo.WithContext(ctx).FindAll(page, violationScanPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			heldIDs, err := uc.duties.heldRoleIDs(user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(heldIDs) == 0 {
+				continue
+			}
+			held, err := uc.duties.withInherited(heldIDs)
+			if err != nil {
+				return nil, err
+			}
+			if violation(constraint, held) == nil {
+				continue
+			}
+
+			item := &dto.RoleConstraintViolation{
+				User:  dto.UserSimple{ID: user.ID, Username: user.Username, Email: user.Email},
+				Roles: []dto.RoleSimple{},
+			}
+			for _, role := range constraint.Roles {
+				if held[role.ID] {
+					item.Roles = append(item.Roles, dto.RoleSimple{ID: role.ID, Name: role.Name})
+				}
+			}
+			response = append(response, item)
+		}
+
+		if len(users) < violationScanPageSize {
+			return response, nil
+		}
+	}
+}
+
+// checkRoles requires at least two distinct, existing roles
+func (uc *roleConstraintUseCase) checkRoles(roleIDs []uuid.UUID) error {
+	unique := make(map[uuid.UUID]bool)
+	for _, roleID := range roleIDs {
+		unique[roleID] = true
+	}
+	if len(unique) < 2 {
+		return errors.New("a constraint needs at least two roles")
+	}
+
+	roles, err := uc.roleRepo.FindByIDs(roleIDs)
+	if err != nil {
+		return err
+	}
+	if len(roles) != len(unique) {
+		return errors.New("role not found")
+	}
+	return nil
+}
+
+func mapToRoleConstraintResponse(constraint *entities.RoleConstraint) *dto.RoleConstraintResponse {
+	resp := &dto.RoleConstraintResponse{
+		ID:          constraint.ID,
+		Name:        constraint.Name,
+		Description: constraint.Description,
+		Roles:       []dto.RoleSimple{},
+		CreatedAt:   constraint.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   constraint.UpdatedAt.Format(time.RFC3339),
+	}
+
+	for _, role := range constraint.Roles {
+		resp.Roles = append(resp.Roles, dto.RoleSimple{ID: role.ID, Name: role.Name})
+	}
+
+	return resp
+}
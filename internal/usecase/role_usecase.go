@@ -1,47 +1,70 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 	"usermanagement-api/domain/entities"
 	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
 
 	"github.com/google/uuid"
 )
 
+// ErrRoleHierarchyCycle is returned when a parent assignment would make a role its own ancestor
+var ErrRoleHierarchyCycle = repositories.ErrRoleHierarchyCycle
+
 type RoleUseCase interface {
-	Create(req *dto.CreateRoleRequest) (*dto.RoleResponse, error)
-	GetByID(id uuid.UUID) (*dto.RoleResponse, error)
-	GetAll(page, pageSize int) ([]*dto.RoleResponse, int64, error)
-	Update(id uuid.UUID, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error)
-	Delete(id uuid.UUID) error
-	AssignPermissions(roleID uuid.UUID, permissionIDs []uuid.UUID) (*dto.RoleResponse, error)
-	GetUserRoles(userID uuid.UUID) ([]*dto.RoleResponse, error)
+	Create(ctx context.Context, req *dto.CreateRoleRequest) (*dto.RoleResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.RoleResponse, error)
+	GetAll(ctx context.Context, page, pageSize int) ([]*dto.RoleResponse, int64, error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	AssignPermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) (*dto.RoleResponse, error)
+	AssignParents(ctx context.Context, roleID uuid.UUID, parentIDs []uuid.UUID) (*dto.RoleResponse, error)
+	AssignDeniedPermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) (*dto.RoleResponse, error)
+	AssignApprovers(ctx context.Context, roleID uuid.UUID, userIDs []uuid.UUID) (*dto.RoleResponse, error)
+	AssignDelegation(ctx context.Context, roleID uuid.UUID, req *dto.AssignDelegationRequest) (*dto.RoleResponse, error)
+	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*dto.RoleResponse, error)
 }
 
 type roleUseCase struct {
 	roleRepo       repositories.RoleRepository
 	permissionRepo repositories.PermissionRepository
+	userRepo       repositories.UserRepository
+	audit          AuditUseCase
 }
 
-func NewRoleUseCase(roleRepo repositories.RoleRepository, permissionRepo repositories.PermissionRepository) RoleUseCase {
+func NewRoleUseCase(roleRepo repositories.RoleRepository, permissionRepo repositories.PermissionRepository, userRepo repositories.UserRepository, audit AuditUseCase) RoleUseCase {
 	return &roleUseCase{
 		roleRepo:       roleRepo,
 		permissionRepo: permissionRepo,
+		userRepo:       userRepo,
+		audit:          audit,
 	}
 }
 
-func (uc *roleUseCase) Create(req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
-	// Check if role name already exists
-	if _, err := uc.roleRepo.FindByName(req.Name); err == nil {
-		return nil, errors.New("role name already exists")
+func (uc *roleUseCase) Create(ctx context.Context, req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	// Create role, inside the caller's organization when it acts in one
+	role := &entities.Role{
+		Name:           req.Name,
+		Description:    req.Description,
+		OrganizationID: activeTenant(ctx),
+	}
+	if role.OrganizationID == nil && req.OrganizationID != nil {
+		if !requestctx.FromContext(ctx).IsSuperuser {
+			return nil, ErrForbidden
+		}
+		role.OrganizationID = req.OrganizationID
 	}
 
-	// Create role
-	role := &entities.Role{
-		Name:        req.Name,
-		Description: req.Description,
+	// Check if role name already exists in the organization
+	if _, err := uc.roleRepo.WithContext(ctx).FindByName(req.Name, role.OrganizationID); err == nil {
+		return nil, errors.New("role name already exists")
 	}
 
 	// Save role
@@ -49,20 +72,30 @@ func (uc *roleUseCase) Create(req *dto.CreateRoleRequest) (*dto.RoleResponse, er
 		return nil, err
 	}
 
-	return uc.mapToRoleResponse(role), nil
+	response := uc.mapToRoleResponse(role)
+	uc.audit.Record(ctx, constants.AuditActionRoleCreate, constants.AuditTargetRole, role.ID.String(), nil, response)
+
+	return response, nil
 }
 
-func (uc *roleUseCase) GetByID(id uuid.UUID) (*dto.RoleResponse, error) {
-	role, err := uc.roleRepo.FindByID(id)
+func (uc *roleUseCase) GetByID(ctx context.Context, id uuid.UUID) (*dto.RoleResponse, error) {
+	role, err := uc.roleRepo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	return uc.mapToRoleResponse(role), nil
+	response := uc.mapToRoleResponse(role)
+	response.InheritedPermissions, err = uc.inheritedPermissions(role)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
 }
 
-func (uc *roleUseCase) GetAll(page, pageSize int) ([]*dto.RoleResponse, int64, error) {
-	roles, total, err := uc.roleRepo.FindAll(page, pageSize)
+// GetAll lists the shared roles and those of the caller's organization
+func (uc *roleUseCase) GetAll(ctx context.Context, page, pageSize int) ([]*dto.RoleResponse, int64, error) {
+	roles, total, err := uc.roleRepo.WithContext(ctx).FindAll(page, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -75,16 +108,17 @@ func (uc *roleUseCase) GetAll(page, pageSize int) ([]*dto.RoleResponse, int64, e
 	return response, total, nil
 }
 
-func (uc *roleUseCase) Update(id uuid.UUID, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
-	role, err := uc.roleRepo.FindByID(id)
+func (uc *roleUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	role, err := uc.findManageable(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := uc.mapToRoleResponse(role)
 
 	// Update fields if provided
 	if req.Name != "" && req.Name != role.Name {
 		// Check if new name already exists
-		if existingRole, err := uc.roleRepo.FindByName(req.Name); err == nil && existingRole.ID != id {
+		if existingRole, err := uc.roleRepo.WithContext(ctx).FindByName(req.Name, role.OrganizationID); err == nil && existingRole.ID != id {
 			return nil, errors.New("role name already exists")
 		}
 		role.Name = req.Name
@@ -95,23 +129,50 @@ func (uc *roleUseCase) Update(id uuid.UUID, req *dto.UpdateRoleRequest) (*dto.Ro
 	}
 
 	// Update role
-	if err := uc.roleRepo.Update(role); err != nil {
+	if err := uc.roleRepo.WithContext(ctx).Update(role); err != nil {
 		return nil, err
 	}
 
-	return uc.mapToRoleResponse(role), nil
+	response := uc.mapToRoleResponse(role)
+	uc.audit.Record(ctx, constants.AuditActionRoleUpdate, constants.AuditTargetRole, id.String(), before, response)
+
+	return response, nil
 }
 
-func (uc *roleUseCase) Delete(id uuid.UUID) error {
-	return uc.roleRepo.Delete(id)
+func (uc *roleUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	role, err := uc.findManageable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.roleRepo.WithContext(ctx).Delete(id); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionRoleDelete, constants.AuditTargetRole, id.String(), uc.mapToRoleResponse(role), nil)
+	return nil
 }
 
-func (uc *roleUseCase) AssignPermissions(roleID uuid.UUID, permissionIDs []uuid.UUID) (*dto.RoleResponse, error) {
+func (uc *roleUseCase) AssignPermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) (*dto.RoleResponse, error) {
 	// Check if role exists
-	_, err := uc.roleRepo.FindByID(roleID)
+	role, err := uc.findManageable(ctx, roleID)
 	if err != nil {
 		return nil, err
 	}
+	before := uc.mapToRoleResponse(role)
+
+	// Roles of an organization cannot carry permissions that act on every organization
+	if activeTenant(ctx) != nil {
+		for _, permissionID := range permissionIDs {
+			permission, err := uc.permissionRepo.FindByID(permissionID)
+			if err != nil {
+				return nil, fmt.Errorf("permission %s not found", permissionID)
+			}
+			if constants.IsGlobalPermission(permission.Name) {
+				return nil, ErrForbidden
+			}
+		}
+	}
 
 	// Assign permissions
 	if err := uc.roleRepo.AssignPermissions(roleID, permissionIDs); err != nil {
@@ -124,17 +185,219 @@ func (uc *roleUseCase) AssignPermissions(roleID uuid.UUID, permissionIDs []uuid.
 		return nil, err
 	}
 
-	return uc.mapToRoleResponse(updatedRole), nil
+	response := uc.mapToRoleResponse(updatedRole)
+	uc.audit.Record(ctx, constants.AuditActionRoleAssignPermissions, constants.AuditTargetRole, roleID.String(), before, response)
+
+	return response, nil
+}
+
+// AssignDeniedPermissions replaces the permissions explicitly denied to holders of the role.
+// Denies are inherited by child roles and override any grant.
+func (uc *roleUseCase) AssignDeniedPermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) (*dto.RoleResponse, error) {
+	role, err := uc.findManageable(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	before := uc.mapToRoleResponse(role)
+
+	if err := uc.roleRepo.AssignDeniedPermissions(roleID, permissionIDs); err != nil {
+		return nil, err
+	}
+
+	updatedRole, err := uc.roleRepo.FindByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToRoleResponse(updatedRole)
+	uc.audit.Record(ctx, constants.AuditActionRoleAssignDenied, constants.AuditTargetRole, roleID.String(), before, response)
+
+	return response, nil
+}
+
+// AssignParents replaces the roles this role inherits from, rejecting changes that would create a cycle
+func (uc *roleUseCase) AssignParents(ctx context.Context, roleID uuid.UUID, parentIDs []uuid.UUID) (*dto.RoleResponse, error) {
+	role, err := uc.findManageable(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	before := uc.mapToRoleResponse(role)
+
+	seen := make(map[uuid.UUID]bool)
+	var uniqueParentIDs []uuid.UUID
+	for _, parentID := range parentIDs {
+		if parentID == roleID {
+			return nil, ErrRoleHierarchyCycle
+		}
+		if seen[parentID] {
+			continue
+		}
+		seen[parentID] = true
+		uniqueParentIDs = append(uniqueParentIDs, parentID)
+	}
+
+	parents, err := uc.roleRepo.WithContext(ctx).FindByIDs(uniqueParentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(parents) != len(uniqueParentIDs) {
+		return nil, errors.New("parent role not found")
+	}
+	// Shared roles may only inherit from shared roles, tenant roles also from their own organization's
+	for _, parent := range parents {
+		if !parent.AppliesIn(role.OrganizationID) {
+			return nil, errors.New("parent role belongs to another organization")
+		}
+	}
+
+	// Fails with ErrRoleHierarchyCycle when the role is already an ancestor of a new parent
+	if err := uc.roleRepo.AssignParents(roleID, uniqueParentIDs); err != nil {
+		return nil, err
+	}
+
+	updatedRole, err := uc.roleRepo.FindByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToRoleResponse(updatedRole)
+	uc.audit.Record(ctx, constants.AuditActionRoleAssignParents, constants.AuditTargetRole, roleID.String(), before, response)
+
+	response.InheritedPermissions, err = uc.inheritedPermissions(updatedRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// AssignApprovers replaces the users who decide access requests for the role
+func (uc *roleUseCase) AssignApprovers(ctx context.Context, roleID uuid.UUID, userIDs []uuid.UUID) (*dto.RoleResponse, error) {
+	role, err := uc.findManageable(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	before := uc.mapToRoleResponse(role)
+
+	// Only users visible to the caller can become approvers
+	for _, userID := range userIDs {
+		if _, err := uc.userRepo.WithContext(ctx).FindByID(userID); err != nil {
+			return nil, fmt.Errorf("user %s not found", userID)
+		}
+	}
+
+	if err := uc.roleRepo.AssignApprovers(roleID, userIDs); err != nil {
+		return nil, err
+	}
+
+	updatedRole, err := uc.roleRepo.FindByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToRoleResponse(updatedRole)
+	uc.audit.Record(ctx, constants.AuditActionRoleAssignApprovers, constants.AuditTargetRole, roleID.String(), before, response)
+
+	return response, nil
 }
 
-func (uc *roleUseCase) GetUserRoles(userID uuid.UUID) ([]*dto.RoleResponse, error) {
-	roles, err := uc.roleRepo.FindRolesByUserID(userID)
+// AssignDelegation sets which users holders of the role may manage and which roles they may assign
+func (uc *roleUseCase) AssignDelegation(ctx context.Context, roleID uuid.UUID, req *dto.AssignDelegationRequest) (*dto.RoleResponse, error) {
+	role, err := uc.findManageable(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	before := uc.mapToRoleResponse(role)
+
+	if req.ManageableUserScope == "" && len(req.ManageableRoleIDs) > 0 {
+		return nil, errors.New("manageable_user_scope is required with manageable_role_ids")
+	}
+	for _, manageableID := range req.ManageableRoleIDs {
+		if _, err := uc.roleRepo.WithContext(ctx).FindByID(manageableID); err != nil {
+			return nil, fmt.Errorf("role %s not found", manageableID)
+		}
+	}
+
+	if err := uc.roleRepo.AssignDelegation(roleID, req.ManageableUserScope, req.ManageableRoleIDs); err != nil {
+		return nil, err
+	}
+
+	updatedRole, err := uc.roleRepo.FindByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToRoleResponse(updatedRole)
+	uc.audit.Record(ctx, constants.AuditActionRoleAssignDelegation, constants.AuditTargetRole, roleID.String(), before, response)
+
+	return response, nil
+}
+
+// findManageable loads a role the caller may change. Callers acting in an organization see the
+// shared roles but may only change their organization's own.
+func (uc *roleUseCase) findManageable(ctx context.Context, id uuid.UUID) (*entities.Role, error) {
+	role, err := uc.roleRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if activeTenant(ctx) != nil && role.OrganizationID == nil {
+		return nil, ErrForbidden
+	}
+	return role, nil
+}
+
+// inheritedPermissions lists the permissions a role receives from its ancestors but does not hold directly
+func (uc *roleUseCase) inheritedPermissions(role *entities.Role) ([]dto.InheritedPermission, error) {
+	ancestorIDs, err := uc.roleRepo.FindAncestorIDs([]uuid.UUID{role.ID})
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := uc.roleRepo.FindByIDs(ancestorIDs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ancestors, func(i, j int) bool { return ancestors[i].Name < ancestors[j].Name })
+
+	direct := make(map[uuid.UUID]bool)
+	for _, permission := range role.Permissions {
+		direct[permission.ID] = true
+	}
+
+	index := make(map[uuid.UUID]int)
+	var inherited []dto.InheritedPermission
+	for _, ancestor := range ancestors {
+		source := dto.RoleSimple{ID: ancestor.ID, Name: ancestor.Name}
+		for _, permission := range ancestor.Permissions {
+			if direct[permission.ID] {
+				continue
+			}
+			if i, ok := index[permission.ID]; ok {
+				inherited[i].InheritedFrom = append(inherited[i].InheritedFrom, source)
+				continue
+			}
+			index[permission.ID] = len(inherited)
+			inherited = append(inherited, dto.InheritedPermission{
+				ID:            permission.ID,
+				Name:          permission.Name,
+				InheritedFrom: []dto.RoleSimple{source},
+			})
+		}
+	}
+
+	sort.Slice(inherited, func(i, j int) bool { return inherited[i].Name < inherited[j].Name })
+	return inherited, nil
+}
+
+// GetUserRoles returns the roles the user holds in the organization the caller acts in
+func (uc *roleUseCase) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*dto.RoleResponse, error) {
+	organizationID := requestctx.FromContext(ctx).OrganizationID
+	roles, err := uc.roleRepo.FindRolesByUserID(userID, organizationID)
 	if err != nil {
 		return nil, err
 	}
 
 	var response []*dto.RoleResponse
-	for _, role := range roles {
+	for _, role := range rolesInOrganization(roles, organizationID) {
 		response = append(response, uc.mapToRoleResponse(role))
 	}
 
@@ -143,11 +406,12 @@ func (uc *roleUseCase) GetUserRoles(userID uuid.UUID) ([]*dto.RoleResponse, erro
 
 func (uc *roleUseCase) mapToRoleResponse(role *entities.Role) *dto.RoleResponse {
 	resp := &dto.RoleResponse{
-		ID:          role.ID,
-		Name:        role.Name,
-		Description: role.Description,
-		CreatedAt:   role.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   role.UpdatedAt.Format(time.RFC3339),
+		ID:             role.ID,
+		Name:           role.Name,
+		Description:    role.Description,
+		OrganizationID: role.OrganizationID,
+		CreatedAt:      role.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      role.UpdatedAt.Format(time.RFC3339),
 	}
 
 	// Map users
@@ -171,5 +435,39 @@ func (uc *roleUseCase) mapToRoleResponse(role *entities.Role) *dto.RoleResponse
 		}
 	}
 
+	// Map denied permissions
+	for _, permission := range role.DeniedPermissions {
+		resp.DeniedPermissions = append(resp.DeniedPermissions, dto.PermissionSimple{
+			ID:   permission.ID,
+			Name: permission.Name,
+		})
+	}
+
+	// Map parents
+	for _, parent := range role.Parents {
+		resp.Parents = append(resp.Parents, dto.RoleSimple{
+			ID:   parent.ID,
+			Name: parent.Name,
+		})
+	}
+
+	// Map approvers
+	for _, approver := range role.Approvers {
+		resp.Approvers = append(resp.Approvers, dto.UserSimple{
+			ID:       approver.ID,
+			Username: approver.Username,
+			Email:    approver.Email,
+		})
+	}
+
+	// Map delegation scope
+	resp.ManageableUserScope = role.ManageableUserScope
+	for _, manageable := range role.ManageableRoles {
+		resp.ManageableRoles = append(resp.ManageableRoles, dto.RoleSimple{
+			ID:   manageable.ID,
+			Name: manageable.Name,
+		})
+	}
+
 	return resp
 }
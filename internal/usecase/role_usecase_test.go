@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+func TestRoleAssignPermissionsKeepsGlobalPermissionsOffTenantRoles(t *testing.T) {
+	organizationID := uuid.New()
+	roles := newFakeRoleRepository()
+	permissions := &fakePermissionRepository{}
+	uc := &roleUseCase{roleRepo: roles, permissionRepo: permissions, audit: &fakeAudit{}}
+
+	role := roles.add("support")
+	role.OrganizationID = &organizationID
+	usersRead := permissions.add(constants.PermissionUsersRead)
+
+	tests := []struct {
+		name       string
+		permission string
+	}{
+		{"policies", constants.PermissionPoliciesWrite},
+		{"permissions", constants.PermissionPermissionsWrite},
+		{"organizations", constants.PermissionOrganizationsRead},
+		{"role constraints", constants.PermissionRoleConstraintsDelete},
+		{"access reviews", constants.PermissionAccessReviewsWrite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			global := permissions.add(tt.permission)
+			_, err := uc.AssignPermissions(tenantContext(&organizationID), role.ID, []uuid.UUID{usersRead.ID, global.ID})
+			if !errors.Is(err, ErrForbidden) {
+				t.Errorf("AssignPermissions() = %v, want ErrForbidden", err)
+			}
+
+			// Superusers and background work may still grant them
+			if _, err := uc.AssignPermissions(context.Background(), role.ID, []uuid.UUID{global.ID}); err != nil {
+				t.Errorf("AssignPermissions() outside a tenant: %v", err)
+			}
+		})
+	}
+
+	if _, err := uc.AssignPermissions(tenantContext(&organizationID), role.ID, []uuid.UUID{usersRead.ID}); err != nil {
+		t.Errorf("AssignPermissions() of a tenant permission: %v", err)
+	}
+}
+
+func TestRoleCreateChecksNamesWithinTheOrganization(t *testing.T) {
+	acme, globex := uuid.New(), uuid.New()
+	roles := newFakeRoleRepository()
+	uc := &roleUseCase{roleRepo: roles, audit: &fakeAudit{}}
+
+	roles.add("admin")
+	support := roles.add("support")
+	support.OrganizationID = &acme
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		role             string
+		wantErr          bool
+		wantOrganization *uuid.UUID
+	}{
+		{"taken in the organization", tenantContext(&acme), "support", true, nil},
+		{"free in another organization", tenantContext(&globex), "support", false, &globex},
+		{"taken by a shared role", tenantContext(&globex), "admin", true, nil},
+		{"taken by a tenant role, seen globally", context.Background(), "support", true, nil},
+		{"free globally", context.Background(), "auditor", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := uc.Create(tt.ctx, &dto.CreateRoleRequest{Name: tt.role})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Create(%q) succeeded, want a duplicate name error", tt.role)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create(%q): %v", tt.role, err)
+			}
+			if !sameOrganization(resp.OrganizationID, tt.wantOrganization) {
+				t.Errorf("Create(%q) organization = %v, want %v", tt.role, resp.OrganizationID, tt.wantOrganization)
+			}
+		})
+	}
+}
+
+func TestRoleUpdateAndDeleteKeepTenantsOffSharedRoles(t *testing.T) {
+	organizationID := uuid.New()
+	roles := newFakeRoleRepository()
+	uc := &roleUseCase{roleRepo: roles, audit: &fakeAudit{}}
+
+	shared := roles.add("admin")
+	own := roles.add("support")
+	own.OrganizationID = &organizationID
+	ctx := tenantContext(&organizationID)
+
+	if _, err := uc.Update(ctx, shared.ID, &dto.UpdateRoleRequest{Description: "changed"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Update() of a shared role = %v, want ErrForbidden", err)
+	}
+	if err := uc.Delete(ctx, shared.ID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Delete() of a shared role = %v, want ErrForbidden", err)
+	}
+	if shared.Description != "" || roles.roles[shared.ID] == nil {
+		t.Error("the shared role was changed by a tenant caller")
+	}
+
+	if _, err := uc.Update(ctx, own.ID, &dto.UpdateRoleRequest{Description: "changed"}); err != nil {
+		t.Errorf("Update() of the organization's role: %v", err)
+	}
+	if err := uc.Delete(ctx, own.ID); err != nil {
+		t.Errorf("Delete() of the organization's role: %v", err)
+	}
+	if roles.roles[own.ID] != nil {
+		t.Error("the organization's role was not deleted")
+	}
+
+	// Callers outside any organization manage shared roles
+	if _, err := uc.Update(context.Background(), shared.ID, &dto.UpdateRoleRequest{Description: "changed"}); err != nil {
+		t.Errorf("Update() of a shared role outside a tenant: %v", err)
+	}
+}
+
+func sameOrganization(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+
+	"github.com/google/uuid"
+)
+
+// RoleConflictError is returned when a change would give a user roles that a separation-of-duties
+// constraint keeps apart
+type RoleConflictError struct {
+	Constraint string
+	Roles      []string
+}
+
+func (e *RoleConflictError) Error() string {
+	return fmt.Sprintf("roles %s cannot be held together (constraint %q)", strings.Join(e.Roles, ", "), e.Constraint)
+}
+
+// separationOfDuties enforces role constraints. A user holds a role when it is assigned to them
+// (scheduled assignments included, expired ones not), carried by one of their groups, or inherited
+// by one of those roles, in any organization.
+type separationOfDuties struct {
+	constraintRepo repositories.RoleConstraintRepository
+	userRepo       repositories.UserRepository
+	roleRepo       repositories.RoleRepository
+}
+
+// checkAssignedRoles checks a user whose direct assignments in the organization are about to be
+// replaced by roleIDs. userID may be uuid.Nil for a user that does not exist yet.
+func (s *separationOfDuties) checkAssignedRoles(userID, organizationID uuid.UUID, roleIDs []uuid.UUID) error {
+	assignments, err := s.userRepo.FindRoleAssignments(userID)
+	if err != nil {
+		return err
+	}
+	groupRoles, err := s.roleRepo.FindAllGroupRolesByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	held := append([]uuid.UUID{}, roleIDs...)
+	for _, assignment := range assignments {
+		if assignment.OrganizationID != organizationID && (assignment.ExpiresAt == nil || assignment.ExpiresAt.After(now)) {
+			held = append(held, assignment.RoleID)
+		}
+	}
+	for _, role := range groupRoles {
+		held = append(held, role.ID)
+	}
+	return s.check(held)
+}
+
+// checkAddedRoles checks a user who is about to receive roleIDs on top of the roles they hold
+func (s *separationOfDuties) checkAddedRoles(userID uuid.UUID, roleIDs []uuid.UUID) error {
+	held, err := s.heldRoleIDs(userID)
+	if err != nil {
+		return err
+	}
+	return s.check(append(held, roleIDs...))
+}
+
+// checkGroupRoles checks a user whose roles held through groups are about to become groupRoleIDs
+func (s *separationOfDuties) checkGroupRoles(userID uuid.UUID, groupRoleIDs []uuid.UUID) error {
+	held, err := s.assignedRoleIDs(userID)
+	if err != nil {
+		return err
+	}
+	return s.check(append(held, groupRoleIDs...))
+}
+
+// heldRoleIDs returns the roles assigned to the user directly or through groups, before inheritance
+func (s *separationOfDuties) heldRoleIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	held, err := s.assignedRoleIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	groupRoles, err := s.roleRepo.FindAllGroupRolesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range groupRoles {
+		held = append(held, role.ID)
+	}
+	return held, nil
+}
+
+// assignedRoleIDs returns the roles assigned to the user directly that have not expired
+func (s *separationOfDuties) assignedRoleIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	assignments, err := s.userRepo.FindRoleAssignments(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var held []uuid.UUID
+	for _, assignment := range assignments {
+		if assignment.ExpiresAt == nil || assignment.ExpiresAt.After(now) {
+			held = append(held, assignment.RoleID)
+		}
+	}
+	return held, nil
+}
+
+// check returns a RoleConflictError for the first constraint roleIDs, and the roles they inherit,
+// would violate
+func (s *separationOfDuties) check(roleIDs []uuid.UUID) error {
+	conflicts, err := s.conflicts(roleIDs)
+	if err != nil || len(conflicts) == 0 {
+		return err
+	}
+	return conflicts[0]
+}
+
+// conflicts lists every constraint violated by holding roleIDs and the roles they inherit
+func (s *separationOfDuties) conflicts(roleIDs []uuid.UUID) ([]*RoleConflictError, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	held, err := s.withInherited(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	heldIDs := make([]uuid.UUID, 0, len(held))
+	for id := range held {
+		heldIDs = append(heldIDs, id)
+	}
+	constraints, err := s.constraintRepo.FindByRoleIDs(heldIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []*RoleConflictError
+	for _, constraint := range constraints {
+		if conflict := violation(constraint, held); conflict != nil {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts, nil
+}
+
+// withInherited returns roleIDs and every role they inherit from
+func (s *separationOfDuties) withInherited(roleIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	ancestorIDs, err := s.roleRepo.FindAncestorIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	held := make(map[uuid.UUID]bool)
+	for _, id := range append(roleIDs, ancestorIDs...) {
+		held[id] = true
+	}
+	return held, nil
+}
+
+// violation reports the roles of the constraint found in held, when there is more than one
+func violation(constraint *entities.RoleConstraint, held map[uuid.UUID]bool) *RoleConflictError {
+	var names []string
+	for _, role := range constraint.Roles {
+		if held[role.ID] {
+			names = append(names, role.Name)
+		}
+	}
+	if len(names) < 2 {
+		return nil
+	}
+	sort.Strings(names)
+	return &RoleConflictError{Constraint: constraint.Name, Roles: names}
+}
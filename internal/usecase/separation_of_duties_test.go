@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type dutiesFixture struct {
+	duties      *separationOfDuties
+	users       *fakeUserRepository
+	roles       *fakeRoleRepository
+	groups      *fakeGroupRepository
+	constraints *fakeRoleConstraintRepository
+
+	userID                     uuid.UUID
+	requester, approver, other *entities.Role
+}
+
+// newDutiesFixture keeps requester and approver apart
+func newDutiesFixture() *dutiesFixture {
+	f := &dutiesFixture{
+		users:       newFakeUserRepository(),
+		roles:       newFakeRoleRepository(),
+		constraints: &fakeRoleConstraintRepository{},
+		userID:      uuid.New(),
+	}
+	f.groups = newFakeGroupRepository(f.roles)
+	f.duties = &separationOfDuties{constraintRepo: f.constraints, userRepo: f.users, roleRepo: f.roles}
+	f.requester = f.roles.add("requester")
+	f.approver = f.roles.add("approver")
+	f.other = f.roles.add("other")
+	f.constraints.add("payments", f.approver, f.requester)
+	return f
+}
+
+func (f *dutiesFixture) assign(role *entities.Role, organizationID uuid.UUID, expiresAt *time.Time) {
+	f.users.assignments[f.userID] = append(f.users.assignments[f.userID], &entities.UserRole{
+		UserID:         f.userID,
+		RoleID:         role.ID,
+		OrganizationID: organizationID,
+		ExpiresAt:      expiresAt,
+	})
+}
+
+func assertRoleConflict(t *testing.T, err error, constraint string, roles ...string) {
+	t.Helper()
+	var conflict *RoleConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("error = %v, want a RoleConflictError", err)
+	}
+	if conflict.Constraint != constraint || !reflect.DeepEqual(conflict.Roles, roles) {
+		t.Errorf("conflict = %q %v, want %q %v", conflict.Constraint, conflict.Roles, constraint, roles)
+	}
+}
+
+func TestSeparationOfDutiesCheckAddedRoles(t *testing.T) {
+	f := newDutiesFixture()
+	f.assign(f.requester, uuid.Nil, nil)
+
+	assertRoleConflict(t, f.duties.checkAddedRoles(f.userID, []uuid.UUID{f.approver.ID}), "payments", "approver", "requester")
+
+	if err := f.duties.checkAddedRoles(f.userID, []uuid.UUID{f.other.ID}); err != nil {
+		t.Errorf("adding an unconstrained role: %v", err)
+	}
+}
+
+func TestSeparationOfDutiesIgnoresExpiredAssignments(t *testing.T) {
+	f := newDutiesFixture()
+	expired := time.Now().Add(-time.Hour)
+	f.assign(f.requester, uuid.Nil, &expired)
+
+	if err := f.duties.checkAddedRoles(f.userID, []uuid.UUID{f.approver.ID}); err != nil {
+		t.Errorf("expired assignment counted: %v", err)
+	}
+
+	expiresLater := time.Now().Add(time.Hour)
+	f.assign(f.requester, uuid.New(), &expiresLater)
+	assertRoleConflict(t, f.duties.checkAddedRoles(f.userID, []uuid.UUID{f.approver.ID}), "payments", "approver", "requester")
+}
+
+func TestSeparationOfDutiesCountsInheritedAndGroupRoles(t *testing.T) {
+	f := newDutiesFixture()
+	senior := f.roles.add("senior approver")
+	f.roles.parents[senior.ID] = []uuid.UUID{f.approver.ID}
+
+	group := f.groups.add("requesters", nil, f.requester)
+	f.groups.AddMembers(group.ID, []uuid.UUID{f.userID})
+	assertRoleConflict(t, f.duties.checkAddedRoles(f.userID, []uuid.UUID{senior.ID}), "payments", "approver", "requester")
+}
+
+func TestSeparationOfDutiesCheckAssignedRoles(t *testing.T) {
+	f := newDutiesFixture()
+	organizationID := uuid.New()
+	f.assign(f.requester, organizationID, nil)
+
+	// The assignments in the organization are replaced, so dropping requester for approver is fine
+	if err := f.duties.checkAssignedRoles(f.userID, organizationID, []uuid.UUID{f.approver.ID}); err != nil {
+		t.Errorf("replacing the organization's roles: %v", err)
+	}
+
+	// Assignments in other organizations are kept
+	assertRoleConflict(t, f.duties.checkAssignedRoles(f.userID, uuid.Nil, []uuid.UUID{f.approver.ID}), "payments", "approver", "requester")
+
+	// A user that does not exist yet only holds the given roles
+	assertRoleConflict(t, f.duties.checkAssignedRoles(uuid.Nil, uuid.Nil, []uuid.UUID{f.approver.ID, f.requester.ID}), "payments", "approver", "requester")
+}
+
+func TestSeparationOfDutiesCheckGroupRoles(t *testing.T) {
+	f := newDutiesFixture()
+	f.assign(f.requester, uuid.Nil, nil)
+	// Groups of every organization count
+	organizationID := uuid.New()
+	group := f.groups.add("approvers", &organizationID, f.approver)
+	f.groups.AddMembers(group.ID, []uuid.UUID{f.userID})
+
+	// The roles held through groups are replaced by the given ones
+	if err := f.duties.checkGroupRoles(f.userID, []uuid.UUID{f.other.ID}); err != nil {
+		t.Errorf("replacing the group roles: %v", err)
+	}
+	assertRoleConflict(t, f.duties.checkGroupRoles(f.userID, []uuid.UUID{f.approver.ID}), "payments", "approver", "requester")
+}
+
+func TestSeparationOfDutiesConflictsListsEveryConstraint(t *testing.T) {
+	f := newDutiesFixture()
+	auditor := f.roles.add("auditor")
+	f.constraints.add("audit", auditor, f.approver)
+
+	conflicts, err := f.duties.conflicts([]uuid.UUID{f.requester.ID, f.approver.ID, auditor.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 2 {
+		t.Fatalf("conflicts = %v, want both constraints", conflicts)
+	}
+
+	conflicts, err = f.duties.conflicts([]uuid.UUID{f.approver.ID, f.other.ID})
+	if err != nil || len(conflicts) != 0 {
+		t.Errorf("conflicts = %v, %v, want none", conflicts, err)
+	}
+}
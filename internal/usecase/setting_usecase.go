@@ -10,28 +10,42 @@ import (
 	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
 	"usermanagement-api/pkg/cache"
+
+	"github.com/google/uuid"
 )
 
 type SettingUseCase interface {
-	CreateOrUpdate(key, value string) error
-	GetByKey(key string) (*dto.SettingResponse, error)
-	GetAll() (map[string]string, error)
-	Delete(key string) error
+	CreateOrUpdate(ctx context.Context, key, value string) error
+	GetByKey(ctx context.Context, key string) (*dto.SettingResponse, error)
+	GetAll(ctx context.Context) (map[string]string, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type settingUseCase struct {
 	settingRepo repositories.SettingRepository
 	cache       cache.Cache
+	audit       AuditUseCase
 }
 
-func NewSettingUseCase(settingRepo repositories.SettingRepository, cache cache.Cache) SettingUseCase {
+func NewSettingUseCase(settingRepo repositories.SettingRepository, cache cache.Cache, audit AuditUseCase) SettingUseCase {
 	return &settingUseCase{
 		settingRepo: settingRepo,
 		cache:       cache,
+		audit:       audit,
 	}
 }
 
-func (uc *settingUseCase) CreateOrUpdate(key, value string) error {
+// CreateOrUpdate writes a global setting, or an override of it when the caller acts in an organization
+func (uc *settingUseCase) CreateOrUpdate(ctx context.Context, key, value string) error {
+	if organizationID := activeTenant(ctx); organizationID != nil {
+		return uc.upsertOrganizationSetting(ctx, *organizationID, key, value)
+	}
+
+	var before *dto.SettingResponse
+	if existing, err := uc.settingRepo.FindByKey(key); err == nil {
+		before = &dto.SettingResponse{Key: existing.Key, Value: existing.Value}
+	}
+
 	setting := &entities.Setting{
 		Key:   key,
 		Value: value,
@@ -41,16 +55,27 @@ func (uc *settingUseCase) CreateOrUpdate(key, value string) error {
 		return err
 	}
 
+	uc.audit.Record(ctx, constants.AuditActionSettingUpsert, constants.AuditTargetSetting, key, before, &dto.SettingResponse{Key: key, Value: value})
+
 	// Clear cache
-	ctx := context.Background()
 	_ = uc.cache.Delete(ctx, constants.SettingsCacheKey)
 
 	return nil
 }
 
-func (uc *settingUseCase) GetByKey(key string) (*dto.SettingResponse, error) {
+// GetByKey returns the setting, preferring the override of the caller's organization
+func (uc *settingUseCase) GetByKey(ctx context.Context, key string) (*dto.SettingResponse, error) {
+	if organizationID := activeTenant(ctx); organizationID != nil {
+		overrides, err := uc.organizationSettings(ctx, *organizationID)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := overrides[key]; ok {
+			return &dto.SettingResponse{Key: key, Value: value, OrganizationID: organizationID}, nil
+		}
+	}
+
 	// Check cache first
-	ctx := context.Background()
 	cachedData, err := uc.cache.Get(ctx, constants.SettingsCacheKey)
 	if err == nil && cachedData != "" {
 		var settingsMap map[string]string
@@ -79,9 +104,28 @@ func (uc *settingUseCase) GetByKey(key string) (*dto.SettingResponse, error) {
 	}, nil
 }
 
-func (uc *settingUseCase) GetAll() (map[string]string, error) {
+// GetAll returns the global settings merged with the overrides of the caller's organization
+func (uc *settingUseCase) GetAll(ctx context.Context) (map[string]string, error) {
+	settingsMap, err := uc.globalSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if organizationID := activeTenant(ctx); organizationID != nil {
+		overrides, err := uc.organizationSettings(ctx, *organizationID)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range overrides {
+			settingsMap[key] = value
+		}
+	}
+
+	return settingsMap, nil
+}
+
+func (uc *settingUseCase) globalSettings(ctx context.Context) (map[string]string, error) {
 	// Check cache first
-	ctx := context.Background()
 	cachedData, err := uc.cache.Get(ctx, constants.SettingsCacheKey)
 	if err == nil && cachedData != "" {
 		var settingsMap map[string]string
@@ -107,13 +151,24 @@ func (uc *settingUseCase) GetAll() (map[string]string, error) {
 	return settingsMap, nil
 }
 
-func (uc *settingUseCase) Delete(key string) error {
+// Delete removes a global setting, or the override of the caller's organization
+func (uc *settingUseCase) Delete(ctx context.Context, key string) error {
+	if organizationID := activeTenant(ctx); organizationID != nil {
+		return uc.deleteOrganizationSetting(ctx, *organizationID, key)
+	}
+
+	existing, err := uc.settingRepo.FindByKey(key)
+	if err != nil {
+		return err
+	}
+
 	if err := uc.settingRepo.Delete(key); err != nil {
 		return err
 	}
 
+	uc.audit.Record(ctx, constants.AuditActionSettingDelete, constants.AuditTargetSetting, key, &dto.SettingResponse{Key: existing.Key, Value: existing.Value}, nil)
+
 	// Clear cache
-	ctx := context.Background()
 	_ = uc.cache.Delete(ctx, constants.SettingsCacheKey)
 
 	return nil
@@ -131,3 +186,70 @@ func (uc *settingUseCase) updateSettingsCache() {
 		_ = uc.cache.Set(ctx, constants.SettingsCacheKey, settingsMap, 1*time.Hour)
 	}
 }
+
+func (uc *settingUseCase) organizationSettings(ctx context.Context, organizationID uuid.UUID) (map[string]string, error) {
+	cacheKey := organizationSettingsCacheKey(organizationID)
+	cachedData, err := uc.cache.Get(ctx, cacheKey)
+	if err == nil && cachedData != "" {
+		var settingsMap map[string]string
+		if err := json.Unmarshal([]byte(cachedData), &settingsMap); err == nil {
+			return settingsMap, nil
+		}
+	}
+
+	settings, err := uc.settingRepo.FindOrganizationSettings(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	settingsMap := make(map[string]string)
+	for _, setting := range settings {
+		settingsMap[setting.Key] = setting.Value
+	}
+	_ = uc.cache.Set(ctx, cacheKey, settingsMap, 1*time.Hour)
+
+	return settingsMap, nil
+}
+
+func (uc *settingUseCase) upsertOrganizationSetting(ctx context.Context, organizationID uuid.UUID, key, value string) error {
+	var before *dto.SettingResponse
+	if existing, err := uc.settingRepo.FindOrganizationSetting(organizationID, key); err == nil {
+		before = &dto.SettingResponse{Key: existing.Key, Value: existing.Value, OrganizationID: &organizationID}
+	}
+
+	setting := &entities.OrganizationSetting{
+		OrganizationID: organizationID,
+		Key:            key,
+		Value:          value,
+	}
+	if err := uc.settingRepo.UpsertOrganizationSetting(setting); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionSettingUpsert, constants.AuditTargetSetting, key, before, &dto.SettingResponse{Key: key, Value: value, OrganizationID: &organizationID})
+
+	_ = uc.cache.Delete(ctx, organizationSettingsCacheKey(organizationID))
+
+	return nil
+}
+
+func (uc *settingUseCase) deleteOrganizationSetting(ctx context.Context, organizationID uuid.UUID, key string) error {
+	existing, err := uc.settingRepo.FindOrganizationSetting(organizationID, key)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.settingRepo.DeleteOrganizationSetting(organizationID, key); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionSettingDelete, constants.AuditTargetSetting, key, &dto.SettingResponse{Key: existing.Key, Value: existing.Value, OrganizationID: &organizationID}, nil)
+
+	_ = uc.cache.Delete(ctx, organizationSettingsCacheKey(organizationID))
+
+	return nil
+}
+
+func organizationSettingsCacheKey(organizationID uuid.UUID) string {
+	return constants.SettingsCacheKey + ":" + organizationID.String()
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// activeTenant returns the organization a tenant-scoped caller acts in. It is nil for
+// superusers, background work and callers without an active organization.
+func activeTenant(ctx context.Context) *uuid.UUID {
+	info := requestctx.FromContext(ctx)
+	if !info.TenantScoped() {
+		return nil
+	}
+	return info.OrganizationID
+}
+
+// rolesInOrganization keeps the roles that apply while acting in the organization
+func rolesInOrganization(roles []*entities.Role, organizationID *uuid.UUID) []*entities.Role {
+	var applicable []*entities.Role
+	for _, role := range roles {
+		if role.AppliesIn(organizationID) {
+			applicable = append(applicable, role)
+		}
+	}
+	return applicable
+}
+
+// assignmentOrganization returns the organization the caller's role assignments are made in,
+// uuid.Nil when the caller acts outside any organization
+func assignmentOrganization(ctx context.Context) uuid.UUID {
+	if organizationID := requestctx.FromContext(ctx).OrganizationID; organizationID != nil {
+		return *organizationID
+	}
+	return uuid.Nil
+}
+
+// actingIn returns a copy of ctx whose caller acts in the organization the role assignment was
+// made in, for work done on behalf of an assignment rather than of the caller's own organization
+func actingIn(ctx context.Context, organizationID uuid.UUID) context.Context {
+	info := *requestctx.FromContext(ctx)
+	info.OrganizationID = nil
+	if organizationID != uuid.Nil {
+		info.OrganizationID = &organizationID
+	}
+	return requestctx.WithInfo(ctx, &info)
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"usermanagement-api/domain/entities"
+	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
+	"usermanagement-api/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// metaAccess applies user meta key visibility to the caller carried in ctx.
+// Owners manage their own default and private keys, user_meta.manage holders manage
+// default and admin-only keys of anyone.
+type metaAccess struct {
+	policyRepo repositories.UserMetaKeyPolicyRepository
+}
+
+// visibilities returns the visibility of every key with a built-in or stored policy
+func (a *metaAccess) visibilities() (map[string]string, error) {
+	visibilities := make(map[string]string, len(constants.DefaultMetaVisibility))
+	for key, visibility := range constants.DefaultMetaVisibility {
+		visibilities[key] = visibility
+	}
+
+	policies, err := a.policyRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		visibilities[policy.Key] = policy.Visibility
+	}
+
+	return visibilities, nil
+}
+
+// authorize returns ErrForbidden unless the caller may access every key of ownerID's meta
+func (a *metaAccess) authorize(ctx context.Context, ownerID uuid.UUID, keys ...string) error {
+	visibilities, err := a.visibilities()
+	if err != nil {
+		return err
+	}
+
+	info := requestctx.FromContext(ctx)
+	for _, key := range keys {
+		if !canAccessMeta(info, ownerID, visibilities[key]) {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// filter drops the keys the caller may not see from ownerID's meta
+func (a *metaAccess) filter(ctx context.Context, ownerID uuid.UUID, meta map[string]string) (map[string]string, error) {
+	visibilities, err := a.visibilities()
+	if err != nil {
+		return nil, err
+	}
+
+	info := requestctx.FromContext(ctx)
+	visible := make(map[string]string, len(meta))
+	for key, value := range meta {
+		if canAccessMeta(info, ownerID, visibilities[key]) {
+			visible[key] = value
+		}
+	}
+	return visible, nil
+}
+
+// trusted keeps the meta of ownerID that authorization may rely on: admin-only keys, unless the
+// owner wrote the value themselves. Users cannot grant themselves access through their own meta.
+func (a *metaAccess) trusted(ownerID uuid.UUID, metas []*entities.UserMeta) (map[string]string, error) {
+	visibilities, err := a.visibilities()
+	if err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string]string, len(metas))
+	for _, m := range metas {
+		if visibilities[m.Key] != constants.MetaVisibilityAdminOnly || (m.UpdatedBy != nil && *m.UpdatedBy == ownerID) {
+			continue
+		}
+		meta[m.Key] = m.Value
+	}
+	return meta, nil
+}
+
+func canAccessMeta(info *requestctx.Info, ownerID uuid.UUID, visibility string) bool {
+	isOwner := info.IsUser(ownerID)
+	canManage := info.Can(constants.PermissionUserMetaManage)
+
+	switch visibility {
+	case constants.MetaVisibilityPrivate:
+		return isOwner
+	case constants.MetaVisibilityAdminOnly:
+		return canManage
+	default:
+		return isOwner || canManage
+	}
+}
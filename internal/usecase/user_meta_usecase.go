@@ -5,54 +5,76 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"sort"
 	"time"
 	"usermanagement-api/domain/entities"
 	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
 	"usermanagement-api/pkg/cache"
+	"usermanagement-api/pkg/requestctx"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserMetaUseCase interface {
-	CreateOrUpdate(userID uuid.UUID, key, value string) error
-	GetByKey(userID uuid.UUID, key string) (*dto.UserMetaResponse, error)
-	GetAllByUserID(userID uuid.UUID) (map[string]string, error)
-	Delete(userID uuid.UUID, key string) error
+	CreateOrUpdate(ctx context.Context, userID uuid.UUID, key, value string) error
+	GetByKey(ctx context.Context, userID uuid.UUID, key string) (*dto.UserMetaResponse, error)
+	GetAllByUserID(ctx context.Context, userID uuid.UUID) (map[string]string, error)
+	Delete(ctx context.Context, userID uuid.UUID, key string) error
+	GetKeyPolicies() ([]*dto.UserMetaKeyPolicyResponse, error)
+	SetKeyPolicy(ctx context.Context, key, visibility string) (*dto.UserMetaKeyPolicyResponse, error)
+	DeleteKeyPolicy(ctx context.Context, key string) error
 }
 
 type userMetaUseCase struct {
 	userMetaRepo repositories.UserMetaRepository
+	policyRepo   repositories.UserMetaKeyPolicyRepository
 	cache        cache.Cache
+	access       *metaAccess
+	roleRules    RoleAssignmentRuleUseCase
+	audit        AuditUseCase
 }
 
-func NewUserMetaUseCase(userMetaRepo repositories.UserMetaRepository, cache cache.Cache) UserMetaUseCase {
+func NewUserMetaUseCase(userMetaRepo repositories.UserMetaRepository, policyRepo repositories.UserMetaKeyPolicyRepository, cache cache.Cache, roleRules RoleAssignmentRuleUseCase, audit AuditUseCase) UserMetaUseCase {
 	return &userMetaUseCase{
 		userMetaRepo: userMetaRepo,
+		policyRepo:   policyRepo,
 		cache:        cache,
+		access:       &metaAccess{policyRepo: policyRepo},
+		roleRules:    roleRules,
+		audit:        audit,
 	}
 }
 
-func (uc *userMetaUseCase) CreateOrUpdate(userID uuid.UUID, key, value string) error {
+func (uc *userMetaUseCase) CreateOrUpdate(ctx context.Context, userID uuid.UUID, key, value string) error {
+	if err := uc.access.authorize(ctx, userID, key); err != nil {
+		return err
+	}
+
 	// Try to find existing meta
 	existingMeta, err := uc.userMetaRepo.FindByUserIDAndKey(userID, key)
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return err
 	}
 
+	updatedBy := requestctx.FromContext(ctx).UserID
 	if existingMeta != nil {
 		// Update existing
 		existingMeta.Value = value
+		existingMeta.UpdatedBy = updatedBy
 		if err := uc.userMetaRepo.Update(existingMeta); err != nil {
 			return err
 		}
 	} else {
 		// Create new
 		userMeta := &entities.UserMeta{
-			Key:    key,
-			Value:  value,
-			UserID: userID,
+			Key:       key,
+			Value:     value,
+			UserID:    userID,
+			UpdatedBy: updatedBy,
 		}
 		if err := uc.userMetaRepo.Create(userMeta); err != nil {
 			return err
@@ -61,15 +83,18 @@ func (uc *userMetaUseCase) CreateOrUpdate(userID uuid.UUID, key, value string) e
 
 	// Clear cache
 	cacheKey := uc.getUserMetaCacheKey(userID)
-	ctx := context.Background()
 	_ = uc.cache.Delete(ctx, cacheKey)
 
+	uc.applyRoleRules(ctx, userID)
 	return nil
 }
 
-func (uc *userMetaUseCase) GetByKey(userID uuid.UUID, key string) (*dto.UserMetaResponse, error) {
+func (uc *userMetaUseCase) GetByKey(ctx context.Context, userID uuid.UUID, key string) (*dto.UserMetaResponse, error) {
+	if err := uc.access.authorize(ctx, userID, key); err != nil {
+		return nil, err
+	}
+
 	// Check cache first
-	ctx := context.Background()
 	cacheKey := uc.getUserMetaCacheKey(userID)
 
 	cachedData, err := uc.cache.Get(ctx, cacheKey)
@@ -103,16 +128,16 @@ func (uc *userMetaUseCase) GetByKey(userID uuid.UUID, key string) (*dto.UserMeta
 	}, nil
 }
 
-func (uc *userMetaUseCase) GetAllByUserID(userID uuid.UUID) (map[string]string, error) {
+// GetAllByUserID returns the user's meta, without the keys the caller may not see
+func (uc *userMetaUseCase) GetAllByUserID(ctx context.Context, userID uuid.UUID) (map[string]string, error) {
 	// Check cache first
-	ctx := context.Background()
 	cacheKey := uc.getUserMetaCacheKey(userID)
 
 	cachedData, err := uc.cache.Get(ctx, cacheKey)
 	if err == nil && cachedData != "" {
 		var metaMap map[string]string
 		if err := json.Unmarshal([]byte(cachedData), &metaMap); err == nil {
-			return metaMap, nil
+			return uc.access.filter(ctx, userID, metaMap)
 		}
 	}
 
@@ -125,10 +150,14 @@ func (uc *userMetaUseCase) GetAllByUserID(userID uuid.UUID) (map[string]string,
 	// Update cache
 	_ = uc.cache.Set(ctx, cacheKey, metaMap, 30*time.Minute)
 
-	return metaMap, nil
+	return uc.access.filter(ctx, userID, metaMap)
 }
 
-func (uc *userMetaUseCase) Delete(userID uuid.UUID, key string) error {
+func (uc *userMetaUseCase) Delete(ctx context.Context, userID uuid.UUID, key string) error {
+	if err := uc.access.authorize(ctx, userID, key); err != nil {
+		return err
+	}
+
 	userMeta, err := uc.userMetaRepo.FindByUserIDAndKey(userID, key)
 	if err != nil {
 		return err
@@ -140,12 +169,96 @@ func (uc *userMetaUseCase) Delete(userID uuid.UUID, key string) error {
 
 	// Clear cache
 	cacheKey := uc.getUserMetaCacheKey(userID)
-	ctx := context.Background()
 	_ = uc.cache.Delete(ctx, cacheKey)
 
+	uc.applyRoleRules(ctx, userID)
+	return nil
+}
+
+// applyRoleRules re-evaluates the role assignment rules after a meta change. A failure does not
+// undo the change, the periodic sync catches up. Users changing their own meta never trigger
+// rules, rules also ignore the values they wrote themselves.
+func (uc *userMetaUseCase) applyRoleRules(ctx context.Context, userID uuid.UUID) {
+	if requestctx.FromContext(ctx).IsUser(userID) {
+		return
+	}
+	if err := uc.roleRules.EvaluateUser(ctx, userID); err != nil {
+		log.Printf("Failed to apply role assignment rules to user %s: %v", userID, err)
+	}
+}
+
+// GetKeyPolicies lists every key with a non-default visibility, stored or built in
+func (uc *userMetaUseCase) GetKeyPolicies() ([]*dto.UserMetaKeyPolicyResponse, error) {
+	policies, err := uc.policyRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]bool)
+	var response []*dto.UserMetaKeyPolicyResponse
+	for _, policy := range policies {
+		stored[policy.Key] = true
+		response = append(response, uc.mapToKeyPolicyResponse(policy))
+	}
+	for key, visibility := range constants.DefaultMetaVisibility {
+		if !stored[key] {
+			response = append(response, &dto.UserMetaKeyPolicyResponse{
+				Key:        key,
+				Visibility: visibility,
+				BuiltIn:    true,
+			})
+		}
+	}
+
+	sort.Slice(response, func(i, j int) bool { return response[i].Key < response[j].Key })
+	return response, nil
+}
+
+func (uc *userMetaUseCase) SetKeyPolicy(ctx context.Context, key, visibility string) (*dto.UserMetaKeyPolicyResponse, error) {
+	var before *dto.UserMetaKeyPolicyResponse
+	if existing, err := uc.policyRepo.FindByKey(key); err == nil {
+		before = uc.mapToKeyPolicyResponse(existing)
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	policy := &entities.UserMetaKeyPolicy{
+		Key:        key,
+		Visibility: visibility,
+	}
+	if err := uc.policyRepo.Upsert(policy); err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToKeyPolicyResponse(policy)
+	uc.audit.Record(ctx, constants.AuditActionUserMetaKeyUpsert, constants.AuditTargetUserMetaKey, key, before, response)
+
+	return response, nil
+}
+
+// DeleteKeyPolicy removes a stored policy, the key falls back to its built-in or default visibility
+func (uc *userMetaUseCase) DeleteKeyPolicy(ctx context.Context, key string) error {
+	policy, err := uc.policyRepo.FindByKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.policyRepo.Delete(key); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionUserMetaKeyDelete, constants.AuditTargetUserMetaKey, key, uc.mapToKeyPolicyResponse(policy), nil)
 	return nil
 }
 
+func (uc *userMetaUseCase) mapToKeyPolicyResponse(policy *entities.UserMetaKeyPolicy) *dto.UserMetaKeyPolicyResponse {
+	return &dto.UserMetaKeyPolicyResponse{
+		Key:        policy.Key,
+		Visibility: policy.Visibility,
+		UpdatedAt:  policy.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
 func (uc *userMetaUseCase) getUserMetaCacheKey(userID uuid.UUID) string {
 	return fmt.Sprintf("user_meta:%s", userID.String())
 }
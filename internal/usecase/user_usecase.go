@@ -1,42 +1,58 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"log"
 	"time"
 	"usermanagement-api/domain/entities"
 	"usermanagement-api/domain/repositories"
+	"usermanagement-api/internal/constants"
 	"usermanagement-api/internal/dto"
+	"usermanagement-api/pkg/requestctx"
 	"usermanagement-api/pkg/utils"
 
 	"github.com/google/uuid"
 )
 
 type UserUseCase interface {
-	Create(req *dto.CreateUserRequest) (*dto.UserResponse, error)
-	GetByID(id uuid.UUID) (*dto.UserResponse, error)
-	GetAll(page, pageSize int) ([]*dto.UserResponse, int64, error)
-	Update(id uuid.UUID, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
-	Delete(id uuid.UUID) error
-	AssignRoles(userID uuid.UUID, roleIDs []uuid.UUID) (*dto.UserResponse, error)
+	Create(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error)
+	GetAll(ctx context.Context, page, pageSize int) ([]*dto.UserResponse, int64, error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	AssignRoles(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) (*dto.UserResponse, error)
+	AssignDeniedPermissions(ctx context.Context, userID uuid.UUID, permissionIDs []uuid.UUID) (*dto.UserResponse, error)
 	GetUserWithMeta(id uuid.UUID) (*dto.UserResponse, error)
 }
 
 type userUseCase struct {
-	userRepo     repositories.UserRepository
-	roleRepo     repositories.RoleRepository
-	userMetaRepo repositories.UserMetaRepository
+	userRepo         repositories.UserRepository
+	roleRepo         repositories.RoleRepository
+	userMetaRepo     repositories.UserMetaRepository
+	organizationRepo repositories.OrganizationRepository
+	metaAccess       *metaAccess
+	duties           *separationOfDuties
+	delegation       *delegatedAdministration
+	roleRules        RoleAssignmentRuleUseCase
+	audit            AuditUseCase
 }
 
-func NewUserUseCase(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, userMetaRepo repositories.UserMetaRepository) UserUseCase {
+func NewUserUseCase(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, userMetaRepo repositories.UserMetaRepository, metaKeyPolicyRepo repositories.UserMetaKeyPolicyRepository, organizationRepo repositories.OrganizationRepository, roleConstraintRepo repositories.RoleConstraintRepository, groupRepo repositories.GroupRepository, roleRules RoleAssignmentRuleUseCase, audit AuditUseCase) UserUseCase {
 	return &userUseCase{
-		userRepo:     userRepo,
-		roleRepo:     roleRepo,
-		userMetaRepo: userMetaRepo,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		userMetaRepo:     userMetaRepo,
+		organizationRepo: organizationRepo,
+		metaAccess:       &metaAccess{policyRepo: metaKeyPolicyRepo},
+		duties:           &separationOfDuties{constraintRepo: roleConstraintRepo, userRepo: userRepo, roleRepo: roleRepo},
+		delegation:       &delegatedAdministration{userRepo: userRepo, roleRepo: roleRepo, groupRepo: groupRepo, organizationRepo: organizationRepo},
+		roleRules:        roleRules,
+		audit:            audit,
 	}
 }
 
-func (uc *userUseCase) Create(req *dto.CreateUserRequest) (*dto.UserResponse, error) {
+func (uc *userUseCase) Create(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error) {
 	// Check if email already exists
 	if _, err := uc.userRepo.FindByEmail(req.Email); err == nil {
 		return nil, errors.New("email already exists")
@@ -47,6 +63,22 @@ func (uc *userUseCase) Create(req *dto.CreateUserRequest) (*dto.UserResponse, er
 		return nil, errors.New("username already exists")
 	}
 
+	// The caller must be allowed to write every meta key
+	if err := uc.metaAccess.authorize(ctx, uuid.Nil, mapKeys(req.MetaData)...); err != nil {
+		return nil, err
+	}
+
+	// Delegated admins may only give the roles they manage
+	if err := uc.delegation.authorize(ctx, uuid.Nil, req.RoleIDs); err != nil {
+		return nil, err
+	}
+
+	if len(req.RoleIDs) > 0 {
+		if err := uc.checkAssignableRoles(ctx, uuid.Nil, req.RoleIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -62,12 +94,14 @@ func (uc *userUseCase) Create(req *dto.CreateUserRequest) (*dto.UserResponse, er
 		LastName:  req.LastName,
 		IsActive:  true,
 	}
+	if req.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
 
-	// Add roles if provided
-	if len(req.RoleIDs) > 0 {
-		for _, roleID := range req.RoleIDs {
-			user.Roles = append(user.Roles, &entities.Role{ID: roleID})
-		}
+	// Including the roles rules would grant the new user
+	if err := uc.authorizeRuleRoles(ctx, nil, user, req.MetaData); err != nil {
+		return nil, err
 	}
 
 	// Save user
@@ -75,12 +109,27 @@ func (uc *userUseCase) Create(req *dto.CreateUserRequest) (*dto.UserResponse, er
 		return nil, err
 	}
 
+	// Users created inside an organization become its members
+	if organizationID := activeTenant(ctx); organizationID != nil {
+		if err := uc.organizationRepo.AddMembers(*organizationID, []uuid.UUID{user.ID}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Add roles if provided
+	if len(req.RoleIDs) > 0 {
+		if err := uc.userRepo.AssignRoles(user.ID, assignmentOrganization(ctx), req.RoleIDs, requestctx.FromContext(ctx).UserID); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(req.MetaData) > 0 {
 		for key, value := range req.MetaData {
 			userMeta := &entities.UserMeta{
-				Key:    key,
-				Value:  value,
-				UserID: user.ID,
+				Key:       key,
+				Value:     value,
+				UserID:    user.ID,
+				UpdatedBy: requestctx.FromContext(ctx).UserID,
 			}
 			if err := uc.userMetaRepo.Create(userMeta); err != nil {
 				// Log error but don't fail the whole operation
@@ -89,18 +138,22 @@ func (uc *userUseCase) Create(req *dto.CreateUserRequest) (*dto.UserResponse, er
 		}
 	}
 
+	uc.applyRoleRules(ctx, user.ID)
+
 	// Get user with roles
 	userWithRoles, err := uc.userRepo.FindByID(user.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Map to response
-	return uc.mapToUserResponse(userWithRoles), nil
+	response := uc.mapToUserResponse(userWithRoles)
+	uc.audit.Record(ctx, constants.AuditActionUserCreate, constants.AuditTargetUser, user.ID.String(), nil, response)
+
+	return response, nil
 }
 
-func (uc *userUseCase) GetByID(id uuid.UUID) (*dto.UserResponse, error) {
-	user, err := uc.userRepo.FindByID(id)
+func (uc *userUseCase) GetByID(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	user, err := uc.userRepo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -108,8 +161,9 @@ func (uc *userUseCase) GetByID(id uuid.UUID) (*dto.UserResponse, error) {
 	return uc.mapToUserResponse(user), nil
 }
 
-func (uc *userUseCase) GetAll(page, pageSize int) ([]*dto.UserResponse, int64, error) {
-	users, total, err := uc.userRepo.FindAll(page, pageSize)
+// GetAll lists users, limited to the members of the caller's organization for tenant-scoped callers
+func (uc *userUseCase) GetAll(ctx context.Context, page, pageSize int) ([]*dto.UserResponse, int64, error) {
+	users, total, err := uc.userRepo.WithContext(ctx).FindAll(page, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -122,8 +176,25 @@ func (uc *userUseCase) GetAll(page, pageSize int) ([]*dto.UserResponse, int64, e
 	return response, total, nil
 }
 
-func (uc *userUseCase) Update(id uuid.UUID, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
-	user, err := uc.userRepo.FindByID(id)
+func (uc *userUseCase) Update(ctx context.Context, id uuid.UUID, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	user, err := uc.userRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := uc.mapToUserResponse(user)
+	original := *user
+
+	// The caller must be allowed to write every meta key
+	if err := uc.metaAccess.authorize(ctx, id, mapKeys(req.MetaData)...); err != nil {
+		return nil, err
+	}
+
+	// Delegated admins may only change users and roles they manage
+	if len(req.RoleIDs) > 0 {
+		err = uc.delegation.authorizeReplace(ctx, id, req.RoleIDs)
+	} else {
+		err = uc.delegation.authorize(ctx, id, nil)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +214,16 @@ func (uc *userUseCase) Update(id uuid.UUID, req *dto.UpdateUserRequest) (*dto.Us
 			return nil, errors.New("email already exists")
 		}
 		user.Email = req.Email
+		user.EmailVerifiedAt = nil
+	}
+
+	if req.EmailVerified != nil {
+		if !*req.EmailVerified {
+			user.EmailVerifiedAt = nil
+		} else if user.EmailVerifiedAt == nil {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+		}
 	}
 
 	if req.Password != "" {
@@ -165,19 +246,25 @@ func (uc *userUseCase) Update(id uuid.UUID, req *dto.UpdateUserRequest) (*dto.Us
 		user.IsActive = *req.Active
 	}
 
+	if len(req.RoleIDs) > 0 {
+		if err := uc.checkAssignableRoles(ctx, id, req.RoleIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// An email or meta change may make rules grant or revoke roles
+	if err := uc.authorizeRuleRoles(ctx, &original, user, req.MetaData); err != nil {
+		return nil, err
+	}
+
 	// Update user
-	if err := uc.userRepo.Update(user); err != nil {
+	if err := uc.userRepo.WithContext(ctx).Update(user); err != nil {
 		return nil, err
 	}
 
 	// Update roles if provided
 	if len(req.RoleIDs) > 0 {
-		if err := uc.userRepo.AssignRoles(id, req.RoleIDs); err != nil {
-			return nil, err
-		}
-		// Get updated user with roles
-		user, err = uc.userRepo.FindByID(id)
-		if err != nil {
+		if err := uc.userRepo.AssignRoles(id, assignmentOrganization(ctx), req.RoleIDs, requestctx.FromContext(ctx).UserID); err != nil {
 			return nil, err
 		}
 	}
@@ -196,6 +283,7 @@ func (uc *userUseCase) Update(id uuid.UUID, req *dto.UpdateUserRequest) (*dto.Us
 				existingUserMeta, err := uc.userMetaRepo.FindByUserIDAndKey(user.ID, key)
 				if err == nil {
 					existingUserMeta.Value = value
+					existingUserMeta.UpdatedBy = requestctx.FromContext(ctx).UserID
 					if err := uc.userMetaRepo.Update(existingUserMeta); err != nil {
 						log.Printf("Failed to update user meta %s: %v", key, err)
 					}
@@ -203,9 +291,10 @@ func (uc *userUseCase) Update(id uuid.UUID, req *dto.UpdateUserRequest) (*dto.Us
 			} else {
 				// Create new meta
 				userMeta := &entities.UserMeta{
-					Key:    key,
-					Value:  value,
-					UserID: user.ID,
+					Key:       key,
+					Value:     value,
+					UserID:    user.ID,
+					UpdatedBy: requestctx.FromContext(ctx).UserID,
 				}
 				if err := uc.userMetaRepo.Create(userMeta); err != nil {
 					log.Printf("Failed to create user meta %s: %v", key, err)
@@ -214,22 +303,55 @@ func (uc *userUseCase) Update(id uuid.UUID, req *dto.UpdateUserRequest) (*dto.Us
 		}
 	}
 
-	return uc.mapToUserResponse(user), nil
+	// Rules may grant or revoke roles after an email or meta change
+	uc.applyRoleRules(ctx, id)
+
+	// Get updated user with roles
+	user, err = uc.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToUserResponse(user)
+	uc.audit.Record(ctx, constants.AuditActionUserUpdate, constants.AuditTargetUser, id.String(), before, response)
+
+	return response, nil
 }
 
-func (uc *userUseCase) Delete(id uuid.UUID) error {
-	return uc.userRepo.Delete(id)
+func (uc *userUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	user, err := uc.userRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := uc.delegation.authorize(ctx, id, nil); err != nil {
+		return err
+	}
+
+	if err := uc.userRepo.WithContext(ctx).Delete(id); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, constants.AuditActionUserDelete, constants.AuditTargetUser, id.String(), uc.mapToUserResponse(user), nil)
+	return nil
 }
 
-func (uc *userUseCase) AssignRoles(userID uuid.UUID, roleIDs []uuid.UUID) (*dto.UserResponse, error) {
+func (uc *userUseCase) AssignRoles(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) (*dto.UserResponse, error) {
 	// Check if user exists
-	_, err := uc.userRepo.FindByID(userID)
+	user, err := uc.userRepo.WithContext(ctx).FindByID(userID)
 	if err != nil {
 		return nil, err
 	}
+	before := uc.mapToUserResponse(user)
+
+	if err := uc.delegation.authorizeReplace(ctx, userID, roleIDs); err != nil {
+		return nil, err
+	}
+	if err := uc.checkAssignableRoles(ctx, userID, roleIDs); err != nil {
+		return nil, err
+	}
 
 	// Assign roles
-	if err := uc.userRepo.AssignRoles(userID, roleIDs); err != nil {
+	if err := uc.userRepo.AssignRoles(userID, assignmentOrganization(ctx), roleIDs, requestctx.FromContext(ctx).UserID); err != nil {
 		return nil, err
 	}
 
@@ -239,7 +361,103 @@ func (uc *userUseCase) AssignRoles(userID uuid.UUID, roleIDs []uuid.UUID) (*dto.
 		return nil, err
 	}
 
-	return uc.mapToUserResponse(updatedUser), nil
+	response := uc.mapToUserResponse(updatedUser)
+	uc.audit.Record(ctx, constants.AuditActionUserAssignRoles, constants.AuditTargetUser, userID.String(), before, response)
+
+	return response, nil
+}
+
+// AssignDeniedPermissions replaces the permissions explicitly denied to the user, they override
+// every grant including superuser status
+func (uc *userUseCase) AssignDeniedPermissions(ctx context.Context, userID uuid.UUID, permissionIDs []uuid.UUID) (*dto.UserResponse, error) {
+	user, err := uc.userRepo.WithContext(ctx).FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	before := uc.mapToUserResponse(user)
+
+	if err := uc.delegation.authorize(ctx, userID, nil); err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.AssignDeniedPermissions(userID, permissionIDs); err != nil {
+		return nil, err
+	}
+
+	updatedUser, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := uc.mapToUserResponse(updatedUser)
+	uc.audit.Record(ctx, constants.AuditActionUserAssignDenied, constants.AuditTargetUser, userID.String(), before, response)
+
+	return response, nil
+}
+
+// applyRoleRules re-evaluates the role assignment rules for a user that was just saved. A failure
+// does not undo the change, the periodic sync catches up.
+func (uc *userUseCase) applyRoleRules(ctx context.Context, userID uuid.UUID) {
+	if err := uc.roleRules.EvaluateUser(ctx, userID); err != nil {
+		log.Printf("Failed to apply role assignment rules to user %s: %v", userID, err)
+	}
+}
+
+// checkAssignableRoles rejects roles that do not exist, belong to another organization or that
+// the user may not hold together. userID is uuid.Nil for a user being created.
+func (uc *userUseCase) checkAssignableRoles(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	unique := make(map[uuid.UUID]bool)
+	for _, roleID := range roleIDs {
+		unique[roleID] = true
+	}
+
+	roles, err := uc.roleRepo.WithContext(ctx).FindByIDs(roleIDs)
+	if err != nil {
+		return err
+	}
+	if len(roles) != len(unique) {
+		return errors.New("role not found")
+	}
+	return uc.duties.checkAssignedRoles(userID, assignmentOrganization(ctx), roleIDs)
+}
+
+// authorizeRuleRoles rejects a change by a delegated admin that would make role assignment rules
+// grant or revoke roles they do not manage. before is nil for a user being created.
+func (uc *userUseCase) authorizeRuleRoles(ctx context.Context, before, after *entities.User, metaChanges map[string]string) error {
+	if before != nil && before.Email == after.Email && (before.EmailVerifiedAt == nil) == (after.EmailVerifiedAt == nil) && len(metaChanges) == 0 {
+		return nil
+	}
+
+	var current []uuid.UUID
+	if before != nil {
+		var err error
+		if current, err = uc.roleRules.RuleRoles(ctx, before, nil); err != nil {
+			return err
+		}
+	}
+	next, err := uc.roleRules.RuleRoles(ctx, after, metaChanges)
+	if err != nil {
+		return err
+	}
+
+	held := make(map[uuid.UUID]bool, len(current))
+	for _, roleID := range current {
+		held[roleID] = true
+	}
+	var changed []uuid.UUID
+	for _, roleID := range next {
+		if !held[roleID] {
+			changed = append(changed, roleID)
+		}
+		delete(held, roleID)
+	}
+	for roleID := range held {
+		changed = append(changed, roleID)
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	return uc.delegation.authorize(ctx, after.ID, changed)
 }
 
 func (uc *userUseCase) mapToUserResponse(user *entities.User) *dto.UserResponse {
@@ -254,6 +472,7 @@ func (uc *userUseCase) mapToUserResponse(user *entities.User) *dto.UserResponse
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
 	}
+	resp.EmailVerified = user.EmailVerifiedAt != nil
 
 	// Map roles
 	if len(user.Roles) > 0 {
@@ -266,6 +485,13 @@ func (uc *userUseCase) mapToUserResponse(user *entities.User) *dto.UserResponse
 	} else {
 		resp.Roles = []dto.RoleSimple{}
 	}
+	// Map denied permissions
+	for _, p := range user.DeniedPermissions {
+		resp.DeniedPermissions = append(resp.DeniedPermissions, dto.PermissionSimple{
+			ID:   p.ID,
+			Name: p.Name,
+		})
+	}
 	// Map privileges
 	resp.Privileges = []dto.MenuResponse{}
 
@@ -290,3 +516,11 @@ func (uc *userUseCase) GetUserWithMeta(id uuid.UUID) (*dto.UserResponse, error)
 
 	return response, nil
 }
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,126 @@
+// pkg/auditlog/checkpoint.go
+package auditlog
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Checkpoint vouches for the head of the audit chain at a point in time.
+// Kept outside the database, it lets a verifier detect a chain that was rewritten
+// or truncated from its newest end.
+type Checkpoint struct {
+	Sequence  int64     `json:"sequence"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	Signature string    `json:"signature"`
+}
+
+// CheckpointFile appends HMAC-signed checkpoints to a JSON lines file
+type CheckpointFile struct {
+	path   string
+	secret []byte
+	mu     sync.Mutex
+}
+
+func NewCheckpointFile(path, secret string) (*CheckpointFile, error) {
+	if path == "" {
+		return nil, errors.New("checkpoint file path is required")
+	}
+	if secret == "" {
+		return nil, errors.New("checkpoint secret is required")
+	}
+	return &CheckpointFile{
+		path:   path,
+		secret: []byte(secret),
+	}, nil
+}
+
+// Append signs and writes a checkpoint for the given chain head
+func (f *CheckpointFile) Append(sequence int64, hash string) (*Checkpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkpoint := &Checkpoint{
+		Sequence:  sequence,
+		Hash:      hash,
+		CreatedAt: time.Now().UTC(),
+	}
+	checkpoint.Signature = f.sign(checkpoint)
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(checkpoint); err != nil {
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
+
+// ReadAll returns every checkpoint in the file, oldest first. A missing file has no checkpoints.
+func (f *CheckpointFile) ReadAll() ([]*Checkpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var checkpoints []*Checkpoint
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var checkpoint Checkpoint
+		if err := json.Unmarshal(scanner.Bytes(), &checkpoint); err != nil {
+			return nil, fmt.Errorf("checkpoint file line %d: %w", line, err)
+		}
+		checkpoints = append(checkpoints, &checkpoint)
+	}
+
+	return checkpoints, scanner.Err()
+}
+
+// Last returns the newest checkpoint, or nil when none was written yet
+func (f *CheckpointFile) Last() (*Checkpoint, error) {
+	checkpoints, err := f.ReadAll()
+	if err != nil || len(checkpoints) == 0 {
+		return nil, err
+	}
+	return checkpoints[len(checkpoints)-1], nil
+}
+
+// Valid reports whether the checkpoint was signed with this file's secret
+func (f *CheckpointFile) Valid(checkpoint *Checkpoint) bool {
+	expected := f.sign(checkpoint)
+	return hmac.Equal([]byte(expected), []byte(checkpoint.Signature))
+}
+
+func (f *CheckpointFile) sign(checkpoint *Checkpoint) string {
+	mac := hmac.New(sha256.New, f.secret)
+	fmt.Fprintf(mac, "%d:%s:%s", checkpoint.Sequence, checkpoint.Hash, checkpoint.CreatedAt.UTC().Format(time.RFC3339Nano))
+	return hex.EncodeToString(mac.Sum(nil))
+}
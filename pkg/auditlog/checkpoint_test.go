@@ -0,0 +1,103 @@
+package auditlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewCheckpointFileRequiresPathAndSecret(t *testing.T) {
+	if _, err := NewCheckpointFile("", "secret"); err == nil {
+		t.Error("NewCheckpointFile without a path succeeded")
+	}
+	if _, err := NewCheckpointFile("checkpoints.jsonl", ""); err == nil {
+		t.Error("NewCheckpointFile without a secret succeeded")
+	}
+}
+
+func TestCheckpointFileAppendAndRead(t *testing.T) {
+	file, err := NewCheckpointFile(filepath.Join(t.TempDir(), "checkpoints.jsonl"), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := file.Last()
+	if err != nil || last != nil {
+		t.Fatalf("Last() on a missing file = %v, %v, want nil, nil", last, err)
+	}
+
+	if _, err := file.Append(1, "hash-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Append(2, "hash-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoints, err := file.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 2 {
+		t.Fatalf("ReadAll() returned %d checkpoints, want 2", len(checkpoints))
+	}
+	for _, checkpoint := range checkpoints {
+		if !file.Valid(checkpoint) {
+			t.Errorf("checkpoint %d read back with an invalid signature", checkpoint.Sequence)
+		}
+	}
+
+	last, err = file.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Sequence != 2 || last.Hash != "hash-2" {
+		t.Errorf("Last() = %+v, want sequence 2 with hash-2", last)
+	}
+}
+
+func TestCheckpointFileRejectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.jsonl")
+	file, err := NewCheckpointFile(path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := file.Append(7, "hash-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forged := *checkpoint
+	forged.Hash = "rewritten"
+	if file.Valid(&forged) {
+		t.Error("Valid() accepted a checkpoint with a changed hash")
+	}
+
+	forged = *checkpoint
+	forged.Sequence = 6
+	if file.Valid(&forged) {
+		t.Error("Valid() accepted a checkpoint with a changed sequence")
+	}
+
+	other, err := NewCheckpointFile(path, "another secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Valid(checkpoint) {
+		t.Error("Valid() accepted a checkpoint signed with another secret")
+	}
+}
+
+func TestCheckpointFileReportsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.jsonl")
+	if err := os.WriteFile(path, []byte("{\"sequence\":1}\n\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := NewCheckpointFile(path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.ReadAll(); err == nil {
+		t.Error("ReadAll() accepted a malformed line")
+	}
+}
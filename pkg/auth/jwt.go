@@ -13,6 +13,10 @@ import (
 type JWTClaims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
+	// ImpersonatorID is set when an administrator acts on behalf of UserID
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+	// OrganizationID is the organization the user acts in, nil outside any organization
+	OrganizationID *uuid.UUID `json:"org_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -36,6 +40,11 @@ func NewJWTService(jwtConfig config.JWTConfig) *JWTService {
 
 // GenerateTokenPair generates a new JWT token pair
 func (s *JWTService) GenerateTokenPair(userID uuid.UUID, email string) (*TokenPair, error) {
+	return s.GenerateTokenPairForOrganization(userID, email, nil)
+}
+
+// GenerateTokenPairForOrganization generates a JWT token pair bound to an active organization
+func (s *JWTService) GenerateTokenPairForOrganization(userID uuid.UUID, email string, organizationID *uuid.UUID) (*TokenPair, error) {
 	// Use access token expiration from config
 	accessExpirationHours := s.jwtConfig.AccessTokenExpiration
 	if accessExpirationHours == 0 {
@@ -51,8 +60,9 @@ func (s *JWTService) GenerateTokenPair(userID uuid.UUID, email string) (*TokenPa
 	// Generate access token
 	accessExpiration := time.Now().Add(time.Duration(accessExpirationHours) * time.Hour)
 	accessClaims := JWTClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:         userID,
+		Email:          email,
+		OrganizationID: organizationID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(accessExpiration),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -69,8 +79,9 @@ func (s *JWTService) GenerateTokenPair(userID uuid.UUID, email string) (*TokenPa
 	// Generate refresh token (with longer expiration)
 	refreshExpiration := time.Now().Add(time.Duration(refreshExpirationHours) * time.Hour)
 	refreshClaims := JWTClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:         userID,
+		Email:          email,
+		OrganizationID: organizationID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(refreshExpiration),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -137,6 +148,14 @@ func GenerateTokenPair(userID uuid.UUID, email string) (*TokenPair, error) {
 	return globalJWTService.GenerateTokenPair(userID, email)
 }
 
+// GenerateTokenPairForOrganization is a legacy function that uses global JWT service
+func GenerateTokenPairForOrganization(userID uuid.UUID, email string, organizationID *uuid.UUID) (*TokenPair, error) {
+	if globalJWTService == nil {
+		return nil, errors.New("JWT service not initialized")
+	}
+	return globalJWTService.GenerateTokenPairForOrganization(userID, email, organizationID)
+}
+
 // ValidateAccessToken is a legacy function that uses global JWT service
 func ValidateAccessToken(tokenString string) (*JWTClaims, error) {
 	if globalJWTService == nil {
@@ -0,0 +1,13 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// migratePolicyNames drops the unique constraint that kept policy names unique across every
+// organization, before AutoMigrate adds the per-organization indexes, see migrateRoleNames
+func migratePolicyNames(db *gorm.DB) error {
+	return db.Exec(`ALTER TABLE IF EXISTS access_policies
+		DROP CONSTRAINT IF EXISTS access_policies_name_key,
+		DROP CONSTRAINT IF EXISTS uni_access_policies_name`).Error
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"fmt"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// protectAuditEventsSQL makes audit_events append-only for every database client, not just this API
+const protectAuditEventsSQL = `
+CREATE OR REPLACE FUNCTION audit_events_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit events are append-only and cannot be modified or deleted';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_no_modify ON audit_events;
+CREATE TRIGGER audit_events_no_modify
+	BEFORE UPDATE OR DELETE ON audit_events
+	FOR EACH ROW EXECUTE FUNCTION audit_events_immutable();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+CREATE TRIGGER audit_events_no_truncate
+	BEFORE TRUNCATE ON audit_events
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_events_immutable();
+`
+
+// checkAuditChain installs the triggers that keep audit_events append-only. It refuses to start when
+// unhashed events were written after the chain began, since only a writer bypassing the API does
+// that; unhashed events from before chaining existed are left for `cmd/audit backfill`.
+func checkAuditChain(db *gorm.DB, zapLogger *zap.Logger) error {
+	unchained, err := findUnchainedAuditEvents(db)
+	if err != nil {
+		return err
+	}
+	if len(unchained) > 0 {
+		zapLogger.Warn("Audit events recorded before hash chaining are not linked into the chain, run the audit backfill command",
+			zap.Int("count", len(unchained)))
+	}
+
+	return db.Exec(protectAuditEventsSQL).Error
+}
+
+// BackfillAuditChain links audit events recorded before hash chaining existed into the chain and
+// returns how many it linked. It refuses, like startup, when unhashed events follow chained ones.
+func BackfillAuditChain(db *gorm.DB) (int, error) {
+	var linked int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Keep the API from appending to the chain while it is extended
+		if err := tx.Exec("LOCK TABLE audit_events IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		unchained, err := findUnchainedAuditEvents(tx)
+		if err != nil || len(unchained) == 0 {
+			return err
+		}
+
+		// The trigger is restored below, in the same transaction
+		if err := tx.Exec("DROP TRIGGER IF EXISTS audit_events_no_modify ON audit_events").Error; err != nil {
+			return err
+		}
+
+		var last entities.AuditEvent
+		if err := tx.Where("hash <> ''").Order("sequence DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+
+		prev := &last
+		for _, event := range unchained {
+			event.Sequence = prev.Sequence + 1
+			event.PrevHash = prev.Hash
+			event.Hash = event.ComputeHash()
+
+			// Raw SQL, the model hooks reject updates
+			if err := tx.Exec("UPDATE audit_events SET sequence = ?, prev_hash = ?, hash = ? WHERE id = ?",
+				event.Sequence, event.PrevHash, event.Hash, event.ID).Error; err != nil {
+				return err
+			}
+			prev = event
+		}
+		linked = len(unchained)
+
+		return tx.Exec(protectAuditEventsSQL).Error
+	})
+	return linked, err
+}
+
+// findUnchainedAuditEvents returns the events without a hash, oldest first. It fails when any of
+// them was recorded after the first chained event.
+func findUnchainedAuditEvents(db *gorm.DB) ([]*entities.AuditEvent, error) {
+	var unchained []*entities.AuditEvent
+	if err := db.Where("hash IS NULL OR hash = ''").Order("created_at ASC, id ASC").Find(&unchained).Error; err != nil {
+		return nil, err
+	}
+	if len(unchained) == 0 {
+		return nil, nil
+	}
+
+	var first entities.AuditEvent
+	if err := db.Where("hash <> ''").Order("sequence ASC").Limit(1).Find(&first).Error; err != nil {
+		return nil, err
+	}
+	if first.ID != uuid.Nil && !unchained[len(unchained)-1].CreatedAt.Before(first.CreatedAt) {
+		return nil, fmt.Errorf("audit events without a hash were recorded after the chain started at sequence %d", first.Sequence)
+	}
+	return unchained, nil
+}
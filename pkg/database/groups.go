@@ -0,0 +1,13 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrateGroupNames drops the unique constraint that kept group names unique across every
+// organization, before AutoMigrate adds the per-organization indexes, see migrateRoleNames
+func migrateGroupNames(db *gorm.DB) error {
+	return db.Exec(`ALTER TABLE IF EXISTS groups
+		DROP CONSTRAINT IF EXISTS groups_name_key,
+		DROP CONSTRAINT IF EXISTS uni_groups_name`).Error
+}
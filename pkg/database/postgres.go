@@ -44,6 +44,11 @@ func ConnectDB(cfg *config.Config, zapLogger *zap.Logger) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := db.Use(TenantScope{}); err != nil {
+		zapLogger.Error("Failed to register tenant scope", zap.Error(err))
+		return nil, err
+	}
+
 	zapLogger.Info("Connected to database", zap.String("host", cfg.Database.Host), zap.Int("port", cfg.Database.Port))
 	return db, nil
 }
@@ -56,20 +61,60 @@ func MigrateDB(db *gorm.DB, zapLogger *zap.Logger) error {
 		zapLogger.Warn("Failed to create uuid extension (might already exist)", zap.Error(err))
 	}
 
+	if err := migrateRoleNames(db); err != nil {
+		zapLogger.Error("Failed to migrate role names", zap.Error(err))
+		return err
+	}
+	if err := migrateGroupNames(db); err != nil {
+		zapLogger.Error("Failed to migrate group names", zap.Error(err))
+		return err
+	}
+	if err := migratePolicyNames(db); err != nil {
+		zapLogger.Error("Failed to migrate policy names", zap.Error(err))
+		return err
+	}
+
 	err := db.AutoMigrate(
 		&entities.User{},
 		&entities.Role{},
+		&entities.UserRole{},
 		&entities.Permission{},
 		&entities.Menu{},
 		&entities.ModelPermission{},
 		&entities.Setting{},
 		&entities.UserMeta{},
+		&entities.InboxItem{},
+		&entities.Notification{},
+		&entities.NotificationDelivery{},
+		&entities.NotificationPreference{},
+		&entities.AuditEvent{},
+		&entities.UserMetaKeyPolicy{},
+		&entities.AccessPolicy{},
+		&entities.Organization{},
+		&entities.OrganizationMember{},
+		&entities.OrganizationSetting{},
+		&entities.Group{},
+		&entities.RoleConstraint{},
+		&entities.AccessRequest{},
+		&entities.AccessReviewCampaign{},
+		&entities.AccessReviewItem{},
+		&entities.RoleAssignmentRule{},
 	)
 	if err != nil {
 		zapLogger.Error("Failed to migrate database", zap.Error(err))
 		return err
 	}
 
+	if err := migrateUserRoles(db, zapLogger); err != nil {
+		zapLogger.Error("Failed to migrate role assignments", zap.Error(err))
+		return err
+	}
+
+	if err := checkAuditChain(db, zapLogger); err != nil {
+		zapLogger.Error("Failed to check audit chain", zap.Error(err))
+		return err
+	}
+
 	zapLogger.Info("Database migration completed")
 	return nil
 }
@@ -0,0 +1,14 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrateRoleNames drops the unique constraint that kept role names unique across every
+// organization, before AutoMigrate adds the per-organization indexes. AutoMigrate would try to
+// drop it itself but only knows its own constraint name, not the one older versions created.
+func migrateRoleNames(db *gorm.DB) error {
+	return db.Exec(`ALTER TABLE IF EXISTS roles
+		DROP CONSTRAINT IF EXISTS roles_name_key,
+		DROP CONSTRAINT IF EXISTS uni_roles_name`).Error
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"errors"
+	"sort"
+	"usermanagement-api/domain/entities"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SeedPermissions creates any missing permissions from the given name/description map and
+// grants the newly created ones to adminRole (created if needed). Permissions that already
+// exist, including soft-deleted ones, are left untouched so manual changes survive restarts.
+func SeedPermissions(db *gorm.DB, permissions map[string]string, adminRole string, zapLogger *zap.Logger) error {
+	names := make([]string, 0, len(permissions))
+	for name := range permissions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var created []*entities.Permission
+		for _, name := range names {
+			var existing entities.Permission
+			err := tx.Unscoped().Where("name = ?", name).First(&existing).Error
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			permission := &entities.Permission{Name: name, Description: permissions[name]}
+			if err := tx.Create(permission).Error; err != nil {
+				return err
+			}
+			created = append(created, permission)
+		}
+
+		if len(created) == 0 {
+			return nil
+		}
+
+		var role entities.Role
+		err := tx.Unscoped().Where("name = ? AND organization_id IS NULL", adminRole).First(&role).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			role = entities.Role{Name: adminRole, Description: "Built-in administrator role"}
+			err = tx.Create(&role).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&role).Association("Permissions").Append(created); err != nil {
+			return err
+		}
+
+		zapLogger.Info("Seeded permissions", zap.Int("count", len(created)), zap.String("role", adminRole))
+		return nil
+	})
+}
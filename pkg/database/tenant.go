@@ -0,0 +1,115 @@
+package database
+
+import (
+	"usermanagement-api/pkg/requestctx"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sharedTenantTables hold rows owned by one organization, rows without an organization are
+// shared by every tenant. Callers without an active organization only see shared rows.
+var sharedTenantTables = map[string]bool{
+	"roles":           true,
+	"menus":           true,
+	"groups":          true,
+	"access_policies": true,
+}
+
+// ownTenantTables hold rows owned by one organization that no other tenant sees. Callers without
+// an active organization see the rows without one, as in a single-tenant deployment.
+var ownTenantTables = map[string]bool{
+	"audit_events": true,
+}
+
+// TenantScope is a gorm plugin that limits reads and writes to the caller's active organization.
+// It applies to statements run with a request context (db.WithContext) of a tenant-scoped
+// caller; superusers, background jobs and context-less internal lookups see every tenant.
+// Updates and deletes of rows outside the tenant affect nothing, and so does the upsert Save
+// falls back to when its update matched no row.
+type TenantScope struct{}
+
+func (TenantScope) Name() string {
+	return "tenant_scope"
+}
+
+func (TenantScope) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", scopeToTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:row", scopeToTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", scopeToTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", scopeToTenant); err != nil {
+		return err
+	}
+	return db.Callback().Create().Before("gorm:create").Register("tenant:upsert", scopeUpsertToTenant)
+}
+
+func scopeToTenant(db *gorm.DB) {
+	if expr, ok := tenantCondition(db); ok {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{expr}})
+	}
+}
+
+// scopeUpsertToTenant keeps an insert that conflicts with a row of another tenant from updating it
+func scopeUpsertToTenant(db *gorm.DB) {
+	c, ok := db.Statement.Clauses["ON CONFLICT"]
+	if !ok {
+		return
+	}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	if !ok || onConflict.DoNothing {
+		return
+	}
+	if expr, ok := tenantCondition(db); ok {
+		onConflict.Where.Exprs = append(onConflict.Where.Exprs, expr)
+		db.Statement.AddClause(onConflict)
+	}
+}
+
+// tenantCondition returns the condition limiting the statement's table to the caller's active
+// organization, false when the statement is not limited
+func tenantCondition(db *gorm.DB) (clause.Expression, bool) {
+	if db.Statement.Schema == nil || db.Statement.Context == nil {
+		return nil, false
+	}
+	info := requestctx.FromContext(db.Statement.Context)
+	if !info.TenantScoped() {
+		return nil, false
+	}
+
+	table := clause.Table{Name: db.Statement.Table}
+	switch {
+	case sharedTenantTables[db.Statement.Schema.Table]:
+		if info.OrganizationID == nil {
+			return clause.Expr{SQL: "?.organization_id IS NULL", Vars: []interface{}{table}}, true
+		}
+		return clause.Expr{
+			SQL:  "(?.organization_id IS NULL OR ?.organization_id = ?)",
+			Vars: []interface{}{table, table, *info.OrganizationID},
+		}, true
+	case ownTenantTables[db.Statement.Schema.Table]:
+		if info.OrganizationID == nil {
+			return clause.Expr{SQL: "?.organization_id IS NULL", Vars: []interface{}{table}}, true
+		}
+		return clause.Expr{SQL: "?.organization_id = ?", Vars: []interface{}{table, *info.OrganizationID}}, true
+	case db.Statement.Schema.Table == "users":
+		// Users belong to organizations through membership. Callers without an active
+		// organization see the users that belong to none, as in a single-tenant deployment.
+		if info.OrganizationID == nil {
+			return clause.Expr{
+				SQL:  "NOT EXISTS (SELECT 1 FROM organization_members WHERE organization_members.user_id = ?.id)",
+				Vars: []interface{}{table},
+			}, true
+		}
+		return clause.Expr{
+			SQL:  "?.id IN (SELECT user_id FROM organization_members WHERE organization_id = ?)",
+			Vars: []interface{}{table, *info.OrganizationID},
+		}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrateUserRoles makes the organization part of the user_roles key, so a user can hold a role
+// in one organization and not in another. AutoMigrate adds the column but never changes a
+// primary key. Earlier assignments of tenant roles move to the role's organization, the others
+// stay outside any organization, and so do the access review items of those assignments.
+func migrateUserRoles(db *gorm.DB, zapLogger *zap.Logger) error {
+	var scoped bool
+	err := db.Raw(`SELECT EXISTS (
+		SELECT 1 FROM information_schema.key_column_usage
+		WHERE table_name = 'user_roles' AND constraint_name = 'user_roles_pkey' AND column_name = 'organization_id')`).
+		Scan(&scoped).Error
+	if err != nil || scoped {
+		return err
+	}
+
+	zapLogger.Info("Scoping role assignments to organizations")
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE user_roles SET organization_id = roles.organization_id
+			FROM roles WHERE roles.id = user_roles.role_id AND roles.organization_id IS NOT NULL`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`UPDATE access_review_items SET organization_id = roles.organization_id
+			FROM roles WHERE roles.id = access_review_items.role_id AND roles.organization_id IS NOT NULL`).Error; err != nil {
+			return err
+		}
+		// Replaced by idx_access_review_assignment, which includes the organization
+		if err := tx.Exec(`DROP INDEX IF EXISTS idx_access_review_item`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_pkey,
+			ADD PRIMARY KEY (user_id, role_id, organization_id)`).Error
+	})
+}
@@ -0,0 +1,115 @@
+// pkg/permission/matcher.go
+package permission
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Wildcard stands for a whole dot-separated segment of a permission name.
+// In the last position it matches one or more remaining segments ("users.*" covers
+// "users.read" and "users.profile.read"); anywhere else it matches exactly one
+// segment ("*.read" covers "users.read" but not "users.profile.read").
+const Wildcard = "*"
+
+// ErrInvalidName is returned by Validate for malformed permission names
+var ErrInvalidName = errors.New("invalid permission name")
+
+// IsWildcard reports whether name contains a wildcard segment
+func IsWildcard(name string) bool {
+	for _, segment := range strings.Split(name, ".") {
+		if segment == Wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that name has no empty segments and only uses "*" as a whole segment
+func Validate(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidName)
+	}
+	for _, segment := range strings.Split(name, ".") {
+		if segment == "" {
+			return fmt.Errorf("%w: %q has an empty segment", ErrInvalidName, name)
+		}
+		if segment != Wildcard && strings.Contains(segment, Wildcard) {
+			return fmt.Errorf("%w: %q uses * inside a segment", ErrInvalidName, name)
+		}
+	}
+	return nil
+}
+
+// Match reports whether the granted permission covers the required one
+func Match(granted, required string) bool {
+	if granted == required {
+		return true
+	}
+	if !IsWildcard(granted) {
+		return false
+	}
+
+	grantedSegments := strings.Split(granted, ".")
+	requiredSegments := strings.Split(required, ".")
+	for i, segment := range grantedSegments {
+		if i >= len(requiredSegments) {
+			return false
+		}
+		if segment == Wildcard {
+			if i == len(grantedSegments)-1 {
+				return true
+			}
+			continue
+		}
+		if segment != requiredSegments[i] {
+			return false
+		}
+	}
+
+	return len(grantedSegments) == len(requiredSegments)
+}
+
+// Set is a collection of granted permission names, exact and wildcard
+type Set struct {
+	exact    map[string]bool
+	patterns []string
+}
+
+// NewSet builds a Set from granted permission names
+func NewSet(names []string) *Set {
+	s := &Set{exact: make(map[string]bool)}
+	for _, name := range names {
+		s.Add(name)
+	}
+	return s
+}
+
+// Add grants name
+func (s *Set) Add(name string) {
+	if IsWildcard(name) {
+		s.patterns = append(s.patterns, name)
+		return
+	}
+	s.exact[name] = true
+}
+
+// Has reports whether the set grants required
+func (s *Set) Has(required string) bool {
+	return s.MatchedBy(required) != ""
+}
+
+// MatchedBy returns the granted name that covers required, preferring an exact grant,
+// or "" when nothing does.
+func (s *Set) MatchedBy(required string) string {
+	if s.exact[required] {
+		return required
+	}
+	for _, pattern := range s.patterns {
+		if Match(pattern, required) {
+			return pattern
+		}
+	}
+	return ""
+}
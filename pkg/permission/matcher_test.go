@@ -0,0 +1,81 @@
+package permission
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		granted  string
+		required string
+		want     bool
+	}{
+		{"users.read", "users.read", true},
+		{"users.read", "users.update", false},
+		{"users.*", "users.read", true},
+		{"users.*", "users.profile.read", true},
+		{"users.*", "users", false},
+		{"users.*", "roles.read", false},
+		{"*.read", "users.read", true},
+		{"*.read", "users.profile.read", false},
+		{"*.read", "users.update", false},
+		{"users.*.read", "users.profile.read", true},
+		{"users.*.read", "users.profile.update", false},
+		{"users.*.read", "users.read", false},
+		{"*", "users", true},
+		{"*", "users.read", true},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.granted, tt.required); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, name := range []string{"users.read", "users.*", "*.read", "*"} {
+		if err := Validate(name); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "  ", "users.", ".read", "users..read", "users.re*d", "users*"} {
+		if err := Validate(name); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Validate(%q) = %v, want ErrInvalidName", name, err)
+		}
+	}
+}
+
+func TestSetMatchedBy(t *testing.T) {
+	set := NewSet([]string{"users.*", "roles.read", "*.export"})
+
+	tests := []struct {
+		required string
+		want     string
+	}{
+		{"roles.read", "roles.read"},
+		{"users.read", "users.*"},
+		{"users.profile.update", "users.*"},
+		{"reports.export", "*.export"},
+		{"roles.update", ""},
+		{"roles", ""},
+	}
+
+	for _, tt := range tests {
+		if got := set.MatchedBy(tt.required); got != tt.want {
+			t.Errorf("MatchedBy(%q) = %q, want %q", tt.required, got, tt.want)
+		}
+		if got := set.Has(tt.required); got != (tt.want != "") {
+			t.Errorf("Has(%q) = %v, want %v", tt.required, got, tt.want != "")
+		}
+	}
+}
+
+func TestSetPrefersExactGrant(t *testing.T) {
+	set := NewSet([]string{"users.*", "users.read"})
+
+	if got := set.MatchedBy("users.read"); got != "users.read" {
+		t.Errorf("MatchedBy(users.read) = %q, want the exact grant", got)
+	}
+}
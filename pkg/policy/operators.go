@@ -0,0 +1,261 @@
+// pkg/policy/operators.go
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Condition operators
+const (
+	OpEq          = "eq"
+	OpNe          = "ne"
+	OpIn          = "in"
+	OpNotIn       = "not_in"
+	OpContains    = "contains"
+	OpGt          = "gt"
+	OpGte         = "gte"
+	OpLt          = "lt"
+	OpLte         = "lte"
+	OpExists      = "exists"
+	OpIPIn        = "ip_in"        // value: CIDR or list of CIDRs
+	OpIPNotIn     = "ip_not_in"    // value: CIDR or list of CIDRs
+	OpTimeBetween = "time_between" // value: ["09:00", "17:00"] or ["09:00", "17:00", "Asia/Jakarta"]
+)
+
+var errMissingAttribute = errors.New("attribute is not set")
+
+type operator func(actual, expected interface{}) (bool, error)
+
+var operators = map[string]operator{
+	OpEq: func(actual, expected interface{}) (bool, error) {
+		if actual == nil {
+			return false, errMissingAttribute
+		}
+		return equal(actual, expected), nil
+	},
+	OpNe: func(actual, expected interface{}) (bool, error) {
+		if actual == nil {
+			return false, errMissingAttribute
+		}
+		return !equal(actual, expected), nil
+	},
+	OpIn: func(actual, expected interface{}) (bool, error) {
+		if actual == nil {
+			return false, errMissingAttribute
+		}
+		return inList(actual, expected), nil
+	},
+	OpNotIn: func(actual, expected interface{}) (bool, error) {
+		if actual == nil {
+			return false, errMissingAttribute
+		}
+		return !inList(actual, expected), nil
+	},
+	OpContains: func(actual, expected interface{}) (bool, error) {
+		switch v := actual.(type) {
+		case nil:
+			return false, errMissingAttribute
+		case string:
+			return strings.Contains(v, fmt.Sprint(expected)), nil
+		default:
+			return inList(expected, actual), nil
+		}
+	},
+	OpGt:  compareWith(func(c int) bool { return c > 0 }),
+	OpGte: compareWith(func(c int) bool { return c >= 0 }),
+	OpLt:  compareWith(func(c int) bool { return c < 0 }),
+	OpLte: compareWith(func(c int) bool { return c <= 0 }),
+	OpExists: func(actual, expected interface{}) (bool, error) {
+		want := true
+		if b, ok := expected.(bool); ok {
+			want = b
+		}
+		return (actual != nil) == want, nil
+	},
+	OpIPIn: ipIn,
+	OpIPNotIn: func(actual, expected interface{}) (bool, error) {
+		in, err := ipIn(actual, expected)
+		return !in && err == nil, err
+	},
+	OpTimeBetween: func(actual, expected interface{}) (bool, error) {
+		start, end, location, err := parseWindow(expected)
+		if err != nil {
+			return false, err
+		}
+		if actual == nil {
+			return false, errMissingAttribute
+		}
+		t, err := toTime(actual)
+		if err != nil {
+			return false, err
+		}
+
+		t = t.In(location)
+		minute := t.Hour()*60 + t.Minute()
+		if start <= end {
+			return minute >= start && minute < end, nil
+		}
+		// The window spans midnight
+		return minute >= start || minute < end, nil
+	},
+}
+
+func ipIn(actual, expected interface{}) (bool, error) {
+	networks, err := parseNetworks(expected)
+	if err != nil {
+		return false, err
+	}
+	if actual == nil {
+		return false, errMissingAttribute
+	}
+	ip := net.ParseIP(fmt.Sprint(actual))
+	if ip == nil {
+		return false, nil
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func equal(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func inList(value, list interface{}) bool {
+	switch items := list.(type) {
+	case []interface{}:
+		for _, item := range items {
+			if equal(value, item) {
+				return true
+			}
+		}
+	case []string:
+		for _, item := range items {
+			if equal(value, item) {
+				return true
+			}
+		}
+	default:
+		return equal(value, list)
+	}
+	return false
+}
+
+func compareWith(accept func(int) bool) operator {
+	return func(actual, expected interface{}) (bool, error) {
+		if actual == nil {
+			return false, errMissingAttribute
+		}
+		if x, ok := toFloat(actual); ok {
+			if y, ok := toFloat(expected); ok {
+				switch {
+				case x < y:
+					return accept(-1), nil
+				case x > y:
+					return accept(1), nil
+				}
+				return accept(0), nil
+			}
+		}
+		return accept(strings.Compare(fmt.Sprint(actual), fmt.Sprint(expected))), nil
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		return time.Parse(time.RFC3339, t)
+	}
+	return time.Time{}, fmt.Errorf("%v is not a time", v)
+}
+
+func parseNetworks(value interface{}) ([]*net.IPNet, error) {
+	var cidrs []string
+	switch v := value.(type) {
+	case string:
+		cidrs = []string{v}
+	case []string:
+		cidrs = v
+	case []interface{}:
+		for _, item := range v {
+			cidrs = append(cidrs, fmt.Sprint(item))
+		}
+	default:
+		return nil, errors.New("ip_in expects a CIDR or a list of CIDRs")
+	}
+
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// parseWindow returns the window bounds in minutes since midnight and its location
+func parseWindow(value interface{}) (int, int, *time.Location, error) {
+	items, ok := value.([]interface{})
+	if !ok || len(items) < 2 || len(items) > 3 {
+		return 0, 0, nil, errors.New(`time_between expects ["HH:MM", "HH:MM"] with an optional timezone`)
+	}
+
+	bounds := make([]int, 2)
+	for i := 0; i < 2; i++ {
+		t, err := time.Parse("15:04", fmt.Sprint(items[i]))
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid time %v, expected HH:MM", items[i])
+		}
+		bounds[i] = t.Hour()*60 + t.Minute()
+	}
+
+	location := time.UTC
+	if len(items) == 3 {
+		var err error
+		if location, err = time.LoadLocation(fmt.Sprint(items[2])); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+	return bounds[0], bounds[1], location, nil
+}
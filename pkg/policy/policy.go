@@ -0,0 +1,254 @@
+// pkg/policy/policy.go
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"usermanagement-api/pkg/permission"
+)
+
+// Effect is what a policy does when it applies
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// Decision is the combined outcome of evaluating policies
+type Decision string
+
+const (
+	DecisionAllow Decision = "allow"
+	DecisionDeny  Decision = "deny"
+	// DecisionNotApplicable means no policy applied, callers fall back to role permissions
+	DecisionNotApplicable Decision = "not_applicable"
+)
+
+// Attribute roots a condition may reference
+const (
+	RootSubject     = "subject"
+	RootResource    = "resource"
+	RootEnvironment = "environment"
+)
+
+// Condition compares an attribute, e.g. "subject.meta.department", with a literal Value
+// or with another attribute named by ValueFrom.
+type Condition struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Value     interface{} `json:"value,omitempty"`
+	ValueFrom string      `json:"value_from,omitempty"`
+}
+
+// Policy applies to the actions it lists (permission names, wildcards allowed) when all its
+// conditions hold.
+type Policy struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Effect     Effect      `json:"effect"`
+	Actions    []string    `json:"actions"`
+	Conditions []Condition `json:"conditions"`
+}
+
+// Input is the request being decided
+type Input struct {
+	Action      string                 `json:"action"`
+	Subject     map[string]interface{} `json:"subject"`
+	Resource    map[string]interface{} `json:"resource"`
+	Environment map[string]interface{} `json:"environment"`
+}
+
+// PolicyResult explains how one policy contributed to a decision
+type PolicyResult struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Effect     Effect `json:"effect"`
+	Applicable bool   `json:"applicable"`
+	// Reason names the first condition that did not hold, or the evaluation error
+	Reason string `json:"reason,omitempty"`
+}
+
+// Result is the combined decision with the per-policy trace
+type Result struct {
+	Decision Decision       `json:"decision"`
+	Policies []PolicyResult `json:"policies"`
+}
+
+// DecidingPolicy returns the first applicable policy with the decided effect
+func (r *Result) DecidingPolicy() *PolicyResult {
+	for i := range r.Policies {
+		p := &r.Policies[i]
+		if p.Applicable && string(p.Effect) == string(r.Decision) {
+			return p
+		}
+	}
+	return nil
+}
+
+// ErrInvalidPolicy is returned by Validate
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// Validate checks a policy before it is stored
+func Validate(p Policy) error {
+	if p.Effect != EffectAllow && p.Effect != EffectDeny {
+		return fmt.Errorf("%w: effect must be allow or deny", ErrInvalidPolicy)
+	}
+	if len(p.Actions) == 0 {
+		return fmt.Errorf("%w: at least one action is required", ErrInvalidPolicy)
+	}
+	for _, action := range p.Actions {
+		if err := permission.Validate(action); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+		}
+	}
+	for _, condition := range p.Conditions {
+		if err := validateCondition(condition); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+		}
+	}
+	return nil
+}
+
+func validateCondition(c Condition) error {
+	if err := validateAttribute(c.Attribute); err != nil {
+		return err
+	}
+	if c.ValueFrom != "" {
+		if err := validateAttribute(c.ValueFrom); err != nil {
+			return err
+		}
+	}
+	if _, ok := operators[c.Operator]; !ok {
+		return fmt.Errorf("unknown operator %q", c.Operator)
+	}
+	if c.ValueFrom == "" && (c.Operator == OpIPIn || c.Operator == OpIPNotIn || c.Operator == OpTimeBetween) {
+		// These operators parse their value before looking at the attribute
+		if _, err := operators[c.Operator](nil, c.Value); err != nil && !errors.Is(err, errMissingAttribute) {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateAttribute(path string) error {
+	root, _, _ := strings.Cut(path, ".")
+	switch root {
+	case RootSubject, RootResource, RootEnvironment:
+		return nil
+	}
+	return fmt.Errorf("attribute %q must start with subject., resource. or environment.", path)
+}
+
+// Applies reports whether the policy targets action
+func (p Policy) Applies(action string) bool {
+	for _, pattern := range p.Actions {
+		if permission.Match(pattern, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate combines the policies targeting input.Action with deny-overrides: any applicable
+// deny wins, otherwise any applicable allow, otherwise the decision is not applicable.
+// A policy whose conditions fail to evaluate is treated as applicable when it denies, so
+// broken deny rules fail closed.
+func Evaluate(policies []Policy, input Input) Result {
+	result := Result{Decision: DecisionNotApplicable, Policies: []PolicyResult{}}
+	allowed, denied := false, false
+
+	for _, p := range policies {
+		if !p.Applies(input.Action) {
+			continue
+		}
+
+		pr := PolicyResult{ID: p.ID, Name: p.Name, Effect: p.Effect}
+		holds, reason, err := p.conditionsHold(input)
+		switch {
+		case err != nil:
+			pr.Reason = err.Error()
+			pr.Applicable = p.Effect == EffectDeny
+		case !holds:
+			pr.Reason = reason
+		default:
+			pr.Applicable = true
+		}
+
+		if pr.Applicable {
+			if p.Effect == EffectDeny {
+				denied = true
+			} else {
+				allowed = true
+			}
+		}
+		result.Policies = append(result.Policies, pr)
+	}
+
+	switch {
+	case denied:
+		result.Decision = DecisionDeny
+	case allowed:
+		result.Decision = DecisionAllow
+	}
+	return result
+}
+
+func (p Policy) conditionsHold(input Input) (bool, string, error) {
+	for _, c := range p.Conditions {
+		actual, _ := input.lookup(c.Attribute)
+		expected := c.Value
+		if c.ValueFrom != "" {
+			var ok bool
+			if expected, ok = input.lookup(c.ValueFrom); !ok {
+				return false, fmt.Sprintf("%s is not set", c.ValueFrom), nil
+			}
+		}
+
+		holds, err := operators[c.Operator](actual, expected)
+		if errors.Is(err, errMissingAttribute) {
+			return false, fmt.Sprintf("%s is not set", c.Attribute), nil
+		}
+		if err != nil {
+			return false, "", fmt.Errorf("%s %s: %v", c.Attribute, c.Operator, err)
+		}
+		if !holds {
+			return false, fmt.Sprintf("%s %s failed", c.Attribute, c.Operator), nil
+		}
+	}
+	return true, "", nil
+}
+
+// lookup resolves a dotted attribute path through nested maps
+func (in Input) lookup(path string) (interface{}, bool) {
+	root, rest, _ := strings.Cut(path, ".")
+	var current interface{}
+	switch root {
+	case RootSubject:
+		current = in.Subject
+	case RootResource:
+		current = in.Resource
+	case RootEnvironment:
+		current = in.Environment
+	default:
+		return nil, false
+	}
+
+	if rest == "" {
+		return current, current != nil
+	}
+	for _, segment := range strings.Split(rest, ".") {
+		var ok bool
+		switch m := current.(type) {
+		case map[string]interface{}:
+			current, ok = m[segment]
+		case map[string]string:
+			current, ok = m[segment]
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
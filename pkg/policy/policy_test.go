@@ -0,0 +1,208 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvaluateDenyOverrides(t *testing.T) {
+	policies := []Policy{
+		{ID: "allow-engineering", Effect: EffectAllow, Actions: []string{"reports.*"}, Conditions: []Condition{
+			{Attribute: "subject.meta.department", Operator: OpEq, Value: "engineering"},
+		}},
+		{ID: "deny-outside-office", Effect: EffectDeny, Actions: []string{"reports.export"}, Conditions: []Condition{
+			{Attribute: "environment.ip", Operator: OpIPNotIn, Value: []interface{}{"10.0.0.0/8"}},
+		}},
+	}
+	subject := map[string]interface{}{"meta": map[string]string{"department": "engineering"}}
+
+	tests := []struct {
+		name   string
+		action string
+		ip     string
+		want   Decision
+	}{
+		{"allow applies", "reports.read", "192.0.2.1", DecisionAllow},
+		{"deny wins over allow", "reports.export", "192.0.2.1", DecisionDeny},
+		{"deny condition does not hold", "reports.export", "10.1.2.3", DecisionAllow},
+		{"no policy targets the action", "users.read", "192.0.2.1", DecisionNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(policies, Input{
+				Action:      tt.action,
+				Subject:     subject,
+				Environment: map[string]interface{}{"ip": tt.ip},
+			})
+			if result.Decision != tt.want {
+				t.Errorf("Decision = %s, want %s (trace %+v)", result.Decision, tt.want, result.Policies)
+			}
+		})
+	}
+}
+
+func TestEvaluateReportsFailedCondition(t *testing.T) {
+	policies := []Policy{
+		{ID: "p", Effect: EffectAllow, Actions: []string{"users.read"}, Conditions: []Condition{
+			{Attribute: "subject.meta.department", Operator: OpEq, Value: "engineering"},
+		}},
+	}
+
+	result := Evaluate(policies, Input{Action: "users.read", Subject: map[string]interface{}{}})
+	if result.Decision != DecisionNotApplicable {
+		t.Fatalf("Decision = %s, want %s", result.Decision, DecisionNotApplicable)
+	}
+	if len(result.Policies) != 1 || result.Policies[0].Reason != "subject.meta.department is not set" {
+		t.Errorf("Policies = %+v, want the missing attribute as reason", result.Policies)
+	}
+	if result.DecidingPolicy() != nil {
+		t.Errorf("DecidingPolicy() = %+v, want nil", result.DecidingPolicy())
+	}
+}
+
+func TestEvaluateBrokenDenyFailsClosed(t *testing.T) {
+	policies := []Policy{
+		{ID: "allow", Effect: EffectAllow, Actions: []string{"users.read"}},
+		{ID: "broken-deny", Effect: EffectDeny, Actions: []string{"users.read"}, Conditions: []Condition{
+			{Attribute: "environment.time", Operator: OpTimeBetween, Value: []interface{}{"09:00", "17:00"}},
+		}},
+	}
+
+	result := Evaluate(policies, Input{
+		Action:      "users.read",
+		Environment: map[string]interface{}{"time": "not a time"},
+	})
+	if result.Decision != DecisionDeny {
+		t.Fatalf("Decision = %s, want %s", result.Decision, DecisionDeny)
+	}
+	if deciding := result.DecidingPolicy(); deciding == nil || deciding.ID != "broken-deny" {
+		t.Errorf("DecidingPolicy() = %+v, want broken-deny", deciding)
+	}
+}
+
+func TestEvaluateValueFrom(t *testing.T) {
+	policies := []Policy{
+		{ID: "owner", Effect: EffectAllow, Actions: []string{"documents.update"}, Conditions: []Condition{
+			{Attribute: "resource.owner_id", Operator: OpEq, ValueFrom: "subject.id"},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		resource map[string]interface{}
+		want     Decision
+	}{
+		{"owner", map[string]interface{}{"owner_id": "u1"}, DecisionAllow},
+		{"someone else", map[string]interface{}{"owner_id": "u2"}, DecisionNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(policies, Input{
+				Action:   "documents.update",
+				Subject:  map[string]interface{}{"id": "u1"},
+				Resource: tt.resource,
+			})
+			if result.Decision != tt.want {
+				t.Errorf("Decision = %s, want %s", result.Decision, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperators(t *testing.T) {
+	noon := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		operator string
+		actual   interface{}
+		expected interface{}
+		want     bool
+	}{
+		{"eq number and string", OpEq, float64(3), "3", true},
+		{"ne", OpNe, "a", "b", true},
+		{"in", OpIn, "b", []interface{}{"a", "b"}, true},
+		{"not_in", OpNotIn, "c", []interface{}{"a", "b"}, true},
+		{"contains substring", OpContains, "engineering", "gine", true},
+		{"contains list item", OpContains, []interface{}{"a", "b"}, "b", true},
+		{"gt numbers", OpGt, float64(10), float64(9), true},
+		{"lte numbers", OpLte, float64(10), float64(9), false},
+		{"lt strings", OpLt, "a", "b", true},
+		{"exists", OpExists, "x", nil, true},
+		{"exists false", OpExists, nil, false, true},
+		{"ip_in single address", OpIPIn, "192.0.2.1", "192.0.2.1", true},
+		{"ip_in cidr", OpIPIn, "10.2.3.4", []interface{}{"10.0.0.0/8"}, true},
+		{"ip_in outside", OpIPIn, "192.0.2.1", []interface{}{"10.0.0.0/8"}, false},
+		{"ip_not_in", OpIPNotIn, "192.0.2.1", "10.0.0.0/8", true},
+		{"time_between inside", OpTimeBetween, noon, []interface{}{"09:00", "17:00"}, true},
+		{"time_between outside", OpTimeBetween, night, []interface{}{"09:00", "17:00"}, false},
+		{"time_between across midnight", OpTimeBetween, night, []interface{}{"22:00", "06:00"}, true},
+		{"time_between with timezone", OpTimeBetween, noon, []interface{}{"18:00", "20:00", "Asia/Jakarta"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := operators[tt.operator](tt.actual, tt.expected)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("%s(%v, %v) = %v, want %v", tt.operator, tt.actual, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperatorsMissingAttribute(t *testing.T) {
+	for _, op := range []string{OpEq, OpNe, OpIn, OpNotIn, OpContains, OpGt, OpIPIn, OpIPNotIn} {
+		if _, err := operators[op](nil, "10.0.0.0/8"); !errors.Is(err, errMissingAttribute) {
+			t.Errorf("%s(nil) error = %v, want errMissingAttribute", op, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Policy{Effect: EffectAllow, Actions: []string{"users.*"}, Conditions: []Condition{
+		{Attribute: "environment.ip", Operator: OpIPIn, Value: "10.0.0.0/8"},
+		{Attribute: "resource.owner_id", Operator: OpEq, ValueFrom: "subject.id"},
+	}}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("Validate(valid) = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+	}{
+		{"unknown effect", Policy{Effect: "maybe", Actions: []string{"users.read"}}},
+		{"no actions", Policy{Effect: EffectAllow}},
+		{"invalid action", Policy{Effect: EffectAllow, Actions: []string{"users..read"}}},
+		{"unknown root", Policy{Effect: EffectAllow, Actions: []string{"users.read"}, Conditions: []Condition{
+			{Attribute: "request.ip", Operator: OpEq, Value: "x"},
+		}}},
+		{"unknown value_from root", Policy{Effect: EffectAllow, Actions: []string{"users.read"}, Conditions: []Condition{
+			{Attribute: "subject.id", Operator: OpEq, ValueFrom: "owner"},
+		}}},
+		{"unknown operator", Policy{Effect: EffectAllow, Actions: []string{"users.read"}, Conditions: []Condition{
+			{Attribute: "subject.id", Operator: "like", Value: "x"},
+		}}},
+		{"bad cidr", Policy{Effect: EffectAllow, Actions: []string{"users.read"}, Conditions: []Condition{
+			{Attribute: "environment.ip", Operator: OpIPIn, Value: "10.0.0.0/99"},
+		}}},
+		{"bad window", Policy{Effect: EffectAllow, Actions: []string{"users.read"}, Conditions: []Condition{
+			{Attribute: "environment.time", Operator: OpTimeBetween, Value: []interface{}{"9am", "5pm"}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.policy); !errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("Validate() = %v, want ErrInvalidPolicy", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// pkg/pubsub/pubsub.go
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PubSub fans messages out to every subscriber of a channel, across all API instances.
+type PubSub interface {
+	Publish(ctx context.Context, channel string, message interface{}) error
+	Subscribe(ctx context.Context, channel string) Subscription
+	Close() error
+}
+
+// Subscription delivers raw JSON payloads published on a channel.
+type Subscription interface {
+	Messages() <-chan string
+	Close() error
+}
+
+type RedisPubSub struct {
+	client *redis.Client
+}
+
+func NewRedisPubSub(addr, password string, db int) *RedisPubSub {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	return &RedisPubSub{
+		client: client,
+	}
+}
+
+func (p *RedisPubSub) Publish(ctx context.Context, channel string, message interface{}) error {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return p.client.Publish(ctx, channel, jsonData).Err()
+}
+
+func (p *RedisPubSub) Subscribe(ctx context.Context, channel string) Subscription {
+	ps := p.client.Subscribe(ctx, channel)
+	messages := make(chan string)
+
+	go func() {
+		defer close(messages)
+		for msg := range ps.Channel() {
+			select {
+			case messages <- msg.Payload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &redisSubscription{pubsub: ps, messages: messages}
+}
+
+// Close closes the underlying Redis connection
+func (p *RedisPubSub) Close() error {
+	return p.client.Close()
+}
+
+type redisSubscription struct {
+	pubsub   *redis.PubSub
+	messages chan string
+}
+
+func (s *redisSubscription) Messages() <-chan string {
+	return s.messages
+}
+
+func (s *redisSubscription) Close() error {
+	return s.pubsub.Close()
+}
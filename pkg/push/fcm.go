@@ -0,0 +1,128 @@
+// pkg/push/fcm.go
+package push
+
+import (
+	"context"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+)
+
+// fcmDriver sends push notifications through Firebase Cloud Messaging
+type fcmDriver struct {
+	client *messaging.Client
+	logger *zap.Logger
+}
+
+func NewFCMDriver(credentialsFile string, logger *zap.Logger) (Driver, error) {
+	opt := option.WithCredentialsFile(credentialsFile)
+	app, err := firebase.NewApp(context.Background(), nil, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := app.Messaging(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return &fcmDriver{
+		client: client,
+		logger: logger,
+	}, nil
+}
+
+func (f *fcmDriver) SendToDevice(token string, title, body string, data map[string]string) (string, error) {
+	message := &messaging.Message{
+		Notification: &messaging.Notification{
+			Title: title,
+			Body:  body,
+		},
+		Data:  data,
+		Token: token,
+	}
+
+	response, err := f.client.Send(context.Background(), message)
+	if err != nil {
+		f.logger.Error("Error sending message to device", zap.Error(err), zap.String("token", MaskToken(token)))
+		return "", err
+	}
+
+	return response, nil
+}
+
+func (f *fcmDriver) SendToDevices(tokens []string, title, body string, data map[string]string) ([]SendResult, error) {
+	message := &messaging.MulticastMessage{
+		Notification: &messaging.Notification{
+			Title: title,
+			Body:  body,
+		},
+		Data:   data,
+		Tokens: tokens,
+	}
+
+	response, err := f.client.SendEachForMulticast(context.Background(), message)
+	if err != nil {
+		f.logger.Error("Error sending message to devices", zap.Error(err), zap.Int("token_count", len(tokens)))
+		return nil, err
+	}
+
+	// Responses are returned in the same order as the tokens
+	results := make([]SendResult, len(response.Responses))
+	for i, resp := range response.Responses {
+		results[i] = SendResult{
+			Token:     tokens[i],
+			Success:   resp.Success,
+			MessageID: resp.MessageID,
+		}
+		if resp.Error != nil {
+			results[i].ErrorCode = errorCode(resp.Error)
+			results[i].Error = resp.Error.Error()
+		}
+	}
+
+	return results, nil
+}
+
+func (f *fcmDriver) SendToTopic(topic, title, body string, data map[string]string) (string, error) {
+	message := &messaging.Message{
+		Notification: &messaging.Notification{
+			Title: title,
+			Body:  body,
+		},
+		Data:  data,
+		Topic: topic,
+	}
+
+	response, err := f.client.Send(context.Background(), message)
+	if err != nil {
+		f.logger.Error("Error sending message to topic", zap.Error(err), zap.String("topic", topic))
+		return "", err
+	}
+
+	return response, nil
+}
+
+// errorCode maps an FCM error to a stable code that can be stored and aggregated
+func errorCode(err error) string {
+	switch {
+	case messaging.IsUnregistered(err):
+		return ErrorCodeUnregistered
+	case messaging.IsInvalidArgument(err):
+		return ErrorCodeInvalidArgument
+	case messaging.IsQuotaExceeded(err):
+		return ErrorCodeQuotaExceeded
+	case messaging.IsSenderIDMismatch(err):
+		return ErrorCodeSenderIDMismatch
+	case messaging.IsThirdPartyAuthError(err):
+		return ErrorCodeThirdPartyAuth
+	case messaging.IsUnavailable(err):
+		return ErrorCodeUnavailable
+	case messaging.IsInternal(err):
+		return ErrorCodeInternal
+	default:
+		return ErrorCodeUnknown
+	}
+}
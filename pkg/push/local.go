@@ -0,0 +1,153 @@
+// pkg/push/local.go
+package push
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Message is a push notification captured by the log, file and memory drivers
+type Message struct {
+	ID     string            `json:"id"`
+	Token  string            `json:"token,omitempty"`
+	Topic  string            `json:"topic,omitempty"`
+	Title  string            `json:"title"`
+	Body   string            `json:"body"`
+	Data   map[string]string `json:"data,omitempty"`
+	SentAt time.Time         `json:"sent_at"`
+}
+
+// recorder turns sends into Messages and hands them to record
+type recorder struct {
+	record func(msg Message) error
+}
+
+func (r *recorder) SendToDevice(token string, title, body string, data map[string]string) (string, error) {
+	return r.send(Message{Token: token, Title: title, Body: body, Data: data})
+}
+
+func (r *recorder) SendToDevices(tokens []string, title, body string, data map[string]string) ([]SendResult, error) {
+	results := make([]SendResult, len(tokens))
+	for i, token := range tokens {
+		results[i] = SendResult{Token: token}
+		messageID, err := r.SendToDevice(token, title, body, data)
+		if err != nil {
+			results[i].ErrorCode = ErrorCodeInternal
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Success = true
+		results[i].MessageID = messageID
+	}
+	return results, nil
+}
+
+func (r *recorder) SendToTopic(topic, title, body string, data map[string]string) (string, error) {
+	return r.send(Message{Topic: topic, Title: title, Body: body, Data: data})
+}
+
+func (r *recorder) send(msg Message) (string, error) {
+	msg.ID = uuid.New().String()
+	msg.SentAt = time.Now()
+	if err := r.record(msg); err != nil {
+		return "", err
+	}
+	return msg.ID, nil
+}
+
+// NewLogDriver creates a driver that writes every payload to the logger instead of sending it
+func NewLogDriver(logger *zap.Logger) Driver {
+	return &recorder{
+		record: func(msg Message) error {
+			logger.Info("Push notification",
+				zap.String("message_id", msg.ID),
+				zap.String("token", msg.Token),
+				zap.String("topic", msg.Topic),
+				zap.String("title", msg.Title),
+				zap.String("body", msg.Body),
+				zap.Any("data", msg.Data),
+			)
+			return nil
+		},
+	}
+}
+
+// NewFileDriver creates a driver that appends every payload as a JSON line to path
+func NewFileDriver(path string) (Driver, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	encoder := json.NewEncoder(file)
+	return &recorder{
+		record: func(msg Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return encoder.Encode(msg)
+		},
+	}, nil
+}
+
+// MemoryDriver keeps every payload in memory, for tests and local development
+type MemoryDriver struct {
+	recorder
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryDriver() *MemoryDriver {
+	d := &MemoryDriver{}
+	d.record = func(msg Message) error {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.messages = append(d.messages, msg)
+		return nil
+	}
+	return d
+}
+
+// Messages returns a copy of the messages recorded so far
+func (d *MemoryDriver) Messages() []Message {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Message(nil), d.messages...)
+}
+
+// Reset discards all recorded messages
+func (d *MemoryDriver) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.messages = nil
+}
+
+// disabledDriver is used when push is not configured, every send fails with ErrDisabled
+type disabledDriver struct{}
+
+func NewDisabledDriver() Driver {
+	return disabledDriver{}
+}
+
+// Disabled reports whether d is the driver used when push is not configured
+func Disabled(d Driver) bool {
+	_, disabled := d.(disabledDriver)
+	return disabled
+}
+
+func (disabledDriver) SendToDevice(token string, title, body string, data map[string]string) (string, error) {
+	return "", ErrDisabled
+}
+
+func (disabledDriver) SendToDevices(tokens []string, title, body string, data map[string]string) ([]SendResult, error) {
+	return nil, ErrDisabled
+}
+
+func (disabledDriver) SendToTopic(topic, title, body string, data map[string]string) (string, error) {
+	return "", ErrDisabled
+}
@@ -0,0 +1,100 @@
+// pkg/push/push.go
+package push
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Supported push drivers
+const (
+	DriverFCM      = "fcm"
+	DriverLog      = "log"
+	DriverFile     = "file"
+	DriverMemory   = "memory"
+	DriverDisabled = "disabled"
+)
+
+// ErrDisabled is returned by every send when no push driver is available
+var ErrDisabled = errors.New("push notifications are disabled")
+
+// Driver delivers push notifications to devices and topics
+type Driver interface {
+	SendToDevice(token string, title, body string, data map[string]string) (string, error)
+	SendToDevices(tokens []string, title, body string, data map[string]string) ([]SendResult, error)
+	SendToTopic(topic, title, body string, data map[string]string) (string, error)
+}
+
+// SendResult is the outcome of a message sent to a single device token
+type SendResult struct {
+	Token     string
+	Success   bool
+	MessageID string
+	ErrorCode string
+	Error     string
+}
+
+// MaskToken keeps just enough of a device token to tell devices apart in logs and reports
+func MaskToken(token string) string {
+	if len(token) <= 10 {
+		return token
+	}
+	return token[:10] + "..."
+}
+
+// Error codes reported in SendResult.ErrorCode
+const (
+	ErrorCodeUnregistered     = "unregistered"
+	ErrorCodeInvalidArgument  = "invalid_argument"
+	ErrorCodeQuotaExceeded    = "quota_exceeded"
+	ErrorCodeSenderIDMismatch = "sender_id_mismatch"
+	ErrorCodeThirdPartyAuth   = "third_party_auth_error"
+	ErrorCodeUnavailable      = "unavailable"
+	ErrorCodeInternal         = "internal"
+	ErrorCodeDisabled         = "push_disabled"
+	ErrorCodeUnknown          = "unknown"
+)
+
+// Config selects and configures the push driver
+type Config struct {
+	Driver             string // fcm, log, file, memory or disabled
+	FCMCredentialsFile string
+	FilePath           string
+}
+
+// NewDriver creates the push driver selected by cfg.Driver.
+// When no driver is configured, fcm is used if credentials are available
+// and push is disabled otherwise.
+func NewDriver(cfg Config, logger *zap.Logger) (Driver, error) {
+	driver := cfg.Driver
+	if driver == "" {
+		if cfg.FCMCredentialsFile != "" {
+			driver = DriverFCM
+		} else {
+			driver = DriverDisabled
+		}
+	}
+
+	switch driver {
+	case DriverFCM:
+		if cfg.FCMCredentialsFile == "" {
+			return nil, errors.New("fcm push driver requires FIREBASE_CREDENTIALS_FILE")
+		}
+		return NewFCMDriver(cfg.FCMCredentialsFile, logger)
+	case DriverLog:
+		return NewLogDriver(logger), nil
+	case DriverFile:
+		if cfg.FilePath == "" {
+			return nil, errors.New("file push driver requires PUSH_FILE_PATH")
+		}
+		return NewFileDriver(cfg.FilePath)
+	case DriverMemory:
+		return NewMemoryDriver(), nil
+	case DriverDisabled:
+		return NewDisabledDriver(), nil
+	default:
+		return nil, fmt.Errorf("unknown push driver %q", driver)
+	}
+}
@@ -0,0 +1,20 @@
+package push
+
+import "testing"
+
+func TestMaskToken(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"", ""},
+		{"short", "short"},
+		{"0123456789", "0123456789"},
+		{"0123456789abcdef", "0123456789..."},
+	}
+	for _, tt := range tests {
+		if got := MaskToken(tt.token); got != tt.want {
+			t.Errorf("MaskToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package rbac keeps permissions, shared roles and shared menus in line with a declarative manifest.
+package rbac
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes the permissions, shared roles and shared menus an environment should have.
+// Tenant roles and menus are left to the organizations that own them.
+type Manifest struct {
+	Permissions []Permission `yaml:"permissions" json:"permissions"`
+	Roles       []Role       `yaml:"roles" json:"roles"`
+	Menus       []Menu       `yaml:"menus" json:"menus"`
+}
+
+// Permission is a permission declared by the manifest
+type Permission struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+}
+
+// Role is a shared role with the exact set of permissions and parent roles it should have
+type Role struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Permissions []string `yaml:"permissions" json:"permissions"`
+	Parents     []string `yaml:"parents" json:"parents"`
+}
+
+// Menu is a shared menu with the exact set of permissions that grant it. Parent refers to
+// another menu of the manifest by name.
+type Menu struct {
+	Name        string   `yaml:"name" json:"name"`
+	Url         string   `yaml:"url" json:"url"`
+	Icon        string   `yaml:"icon" json:"icon"`
+	Description string   `yaml:"description" json:"description"`
+	Parent      string   `yaml:"parent" json:"parent"`
+	Sequence    int      `yaml:"sequence" json:"sequence"`
+	IsActive    *bool    `yaml:"is_active" json:"is_active"`   // defaults to true
+	IsVisible   *bool    `yaml:"is_visible" json:"is_visible"` // defaults to true
+	Permissions []string `yaml:"permissions" json:"permissions"`
+}
+
+// Load reads a manifest file, ".json" files are parsed as JSON and everything else as YAML
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &manifest)
+	} else {
+		err = yaml.Unmarshal(data, &manifest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Validate checks names are present and unique, and that role and menu parents are declared
+// in the manifest without forming a cycle. Permission references are checked during sync,
+// since they may also name permissions that already exist.
+func (m *Manifest) Validate() error {
+	permissions := make(map[string]bool)
+	for _, permission := range m.Permissions {
+		if permission.Name == "" {
+			return errors.New("permission without a name")
+		}
+		if permissions[permission.Name] {
+			return fmt.Errorf("permission %q is declared twice", permission.Name)
+		}
+		permissions[permission.Name] = true
+	}
+
+	roleParents := make(map[string][]string)
+	for _, role := range m.Roles {
+		if role.Name == "" {
+			return errors.New("role without a name")
+		}
+		if _, ok := roleParents[role.Name]; ok {
+			return fmt.Errorf("role %q is declared twice", role.Name)
+		}
+		if err := checkUnique(fmt.Sprintf("role %q", role.Name), "permission", role.Permissions); err != nil {
+			return err
+		}
+		if err := checkUnique(fmt.Sprintf("role %q", role.Name), "parent", role.Parents); err != nil {
+			return err
+		}
+		roleParents[role.Name] = role.Parents
+	}
+	if err := checkHierarchy("role", roleParents); err != nil {
+		return err
+	}
+
+	menuParents := make(map[string][]string)
+	for _, menu := range m.Menus {
+		if menu.Name == "" {
+			return errors.New("menu without a name")
+		}
+		if _, ok := menuParents[menu.Name]; ok {
+			return fmt.Errorf("menu %q is declared twice", menu.Name)
+		}
+		if err := checkUnique(fmt.Sprintf("menu %q", menu.Name), "permission", menu.Permissions); err != nil {
+			return err
+		}
+		menuParents[menu.Name] = nil
+		if menu.Parent != "" {
+			menuParents[menu.Name] = []string{menu.Parent}
+		}
+	}
+	return checkHierarchy("menu", menuParents)
+}
+
+// checkUnique rejects a list that names the same entry twice
+func checkUnique(owner, kind string, names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("%s lists %s %q twice", owner, kind, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// checkHierarchy rejects parents that are not declared and parent chains that loop
+func checkHierarchy(kind string, parents map[string][]string) error {
+	for name, names := range parents {
+		for _, parent := range names {
+			if _, ok := parents[parent]; !ok {
+				return fmt.Errorf("%s %q has undeclared parent %q", kind, name, parent)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("%s hierarchy contains a cycle through %q", kind, name)
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		for _, parent := range parents[name] {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	for name := range parents {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
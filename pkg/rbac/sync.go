@@ -0,0 +1,532 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"usermanagement-api/domain/entities"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// menuModelType is the model_permissions type linking menus to the permissions that grant them
+const menuModelType = "menu"
+
+// Change actions reported by Sync
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionRestore = "restore"
+	ActionDelete  = "delete"
+	ActionGrant   = "grant"
+	ActionRevoke  = "revoke"
+)
+
+// Change is one difference between the database and the manifest
+type Change struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"` // permission, role or menu
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Options controls how Sync applies a manifest
+type Options struct {
+	// DryRun computes the changes without writing them
+	DryRun bool
+	// Prune deletes permissions, shared roles and shared menus that are not in the manifest
+	Prune bool
+	// ProtectedPermissions and ProtectedRoles are never pruned, typically the built-in
+	// permissions and the admin role
+	ProtectedPermissions []string
+	ProtectedRoles       []string
+}
+
+// errDryRun rolls back the sync transaction once the changes are known
+var errDryRun = errors.New("dry run")
+
+// Sync brings the database in line with the manifest in a single transaction and returns what
+// changed. Running it again with the same manifest reports no changes. Soft-deleted entries named
+// in the manifest are restored, and entries are only deleted when opts.Prune is set.
+func Sync(db *gorm.DB, manifest *Manifest, opts Options, zapLogger *zap.Logger) ([]Change, error) {
+	s := &syncer{
+		manifest:    manifest,
+		opts:        opts,
+		permissions: make(map[string]*entities.Permission),
+		roles:       make(map[string]*entities.Role),
+		menus:       make(map[string]*entities.Menu),
+		referenced:  make(map[string]bool),
+		changes:     []Change{},
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		s.tx = tx
+		if err := s.run(); err != nil {
+			return err
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	zapLogger.Info("Synced RBAC manifest",
+		zap.Int("changes", len(s.changes)),
+		zap.Bool("dry_run", opts.DryRun),
+		zap.Bool("prune", opts.Prune))
+	return s.changes, nil
+}
+
+type syncer struct {
+	tx          *gorm.DB
+	manifest    *Manifest
+	opts        Options
+	permissions map[string]*entities.Permission
+	roles       map[string]*entities.Role
+	menus       map[string]*entities.Menu
+	// referenced holds every permission name a role or menu of the manifest points at
+	referenced map[string]bool
+	changes    []Change
+}
+
+func (s *syncer) run() error {
+	if err := s.syncPermissions(); err != nil {
+		return err
+	}
+	if err := s.syncRoles(); err != nil {
+		return err
+	}
+	if err := s.syncMenus(); err != nil {
+		return err
+	}
+	if !s.opts.Prune {
+		return nil
+	}
+	return s.prune()
+}
+
+func (s *syncer) record(action, kind, name, detail string) {
+	s.changes = append(s.changes, Change{Action: action, Kind: kind, Name: name, Detail: detail})
+}
+
+func (s *syncer) syncPermissions() error {
+	for _, declared := range s.manifest.Permissions {
+		var permission entities.Permission
+		err := s.tx.Unscoped().Where("name = ?", declared.Name).First(&permission).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			permission = entities.Permission{Name: declared.Name, Description: declared.Description}
+			if err := s.tx.Create(&permission).Error; err != nil {
+				return err
+			}
+			s.record(ActionCreate, "permission", declared.Name, "")
+			s.permissions[declared.Name] = &permission
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		updates := make(map[string]interface{})
+		if permission.Description != declared.Description {
+			updates["description"] = declared.Description
+		}
+		if err := s.update(&permission, "permission", declared.Name, &permission.DeletedAt, updates); err != nil {
+			return err
+		}
+		s.permissions[declared.Name] = &permission
+	}
+	return nil
+}
+
+// update restores a soft-deleted entry and applies the changed columns, recording both
+func (s *syncer) update(model interface{}, kind, name string, deletedAt *gorm.DeletedAt, updates map[string]interface{}) error {
+	if len(updates) > 0 {
+		columns := make([]string, 0, len(updates))
+		for column := range updates {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		s.record(ActionUpdate, kind, name, strings.Join(columns, ", "))
+	}
+	if deletedAt.Valid {
+		updates["deleted_at"] = nil
+		*deletedAt = gorm.DeletedAt{}
+		s.record(ActionRestore, kind, name, "")
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return s.tx.Unscoped().Model(model).Updates(updates).Error
+}
+
+// permission resolves a permission a role or menu refers to, either declared by the manifest
+// or already present in the database
+func (s *syncer) permission(owner, name string) (*entities.Permission, error) {
+	s.referenced[name] = true
+	if permission, ok := s.permissions[name]; ok {
+		return permission, nil
+	}
+
+	var permission entities.Permission
+	err := s.tx.Where("name = ?", name).First(&permission).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%s refers to unknown permission %q", owner, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.permissions[name] = &permission
+	return &permission, nil
+}
+
+func (s *syncer) syncRoles() error {
+	// Create every role first so parents can refer to roles declared later in the manifest
+	for _, declared := range s.manifest.Roles {
+		var role entities.Role
+		err := s.tx.Unscoped().Where("name = ?", declared.Name).First(&role).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			role = entities.Role{Name: declared.Name, Description: declared.Description}
+			if err := s.tx.Create(&role).Error; err != nil {
+				return err
+			}
+			s.record(ActionCreate, "role", declared.Name, "")
+			s.roles[declared.Name] = &role
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if role.OrganizationID != nil {
+			return fmt.Errorf("role %q belongs to an organization and cannot be managed by the manifest", declared.Name)
+		}
+
+		updates := make(map[string]interface{})
+		if role.Description != declared.Description {
+			updates["description"] = declared.Description
+		}
+		if err := s.update(&role, "role", declared.Name, &role.DeletedAt, updates); err != nil {
+			return err
+		}
+		s.roles[declared.Name] = &role
+	}
+
+	for _, declared := range s.manifest.Roles {
+		role := s.roles[declared.Name]
+		owner := fmt.Sprintf("role %q", declared.Name)
+
+		var permissions []*entities.Permission
+		for _, name := range declared.Permissions {
+			permission, err := s.permission(owner, name)
+			if err != nil {
+				return err
+			}
+			permissions = append(permissions, permission)
+		}
+		if err := s.replacePermissions(role, declared.Name, permissions); err != nil {
+			return err
+		}
+
+		var parents []*entities.Role
+		for _, name := range declared.Parents {
+			parents = append(parents, s.roles[name])
+		}
+		if err := s.replaceParents(role, declared.Name, parents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syncer) replacePermissions(role *entities.Role, name string, permissions []*entities.Permission) error {
+	var current []*entities.Permission
+	if err := s.tx.Model(role).Association("Permissions").Find(&current); err != nil {
+		return err
+	}
+
+	granted, revoked := diffNames(permissionNames(current), permissionNames(permissions))
+	if len(granted) == 0 && len(revoked) == 0 {
+		return nil
+	}
+	for _, permission := range granted {
+		s.record(ActionGrant, "role", name, "permission "+permission)
+	}
+	for _, permission := range revoked {
+		s.record(ActionRevoke, "role", name, "permission "+permission)
+	}
+	return s.tx.Model(role).Omit("Permissions.*").Association("Permissions").Replace(permissions)
+}
+
+func (s *syncer) replaceParents(role *entities.Role, name string, parents []*entities.Role) error {
+	var current []*entities.Role
+	if err := s.tx.Model(role).Association("Parents").Find(&current); err != nil {
+		return err
+	}
+
+	added, removed := diffNames(roleNames(current), roleNames(parents))
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	for _, parent := range added {
+		s.record(ActionGrant, "role", name, "parent "+parent)
+	}
+	for _, parent := range removed {
+		s.record(ActionRevoke, "role", name, "parent "+parent)
+	}
+	// Same lock as RoleRepository.AssignParents, so the API cannot close a cycle alongside the sync
+	if err := s.tx.Exec("LOCK TABLE role_parents IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		return err
+	}
+	return s.tx.Model(role).Omit("Parents.*").Association("Parents").Replace(parents)
+}
+
+func (s *syncer) syncMenus() error {
+	declaredMenus := make(map[string]Menu)
+	for _, declared := range s.manifest.Menus {
+		declaredMenus[declared.Name] = declared
+	}
+
+	// Parents are synced before their children so the parent id is known
+	var visit func(name string) error
+	visit = func(name string) error {
+		if _, ok := s.menus[name]; ok {
+			return nil
+		}
+		declared := declaredMenus[name]
+		var parentID *uuid.UUID
+		if declared.Parent != "" {
+			if err := visit(declared.Parent); err != nil {
+				return err
+			}
+			parentID = &s.menus[declared.Parent].ID
+		}
+		return s.syncMenu(declared, parentID)
+	}
+	for _, declared := range s.manifest.Menus {
+		if err := visit(declared.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, declared := range s.manifest.Menus {
+		owner := fmt.Sprintf("menu %q", declared.Name)
+		var permissions []*entities.Permission
+		for _, name := range declared.Permissions {
+			permission, err := s.permission(owner, name)
+			if err != nil {
+				return err
+			}
+			permissions = append(permissions, permission)
+		}
+		if err := s.replaceMenuPermissions(s.menus[declared.Name], permissions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syncer) syncMenu(declared Menu, parentID *uuid.UUID) error {
+	isActive := declared.IsActive == nil || *declared.IsActive
+	isVisible := declared.IsVisible == nil || *declared.IsVisible
+
+	var menu entities.Menu
+	err := s.tx.Unscoped().Where("name = ?", declared.Name).First(&menu).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		menu = entities.Menu{
+			Name:        declared.Name,
+			Url:         declared.Url,
+			Icon:        declared.Icon,
+			Description: declared.Description,
+			ParentID:    parentID,
+			Sequence:    declared.Sequence,
+		}
+		if err := s.tx.Create(&menu).Error; err != nil {
+			return err
+		}
+		// The flags default to true on insert, so false has to be written separately
+		if !isActive || !isVisible {
+			flags := map[string]interface{}{"is_active": isActive, "is_visible": isVisible}
+			if err := s.tx.Model(&menu).Updates(flags).Error; err != nil {
+				return err
+			}
+		}
+		s.record(ActionCreate, "menu", declared.Name, "")
+		s.menus[declared.Name] = &menu
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if menu.OrganizationID != nil {
+		return fmt.Errorf("menu %q belongs to an organization and cannot be managed by the manifest", declared.Name)
+	}
+
+	updates := make(map[string]interface{})
+	if menu.Url != declared.Url {
+		updates["url"] = declared.Url
+	}
+	if menu.Icon != declared.Icon {
+		updates["icon"] = declared.Icon
+	}
+	if menu.Description != declared.Description {
+		updates["description"] = declared.Description
+	}
+	if menu.Sequence != declared.Sequence {
+		updates["sequence"] = declared.Sequence
+	}
+	if menu.IsActive != isActive {
+		updates["is_active"] = isActive
+	}
+	if menu.IsVisible != isVisible {
+		updates["is_visible"] = isVisible
+	}
+	if (menu.ParentID == nil) != (parentID == nil) || (parentID != nil && *menu.ParentID != *parentID) {
+		updates["parent_id"] = parentID
+	}
+	if err := s.update(&menu, "menu", declared.Name, &menu.DeletedAt, updates); err != nil {
+		return err
+	}
+	s.menus[declared.Name] = &menu
+	return nil
+}
+
+func (s *syncer) replaceMenuPermissions(menu *entities.Menu, permissions []*entities.Permission) error {
+	var links []*entities.ModelPermission
+	err := s.tx.Preload("Permission").
+		Where("model_type = ? AND model_id = ?", menuModelType, menu.ID).
+		Find(&links).Error
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[uuid.UUID]bool)
+	for _, permission := range permissions {
+		wanted[permission.ID] = true
+	}
+	linked := make(map[uuid.UUID]bool)
+	for _, link := range links {
+		if wanted[link.PermissionID] && !linked[link.PermissionID] {
+			linked[link.PermissionID] = true
+			continue
+		}
+		// Links to permissions no longer listed, and duplicate links, are removed
+		if err := s.tx.Delete(link).Error; err != nil {
+			return err
+		}
+		if !wanted[link.PermissionID] {
+			s.record(ActionRevoke, "menu", menu.Name, "permission "+link.Permission.Name)
+		}
+	}
+
+	for _, permission := range permissions {
+		if linked[permission.ID] {
+			continue
+		}
+		linked[permission.ID] = true
+		link := &entities.ModelPermission{ModelID: menu.ID, ModelType: menuModelType, PermissionID: permission.ID}
+		if err := s.tx.Omit("Permission").Create(link).Error; err != nil {
+			return err
+		}
+		s.record(ActionGrant, "menu", menu.Name, "permission "+permission.Name)
+	}
+	return nil
+}
+
+// prune deletes shared menus, shared roles and permissions the manifest does not mention.
+// Permissions a role or menu of the manifest refers to are kept.
+func (s *syncer) prune() error {
+	var menus []*entities.Menu
+	if err := s.tx.Where("organization_id IS NULL").Order("name").Find(&menus).Error; err != nil {
+		return err
+	}
+	for _, menu := range menus {
+		if _, ok := s.menus[menu.Name]; ok {
+			continue
+		}
+		if err := s.tx.Delete(menu).Error; err != nil {
+			return err
+		}
+		s.record(ActionDelete, "menu", menu.Name, "")
+	}
+
+	protectedRoles := make(map[string]bool)
+	for _, name := range s.opts.ProtectedRoles {
+		protectedRoles[name] = true
+	}
+	var roles []*entities.Role
+	if err := s.tx.Where("organization_id IS NULL").Order("name").Find(&roles).Error; err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if _, ok := s.roles[role.Name]; ok || protectedRoles[role.Name] {
+			continue
+		}
+		if err := s.tx.Delete(role).Error; err != nil {
+			return err
+		}
+		s.record(ActionDelete, "role", role.Name, "")
+	}
+
+	keep := make(map[string]bool)
+	for _, name := range s.opts.ProtectedPermissions {
+		keep[name] = true
+	}
+	var permissions []*entities.Permission
+	if err := s.tx.Order("name").Find(&permissions).Error; err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		if _, ok := s.permissions[permission.Name]; ok || keep[permission.Name] || s.referenced[permission.Name] {
+			continue
+		}
+		if err := s.tx.Delete(permission).Error; err != nil {
+			return err
+		}
+		s.record(ActionDelete, "permission", permission.Name, "")
+	}
+	return nil
+}
+
+// diffNames returns the sorted names in wanted but not current, and in current but not wanted
+func diffNames(current, wanted []string) (added, removed []string) {
+	currentSet := make(map[string]bool)
+	for _, name := range current {
+		currentSet[name] = true
+	}
+	wantedSet := make(map[string]bool)
+	for _, name := range wanted {
+		wantedSet[name] = true
+		if !currentSet[name] {
+			added = append(added, name)
+		}
+	}
+	for _, name := range current {
+		if !wantedSet[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func permissionNames(permissions []*entities.Permission) []string {
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
+
+func roleNames(roles []*entities.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
@@ -0,0 +1,63 @@
+// pkg/requestctx/requestctx.go
+package requestctx
+
+import (
+	"context"
+	"usermanagement-api/pkg/permission"
+
+	"github.com/google/uuid"
+)
+
+type contextKey struct{}
+
+// Info describes who made a request and from where, for use below the HTTP layer
+type Info struct {
+	UserID         *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	IP             string
+	UserAgent      string
+	RequestID      string
+	IsSuperuser    bool
+	// Permissions is the caller's effective permission set, nil for anonymous requests
+	Permissions *permission.Set
+	// Denied holds the caller's explicit denies, they override every grant
+	Denied *permission.Set
+	// OrganizationID is the active tenant from the token, nil when the caller has none
+	OrganizationID *uuid.UUID
+}
+
+// Can reports whether the caller holds the named permission. Superusers hold every permission
+// that is not explicitly denied to them.
+func (i *Info) Can(name string) bool {
+	if i.Denied != nil && i.Denied.Has(name) {
+		return false
+	}
+	return i.IsSuperuser || (i.Permissions != nil && i.Permissions.Has(name))
+}
+
+// IsUser reports whether the caller is the given user
+func (i *Info) IsUser(userID uuid.UUID) bool {
+	return i.UserID != nil && *i.UserID == userID
+}
+
+// TenantScoped reports whether data access should be limited to the caller's active
+// organization. Anonymous and background requests and superusers are not scoped.
+func (i *Info) TenantScoped() bool {
+	return i.UserID != nil && !i.IsSuperuser
+}
+
+// WithInfo returns a copy of ctx carrying info
+func WithInfo(ctx context.Context, info *Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the request info stored in ctx, or an empty Info when there is none
+// (background jobs, CLI commands).
+func FromContext(ctx context.Context) *Info {
+	if ctx != nil {
+		if info, ok := ctx.Value(contextKey{}).(*Info); ok && info != nil {
+			return info
+		}
+	}
+	return &Info{}
+}